@@ -15,6 +15,10 @@ import (
 // @in header
 // @name Authorization
 // @description Token JWT en formato Bearer. Ejemplo: "Bearer {token}"
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key personal (vk_...). Solo da acceso a las rutas cubiertas por sus scopes
 func main() {
 	logger := logrus.New()
 	//err := godotenv.Load(".env")
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+)
+
+// AdminUserResponse is a user as listed to admins. Credentials and secrets
+// are never included.
+type AdminUserResponse struct {
+	ID               uint       `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	CreatedAt        time.Time  `json:"created_at"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	DisabledAt       *time.Time `json:"disabled_at"`
+}
+
+func NewAdminUserResponse(user *models.User) AdminUserResponse {
+	return AdminUserResponse{
+		ID:               user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		Role:             user.Role,
+		CreatedAt:        user.CreatedAt,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
+		DisabledAt:       user.DisabledAt,
+	}
+}
+
+type AdminUserListResponse struct {
+	Items      []AdminUserResponse `json:"items"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalItems int64               `json:"total_items"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+type AdminAuditLogResponse struct {
+	Items      []models.AdminAuditEntry `json:"items"`
+	Page       int                      `json:"page"`
+	PageSize   int                      `json:"page_size"`
+	TotalItems int64                    `json:"total_items"`
+	TotalPages int                      `json:"total_pages"`
+}
+
+// ImpersonationResponse carries tokens that act as User. They belong to a
+// session marked as opened by the admin.
+type ImpersonationResponse struct {
+	Token        string            `json:"token"`
+	RefreshToken string            `json:"refresh_token"`
+	User         AdminUserResponse `json:"user"`
+}
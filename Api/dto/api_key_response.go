@@ -0,0 +1,24 @@
+package dto
+
+import "pruebaVertice/Api/models"
+
+// ApiKeyCreatedResponse is the only response that includes the key itself.
+type ApiKeyCreatedResponse struct {
+	models.ApiKey
+	Key string `json:"key"`
+}
+
+// OAuthClientCreatedResponse is the only response that includes the client
+// secret.
+type OAuthClientCreatedResponse struct {
+	models.OAuthClient
+	ClientSecret string `json:"client_secret"`
+}
+
+// OAuthTokenResponse follows RFC 6749 section 5.1.
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "pruebaVertice/Api/models"
+
+type OrderListResponse struct {
+	Items      []models.Order `json:"items"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	TotalItems int64          `json:"total_items"`
+	TotalPages int            `json:"total_pages"`
+}
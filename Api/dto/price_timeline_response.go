@@ -0,0 +1,12 @@
+package dto
+
+import "pruebaVertice/Api/models"
+
+type PriceTimelineResponse struct {
+	ProductID    uint                          `json:"product_id"`
+	CurrentPrice float64                       `json:"current_price"`
+	RegularPrice float64                       `json:"regular_price"`
+	OnSale       bool                          `json:"on_sale"`
+	History      []models.PriceHistory         `json:"history"`
+	Upcoming     []models.ScheduledPriceChange `json:"upcoming"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "pruebaVertice/Api/models"
+
+type SalesReportResponse struct {
+	From              string               `json:"from"`
+	To                string               `json:"to"`
+	Interval          string               `json:"interval"`
+	Revenue           float64              `json:"revenue"`
+	Shipping          float64              `json:"shipping"`
+	Orders            int64                `json:"orders"`
+	Units             int64                `json:"units"`
+	AverageOrderValue float64              `json:"average_order_value"`
+	Periods           []models.SalesPeriod `json:"periods"`
+}
+
+type ProductSalesReportResponse struct {
+	From  string                `json:"from"`
+	To    string                `json:"to"`
+	Items []models.ProductSales `json:"items"`
+}
+
+type CustomerSalesReportResponse struct {
+	From  string                 `json:"from"`
+	To    string                 `json:"to"`
+	Items []models.CustomerSales `json:"items"`
+}
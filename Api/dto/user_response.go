@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+)
+
+// UserResponse is the user as shown to the user themselves. Handlers must
+// never serialize models.User directly: it carries the password hash, the
+// stored tokens and the TOTP secret.
+type UserResponse struct {
+	ID                 uint       `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	Role               string     `json:"role"`
+	CreatedAt          time.Time  `json:"created_at"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+}
+
+func NewUserResponse(user *models.User) UserResponse {
+	return UserResponse{
+		ID:                 user.ID,
+		Username:           user.Username,
+		Email:              user.Email,
+		Role:               user.Role,
+		CreatedAt:          user.CreatedAt,
+		EmailVerifiedAt:    user.EmailVerifiedAt,
+		TwoFactorEnabledAt: user.TwoFactorEnabledAt,
+	}
+}
+
+// UserSessionResponse is a user together with the tokens of a session that
+// was just opened for them, as on registration or after an email change.
+// The tokens are omitted when no session was opened.
+type UserSessionResponse struct {
+	UserResponse
+	*LoginResponse
+}
+
+func NewUserSessionResponse(user *models.User) UserSessionResponse {
+	res := UserSessionResponse{UserResponse: NewUserResponse(user)}
+	if user.Token != "" {
+		res.LoginResponse = &LoginResponse{Token: user.Token, RefreshToken: user.RefreshToken}
+	}
+	return res
+}
@@ -0,0 +1,253 @@
+package address
+
+import (
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_address "pruebaVertice/Api/services/address"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type AddressHandler struct {
+	addressService services_address.AddressService
+	userService    services_user.UserService
+	logger         *logrus.Logger
+}
+
+func NewAddressHandler(addressService services_address.AddressService, userService services_user.UserService, logger *logrus.Logger) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+		userService:    userService,
+		logger:         logger,
+	}
+}
+
+// ListAddresses godoc
+// @Summary Listar direcciones del usuario
+// @Description Devuelve la libreta de direcciones del usuario autenticado, con la dirección por defecto primero
+// @Tags Addresses
+// @Produce json
+// @Success 200 {array} models.Address
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/addresses [get]
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	user, ok := h.currentUser(c, "ListAddresses")
+	if !ok {
+		return
+	}
+
+	addresses, err := h.addressService.ListAddresses(user.ID)
+	if err != nil {
+		h.logger.Error("Layer: addressHandler, Method: ListAddresses, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+// GetAddress godoc
+// @Summary Obtener una dirección
+// @Description Devuelve una dirección del usuario autenticado
+// @Tags Addresses
+// @Produce json
+// @Param id path int true "ID de la dirección"
+// @Success 200 {object} models.Address
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/addresses/{id} [get]
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	id, ok := h.addressID(c, "GetAddress")
+	if !ok {
+		return
+	}
+	user, ok := h.currentUser(c, "GetAddress")
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.GetAddress(user.ID, id)
+	if err != nil {
+		h.logger.Error("Layer: addressHandler, Method: GetAddress, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// CreateAddress godoc
+// @Summary Crear una dirección
+// @Description Agrega una dirección a la libreta del usuario. La primera dirección queda como dirección por defecto
+// @Tags Addresses
+// @Accept json
+// @Produce json
+// @Param address body models.AddressRequest true "Datos de la dirección"
+// @Success 201 {object} models.Address
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/addresses [post]
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var req models.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: addressHandler, Method: CreateAddress, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "CreateAddress")
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.CreateAddress(user.ID, req)
+	if err != nil {
+		h.logger.Error("Layer: addressHandler, Method: CreateAddress, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// UpdateAddress godoc
+// @Summary Actualizar una dirección
+// @Description Reemplaza los datos de una dirección del usuario autenticado
+// @Tags Addresses
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la dirección"
+// @Param address body models.AddressRequest true "Datos de la dirección"
+// @Success 200 {object} models.Address
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/addresses/{id} [put]
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	id, ok := h.addressID(c, "UpdateAddress")
+	if !ok {
+		return
+	}
+	var req models.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: addressHandler, Method: UpdateAddress, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "UpdateAddress")
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(user.ID, id, req)
+	if err != nil {
+		h.logger.Error("Layer: addressHandler, Method: UpdateAddress, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress godoc
+// @Summary Eliminar una dirección
+// @Description Elimina una dirección del usuario autenticado. Las órdenes ya creadas conservan su copia de la dirección. Si era la dirección predeterminada, la más antigua de las restantes pasa a serlo
+// @Tags Addresses
+// @Produce json
+// @Param id path int true "ID de la dirección"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/addresses/{id} [delete]
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	id, ok := h.addressID(c, "DeleteAddress")
+	if !ok {
+		return
+	}
+	user, ok := h.currentUser(c, "DeleteAddress")
+	if !ok {
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(user.ID, id); err != nil {
+		h.logger.Error("Layer: addressHandler, Method: DeleteAddress, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Address deleted successfully"})
+}
+
+// SetDefaultAddress godoc
+// @Summary Marcar dirección por defecto
+// @Description Marca la dirección como la dirección por defecto del usuario autenticado
+// @Tags Addresses
+// @Produce json
+// @Param id path int true "ID de la dirección"
+// @Success 200 {object} models.Address
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/addresses/{id}/default [post]
+func (h *AddressHandler) SetDefaultAddress(c *gin.Context) {
+	id, ok := h.addressID(c, "SetDefaultAddress")
+	if !ok {
+		return
+	}
+	user, ok := h.currentUser(c, "SetDefaultAddress")
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.SetDefaultAddress(user.ID, id)
+	if err != nil {
+		h.logger.Error("Layer: addressHandler, Method: SetDefaultAddress, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+func (h *AddressHandler) addressID(c *gin.Context, method string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: addressHandler, Method: "+method+", Error: invalid address ID:", err)
+		problem.Respond(c, problem.InvalidID("address"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *AddressHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: addressHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, err)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,130 @@
+package address
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_address "pruebaVertice/Api/services/address"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newUserMock() *UserServiceMock {
+	return &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+}
+
+func TestListAddresses_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	addresses := []models.Address{{ID: 1, UserID: 2, Label: "Casa", IsDefault: true}}
+	serviceMock := &AddressServiceMock{}
+	serviceMock.On("ListAddresses", uint(2)).Return(addresses, nil)
+	h := NewAddressHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me/addresses", nil)
+	c.Set("userEmail", "user@example.com")
+
+	h.ListAddresses(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp []models.Address
+	err := json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, addresses, resp)
+}
+
+func TestListAddresses_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewAddressHandler(&AddressServiceMock{}, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me/addresses", nil)
+
+	h.ListAddresses(c)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestCreateAddress_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.AddressRequest{Label: "Casa", PostalAddress: models.PostalAddress{Recipient: "Ana", Line1: "Calle 1", City: "Santiago", PostalCode: "8320000", Country: "CL"}}
+	created := &models.Address{ID: 1, UserID: 2, Label: "Casa", IsDefault: true, PostalAddress: req.PostalAddress}
+	serviceMock := &AddressServiceMock{}
+	serviceMock.On("CreateAddress", uint(2), req).Return(created, nil)
+	h := NewAddressHandler(serviceMock, newUserMock(), logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/me/addresses", bytes.NewReader(body))
+	c.Set("userEmail", "user@example.com")
+
+	h.CreateAddress(c)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var resp models.Address
+	err := json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ana", resp.Recipient)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestCreateAddress_Invalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &AddressServiceMock{}
+	serviceMock.On("CreateAddress", uint(2), models.AddressRequest{}).Return(nil, services_address.ErrInvalidAddress)
+	h := NewAddressHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/me/addresses", bytes.NewReader([]byte(`{}`)))
+	c.Set("userEmail", "user@example.com")
+
+	h.CreateAddress(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDeleteAddress_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &AddressServiceMock{}
+	serviceMock.On("DeleteAddress", uint(2), uint(7)).Return(services_address.ErrAddressNotFound)
+	h := NewAddressHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/me/addresses/7", nil)
+	c.Set("userEmail", "user@example.com")
+
+	h.DeleteAddress(c)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSetDefaultAddress_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewAddressHandler(&AddressServiceMock{}, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/me/addresses/abc/default", nil)
+	c.Set("userEmail", "user@example.com")
+
+	h.SetDefaultAddress(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,77 @@
+package address
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AddressServiceMock is a mock implementation of services_address.AddressService
+// for handler tests.
+type AddressServiceMock struct {
+	mock.Mock
+}
+
+func (m *AddressServiceMock) ListAddresses(userID uint) ([]models.Address, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressServiceMock) GetAddress(userID, id uint) (*models.Address, error) {
+	args := m.Called(userID, id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressServiceMock) CreateAddress(userID uint, req models.AddressRequest) (*models.Address, error) {
+	args := m.Called(userID, req)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressServiceMock) UpdateAddress(userID, id uint, req models.AddressRequest) (*models.Address, error) {
+	args := m.Called(userID, id, req)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressServiceMock) DeleteAddress(userID, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
+
+func (m *AddressServiceMock) SetDefaultAddress(userID, id uint) (*models.Address, error) {
+	args := m.Called(userID, id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UserServiceMock is a mock implementation of services_user.UserService.
+// Only GetUserByEmail is needed here.
+type UserServiceMock struct {
+	GetUserEmailFn func(email string) (*models.User, error)
+}
+
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
+func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
+	return m.GetUserEmailFn(email)
+}
@@ -0,0 +1,306 @@
+package apikeys
+
+import (
+	"errors"
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_apikey "pruebaVertice/Api/services/apikey"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ApiKeysHandler struct {
+	apiKeyService services_apikey.ApiKeyService
+	userService   services_user.UserService
+	logger        *logrus.Logger
+}
+
+func NewApiKeysHandler(apiKeyService services_apikey.ApiKeyService, userService services_user.UserService, logger *logrus.Logger) *ApiKeysHandler {
+	return &ApiKeysHandler{
+		apiKeyService: apiKeyService,
+		userService:   userService,
+		logger:        logger,
+	}
+}
+
+// ListApiKeys godoc
+// @Summary Listar API keys
+// @Description Devuelve las API keys del usuario autenticado, incluidas las revocadas. La clave completa nunca se vuelve a mostrar
+// @Tags ApiKeys
+// @Produce json
+// @Success 200 {array} models.ApiKey
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/api-keys [get]
+func (h *ApiKeysHandler) ListApiKeys(c *gin.Context) {
+	user, ok := h.currentUser(c, "ListApiKeys")
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys(user)
+	if err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: ListApiKeys, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateApiKey godoc
+// @Summary Crear una API key
+// @Description Crea una API key con los scopes indicados. La clave solo se devuelve en esta respuesta; se envía en la cabecera X-API-Key o como Bearer
+// @Tags ApiKeys
+// @Accept json
+// @Produce json
+// @Param key body models.ApiKeyRequest true "Nombre, scopes y caducidad"
+// @Success 201 {object} dto.ApiKeyCreatedResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/api-keys [post]
+func (h *ApiKeysHandler) CreateApiKey(c *gin.Context) {
+	var req models.ApiKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: CreateApiKey, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "CreateApiKey")
+	if !ok {
+		return
+	}
+
+	created, err := h.apiKeyService.CreateKey(user, req)
+	if err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: CreateApiKey, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// RevokeApiKey godoc
+// @Summary Revocar una API key
+// @Description Revoca una API key del usuario autenticado. Deja de aceptarse de inmediato
+// @Tags ApiKeys
+// @Produce json
+// @Param id path int true "ID de la API key"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/api-keys/{id} [delete]
+func (h *ApiKeysHandler) RevokeApiKey(c *gin.Context) {
+	id, ok := h.pathID(c, "RevokeApiKey")
+	if !ok {
+		return
+	}
+	user, ok := h.currentUser(c, "RevokeApiKey")
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(user, id); err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: RevokeApiKey, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+// ListOAuthClients godoc
+// @Summary Listar clientes OAuth
+// @Description Devuelve los clientes OAuth del usuario autenticado, incluidos los revocados
+// @Tags ApiKeys
+// @Produce json
+// @Success 200 {array} models.OAuthClient
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/oauth-clients [get]
+func (h *ApiKeysHandler) ListOAuthClients(c *gin.Context) {
+	user, ok := h.currentUser(c, "ListOAuthClients")
+	if !ok {
+		return
+	}
+
+	clients, err := h.apiKeyService.ListClients(user)
+	if err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: ListOAuthClients, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients)
+}
+
+// CreateOAuthClient godoc
+// @Summary Crear un cliente OAuth
+// @Description Crea un cliente para el grant client_credentials. El client_secret solo se devuelve en esta respuesta
+// @Tags ApiKeys
+// @Accept json
+// @Produce json
+// @Param client body models.OAuthClientRequest true "Nombre y scopes"
+// @Success 201 {object} dto.OAuthClientCreatedResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/oauth-clients [post]
+func (h *ApiKeysHandler) CreateOAuthClient(c *gin.Context) {
+	var req models.OAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: CreateOAuthClient, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "CreateOAuthClient")
+	if !ok {
+		return
+	}
+
+	created, err := h.apiKeyService.CreateClient(user, req)
+	if err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: CreateOAuthClient, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// RevokeOAuthClient godoc
+// @Summary Revocar un cliente OAuth
+// @Description Revoca un cliente OAuth. Sus access tokens dejan de aceptarse de inmediato
+// @Tags ApiKeys
+// @Produce json
+// @Param id path int true "ID del cliente"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/oauth-clients/{id} [delete]
+func (h *ApiKeysHandler) RevokeOAuthClient(c *gin.Context) {
+	id, ok := h.pathID(c, "RevokeOAuthClient")
+	if !ok {
+		return
+	}
+	user, ok := h.currentUser(c, "RevokeOAuthClient")
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.RevokeClient(user, id); err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: RevokeOAuthClient, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "OAuth client revoked"})
+}
+
+// IssueToken godoc
+// @Summary Obtener un access token OAuth2
+// @Description Grant client_credentials (RFC 6749, sección 4.4). El cliente se autentica con HTTP Basic o con client_id y client_secret en el formulario. Los errores siguen el formato OAuth2
+// @Tags ApiKeys
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "client_credentials"
+// @Param client_id formData string false "ID del cliente"
+// @Param client_secret formData string false "Secreto del cliente"
+// @Param scope formData string false "Scopes separados por espacios"
+// @Success 200 {object} dto.OAuthTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/oauth/token [post]
+func (h *ApiKeysHandler) IssueToken(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req models.OAuthTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: IssueToken, Error:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "missing or invalid request parameters"})
+		return
+	}
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = id, secret
+	}
+
+	token, err := h.apiKeyService.IssueToken(req)
+	if err != nil {
+		status, code := oauthError(err)
+		// Only client errors explain themselves; the cause of a server
+		// error stays in the log under the request's trace ID.
+		description := err.Error()
+		if status >= http.StatusInternalServerError {
+			h.logger.Errorf("Layer: apiKeysHandler, Method: IssueToken, TraceID: %s, Error: %v", problem.TraceID(c), err)
+			description = "internal server error"
+		} else {
+			h.logger.Warn("Layer: apiKeysHandler, Method: IssueToken, Error:", err)
+		}
+		if status == http.StatusUnauthorized {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		c.JSON(status, gin.H{"error": code, "error_description": description})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *ApiKeysHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, err)
+		return nil, false
+	}
+	return user, true
+}
+
+func (h *ApiKeysHandler) pathID(c *gin.Context, method string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: apiKeysHandler, Method: "+method+", Error: invalid ID:", err)
+		problem.Respond(c, problem.InvalidID(""))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// oauthError maps a token endpoint failure onto its RFC 6749 section 5.2
+// error code.
+func oauthError(err error) (int, string) {
+	switch {
+	case errors.Is(err, services_apikey.ErrInvalidClient):
+		return http.StatusUnauthorized, "invalid_client"
+	case errors.Is(err, services_apikey.ErrUnsupportedGrantType):
+		return http.StatusBadRequest, "unsupported_grant_type"
+	case errors.Is(err, services_apikey.ErrInvalidScope):
+		return http.StatusBadRequest, "invalid_scope"
+	default:
+		return http.StatusInternalServerError, "server_error"
+	}
+}
@@ -0,0 +1,188 @@
+package apikeys
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_apikey "pruebaVertice/Api/services/apikey"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var owner = &models.User{Model: gorm.Model{ID: 2}, Email: "user@example.com"}
+
+func newUserMock() *UserServiceMock {
+	return &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return owner, nil
+		},
+	}
+}
+
+func TestCreateApiKey_ReturnsKeyOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.ApiKeyRequest{Name: "ERP", Scopes: []string{models.ScopeProductsRead}}
+	serviceMock := &ApiKeyServiceMock{}
+	serviceMock.On("CreateKey", owner, req).Return(&dto.ApiKeyCreatedResponse{
+		ApiKey: models.ApiKey{ID: 1, Name: "ERP", Prefix: "vk_abcd1234", KeyHash: "hash"},
+		Key:    "vk_abcd1234_secret",
+	}, nil)
+	h := NewApiKeysHandler(serviceMock, newUserMock(), logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/me/api-keys", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userEmail", owner.Email)
+
+	h.CreateApiKey(c)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"key":"vk_abcd1234_secret"`)
+	assert.NotContains(t, rec.Body.String(), "hash")
+}
+
+func TestCreateApiKey_InvalidScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.ApiKeyRequest{Name: "ERP", Scopes: []string{"admin"}}
+	serviceMock := &ApiKeyServiceMock{}
+	serviceMock.On("CreateKey", owner, req).Return(nil, services_apikey.ErrInvalidScope)
+	h := NewApiKeysHandler(serviceMock, newUserMock(), logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/me/api-keys", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userEmail", owner.Email)
+
+	h.CreateApiKey(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestRevokeApiKey_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ApiKeyServiceMock{}
+	serviceMock.On("RevokeKey", owner, uint(9)).Return(services_apikey.ErrApiKeyNotFound)
+	h := NewApiKeysHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/me/api-keys/9", nil)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	c.Set("userEmail", owner.Email)
+
+	h.RevokeApiKey(c)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestListOAuthClients_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewApiKeysHandler(&ApiKeyServiceMock{}, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me/oauth-clients", nil)
+
+	h.ListOAuthClients(c)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestIssueToken_BasicAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ApiKeyServiceMock{}
+	serviceMock.On("IssueToken", models.OAuthTokenRequest{
+		GrantType: "client_credentials", ClientID: "vc_1", ClientSecret: "s3cret", Scope: "warehouse:read",
+	}).Return(&dto.OAuthTokenResponse{AccessToken: "tok", TokenType: "Bearer", ExpiresIn: 3600, Scope: "warehouse:read"}, nil)
+	h := NewApiKeysHandler(serviceMock, newUserMock(), logrus.New())
+
+	form := url.Values{"grant_type": {"client_credentials"}, "scope": {"warehouse:read"}}
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Request.SetBasicAuth("vc_1", "s3cret")
+
+	h.IssueToken(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	var resp dto.OAuthTokenResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "tok", resp.AccessToken)
+}
+
+func TestIssueToken_OAuthErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"invalid client", services_apikey.ErrInvalidClient, http.StatusUnauthorized, "invalid_client"},
+		{"unsupported grant", services_apikey.ErrUnsupportedGrantType, http.StatusBadRequest, "unsupported_grant_type"},
+		{"invalid scope", services_apikey.ErrInvalidScope, http.StatusBadRequest, "invalid_scope"},
+		{"server error", errors.New("dial tcp 10.0.0.5:3306: connection refused"), http.StatusInternalServerError, "server_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			serviceMock := &ApiKeyServiceMock{}
+			serviceMock.On("IssueToken", mock.Anything).Return(nil, tt.err)
+			h := NewApiKeysHandler(serviceMock, newUserMock(), logrus.New())
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader("grant_type=x"))
+			c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			h.IssueToken(c)
+
+			assert.Equal(t, tt.status, rec.Code)
+			var resp map[string]string
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.code, resp["error"])
+			if tt.status >= http.StatusInternalServerError {
+				assert.Equal(t, "internal server error", resp["error_description"])
+			} else {
+				assert.Equal(t, tt.err.Error(), resp["error_description"])
+			}
+		})
+	}
+}
+
+func TestIssueToken_InvalidRequestHidesBindingDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ApiKeyServiceMock{}
+	h := NewApiKeysHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(`{"grant_type":`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.IssueToken(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp map[string]string
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "invalid_request", resp["error"])
+	assert.Equal(t, "missing or invalid request parameters", resp["error_description"])
+	serviceMock.AssertNotCalled(t, "IssueToken", mock.Anything)
+}
@@ -0,0 +1,100 @@
+package apikeys
+
+import (
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/utils/jwt"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ApiKeyServiceMock is a mock implementation of services_apikey.ApiKeyService
+// for handler tests.
+type ApiKeyServiceMock struct {
+	mock.Mock
+}
+
+func (m *ApiKeyServiceMock) CreateKey(user *models.User, req models.ApiKeyRequest) (*dto.ApiKeyCreatedResponse, error) {
+	args := m.Called(user, req)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.ApiKeyCreatedResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeyServiceMock) ListKeys(user *models.User) ([]models.ApiKey, error) {
+	args := m.Called(user)
+	if res := args.Get(0); res != nil {
+		return res.([]models.ApiKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeyServiceMock) RevokeKey(user *models.User, id uint) error {
+	args := m.Called(user, id)
+	return args.Error(0)
+}
+
+func (m *ApiKeyServiceMock) CreateClient(user *models.User, req models.OAuthClientRequest) (*dto.OAuthClientCreatedResponse, error) {
+	args := m.Called(user, req)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.OAuthClientCreatedResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeyServiceMock) ListClients(user *models.User) ([]models.OAuthClient, error) {
+	args := m.Called(user)
+	if res := args.Get(0); res != nil {
+		return res.([]models.OAuthClient), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeyServiceMock) RevokeClient(user *models.User, id uint) error {
+	args := m.Called(user, id)
+	return args.Error(0)
+}
+
+func (m *ApiKeyServiceMock) IssueToken(req models.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	args := m.Called(req)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.OAuthTokenResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeyServiceMock) AuthenticateAPIKey(key string) (*jwt.Principal, error) {
+	args := m.Called(key)
+	if res := args.Get(0); res != nil {
+		return res.(*jwt.Principal), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeyServiceMock) AuthenticateClientToken(token string) (*jwt.Principal, error) {
+	args := m.Called(token)
+	if res := args.Get(0); res != nil {
+		return res.(*jwt.Principal), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UserServiceMock is a mock implementation of services_user.UserService.
+// Only GetUserByEmail is needed here.
+type UserServiceMock struct {
+	GetUserEmailFn func(email string) (*models.User, error)
+}
+
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
+func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
+	return m.GetUserEmailFn(email)
+}
@@ -0,0 +1,85 @@
+package invoices
+
+import (
+	"net/http"
+	services_invoice "pruebaVertice/Api/services/invoice"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/problem"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+var errInvalidFormat = apperr.BadRequest("invalid_format", "Invalid format, use pdf or html")
+
+type InvoicesHandler struct {
+	invoiceService services_invoice.InvoiceService
+	userService    services_user.UserService
+	logger         *logrus.Logger
+}
+
+func NewInvoicesHandler(invoiceService services_invoice.InvoiceService, userService services_user.UserService, logger *logrus.Logger) *InvoicesHandler {
+	return &InvoicesHandler{
+		invoiceService: invoiceService,
+		userService:    userService,
+		logger:         logger,
+	}
+}
+
+// GetInvoice godoc
+// @Summary Descargar factura de una orden
+// @Description Devuelve la factura de una orden del usuario autenticado en PDF (por defecto) o HTML. La factura se emite con número correlativo en la primera descarga y las siguientes devuelven el mismo documento
+// @Tags Orders
+// @Produce application/pdf
+// @Produce text/html
+// @Param id path int true "ID de la orden"
+// @Param format query string false "Formato del documento (pdf, html)"
+// @Success 200 {file} file
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/orders/{id}/invoice [get]
+func (h *InvoicesHandler) GetInvoice(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: invoicesHandler, Method: GetInvoice, Error: invalid order ID:", err)
+		problem.Respond(c, problem.InvalidID("order"))
+		return
+	}
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "html" {
+		problem.Respond(c, errInvalidFormat)
+		return
+	}
+
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return
+	}
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: invoicesHandler, Method: GetInvoice, Error fetching user:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	invoice, err := h.invoiceService.GetUserInvoice(user.ID, uint(orderID))
+	if err != nil {
+		h.logger.Error("Layer: invoicesHandler, Method: GetInvoice, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	if format == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(invoice.HTML))
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+invoice.Code+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", invoice.PDF)
+}
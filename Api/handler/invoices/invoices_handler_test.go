@@ -0,0 +1,80 @@
+package invoices
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_invoice "pruebaVertice/Api/services/invoice"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newUserMock() *UserServiceMock {
+	return &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+}
+
+func invoiceRequest(h *InvoicesHandler, url string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, url, nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("userEmail", "user@example.com")
+	h.GetInvoice(c)
+	return rec
+}
+
+var storedInvoice = &models.Invoice{ID: 1, OrderID: 7, Code: "F-000003", HTML: "<html>factura</html>", PDF: []byte("%PDF-1.4 stored")}
+
+func TestGetInvoice_PDF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &InvoiceServiceMock{}
+	serviceMock.On("GetUserInvoice", uint(2), uint(7)).Return(storedInvoice, nil)
+	h := NewInvoicesHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := invoiceRequest(h, "/orders/7/invoice")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "F-000003.pdf")
+	assert.Equal(t, storedInvoice.PDF, rec.Body.Bytes())
+}
+
+func TestGetInvoice_HTML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &InvoiceServiceMock{}
+	serviceMock.On("GetUserInvoice", uint(2), uint(7)).Return(storedInvoice, nil)
+	h := NewInvoicesHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := invoiceRequest(h, "/orders/7/invoice?format=html")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, storedInvoice.HTML, rec.Body.String())
+}
+
+func TestGetInvoice_InvalidFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewInvoicesHandler(&InvoiceServiceMock{}, newUserMock(), logrus.New())
+
+	rec := invoiceRequest(h, "/orders/7/invoice?format=docx")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetInvoice_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &InvoiceServiceMock{}
+	serviceMock.On("GetUserInvoice", uint(2), uint(7)).Return(nil, services_invoice.ErrOrderNotFound)
+	h := NewInvoicesHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := invoiceRequest(h, "/orders/7/invoice")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,40 @@
+package invoices
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// InvoiceServiceMock is a mock implementation of services_invoice.InvoiceService
+// for handler tests.
+type InvoiceServiceMock struct {
+	mock.Mock
+}
+
+func (m *InvoiceServiceMock) GetUserInvoice(userID, orderID uint) (*models.Invoice, error) {
+	args := m.Called(userID, orderID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Invoice), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UserServiceMock is a mock implementation of services_user.UserService.
+// Only GetUserByEmail is needed here.
+type UserServiceMock struct {
+	GetUserEmailFn func(email string) (*models.User, error)
+}
+
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
+func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
+	return m.GetUserEmailFn(email)
+}
@@ -5,6 +5,9 @@ import (
 	"pruebaVertice/Api/models"
 	services_order "pruebaVertice/Api/services/order"
 	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -26,29 +29,32 @@ func NewOrdersHandler(ordersService services_order.OrdersService, userService se
 
 // CreateOrder godoc
 // @Summary Crear una nueva orden
-// @Description Crea una nueva orden con los productos seleccionados
+// @Description Crea una nueva orden con los productos seleccionados, la dirección de envío (address_id o la dirección por defecto) y el método de envío
 // @Tags Orders
 // @Accept json
 // @Produce json
-// @Param order body models.CreateOrderRequest true "Lista de productos para la orden"
+// @Param order body models.CreateOrderRequest true "Lista de productos, dirección y método de envío"
 // @Success 201 {object} models.Order
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Security BearerAuth
 // @Router /api/auth/orders [post]
 func (h *OrdersHandler) CreateOrder(c *gin.Context) {
 	var req models.CreateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Layer: ordersHandler, Method: CreateOrder, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		validation.Respond(c, err)
 		return
 	}
 
 	emailVal, exists := c.Get("userEmail")
 	if !exists {
 		h.logger.Error("User email not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		problem.Respond(c, problem.ErrUnauthorized)
 		return
 	}
 	email := emailVal.(string)
@@ -56,14 +62,14 @@ func (h *OrdersHandler) CreateOrder(c *gin.Context) {
 	user, err := h.userService.GetUserByEmail(email)
 	if err != nil {
 		h.logger.Error("Layer: ordersHandler, Method: CreateOrder, Error fetching user:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
-	order, err := h.ordersService.CreateOrder(user.ID, req.OrderItems)
+	order, err := h.ordersService.CreateOrder(user.ID, req)
 	if err != nil {
 		h.logger.Error("Layer: ordersHandler, Method: CreateOrder, Error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
@@ -72,19 +78,36 @@ func (h *OrdersHandler) CreateOrder(c *gin.Context) {
 
 // GetUserOrders godoc
 // @Summary Obtener historial de órdenes del usuario autenticado
-// @Description Devuelve todas las órdenes del usuario autenticado
+// @Description Devuelve las órdenes del usuario autenticado, paginadas y con filtros opcionales
 // @Tags Orders
 // @Produce json
-// @Success 200 {array} models.Order
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param status query string false "Estado de la orden (placed, partially_shipped, shipped, delivered, cancelled)"
+// @Param from query string false "Fecha mínima de creación (RFC3339)"
+// @Param to query string false "Fecha máxima de creación (RFC3339)"
+// @Param min_total query number false "Total mínimo"
+// @Param max_total query number false "Total máximo"
+// @Param sort_by query string false "Campo de ordenamiento (created_at, total, status)"
+// @Param sort_dir query string false "Dirección de ordenamiento (asc, desc)"
+// @Param page query int false "Número de página (por defecto 1)"
+// @Param page_size query int false "Tamaño de página (por defecto 20, máximo 100)"
+// @Success 200 {object} dto.OrderListResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Security BearerAuth
 // @Router /api/auth/orders [get]
 func (h *OrdersHandler) GetUserOrders(c *gin.Context) {
+	var filter models.OrderFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: GetUserOrders, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
 	emailVal, exists := c.Get("userEmail")
 	if !exists {
 		h.logger.Error("User email not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		problem.Respond(c, problem.ErrUnauthorized)
 		return
 	}
 	email := emailVal.(string)
@@ -92,16 +115,164 @@ func (h *OrdersHandler) GetUserOrders(c *gin.Context) {
 	user, err := h.userService.GetUserByEmail(email)
 	if err != nil {
 		h.logger.Error("Layer: ordersHandler, Method: GetUserOrders, Error fetching user:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
-	orders, err := h.ordersService.GetUserOrders(user.ID)
+	orders, err := h.ordersService.GetUserOrders(user.ID, filter)
 	if err != nil {
 		h.logger.Error("Layer: ordersHandler, Method: GetUserOrders, Error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// GetOrdersForUser godoc
+// @Summary Obtener las órdenes de un usuario
+// @Description Devuelve las órdenes de cualquier usuario, paginadas y con los mismos filtros que el historial propio. Solo administradores
+// @Tags Admin
+// @Produce json
+// @Param id path int true "ID del usuario"
+// @Param status query string false "Estado de la orden (placed, partially_shipped, shipped, delivered, cancelled)"
+// @Param from query string false "Fecha mínima de creación (RFC3339)"
+// @Param to query string false "Fecha máxima de creación (RFC3339)"
+// @Param sort_by query string false "Campo de ordenamiento (created_at, total, status)"
+// @Param sort_dir query string false "Dirección de ordenamiento (asc, desc)"
+// @Param page query int false "Número de página (por defecto 1)"
+// @Param page_size query int false "Tamaño de página (por defecto 20, máximo 100)"
+// @Success 200 {object} dto.OrderListResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/orders [get]
+func (h *OrdersHandler) GetOrdersForUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: GetOrdersForUser, Error: invalid user ID:", err)
+		problem.Respond(c, problem.InvalidID("user"))
+		return
+	}
+	var filter models.OrderFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: GetOrdersForUser, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	orders, err := h.ordersService.GetUserOrders(uint(userID), filter)
+	if err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: GetOrdersForUser, Error:", err)
+		problem.Respond(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, orders)
 }
+
+// GetOrderByID godoc
+// @Summary Obtener una orden del usuario autenticado
+// @Description Devuelve el detalle de una orden. Solo el dueño de la orden puede consultarla
+// @Tags Orders
+// @Produce json
+// @Param id path int true "ID de la orden"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/orders/{id} [get]
+func (h *OrdersHandler) GetOrderByID(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: GetOrderByID, Error: invalid order ID:", err)
+		problem.Respond(c, problem.InvalidID("order"))
+		return
+	}
+
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return
+	}
+	email := emailVal.(string)
+
+	user, err := h.userService.GetUserByEmail(email)
+	if err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: GetOrderByID, Error fetching user:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	order, err := h.ordersService.GetUserOrder(user.ID, uint(orderID))
+	if err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: GetOrderByID, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CancelOrder godoc
+// @Summary Cancelar una orden del usuario autenticado
+// @Description Cancela una orden que aún no ha comenzado a enviarse y devuelve sus unidades al stock
+// @Tags Orders
+// @Produce json
+// @Param id path int true "ID de la orden"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/orders/{id}/cancel [post]
+func (h *OrdersHandler) CancelOrder(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: CancelOrder, Error: invalid order ID:", err)
+		problem.Respond(c, problem.InvalidID("order"))
+		return
+	}
+
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return
+	}
+	email := emailVal.(string)
+
+	user, err := h.userService.GetUserByEmail(email)
+	if err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: CancelOrder, Error fetching user:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	order, err := h.ordersService.CancelOrder(user.ID, uint(orderID))
+	if err != nil {
+		h.logger.Error("Layer: ordersHandler, Method: CancelOrder, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ListShippingMethods godoc
+// @Summary Listar métodos de envío
+// @Description Devuelve los métodos de envío disponibles para usar en shipping_method al crear una orden
+// @Tags Orders
+// @Produce json
+// @Success 200 {array} string
+// @Security BearerAuth
+// @Router /api/auth/orders/shipping-methods [get]
+func (h *OrdersHandler) ListShippingMethods(c *gin.Context) {
+	c.JSON(http.StatusOK, h.ordersService.ShippingMethods())
+}
@@ -3,10 +3,14 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
+	"pruebaVertice/Api/dto"
 	"pruebaVertice/Api/models"
-	"gorm.io/gorm"
+	services_order "pruebaVertice/Api/services/order"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -21,11 +25,11 @@ type UserServiceMock struct {
 	GetUserEmailFn func(email string) (*models.User, error)
 }
 
-func (m *UserServiceMock) CreateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) { return nil, nil }
 func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
 func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
 func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
-func (m *UserServiceMock) Login(email, password string) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) { return nil, nil }
 func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
 	return m.GetUserEmailFn(email)
 }
@@ -35,7 +39,7 @@ func TestCreateOrder_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockOrder := &models.Order{ID: 1, UserID: 2, Total: 10.0}
 	ordersMock := &OrdersServiceMock{}
-	ordersMock.On("CreateOrder", uint(2), []models.OrderProduct{{ProductID: 1, Quantity: 2}}).Return(mockOrder, nil)
+	ordersMock.On("CreateOrder", uint(2), models.CreateOrderRequest{OrderItems: []models.OrderProduct{{ProductID: 1, Quantity: 2}}}).Return(mockOrder, nil)
 
 	userMock := &UserServiceMock{
 		GetUserEmailFn: func(email string) (*models.User, error) {
@@ -47,7 +51,7 @@ func TestCreateOrder_Success(t *testing.T) {
 
 	// Prepare request
 	items := []models.OrderProduct{{ProductID: 1, Quantity: 2, UnitPrice: 0}}
-	body, _ := json.Marshal(items)
+	body, _ := json.Marshal(models.CreateOrderRequest{OrderItems: items})
 	rec := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(rec)
 	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
@@ -65,6 +69,59 @@ func TestCreateOrder_Success(t *testing.T) {
 	ordersMock.AssertExpectations(t)
 }
 
+func TestCreateOrder_AddressRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ordersMock := &OrdersServiceMock{}
+	req := models.CreateOrderRequest{OrderItems: []models.OrderProduct{{ProductID: 1, Quantity: 1}}}
+	ordersMock.On("CreateOrder", uint(2), req).Return(nil, services_order.ErrAddressRequired)
+	userMock := &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+	h := NewOrdersHandler(ordersMock, userMock, logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	c.Set("userEmail", "user@example.com")
+
+	h.CreateOrder(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestCreateOrder_InsufficientStock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ordersMock := &OrdersServiceMock{}
+	req := models.CreateOrderRequest{OrderItems: []models.OrderProduct{{ProductID: 1, Quantity: 5}}}
+	ordersMock.On("CreateOrder", uint(2), req).Return(nil, services_order.ErrInsufficientStock.Detailf("insufficient stock for product ID 1"))
+	userMock := &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+	h := NewOrdersHandler(ordersMock, userMock, logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/auth/orders", bytes.NewReader(body))
+	c.Set("userEmail", "user@example.com")
+
+	h.CreateOrder(c)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var resp problem.Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "insufficient_stock", resp.Code)
+	assert.Equal(t, "insufficient stock for product ID 1", resp.Detail)
+	assert.Equal(t, "/api/auth/orders", resp.Instance)
+	assert.NotEmpty(t, resp.TraceID)
+}
+
 func TestCreateOrder_BindError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ordersMock := &OrdersServiceMock{}
@@ -82,6 +139,27 @@ func TestCreateOrder_BindError(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
 
+func TestCreateOrder_InvalidItems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ordersMock := &OrdersServiceMock{}
+	h := NewOrdersHandler(ordersMock, &UserServiceMock{}, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"order_items":[{"product_id":1,"quantity":1},{"product_id":1,"quantity":-2}]}`)))
+	c.Set("userEmail", "user@example.com")
+
+	h.CreateOrder(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp problem.Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.ElementsMatch(t, []validation.FieldError{
+		{Field: "order_items", Rule: "unique_products", Message: "must not contain the same product_id more than once"},
+	}, resp.Errors)
+	ordersMock.AssertNotCalled(t, "CreateOrder")
+}
+
 func TestCreateOrder_Unauthorized(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ordersMock := &OrdersServiceMock{}
@@ -91,7 +169,7 @@ func TestCreateOrder_Unauthorized(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(rec)
-	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`[]`)))
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"order_items":[{"product_id":1,"quantity":1}]}`)))
 	// No userEmail set
 
 	h.CreateOrder(c)
@@ -101,9 +179,15 @@ func TestCreateOrder_Unauthorized(t *testing.T) {
 
 func TestGetUserOrders_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	ordersList := []models.Order{{ID: 1, UserID: 2, Total: 20.0}}
+	page := &dto.OrderListResponse{
+		Items:      []models.Order{{ID: 1, UserID: 2, Total: 20.0}},
+		Page:       2,
+		PageSize:   5,
+		TotalItems: 6,
+		TotalPages: 2,
+	}
 	ordersMock := &OrdersServiceMock{}
-	ordersMock.On("GetUserOrders", uint(2)).Return(ordersList, nil)
+	ordersMock.On("GetUserOrders", uint(2), models.OrderFilter{Status: "placed", Page: 2, PageSize: 5, SortBy: "total"}).Return(page, nil)
 
 	userMock := &UserServiceMock{
 		GetUserEmailFn: func(email string) (*models.User, error) {
@@ -115,19 +199,116 @@ func TestGetUserOrders_Success(t *testing.T) {
 
 	rec := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(rec)
-	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/?status=placed&page=2&page_size=5&sort_by=total", nil)
 	c.Set("userEmail", "user@example.com")
 
 	h.GetUserOrders(c)
 
 	assert.Equal(t, http.StatusOK, rec.Code)
-	var resp []models.Order
+	var resp dto.OrderListResponse
 	err := json.Unmarshal(rec.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, ordersList, resp)
+	assert.Equal(t, *page, resp)
 	ordersMock.AssertExpectations(t)
 }
 
+func TestGetUserOrders_InvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ordersMock := &OrdersServiceMock{}
+	ordersMock.On("GetUserOrders", uint(2), models.OrderFilter{SortBy: "password"}).Return(nil, services_order.ErrInvalidFilter)
+	userMock := &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+	h := NewOrdersHandler(ordersMock, userMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/?sort_by=password", nil)
+	c.Set("userEmail", "user@example.com")
+
+	h.GetUserOrders(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetOrdersForUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	page := &dto.OrderListResponse{Items: []models.Order{{ID: 4, UserID: 7}}, Page: 1, PageSize: 20, TotalItems: 1, TotalPages: 1}
+	ordersMock := &OrdersServiceMock{}
+	ordersMock.On("GetUserOrders", uint(7), models.OrderFilter{Status: "placed"}).Return(page, nil)
+	h := NewOrdersHandler(ordersMock, &UserServiceMock{}, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/?status=placed", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+
+	h.GetOrdersForUser(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	ordersMock.AssertExpectations(t)
+
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	h.GetOrdersForUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetOrderByID_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	order := &models.Order{ID: 9, UserID: 2, Total: 15, OrderItems: []models.OrderProduct{{ID: 1, OrderID: 9, ProductID: 3, Quantity: 1, UnitPrice: 15, Snapshot: models.ProductSnapshot{Name: "Mouse", SKU: "MS-1", TaxCategory: "standard"}}}}
+	ordersMock := &OrdersServiceMock{}
+	ordersMock.On("GetUserOrder", uint(2), uint(9)).Return(order, nil)
+	userMock := &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+	h := NewOrdersHandler(ordersMock, userMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/orders/9", nil)
+	c.Set("userEmail", "user@example.com")
+
+	h.GetOrderByID(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp models.Order
+	err := json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, *order, resp)
+}
+
+func TestGetOrderByID_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ordersMock := &OrdersServiceMock{}
+	ordersMock.On("GetUserOrder", uint(2), uint(9)).Return(nil, services_order.ErrOrderNotFound)
+	userMock := &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+	h := NewOrdersHandler(ordersMock, userMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/orders/9", nil)
+	c.Set("userEmail", "user@example.com")
+
+	h.GetOrderByID(c)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
 func TestGetUserOrders_Unauthorized(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	ordersMock := &OrdersServiceMock{}
@@ -144,3 +325,66 @@ func TestGetUserOrders_Unauthorized(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, rec.Code)
 }
+
+func TestListShippingMethods(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ordersMock := &OrdersServiceMock{}
+	ordersMock.On("ShippingMethods").Return([]string{"express", "standard"})
+	h := NewOrdersHandler(ordersMock, &UserServiceMock{}, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/orders/shipping-methods", nil)
+
+	h.ListShippingMethods(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `["express","standard"]`, rec.Body.String())
+}
+
+func TestCancelOrder_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ordersMock := &OrdersServiceMock{}
+	ordersMock.On("CancelOrder", uint(2), uint(9)).Return(&models.Order{ID: 9, UserID: 2, Status: models.OrderStatusCancelled}, nil)
+	userMock := &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+	h := NewOrdersHandler(ordersMock, userMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/orders/9/cancel", nil)
+	c.Set("userEmail", "user@example.com")
+
+	h.CancelOrder(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp models.Order
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, models.OrderStatusCancelled, resp.Status)
+}
+
+func TestCancelOrder_NotCancellable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ordersMock := &OrdersServiceMock{}
+	ordersMock.On("CancelOrder", uint(2), uint(9)).Return(nil, services_order.ErrOrderNotCancellable)
+	userMock := &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+	h := NewOrdersHandler(ordersMock, userMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/orders/9/cancel", nil)
+	c.Set("userEmail", "user@example.com")
+
+	h.CancelOrder(c)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
@@ -1,8 +1,9 @@
 package handler
 
 import (
+	"pruebaVertice/Api/dto"
 	"pruebaVertice/Api/models"
-	
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -11,18 +12,39 @@ type OrdersServiceMock struct {
 	mock.Mock
 }
 
-func (m *OrdersServiceMock) CreateOrder(userID uint, items []models.OrderProduct) (*models.Order, error) {
-	args := m.Called(userID, items)
+func (m *OrdersServiceMock) CreateOrder(userID uint, req models.CreateOrderRequest) (*models.Order, error) {
+	args := m.Called(userID, req)
 	if res := args.Get(0); res != nil {
 		return res.(*models.Order), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *OrdersServiceMock) GetUserOrders(userID uint) ([]models.Order, error) {
-	args := m.Called(userID)
+func (m *OrdersServiceMock) GetUserOrders(userID uint, filter models.OrderFilter) (*dto.OrderListResponse, error) {
+	args := m.Called(userID, filter)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.OrderListResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersServiceMock) GetUserOrder(userID, orderID uint) (*models.Order, error) {
+	args := m.Called(userID, orderID)
 	if res := args.Get(0); res != nil {
-		return res.([]models.Order), args.Error(1)
+		return res.(*models.Order), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
+
+func (m *OrdersServiceMock) CancelOrder(userID, orderID uint) (*models.Order, error) {
+	args := m.Called(userID, orderID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Order), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersServiceMock) ShippingMethods() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
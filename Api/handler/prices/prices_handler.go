@@ -0,0 +1,139 @@
+package prices
+
+import (
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_price "pruebaVertice/Api/services/price"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PricesHandler struct {
+	services services_price.PriceService
+	logger   *logrus.Logger
+}
+
+func NewPricesHandler(services services_price.PriceService, logger *logrus.Logger) *PricesHandler {
+	return &PricesHandler{services: services, logger: logger}
+}
+
+// GetPriceTimeline godoc
+// @Summary Historial de precios de un producto
+// @Description Devuelve el historial de precios del producto y los cambios programados pendientes o en curso
+// @Tags Prices
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Success 200 {object} dto.PriceTimelineResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/products/{id}/prices [get]
+func (h *PricesHandler) GetPriceTimeline(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: pricesHandler, Method: GetPriceTimeline, Error: invalid product ID:", err)
+		problem.Respond(c, problem.InvalidID("product"))
+		return
+	}
+
+	timeline, err := h.services.GetPriceTimeline(uint(productID))
+	if err != nil {
+		h.logger.Error("Layer: pricesHandler, Method: GetPriceTimeline, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// SchedulePriceChange godoc
+// @Summary Programar un cambio de precio
+// @Description Programa un cambio de precio regular o una oferta con fecha de inicio y fin. Sin starts_at se aplica de inmediato. Solo administradores
+// @Tags Prices
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Param change body models.SchedulePriceChangeRequest true "Cambio de precio"
+// @Success 201 {object} models.ScheduledPriceChange
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/products/{id}/prices [post]
+func (h *PricesHandler) SchedulePriceChange(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: pricesHandler, Method: SchedulePriceChange, Error: invalid product ID:", err)
+		problem.Respond(c, problem.InvalidID("product"))
+		return
+	}
+
+	var req models.SchedulePriceChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: pricesHandler, Method: SchedulePriceChange, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return
+	}
+	email := emailVal.(string)
+
+	change, err := h.services.SchedulePriceChange(uint(productID), req, email)
+	if err != nil {
+		h.logger.Error("Layer: pricesHandler, Method: SchedulePriceChange, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, change)
+}
+
+// CancelScheduledChange godoc
+// @Summary Cancelar un cambio de precio programado
+// @Description Cancela un cambio pendiente. Si es una oferta en curso, la finaliza y restaura el precio regular. Solo administradores
+// @Tags Prices
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Param changeId path int true "ID del cambio programado"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/products/{id}/prices/{changeId} [delete]
+func (h *PricesHandler) CancelScheduledChange(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: pricesHandler, Method: CancelScheduledChange, Error: invalid product ID:", err)
+		problem.Respond(c, problem.InvalidID("product"))
+		return
+	}
+	changeID, err := strconv.ParseUint(c.Param("changeId"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: pricesHandler, Method: CancelScheduledChange, Error: invalid change ID:", err)
+		problem.Respond(c, problem.InvalidID("change"))
+		return
+	}
+
+	if err := h.services.CancelScheduledChange(uint(productID), uint(changeID)); err != nil {
+		h.logger.Error("Layer: pricesHandler, Method: CancelScheduledChange, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price change cancelled successfully"})
+}
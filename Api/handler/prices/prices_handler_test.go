@@ -0,0 +1,117 @@
+package prices
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_price "pruebaVertice/Api/services/price"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPriceTimeline_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	timeline := &dto.PriceTimelineResponse{
+		ProductID:    1,
+		CurrentPrice: 5,
+		RegularPrice: 10,
+		OnSale:       true,
+		History:      []models.PriceHistory{{ID: 1, ProductID: 1, Price: 10}},
+		Upcoming:     []models.ScheduledPriceChange{},
+	}
+	serviceMock := &PriceServiceMock{}
+	serviceMock.On("GetPriceTimeline", uint(1)).Return(timeline, nil)
+	h := NewPricesHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/products/1/prices", nil)
+
+	h.GetPriceTimeline(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp dto.PriceTimelineResponse
+	err := json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, *timeline, resp)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestGetPriceTimeline_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &PriceServiceMock{}
+	serviceMock.On("GetPriceTimeline", uint(2)).Return(nil, services_price.ErrProductNotFound)
+	h := NewPricesHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/products/2/prices", nil)
+
+	h.GetPriceTimeline(c)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSchedulePriceChange_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.SchedulePriceChangeRequest{Kind: models.PriceChangeKindRegular, Price: 12}
+	created := &models.ScheduledPriceChange{ID: 1, ProductID: 1, Kind: models.PriceChangeKindRegular, Price: 12, Status: models.PriceChangeStatusApplied}
+	serviceMock := &PriceServiceMock{}
+	serviceMock.On("SchedulePriceChange", uint(1), req, "user@example.com").Return(created, nil)
+	h := NewPricesHandler(serviceMock, logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/products/1/prices", bytes.NewReader(body))
+	c.Set("userEmail", "user@example.com")
+
+	h.SchedulePriceChange(c)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestSchedulePriceChange_InvalidPrice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.SchedulePriceChangeRequest{Price: -1}
+	serviceMock := &PriceServiceMock{}
+	serviceMock.On("SchedulePriceChange", uint(1), req, "user@example.com").Return(nil, services_price.ErrInvalidPrice)
+	h := NewPricesHandler(serviceMock, logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/products/1/prices", bytes.NewReader(body))
+	c.Set("userEmail", "user@example.com")
+
+	h.SchedulePriceChange(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCancelScheduledChange_Conflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &PriceServiceMock{}
+	serviceMock.On("CancelScheduledChange", uint(1), uint(4)).Return(services_price.ErrChangeNotPending)
+	h := NewPricesHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "changeId", Value: "4"}}
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/products/1/prices/4", nil)
+
+	h.CancelScheduledChange(c)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
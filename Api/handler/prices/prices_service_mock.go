@@ -0,0 +1,40 @@
+package prices
+
+import (
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// PriceServiceMock is a mock implementation of services_price.PriceService
+// for handler tests.
+type PriceServiceMock struct {
+	mock.Mock
+}
+
+func (m *PriceServiceMock) SchedulePriceChange(productID uint, req models.SchedulePriceChangeRequest, createdBy string) (*models.ScheduledPriceChange, error) {
+	args := m.Called(productID, req, createdBy)
+	if res := args.Get(0); res != nil {
+		return res.(*models.ScheduledPriceChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PriceServiceMock) CancelScheduledChange(productID, changeID uint) error {
+	args := m.Called(productID, changeID)
+	return args.Error(0)
+}
+
+func (m *PriceServiceMock) GetPriceTimeline(productID uint) (*dto.PriceTimelineResponse, error) {
+	args := m.Called(productID)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.PriceTimelineResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PriceServiceMock) ApplyDueChanges() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,134 @@
+package privacy
+
+import (
+	"fmt"
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_privacy "pruebaVertice/Api/services/privacy"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PrivacyHandler struct {
+	privacyService services_privacy.PrivacyService
+	userService    services_user.UserService
+	logger         *logrus.Logger
+}
+
+func NewPrivacyHandler(privacyService services_privacy.PrivacyService, userService services_user.UserService, logger *logrus.Logger) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+		userService:    userService,
+		logger:         logger,
+	}
+}
+
+// ExportData godoc
+// @Summary Exportar datos personales
+// @Description Descarga un ZIP con los datos personales del usuario en JSON: perfil, direcciones, órdenes con sus líneas, sesiones y reseñas
+// @Tags Privacy
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/export [get]
+func (h *PrivacyHandler) ExportData(c *gin.Context) {
+	user, ok := h.currentUser(c, "ExportData")
+	if !ok {
+		return
+	}
+
+	archive, err := h.privacyService.ExportUserData(user)
+	if err != nil {
+		h.logger.Error("Layer: privacyHandler, Method: ExportData, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="personal-data-%d.zip"`, user.ID))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// RequestErasure godoc
+// @Summary Solicitar el borrado de datos de un usuario
+// @Description Registra una solicitud de supresión (art. 17 RGPD) y la ejecuta en segundo plano: seudonimiza los datos personales del usuario en todas las tablas y da de baja la cuenta, conservando órdenes y facturas. Solo administradores
+// @Tags Privacy
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del usuario"
+// @Param request body models.ErasureRequestBody true "Motivo de la solicitud"
+// @Success 202 {object} models.ErasureRequest
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/users/{id}/erasure [post]
+func (h *PrivacyHandler) RequestErasure(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: privacyHandler, Method: RequestErasure, Error: invalid user ID:", err)
+		problem.Respond(c, problem.InvalidID("user"))
+		return
+	}
+	var req models.ErasureRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: privacyHandler, Method: RequestErasure, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	request, err := h.privacyService.RequestErasure(uint(id), c.GetString("userEmail"), req.Reason)
+	if err != nil {
+		h.logger.Error("Layer: privacyHandler, Method: RequestErasure, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, request)
+}
+
+// ListErasureRequests godoc
+// @Summary Listar solicitudes de borrado
+// @Description Devuelve el registro de auditoría de las solicitudes de supresión, de la más reciente a la más antigua. Solo administradores
+// @Tags Privacy
+// @Produce json
+// @Success 200 {array} models.ErasureRequest
+// @Failure 403 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/erasure-requests [get]
+func (h *PrivacyHandler) ListErasureRequests(c *gin.Context) {
+	requests, err := h.privacyService.ListErasureRequests()
+	if err != nil {
+		h.logger.Error("Layer: privacyHandler, Method: ListErasureRequests, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+func (h *PrivacyHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: privacyHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,116 @@
+package privacy
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_privacy "pruebaVertice/Api/services/privacy"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var owner = &models.User{Model: gorm.Model{ID: 3}, Email: "ana@example.com"}
+
+func newUserMock() *UserServiceMock {
+	return &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return owner, nil
+		},
+	}
+}
+
+func TestExportData_SendsZip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &PrivacyServiceMock{}
+	serviceMock.On("ExportUserData", owner).Return([]byte("PK-archive"), nil)
+	h := NewPrivacyHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me/export", nil)
+	c.Set("userEmail", owner.Email)
+
+	h.ExportData(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "personal-data-3.zip")
+	assert.Equal(t, "PK-archive", rec.Body.String())
+}
+
+func TestExportData_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewPrivacyHandler(&PrivacyServiceMock{}, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me/export", nil)
+
+	h.ExportData(c)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRequestErasure(t *testing.T) {
+	cases := []struct {
+		name   string
+		id     string
+		body   string
+		err    error
+		status int
+	}{
+		{"accepted", "7", `{"reason":"art. 17"}`, nil, http.StatusAccepted},
+		{"missing reason", "7", `{}`, nil, http.StatusBadRequest},
+		{"invalid id", "abc", `{"reason":"art. 17"}`, nil, http.StatusBadRequest},
+		{"unknown user", "7", `{"reason":"art. 17"}`, services_privacy.ErrUserNotFound, http.StatusNotFound},
+		{"already pending", "7", `{"reason":"art. 17"}`, services_privacy.ErrErasurePending, http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			serviceMock := &PrivacyServiceMock{}
+			var request *models.ErasureRequest
+			if tc.err == nil {
+				request = &models.ErasureRequest{ID: 1, UserID: 7, Status: models.ErasureStatusPending}
+			}
+			serviceMock.On("RequestErasure", uint(7), "admin@example.com", "art. 17").Return(request, tc.err)
+			h := NewPrivacyHandler(serviceMock, newUserMock(), logrus.New())
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/admin/users/"+tc.id+"/erasure", bytes.NewBufferString(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: tc.id}}
+			c.Set("userEmail", "admin@example.com")
+
+			h.RequestErasure(c)
+
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestListErasureRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &PrivacyServiceMock{}
+	serviceMock.On("ListErasureRequests").Return([]models.ErasureRequest{{ID: 1, UserID: 7, RequestedBy: "admin@example.com"}}, nil)
+	h := NewPrivacyHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/admin/erasure-requests", nil)
+
+	h.ListErasureRequests(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"requested_by":"admin@example.com"`)
+	serviceMock.AssertCalled(t, "ListErasureRequests")
+	serviceMock.AssertNotCalled(t, "RequestErasure", mock.Anything, mock.Anything, mock.Anything)
+}
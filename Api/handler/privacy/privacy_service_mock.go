@@ -0,0 +1,56 @@
+package privacy
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// PrivacyServiceMock is a mock implementation of
+// services_privacy.PrivacyService for handler tests.
+type PrivacyServiceMock struct {
+	mock.Mock
+}
+
+func (m *PrivacyServiceMock) ExportUserData(user *models.User) ([]byte, error) {
+	args := m.Called(user)
+	if res := args.Get(0); res != nil {
+		return res.([]byte), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PrivacyServiceMock) RequestErasure(userID uint, requestedBy, reason string) (*models.ErasureRequest, error) {
+	args := m.Called(userID, requestedBy, reason)
+	if res := args.Get(0); res != nil {
+		return res.(*models.ErasureRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PrivacyServiceMock) ListErasureRequests() ([]models.ErasureRequest, error) {
+	args := m.Called()
+	if res := args.Get(0); res != nil {
+		return res.([]models.ErasureRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UserServiceMock is a mock implementation of services_user.UserService.
+// Only GetUserByEmail is needed here.
+type UserServiceMock struct {
+	GetUserEmailFn func(email string) (*models.User, error)
+}
+
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
+func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
+	return m.GetUserEmailFn(email)
+}
@@ -4,6 +4,9 @@ import (
 	"net/http"
 	"pruebaVertice/Api/models"
 	services_product "pruebaVertice/Api/services/product"
+	"pruebaVertice/Api/utils/i18n"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -21,29 +24,34 @@ func NewProductsHandler(services services_product.ProductService, logger *logrus
 
 // CreateProducts godoc
 // @Summary Crear productos
-// @Description Crea uno o varios productos nuevos
+// @Description Crea uno o varios productos nuevos. Cada producto puede incluir sus traducciones (translations) de nombre y descripción
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param products body object true "Productos a crear (formato key-value)"
 // @Success 201 {array} models.Product
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Security BearerAuth
 // @Router /api/auth/products/ [post]
 func (h *ProductsHandler) CreateProducts(c *gin.Context) {
 	var productsMap map[string]models.Product
 	if err := c.ShouldBindJSON(&productsMap); err != nil {
 		h.logger.Error("Layer: productsHandler, Method: CreateProducts, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		validation.Respond(c, err)
+		return
+	}
+	if err := validation.Map(productsMap); err != nil {
+		h.logger.Error("Layer: productsHandler, Method: CreateProducts, Error:", err)
+		validation.Respond(c, err)
 		return
 	}
 
 	emailVal, exists := c.Get("userEmail")
 	if !exists {
 		h.logger.Error("User email not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		problem.Respond(c, problem.ErrUnauthorized)
 		return
 	}
 	email := emailVal.(string)
@@ -57,7 +65,7 @@ func (h *ProductsHandler) CreateProducts(c *gin.Context) {
 	created, err := h.services.CreateProducts(productList)
 	if err != nil {
 		h.logger.Error("Layer: productsHandler, Method: CreateProducts, Error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
@@ -66,13 +74,14 @@ func (h *ProductsHandler) CreateProducts(c *gin.Context) {
 
 // GetProductByID godoc
 // @Summary Obtener un producto por ID
-// @Description Obtiene la información de un producto mediante su ID
+// @Description Obtiene la información de un producto mediante su ID, con el nombre y la descripción en el idioma pedido
 // @Tags Products
 // @Produce json
 // @Param id path int true "ID del producto"
+// @Param Accept-Language header string false "Idioma de la respuesta (es, en)"
 // @Success 200 {object} models.Product
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Security BearerAuth
 // @Router /api/auth/products/{id} [get]
 func (h *ProductsHandler) GetProductByID(c *gin.Context) {
@@ -80,36 +89,136 @@ func (h *ProductsHandler) GetProductByID(c *gin.Context) {
 	onceID, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
 		h.logger.Error("Layer: productsHandler, Method: GetProductByID, Error: invalid product ID:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		problem.Respond(c, problem.InvalidID("product"))
 		return
 	}
 
 	product, err := h.services.GetProductByID(uint(onceID))
 	if err != nil {
 		h.logger.Error("Layer: productsHandler, Method: GetProductByID, Error:", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
+	product.Localize(i18n.Language(c))
 	c.JSON(http.StatusOK, product)
 }
 
 // GetAllProducts godoc
 // @Summary Obtener todos los productos
-// @Description Obtiene la lista de todos los productos registrados
+// @Description Obtiene la lista de todos los productos registrados, opcionalmente ordenada por valoración, precio, nombre o fecha, con el nombre y la descripción en el idioma pedido
 // @Tags Products
 // @Produce json
+// @Param Accept-Language header string false "Idioma de la respuesta (es, en)"
+// @Param sort_by query string false "Campo de orden (rating, price, name, created_at)"
+// @Param sort_dir query string false "Dirección del orden (asc, desc). Por defecto desc para rating y asc para el resto"
 // @Success 200 {array} models.Product
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Security BearerAuth
 // @Router /api/auth/products/ [get]
 func (h *ProductsHandler) GetAllProducts(c *gin.Context) {
-	products, err := h.services.GetAllProducts()
+	var sort models.ProductSort
+	if err := c.ShouldBindQuery(&sort); err != nil {
+		h.logger.Error("Layer: productsHandler, Method: GetAllProducts, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	products, err := h.services.GetAllProducts(sort)
 	if err != nil {
 		h.logger.Error("Layer: productsHandler, Method: GetAllProducts, Error:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
+	lang := i18n.Language(c)
+	for i := range products {
+		products[i].Localize(lang)
+	}
 	c.JSON(http.StatusOK, products)
 }
+
+// SetTranslation godoc
+// @Summary Traducir un producto
+// @Description Guarda el nombre y la descripción de un producto en un idioma, reemplazando la traducción anterior. Solo administradores
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Param language path string true "Idioma de la traducción (es, en)"
+// @Param translation body models.ProductTranslationRequest true "Nombre y descripción traducidos"
+// @Success 200 {object} models.ProductTranslation
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/products/{id}/translations/{language} [put]
+func (h *ProductsHandler) SetTranslation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: productsHandler, Method: SetTranslation, Error: invalid product ID:", err)
+		problem.Respond(c, problem.InvalidID("product"))
+		return
+	}
+
+	var req models.ProductTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: productsHandler, Method: SetTranslation, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	translation, err := h.services.SetTranslation(uint(id), c.Param("language"), req)
+	if err != nil {
+		h.logger.Error("Layer: productsHandler, Method: SetTranslation, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+// RestockProduct godoc
+// @Summary Reponer stock de un producto
+// @Description Suma unidades al stock de un producto y avisa a los usuarios suscritos si vuelve a estar disponible
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Param restock body models.RestockRequest true "Unidades a reponer"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/warehouse/products/{id}/restock [post]
+func (h *ProductsHandler) RestockProduct(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: productsHandler, Method: RestockProduct, Error: invalid product ID:", err)
+		problem.Respond(c, problem.InvalidID("product"))
+		return
+	}
+
+	var req models.RestockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: productsHandler, Method: RestockProduct, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	product, err := h.services.RestockProduct(uint(id), req.Quantity)
+	if err != nil {
+		h.logger.Error("Layer: productsHandler, Method: RestockProduct, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"pruebaVertice/Api/models"
+	services_product "pruebaVertice/Api/services/product"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -46,6 +49,28 @@ func TestCreateProducts_Success(t *testing.T) {
 	serviceMock.AssertExpectations(t)
 }
 
+func TestCreateProducts_InvalidProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ProductServiceMock{}
+	h := NewProductsHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"p1":{"name":"","price":-5,"stock":1}}`)))
+	c.Set("userEmail", "user@example.com")
+
+	h.CreateProducts(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp problem.Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, []validation.FieldError{
+		{Field: "p1.name", Rule: "required", Message: "is required"},
+		{Field: "p1.price", Rule: "gt", Message: "must be greater than 0"},
+	}, resp.Errors)
+	serviceMock.AssertNotCalled(t, "CreateProducts")
+}
+
 func TestCreateProducts_Unauthorized(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := &ProductServiceMock{}
@@ -104,7 +129,7 @@ func TestGetProductByID_InvalidID(t *testing.T) {
 func TestGetProductByID_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := &ProductServiceMock{}
-	serviceMock.On("GetProductByID", uint(2)).Return(nil, errors.New("not found"))
+	serviceMock.On("GetProductByID", uint(2)).Return(nil, services_product.ErrProductNotFound)
 	logger := logrus.New()
 	h := NewProductsHandler(serviceMock, logger)
 
@@ -123,7 +148,7 @@ func TestGetAllProducts_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	existing := []models.Product{{Model: models.Product{}.Model, Name: "A"}}
 	serviceMock := &ProductServiceMock{}
-	serviceMock.On("GetAllProducts").Return(existing, nil)
+	serviceMock.On("GetAllProducts", models.ProductSort{}).Return(existing, nil)
 	logger := logrus.New()
 	h := NewProductsHandler(serviceMock, logger)
 
@@ -140,3 +165,157 @@ func TestGetAllProducts_Success(t *testing.T) {
 	assert.Equal(t, existing, resp)
 	serviceMock.AssertExpectations(t)
 }
+
+func TestRestockProduct_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ProductServiceMock{}
+	serviceMock.On("RestockProduct", uint(4), 10).Return(&models.Product{Name: "Teclado", Stock: 10}, nil)
+	h := NewProductsHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "4"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/warehouse/products/4/restock", bytes.NewReader([]byte(`{"quantity":10}`)))
+
+	h.RestockProduct(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp models.Product
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 10, resp.Stock)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestRestockProduct_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", services_product.ErrProductNotFound, http.StatusNotFound},
+		{"invalid quantity", services_product.ErrInvalidQuantity, http.StatusUnprocessableEntity},
+		{"internal", errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceMock := &ProductServiceMock{}
+			serviceMock.On("RestockProduct", uint(4), 3).Return(nil, tc.err)
+			h := NewProductsHandler(serviceMock, logrus.New())
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Params = gin.Params{{Key: "id", Value: "4"}}
+			c.Request, _ = http.NewRequest(http.MethodPost, "/warehouse/products/4/restock", bytes.NewReader([]byte(`{"quantity":3}`)))
+
+			h.RestockProduct(c)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+func TestGetAllProducts_InvalidSort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sort := models.ProductSort{SortBy: "password"}
+	serviceMock := &ProductServiceMock{}
+	serviceMock.On("GetAllProducts", sort).Return(nil, services_product.ErrInvalidSort)
+	h := NewProductsHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/products?sort_by=password", nil)
+
+	h.GetAllProducts(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestGetProductByID_Localized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prod := &models.Product{Name: "Chair", Description: "Wooden chair", Price: 20, Translations: []models.ProductTranslation{
+		{Language: "es", Name: "Silla"},
+	}}
+	serviceMock := &ProductServiceMock{}
+	serviceMock.On("GetProductByID", uint(1)).Return(prod, nil)
+	h := NewProductsHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/products/1", nil)
+	c.Request.Header.Set("Accept-Language", "es-AR")
+
+	h.GetProductByID(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp models.Product
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "Silla", resp.Name)
+	// Without a translated description the product's own is kept.
+	assert.Equal(t, "Wooden chair", resp.Description)
+	assert.Empty(t, resp.Translations)
+}
+
+func TestGetAllProducts_Localized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for lang, names := range map[string][]string{"es": {"Silla", "Table"}, "en": {"Chair", "Table"}} {
+		existing := []models.Product{
+			{Name: "Chair", Translations: []models.ProductTranslation{{Language: "es", Name: "Silla", Description: "De madera"}}},
+			{Name: "Table"},
+		}
+		serviceMock := &ProductServiceMock{}
+		serviceMock.On("GetAllProducts", models.ProductSort{}).Return(existing, nil)
+		h := NewProductsHandler(serviceMock, logrus.New())
+
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/products", nil)
+		c.Request.Header.Set("Accept-Language", lang)
+
+		h.GetAllProducts(c)
+
+		var resp []models.Product
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, names, []string{resp[0].Name, resp[1].Name}, lang)
+	}
+}
+
+func TestSetTranslation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.ProductTranslationRequest{Name: "Silla", Description: "De madera"}
+	saved := &models.ProductTranslation{ProductID: 1, Language: "es", Name: "Silla", Description: "De madera"}
+	serviceMock := &ProductServiceMock{}
+	serviceMock.On("SetTranslation", uint(1), "es", req).Return(saved, nil)
+	serviceMock.On("SetTranslation", uint(1), "fr", req).Return(nil, services_product.ErrUnsupportedLang.Detailf("unsupported language: %q", "fr"))
+	h := NewProductsHandler(serviceMock, logrus.New())
+
+	serve := func(id, lang, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Params = gin.Params{{Key: "id", Value: id}, {Key: "language", Value: lang}}
+		c.Request, _ = http.NewRequest(http.MethodPut, "/products/"+id+"/translations/"+lang, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Accept-Language", "es")
+		h.SetTranslation(c)
+		return rec
+	}
+
+	body := `{"name":"Silla","description":"De madera"}`
+	rec := serve("1", "es", body)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"language":"es","name":"Silla","description":"De madera"}`, rec.Body.String())
+
+	rec = serve("1", "fr", body)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, "unsupported_language", p.Code)
+	assert.Equal(t, `idioma no soportado: "fr"`, p.Detail)
+
+	rec = serve("1", "es", `{"name":" "}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, serve("abc", "es", body).Code)
+	serviceMock.AssertExpectations(t)
+}
@@ -27,10 +27,26 @@ func (m *ProductServiceMock) GetProductByID(id uint) (*models.Product, error) {
 	return nil, args.Error(1)
 }
 
-func (m *ProductServiceMock) GetAllProducts() ([]models.Product, error) {
-	args := m.Called()
+func (m *ProductServiceMock) GetAllProducts(sort models.ProductSort) ([]models.Product, error) {
+	args := m.Called(sort)
 	if res := args.Get(0); res != nil {
 		return res.([]models.Product), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
+
+func (m *ProductServiceMock) RestockProduct(id uint, quantity int) (*models.Product, error) {
+	args := m.Called(id, quantity)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductServiceMock) SetTranslation(id uint, lang string, req models.ProductTranslationRequest) (*models.ProductTranslation, error) {
+	args := m.Called(id, lang, req)
+	if res := args.Get(0); res != nil {
+		return res.(*models.ProductTranslation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,244 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_realtime "pruebaVertice/Api/services/realtime"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/problem"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	heartbeatInterval = 25 * time.Second
+	writeTimeout      = 10 * time.Second
+	// retryMillis tells EventSource how long to wait before reconnecting.
+	retryMillis = 3000
+)
+
+var (
+	errInvalidProducts    = apperr.BadRequest("invalid_products", "products must be a comma separated list of IDs")
+	errInvalidLastEventID = apperr.BadRequest("invalid_last_event_id", "Invalid Last-Event-ID")
+)
+
+type RealtimeHandler struct {
+	realtimeService services_realtime.RealtimeService
+	userService     services_user.UserService
+	logger          *logrus.Logger
+}
+
+func NewRealtimeHandler(realtimeService services_realtime.RealtimeService, userService services_user.UserService, logger *logrus.Logger) *RealtimeHandler {
+	return &RealtimeHandler{
+		realtimeService: realtimeService,
+		userService:     userService,
+		logger:          logger,
+	}
+}
+
+// socketCommand is sent by WebSocket clients to change the products they
+// watch.
+type socketCommand struct {
+	Action     string `json:"action" example:"watch"`
+	ProductIDs []uint `json:"product_ids"`
+}
+
+// OrdersStream godoc
+// @Summary Actualizaciones de órdenes y stock (SSE)
+// @Description Flujo Server-Sent Events con los cambios de estado de las órdenes del usuario (evento order_status) y de stock de los productos indicados (evento stock). Cada evento lleva un id; al reconectar, el navegador envía Last-Event-ID y se reenvían los eventos perdidos. Como EventSource no permite cabeceras, el token puede enviarse en access_token
+// @Tags Realtime
+// @Produce text/event-stream
+// @Param products query string false "IDs de productos a observar, separados por coma"
+// @Param Last-Event-ID header int false "Último id recibido, para reanudar"
+// @Param last_event_id query int false "Alternativa a la cabecera Last-Event-ID"
+// @Param access_token query string false "JWT, si no se envía la cabecera Authorization"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/orders/stream [get]
+func (h *RealtimeHandler) OrdersStream(c *gin.Context) {
+	sub, ok := h.subscribe(c, "OrdersStream")
+	if !ok {
+		return
+	}
+	defer h.realtimeService.Unsubscribe(sub)
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", retryMillis)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		case msg, open := <-sub.C():
+			if !open {
+				return
+			}
+			data, err := json.Marshal(msg.Data)
+			if err != nil {
+				h.logger.Error("Layer: realtimeHandler, Method: OrdersStream, Error:", err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, data)
+		}
+		c.Writer.Flush()
+	}
+}
+
+// OrdersSocket godoc
+// @Summary Actualizaciones de órdenes y stock (WebSocket)
+// @Description Mismo contenido que /orders/stream sobre WebSocket. Cada mensaje es un JSON {id, event, data}. El cliente puede enviar {"action":"watch"|"unwatch","product_ids":[...]} para cambiar los productos observados. Para reanudar se envía last_event_id
+// @Tags Realtime
+// @Param products query string false "IDs de productos a observar, separados por coma"
+// @Param last_event_id query int false "Último id recibido, para reanudar"
+// @Param access_token query string false "JWT, si no se envía la cabecera Authorization"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/orders/ws [get]
+func (h *RealtimeHandler) OrdersSocket(c *gin.Context) {
+	sub, ok := h.subscribe(c, "OrdersSocket")
+	if !ok {
+		return
+	}
+	defer h.realtimeService.Unsubscribe(sub)
+
+	server := websocket.Server{
+		// The JWT authenticates the client, so any origin is accepted.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			h.serveSocket(ws, sub)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *RealtimeHandler) serveSocket(ws *websocket.Conn, sub *services_realtime.Subscription) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var cmd socketCommand
+			if err := websocket.JSON.Receive(ws, &cmd); err != nil {
+				return
+			}
+			switch cmd.Action {
+			case "watch":
+				sub.Watch(cmd.ProductIDs...)
+			case "unwatch":
+				sub.Unwatch(cmd.ProductIDs...)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		case msg, open := <-sub.C():
+			if !open {
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				h.logger.Error("Layer: realtimeHandler, Method: serveSocket, Error:", err)
+				return
+			}
+		}
+	}
+}
+
+func (h *RealtimeHandler) subscribe(c *gin.Context, method string) (*services_realtime.Subscription, bool) {
+	products, err := parseIDs(c.Query("products"))
+	if err != nil {
+		h.logger.Error("Layer: realtimeHandler, Method: "+method+", Error:", err)
+		problem.Respond(c, errInvalidProducts)
+		return nil, false
+	}
+	lastEventID, err := lastEventID(c)
+	if err != nil {
+		h.logger.Error("Layer: realtimeHandler, Method: "+method+", Error:", err)
+		problem.Respond(c, errInvalidLastEventID)
+		return nil, false
+	}
+
+	user, ok := h.currentUser(c, method)
+	if !ok {
+		return nil, false
+	}
+
+	sub, err := h.realtimeService.Subscribe(user.ID, products, lastEventID)
+	if err != nil {
+		h.logger.Error("Layer: realtimeHandler, Method: "+method+", Error:", err)
+		problem.Respond(c, err)
+		return nil, false
+	}
+	return sub, true
+}
+
+func (h *RealtimeHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: realtimeHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, err)
+		return nil, false
+	}
+	return user, true
+}
+
+// lastEventID reads the Last-Event-ID header EventSource sends when it
+// reconnects, falling back to the last_event_id query parameter.
+func lastEventID(c *gin.Context) (uint, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	return uint(id), err
+}
+
+func parseIDs(list string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
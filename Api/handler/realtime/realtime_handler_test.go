@@ -0,0 +1,142 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_realtime "pruebaVertice/Api/services/realtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+func newTestServer(t *testing.T, repoMock *OutboxRepoMock) (*httptest.Server, *services_realtime.Hub) {
+	gin.SetMode(gin.TestMode)
+	hub := services_realtime.NewHub(repoMock, logrus.New())
+	userMock := &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+	h := NewRealtimeHandler(hub, userMock, logrus.New())
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userEmail", "user@example.com") })
+	router.GET("/orders/stream", h.OrdersStream)
+	router.GET("/orders/ws", h.OrdersSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, hub
+}
+
+func orderEvent(id, userID uint, status string) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:      id,
+		Type:    models.EventOrderStatusChanged,
+		Payload: fmt.Sprintf(`{"id":9,"user_id":%d,"status":%q}`, userID, status),
+	}
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(lines) > 0 {
+				return lines
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestOrdersStream_ResumesAndStreams(t *testing.T) {
+	repoMock := new(OutboxRepoMock)
+	repoMock.On("GetEventsAfter", uint(4), mock.Anything, mock.Anything).
+		Return([]models.OutboxEvent{orderEvent(5, 2, models.OrderStatusShipped)}, nil)
+	server, hub := newTestServer(t, repoMock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/orders/stream", nil)
+	req.Header.Set("Last-Event-ID", "4")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, []string{"retry: 3000"}, readEvent(t, reader))
+
+	event := readEvent(t, reader)
+	assert.Equal(t, "id: 5", event[0])
+	assert.Equal(t, "event: order_status", event[1])
+	assert.Contains(t, event[2], `"status":"shipped"`)
+
+	// Other users' orders are not streamed.
+	hub.Handle(orderEvent(6, 3, models.OrderStatusDelivered))
+	hub.Handle(orderEvent(7, 2, models.OrderStatusDelivered))
+	event = readEvent(t, reader)
+	assert.Equal(t, "id: 7", event[0])
+}
+
+func TestOrdersStream_InvalidProducts(t *testing.T) {
+	server, _ := newTestServer(t, new(OutboxRepoMock))
+
+	resp, err := http.Get(server.URL + "/orders/stream?products=1,abc")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestOrdersSocket_WatchesProducts(t *testing.T) {
+	server, hub := newTestServer(t, new(OutboxRepoMock))
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/orders/ws"
+	ws, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(5 * time.Second))
+
+	require.NoError(t, websocket.JSON.Send(ws, socketCommand{Action: "watch", ProductIDs: []uint{10}}))
+
+	// Stock changes are dropped until the watch command has been read, so
+	// keep publishing until one gets through.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for id := uint(1); ; id++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+				hub.Handle(models.OutboxEvent{ID: id, Type: models.EventStockChanged, Payload: `{"product_id":10,"stock":4}`})
+			}
+		}
+	}()
+
+	var msg struct {
+		ID    uint                   `json:"id"`
+		Event string                 `json:"event"`
+		Data  map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, websocket.JSON.Receive(ws, &msg))
+	assert.Equal(t, services_realtime.EventStock, msg.Event)
+	assert.Equal(t, 10.0, msg.Data["product_id"])
+	assert.Equal(t, 4.0, msg.Data["stock"])
+}
@@ -0,0 +1,72 @@
+package realtime
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// OutboxRepoMock mocks repo.OutboxRepository so the handler tests can run
+// against a real services_realtime.Hub.
+type OutboxRepoMock struct {
+	mock.Mock
+}
+
+func (m *OutboxRepoMock) GetPendingEvents(now time.Time, limit int) ([]models.OutboxEvent, error) {
+	args := m.Called(now, limit)
+	if res := args.Get(0); res != nil {
+		return res.([]models.OutboxEvent), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OutboxRepoMock) MarkPublished(id uint, publishedAt time.Time) error {
+	args := m.Called(id, publishedAt)
+	return args.Error(0)
+}
+
+func (m *OutboxRepoMock) UpdateEvent(event *models.OutboxEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *OutboxRepoMock) GetDeliveredSinks(eventID uint) ([]string, error) {
+	args := m.Called(eventID)
+	if res := args.Get(0); res != nil {
+		return res.([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OutboxRepoMock) MarkDelivered(eventID uint, sink string, deliveredAt time.Time) error {
+	args := m.Called(eventID, sink, deliveredAt)
+	return args.Error(0)
+}
+
+func (m *OutboxRepoMock) GetEventsAfter(afterID uint, types []string, limit int) ([]models.OutboxEvent, error) {
+	args := m.Called(afterID, types, limit)
+	if res := args.Get(0); res != nil {
+		return res.([]models.OutboxEvent), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UserServiceMock is a mock implementation of services_user.UserService.
+// Only GetUserByEmail is needed here.
+type UserServiceMock struct {
+	GetUserEmailFn func(email string) (*models.User, error)
+}
+
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
+func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
+	return m.GetUserEmailFn(email)
+}
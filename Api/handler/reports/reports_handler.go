@@ -0,0 +1,183 @@
+package reports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_report "pruebaVertice/Api/services/report"
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+var errInvalidFormat = apperr.BadRequest("invalid_format", "Invalid format, use json or csv")
+
+type ReportsHandler struct {
+	reportService services_report.ReportService
+	logger        *logrus.Logger
+}
+
+func NewReportsHandler(reportService services_report.ReportService, logger *logrus.Logger) *ReportsHandler {
+	return &ReportsHandler{
+		reportService: reportService,
+		logger:        logger,
+	}
+}
+
+// SalesReport godoc
+// @Summary Reporte de ventas
+// @Description Ingresos, envíos, cantidad de órdenes, unidades y valor promedio por orden en un rango de fechas, agrupados por día, semana o mes. Los ingresos no incluyen el costo de envío, que se informa por separado. Excluye órdenes canceladas. Requiere rol admin
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Fecha inicial inclusiva (YYYY-MM-DD), por defecto 30 días atrás"
+// @Param to query string false "Fecha final inclusiva (YYYY-MM-DD), por defecto hoy"
+// @Param interval query string false "Agrupación (day, week, month)"
+// @Param format query string false "Formato de salida (json, csv)"
+// @Success 200 {object} dto.SalesReportResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/reports/sales [get]
+func (h *ReportsHandler) SalesReport(c *gin.Context) {
+	filter, ok := h.bindFilter(c, "SalesReport")
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.SalesReport(filter)
+	if err != nil {
+		h.logger.Error("Layer: reportsHandler, Method: SalesReport, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		rows := [][]string{{"period", "revenue", "shipping", "orders", "units", "average_order_value"}}
+		for _, p := range report.Periods {
+			rows = append(rows, []string{p.Period, money(p.Revenue), money(p.Shipping), count(p.Orders), count(p.Units), money(p.AverageOrderValue)})
+		}
+		h.writeCSV(c, fmt.Sprintf("sales_%s_%s.csv", report.From, report.To), rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ProductSalesReport godoc
+// @Summary Reporte de unidades vendidas por producto
+// @Description Productos ordenados por unidades vendidas en un rango de fechas. Requiere rol admin
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Fecha inicial inclusiva (YYYY-MM-DD)"
+// @Param to query string false "Fecha final inclusiva (YYYY-MM-DD)"
+// @Param limit query int false "Cantidad de productos (máximo 100)"
+// @Param format query string false "Formato de salida (json, csv)"
+// @Success 200 {object} dto.ProductSalesReportResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/reports/products [get]
+func (h *ReportsHandler) ProductSalesReport(c *gin.Context) {
+	filter, ok := h.bindFilter(c, "ProductSalesReport")
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.ProductSalesReport(filter)
+	if err != nil {
+		h.logger.Error("Layer: reportsHandler, Method: ProductSalesReport, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		rows := [][]string{{"product_id", "name", "units", "revenue"}}
+		for _, p := range report.Items {
+			rows = append(rows, []string{count(int64(p.ProductID)), p.Name, count(p.Units), money(p.Revenue)})
+		}
+		h.writeCSV(c, fmt.Sprintf("products_%s_%s.csv", report.From, report.To), rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// CustomerSalesReport godoc
+// @Summary Reporte de mejores clientes
+// @Description Clientes ordenados por ingresos en un rango de fechas, sin incluir el costo de envío. Requiere rol admin
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Fecha inicial inclusiva (YYYY-MM-DD)"
+// @Param to query string false "Fecha final inclusiva (YYYY-MM-DD)"
+// @Param limit query int false "Cantidad de clientes (máximo 100)"
+// @Param format query string false "Formato de salida (json, csv)"
+// @Success 200 {object} dto.CustomerSalesReportResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/reports/customers [get]
+func (h *ReportsHandler) CustomerSalesReport(c *gin.Context) {
+	filter, ok := h.bindFilter(c, "CustomerSalesReport")
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.CustomerSalesReport(filter)
+	if err != nil {
+		h.logger.Error("Layer: reportsHandler, Method: CustomerSalesReport, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		rows := [][]string{{"user_id", "email", "orders", "revenue"}}
+		for _, cs := range report.Items {
+			rows = append(rows, []string{count(int64(cs.UserID)), cs.Email, count(cs.Orders), money(cs.Revenue)})
+		}
+		h.writeCSV(c, fmt.Sprintf("customers_%s_%s.csv", report.From, report.To), rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ReportsHandler) bindFilter(c *gin.Context, method string) (models.ReportFilter, bool) {
+	var filter models.ReportFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.logger.Error("Layer: reportsHandler, Method: "+method+", Error:", err)
+		validation.Respond(c, err)
+		return filter, false
+	}
+	if format := c.Query("format"); format != "" && format != "json" && format != "csv" {
+		problem.Respond(c, errInvalidFormat)
+		return filter, false
+	}
+	return filter, true
+}
+
+func (h *ReportsHandler) writeCSV(c *gin.Context, filename string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if err := w.WriteAll(rows); err != nil {
+		h.logger.Error("Layer: reportsHandler, Method: writeCSV, Error:", err)
+	}
+}
+
+func money(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func count(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
@@ -0,0 +1,101 @@
+package reports
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_report "pruebaVertice/Api/services/report"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func get(handler gin.HandlerFunc, url string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, url, nil)
+	handler(c)
+	return rec
+}
+
+var salesReport = &dto.SalesReportResponse{
+	From: "2025-03-01", To: "2025-03-02", Interval: models.ReportIntervalDay,
+	Revenue: 30, Shipping: 5, Orders: 2, Units: 3, AverageOrderValue: 15,
+	Periods: []models.SalesPeriod{
+		{Period: "2025-03-01", Revenue: 30, Shipping: 5, Orders: 2, Units: 3, AverageOrderValue: 15},
+		{Period: "2025-03-02"},
+	},
+}
+
+func TestSalesReport_JSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ReportServiceMock{}
+	serviceMock.On("SalesReport", mock.MatchedBy(func(f models.ReportFilter) bool {
+		return f.From.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) && f.Interval == models.ReportIntervalDay
+	})).Return(salesReport, nil)
+	h := NewReportsHandler(serviceMock, logrus.New())
+
+	rec := get(h.SalesReport, "/admin/reports/sales?from=2025-03-01&to=2025-03-02&interval=day")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp dto.SalesReportResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, *salesReport, resp)
+}
+
+func TestSalesReport_CSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ReportServiceMock{}
+	serviceMock.On("SalesReport", mock.Anything).Return(salesReport, nil)
+	h := NewReportsHandler(serviceMock, logrus.New())
+
+	rec := get(h.SalesReport, "/admin/reports/sales?format=csv")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "sales_2025-03-01_2025-03-02.csv")
+	assert.Equal(t, "period,revenue,shipping,orders,units,average_order_value\n"+
+		"2025-03-01,30.00,5.00,2,3,15.00\n"+
+		"2025-03-02,0.00,0.00,0,0,0.00\n", rec.Body.String())
+}
+
+func TestSalesReport_InvalidDate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewReportsHandler(&ReportServiceMock{}, logrus.New())
+
+	rec := get(h.SalesReport, "/admin/reports/sales?from=01-03-2025")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestProductSalesReport_InvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ReportServiceMock{}
+	serviceMock.On("ProductSalesReport", mock.Anything).Return(nil, services_report.ErrInvalidReportFilter)
+	h := NewReportsHandler(serviceMock, logrus.New())
+
+	rec := get(h.ProductSalesReport, "/admin/reports/products?limit=500")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCustomerSalesReport_CSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ReportServiceMock{}
+	serviceMock.On("CustomerSalesReport", mock.Anything).Return(&dto.CustomerSalesReportResponse{
+		From: "2025-03-01", To: "2025-03-31",
+		Items: []models.CustomerSales{{UserID: 1, Email: "ana@example.com", Orders: 2, Revenue: 50}},
+	}, nil)
+	h := NewReportsHandler(serviceMock, logrus.New())
+
+	rec := get(h.CustomerSalesReport, "/admin/reports/customers?format=csv")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user_id,email,orders,revenue\n1,ana@example.com,2,50.00\n", rec.Body.String())
+}
@@ -0,0 +1,38 @@
+package reports
+
+import (
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ReportServiceMock is a mock implementation of services_report.ReportService
+// for handler tests.
+type ReportServiceMock struct {
+	mock.Mock
+}
+
+func (m *ReportServiceMock) SalesReport(filter models.ReportFilter) (*dto.SalesReportResponse, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.SalesReportResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReportServiceMock) ProductSalesReport(filter models.ReportFilter) (*dto.ProductSalesReportResponse, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.ProductSalesReportResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReportServiceMock) CustomerSalesReport(filter models.ReportFilter) (*dto.CustomerSalesReportResponse, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.CustomerSalesReportResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
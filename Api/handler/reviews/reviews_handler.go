@@ -0,0 +1,186 @@
+package reviews
+
+import (
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_review "pruebaVertice/Api/services/review"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ReviewsHandler struct {
+	reviewService services_review.ReviewService
+	userService   services_user.UserService
+	logger        *logrus.Logger
+}
+
+func NewReviewsHandler(reviewService services_review.ReviewService, userService services_user.UserService, logger *logrus.Logger) *ReviewsHandler {
+	return &ReviewsHandler{
+		reviewService: reviewService,
+		userService:   userService,
+		logger:        logger,
+	}
+}
+
+// GetProductReviews godoc
+// @Summary Listar reseñas de un producto
+// @Description Devuelve las reseñas aprobadas de un producto, las más recientes primero
+// @Tags Reviews
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Success 200 {array} models.Review
+// @Failure 400 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/products/{id}/reviews [get]
+func (h *ReviewsHandler) GetProductReviews(c *gin.Context) {
+	productID, ok := h.parseID(c, "GetProductReviews", "product")
+	if !ok {
+		return
+	}
+
+	reviews, err := h.reviewService.GetProductReviews(productID)
+	if err != nil {
+		h.logger.Error("Layer: reviewsHandler, Method: GetProductReviews, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// CreateReview godoc
+// @Summary Reseñar un producto
+// @Description Crea una reseña de 1 a 5 estrellas. Solo pueden reseñar los usuarios que compraron el producto, una vez por producto. La reseña queda pendiente de moderación
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Param review body models.ReviewRequest true "Datos de la reseña"
+// @Success 201 {object} models.Review
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/products/{id}/reviews [post]
+func (h *ReviewsHandler) CreateReview(c *gin.Context) {
+	productID, ok := h.parseID(c, "CreateReview", "product")
+	if !ok {
+		return
+	}
+	var req models.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: reviewsHandler, Method: CreateReview, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "CreateReview")
+	if !ok {
+		return
+	}
+
+	review, err := h.reviewService.CreateReview(user, productID, req)
+	if err != nil {
+		h.logger.Error("Layer: reviewsHandler, Method: CreateReview, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// ListReviews godoc
+// @Summary Listar reseñas para moderar
+// @Description Devuelve las reseñas filtradas por estado, las más antiguas primero. Solo administradores
+// @Tags Reviews
+// @Produce json
+// @Param status query string false "Estado (pending, approved, rejected)"
+// @Success 200 {array} models.Review
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/reviews [get]
+func (h *ReviewsHandler) ListReviews(c *gin.Context) {
+	reviews, err := h.reviewService.ListReviews(c.Query("status"))
+	if err != nil {
+		h.logger.Error("Layer: reviewsHandler, Method: ListReviews, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// ModerateReview godoc
+// @Summary Moderar una reseña
+// @Description Aprueba o rechaza una reseña y recalcula la valoración del producto. Solo administradores
+// @Tags Reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la reseña"
+// @Param moderation body models.ReviewModerationRequest true "Nuevo estado"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/reviews/{id}/status [put]
+func (h *ReviewsHandler) ModerateReview(c *gin.Context) {
+	id, ok := h.parseID(c, "ModerateReview", "review")
+	if !ok {
+		return
+	}
+	var req models.ReviewModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: reviewsHandler, Method: ModerateReview, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	review, err := h.reviewService.ModerateReview(id, req.Status, c.GetString("userEmail"))
+	if err != nil {
+		h.logger.Error("Layer: reviewsHandler, Method: ModerateReview, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *ReviewsHandler) parseID(c *gin.Context, method, resource string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: reviewsHandler, Method: "+method+", Error: invalid ID:", err)
+		problem.Respond(c, problem.InvalidID(resource))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *ReviewsHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: reviewsHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, err)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,130 @@
+package reviews
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_review "pruebaVertice/Api/services/review"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newUserMock() *UserServiceMock {
+	return &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Username: "ana", Email: email}, nil
+		},
+	}
+}
+
+func newContext(method, path, id string, body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(method, path, bytes.NewReader(body))
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set("userEmail", "user@example.com")
+	return c, rec
+}
+
+func TestCreateReview_Success(t *testing.T) {
+	serviceMock := &ReviewServiceMock{}
+	req := models.ReviewRequest{Rating: 5, Title: "Genial"}
+	serviceMock.On("CreateReview", mock.MatchedBy(func(u *models.User) bool { return u.ID == 2 }), uint(7), req).
+		Return(&models.Review{ID: 1, ProductID: 7, Rating: 5, Status: models.ReviewStatusPending}, nil)
+	h := NewReviewsHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodPost, "/products/7/reviews", "7", []byte(`{"rating":5,"title":"Genial"}`))
+	h.CreateReview(c)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestCreateReview_RatingOutOfRange(t *testing.T) {
+	h := NewReviewsHandler(&ReviewServiceMock{}, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodPost, "/products/7/reviews", "7", []byte(`{"rating":6}`))
+	h.CreateReview(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCreateReview_ErrorStatuses(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not purchased", services_review.ErrNotPurchased, http.StatusForbidden},
+		{"already reviewed", services_review.ErrAlreadyReviewed, http.StatusConflict},
+		{"product not found", services_review.ErrProductNotFound, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceMock := &ReviewServiceMock{}
+			serviceMock.On("CreateReview", mock.Anything, uint(7), mock.Anything).Return(nil, tc.err)
+			h := NewReviewsHandler(serviceMock, newUserMock(), logrus.New())
+
+			c, rec := newContext(http.MethodPost, "/products/7/reviews", "7", []byte(`{"rating":4}`))
+			h.CreateReview(c)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+func TestGetProductReviews(t *testing.T) {
+	serviceMock := &ReviewServiceMock{}
+	serviceMock.On("GetProductReviews", uint(7)).Return([]models.Review{{ID: 1, ProductID: 7, Rating: 4, Author: "ana"}}, nil)
+	h := NewReviewsHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodGet, "/products/7/reviews", "7", nil)
+	h.GetProductReviews(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"author":"ana"`)
+
+	c, rec = newContext(http.MethodGet, "/products/x/reviews", "x", nil)
+	h.GetProductReviews(c)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListReviews_InvalidStatus(t *testing.T) {
+	serviceMock := &ReviewServiceMock{}
+	serviceMock.On("ListReviews", "spam").Return(nil, services_review.ErrInvalidStatus)
+	h := NewReviewsHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodGet, "/admin/reviews?status=spam", "", nil)
+	h.ListReviews(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestModerateReview(t *testing.T) {
+	serviceMock := &ReviewServiceMock{}
+	serviceMock.On("ModerateReview", uint(3), models.ReviewStatusApproved, "user@example.com").
+		Return(&models.Review{ID: 3, Status: models.ReviewStatusApproved}, nil)
+	serviceMock.On("ModerateReview", uint(4), models.ReviewStatusRejected, "user@example.com").
+		Return(nil, services_review.ErrReviewNotFound)
+	h := NewReviewsHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodPut, "/admin/reviews/3/status", "3", []byte(`{"status":"approved"}`))
+	h.ModerateReview(c)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	c, rec = newContext(http.MethodPut, "/admin/reviews/4/status", "4", []byte(`{"status":"rejected"}`))
+	h.ModerateReview(c)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	c, rec = newContext(http.MethodPut, "/admin/reviews/3/status", "3", []byte(`{"status":"pending"}`))
+	h.ModerateReview(c)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
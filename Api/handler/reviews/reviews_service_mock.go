@@ -0,0 +1,64 @@
+package reviews
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ReviewServiceMock is a mock implementation of services_review.ReviewService
+// for handler tests.
+type ReviewServiceMock struct {
+	mock.Mock
+}
+
+func (m *ReviewServiceMock) CreateReview(user *models.User, productID uint, req models.ReviewRequest) (*models.Review, error) {
+	args := m.Called(user, productID, req)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Review), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReviewServiceMock) GetProductReviews(productID uint) ([]models.Review, error) {
+	args := m.Called(productID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Review), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReviewServiceMock) ListReviews(status string) ([]models.Review, error) {
+	args := m.Called(status)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Review), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReviewServiceMock) ModerateReview(id uint, status, moderatedBy string) (*models.Review, error) {
+	args := m.Called(id, status, moderatedBy)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Review), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UserServiceMock is a mock implementation of services_user.UserService.
+// Only GetUserByEmail is needed here.
+type UserServiceMock struct {
+	GetUserEmailFn func(email string) (*models.User, error)
+}
+
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
+func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
+	return m.GetUserEmailFn(email)
+}
@@ -0,0 +1,185 @@
+package shipments
+
+import (
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_shipment "pruebaVertice/Api/services/shipment"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ShipmentsHandler struct {
+	shipmentService services_shipment.ShipmentService
+	userService     services_user.UserService
+	logger          *logrus.Logger
+}
+
+func NewShipmentsHandler(shipmentService services_shipment.ShipmentService, userService services_user.UserService, logger *logrus.Logger) *ShipmentsHandler {
+	return &ShipmentsHandler{
+		shipmentService: shipmentService,
+		userService:     userService,
+		logger:          logger,
+	}
+}
+
+// CreateShipment godoc
+// @Summary Crear un envío
+// @Description Registra un envío para una orden. Si no se indican ítems se envían todas las unidades pendientes. Requiere rol warehouse o admin
+// @Tags Shipments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la orden"
+// @Param shipment body models.CreateShipmentRequest true "Transportista, número de seguimiento e ítems"
+// @Success 201 {object} models.Shipment
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/warehouse/orders/{id}/shipments [post]
+func (h *ShipmentsHandler) CreateShipment(c *gin.Context) {
+	orderID, ok := h.pathID(c, "CreateShipment", "order")
+	if !ok {
+		return
+	}
+	var req models.CreateShipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: shipmentsHandler, Method: CreateShipment, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	shipment, err := h.shipmentService.CreateShipment(orderID, req, c.GetString("userEmail"))
+	if err != nil {
+		h.logger.Error("Layer: shipmentsHandler, Method: CreateShipment, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, shipment)
+}
+
+// GetOrderShipments godoc
+// @Summary Listar envíos de una orden
+// @Description Devuelve los envíos de cualquier orden con sus ítems y eventos de seguimiento. Requiere rol warehouse o admin
+// @Tags Shipments
+// @Produce json
+// @Param id path int true "ID de la orden"
+// @Success 200 {array} models.Shipment
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/warehouse/orders/{id}/shipments [get]
+func (h *ShipmentsHandler) GetOrderShipments(c *gin.Context) {
+	orderID, ok := h.pathID(c, "GetOrderShipments", "order")
+	if !ok {
+		return
+	}
+
+	shipments, err := h.shipmentService.GetOrderShipments(orderID)
+	if err != nil {
+		h.logger.Error("Layer: shipmentsHandler, Method: GetOrderShipments, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shipments)
+}
+
+// AddTrackingEvent godoc
+// @Summary Agregar evento de seguimiento
+// @Description Agrega un evento de seguimiento a un envío y actualiza el estado del envío y de la orden. Requiere rol warehouse o admin
+// @Tags Shipments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del envío"
+// @Param event body models.ShipmentEventRequest true "Evento de seguimiento (label_created, in_transit, out_for_delivery, delivered, exception)"
+// @Success 200 {object} models.Shipment
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/warehouse/shipments/{id}/events [post]
+func (h *ShipmentsHandler) AddTrackingEvent(c *gin.Context) {
+	shipmentID, ok := h.pathID(c, "AddTrackingEvent", "shipment")
+	if !ok {
+		return
+	}
+	var req models.ShipmentEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: shipmentsHandler, Method: AddTrackingEvent, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	shipment, err := h.shipmentService.AddTrackingEvent(shipmentID, req)
+	if err != nil {
+		h.logger.Error("Layer: shipmentsHandler, Method: AddTrackingEvent, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shipment)
+}
+
+// GetUserOrderTracking godoc
+// @Summary Seguimiento de una orden
+// @Description Devuelve los envíos y eventos de seguimiento de una orden del usuario autenticado
+// @Tags Orders
+// @Produce json
+// @Param id path int true "ID de la orden"
+// @Success 200 {array} models.Shipment
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/orders/{id}/shipments [get]
+func (h *ShipmentsHandler) GetUserOrderTracking(c *gin.Context) {
+	orderID, ok := h.pathID(c, "GetUserOrderTracking", "order")
+	if !ok {
+		return
+	}
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return
+	}
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: shipmentsHandler, Method: GetUserOrderTracking, Error fetching user:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	shipments, err := h.shipmentService.GetUserOrderShipments(user.ID, orderID)
+	if err != nil {
+		h.logger.Error("Layer: shipmentsHandler, Method: GetUserOrderTracking, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shipments)
+}
+
+func (h *ShipmentsHandler) pathID(c *gin.Context, method, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: shipmentsHandler, Method: "+method+", Error: invalid "+name+" ID:", err)
+		problem.Respond(c, problem.InvalidID(name))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,122 @@
+package shipments
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_shipment "pruebaVertice/Api/services/shipment"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newUserMock() *UserServiceMock {
+	return &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+}
+
+func TestCreateShipment_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.CreateShipmentRequest{Carrier: "Chilexpress", TrackingNumber: "CX1"}
+	serviceMock := &ShipmentServiceMock{}
+	serviceMock.On("CreateShipment", uint(7), req, "warehouse@example.com").
+		Return(&models.Shipment{ID: 3, OrderID: 7, Status: models.ShipmentStatusLabelCreated}, nil)
+	h := NewShipmentsHandler(serviceMock, newUserMock(), logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/warehouse/orders/7/shipments", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("userEmail", "warehouse@example.com")
+
+	h.CreateShipment(c)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestCreateShipment_NothingToShip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ShipmentServiceMock{}
+	serviceMock.On("CreateShipment", uint(7), models.CreateShipmentRequest{}, "warehouse@example.com").
+		Return(nil, services_shipment.ErrNothingToShip)
+	h := NewShipmentsHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/warehouse/orders/7/shipments", bytes.NewBufferString("{}"))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("userEmail", "warehouse@example.com")
+
+	h.CreateShipment(c)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestAddTrackingEvent_InvalidStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.ShipmentEventRequest{Status: "lost"}
+	serviceMock := &ShipmentServiceMock{}
+	serviceMock.On("AddTrackingEvent", uint(3), req).Return(nil, services_shipment.ErrInvalidEventStatus)
+	h := NewShipmentsHandler(serviceMock, newUserMock(), logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/warehouse/shipments/3/events", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	h.AddTrackingEvent(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestGetUserOrderTracking_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	shipments := []models.Shipment{{ID: 3, OrderID: 7, Status: models.ShipmentStatusInTransit}}
+	serviceMock := &ShipmentServiceMock{}
+	serviceMock.On("GetUserOrderShipments", uint(2), uint(7)).Return(shipments, nil)
+	h := NewShipmentsHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/orders/7/shipments", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("userEmail", "user@example.com")
+
+	h.GetUserOrderTracking(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp []models.Shipment
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, uint(3), resp[0].ID)
+}
+
+func TestGetUserOrderTracking_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &ShipmentServiceMock{}
+	serviceMock.On("GetUserOrderShipments", uint(2), uint(7)).Return(nil, services_shipment.ErrOrderNotFound)
+	h := NewShipmentsHandler(serviceMock, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/orders/7/shipments", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("userEmail", "user@example.com")
+
+	h.GetUserOrderTracking(c)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
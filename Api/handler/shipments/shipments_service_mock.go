@@ -0,0 +1,64 @@
+package shipments
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ShipmentServiceMock is a mock implementation of services_shipment.ShipmentService
+// for handler tests.
+type ShipmentServiceMock struct {
+	mock.Mock
+}
+
+func (m *ShipmentServiceMock) CreateShipment(orderID uint, req models.CreateShipmentRequest, createdBy string) (*models.Shipment, error) {
+	args := m.Called(orderID, req, createdBy)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Shipment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentServiceMock) AddTrackingEvent(shipmentID uint, req models.ShipmentEventRequest) (*models.Shipment, error) {
+	args := m.Called(shipmentID, req)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Shipment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentServiceMock) GetOrderShipments(orderID uint) ([]models.Shipment, error) {
+	args := m.Called(orderID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Shipment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentServiceMock) GetUserOrderShipments(userID, orderID uint) ([]models.Shipment, error) {
+	args := m.Called(userID, orderID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Shipment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UserServiceMock is a mock implementation of services_user.UserService.
+// Only GetUserByEmail is needed here.
+type UserServiceMock struct {
+	GetUserEmailFn func(email string) (*models.User, error)
+}
+
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
+func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
+	return m.GetUserEmailFn(email)
+}
@@ -0,0 +1,165 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// errWrongCurrentPassword answers a wrong current password. The caller is
+// authenticated, so it must not look like an expired session.
+var errWrongCurrentPassword = apperr.Invalid("invalid_current_password", "current password is incorrect")
+
+type AccountHandler struct {
+	accountService services_user.AccountService
+	userService    services_user.UserService
+	logger         *logrus.Logger
+}
+
+func NewAccountHandler(accountService services_user.AccountService, userService services_user.UserService, logger *logrus.Logger) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		userService:    userService,
+		logger:         logger,
+	}
+}
+
+// UpdateProfile godoc
+// @Summary Actualizar perfil
+// @Description Cambia el nombre de usuario y/o el email. Cambiar el email requiere la contraseña actual, avisa a la dirección anterior, obliga a verificar la nueva, cierra las demás sesiones, revoca las API keys y clientes OAuth y devuelve tokens nuevos en la respuesta
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body models.ProfileUpdateRequest true "Campos a cambiar"
+// @Success 200 {object} dto.UserSessionResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me [patch]
+func (h *AccountHandler) UpdateProfile(c *gin.Context) {
+	var req models.ProfileUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: accountHandler, Method: UpdateProfile, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "UpdateProfile")
+	if !ok {
+		return
+	}
+
+	updated, err := h.accountService.UpdateProfile(user, req, clientInfo(c))
+	if err != nil {
+		h.logger.Error("Layer: accountHandler, Method: UpdateProfile, Error:", err)
+		if errors.Is(err, services_user.ErrInvalidPassword) {
+			err = errWrongCurrentPassword
+		}
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewUserSessionResponse(updated))
+}
+
+// ChangePassword godoc
+// @Summary Cambiar contraseña
+// @Description Cambia la contraseña confirmando la actual. Cierra todas las demás sesiones y devuelve tokens nuevos para la sesión actual
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Contraseña actual y nueva"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/password [post]
+func (h *AccountHandler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: accountHandler, Method: ChangePassword, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "ChangePassword")
+	if !ok {
+		return
+	}
+
+	updated, err := h.accountService.ChangePassword(user, req, clientInfo(c))
+	if err != nil {
+		h.logger.Error("Layer: accountHandler, Method: ChangePassword, Error:", err)
+		if errors.Is(err, services_user.ErrInvalidPassword) {
+			err = errWrongCurrentPassword
+		}
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LoginResponse{Token: updated.Token, RefreshToken: updated.RefreshToken})
+}
+
+// DeleteAccount godoc
+// @Summary Eliminar cuenta
+// @Description Elimina la cuenta del usuario confirmando la contraseña actual: anonimiza sus datos personales, revoca sesiones, API keys y clientes OAuth y la da de baja. El historial de órdenes se conserva. No está disponible durante una suplantación
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body models.DeleteAccountRequest true "Contraseña actual"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: accountHandler, Method: DeleteAccount, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "DeleteAccount")
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.DeleteAccount(user, req, c.GetString("sessionID")); err != nil {
+		h.logger.Error("Layer: accountHandler, Method: DeleteAccount, Error:", err)
+		if errors.Is(err, services_user.ErrInvalidPassword) {
+			err = errWrongCurrentPassword
+		}
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+}
+
+func (h *AccountHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: accountHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,153 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func accountRequest(method, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(method, "/me", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userEmail", "ana@example.com")
+	return c, rec
+}
+
+func newAccountHandler() (*AccountHandler, *AccountServiceMock, *models.User) {
+	user := &models.User{Model: gorm.Model{ID: 4}, Email: "ana@example.com"}
+	userMock := &UserServiceMock{}
+	userMock.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+	accountMock := &AccountServiceMock{}
+	return NewAccountHandler(accountMock, userMock, logrus.New()), accountMock, user
+}
+
+func TestUpdateProfile_OnlyBindsProfileFields(t *testing.T) {
+	h, accountMock, user := newAccountHandler()
+	username := "ana.p"
+	accountMock.On("UpdateProfile", user, models.ProfileUpdateRequest{Username: &username}, mock.Anything).
+		Return(&models.User{Username: "ana.p", Email: "ana@example.com"}, nil)
+
+	c, rec := accountRequest(http.MethodPatch, `{"username":"ana.p","password":"x","role":"admin"}`)
+	h.UpdateProfile(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"username":"ana.p"`)
+	accountMock.AssertExpectations(t)
+}
+
+func TestUpdateProfile_Errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{"invalid email", `{"email":"not-an-email"}`, nil, http.StatusBadRequest},
+		{"email without password", `{"email":"bob@example.com"}`, nil, http.StatusBadRequest},
+		{"wrong password", `{"email":"bob@example.com","current_password":"guess"}`, services_user.ErrInvalidPassword, http.StatusUnprocessableEntity},
+		{"taken", `{"email":"bob@example.com","current_password":"secret"}`, services_user.ErrProfileTaken, http.StatusConflict},
+		{"empty", `{}`, services_user.ErrEmptyProfileUpdate, http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, accountMock, _ := newAccountHandler()
+			accountMock.On("UpdateProfile", mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.err)
+
+			c, rec := accountRequest(http.MethodPatch, tc.body)
+			h.UpdateProfile(c)
+
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestChangePassword_ReturnsNewTokens(t *testing.T) {
+	h, accountMock, user := newAccountHandler()
+	req := models.ChangePasswordRequest{CurrentPassword: "old-pass", NewPassword: "New-passw0rd"}
+	accountMock.On("ChangePassword", user, req, mock.Anything).
+		Return(&models.User{Token: "token", RefreshToken: "refresh"}, nil)
+
+	c, rec := accountRequest(http.MethodPost, `{"current_password":"old-pass","new_password":"New-passw0rd"}`)
+	h.ChangePassword(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp dto.LoginResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "token", resp.Token)
+	assert.Equal(t, "refresh", resp.RefreshToken)
+}
+
+func TestChangePassword_Errors(t *testing.T) {
+	h, accountMock, _ := newAccountHandler()
+	accountMock.On("ChangePassword", mock.Anything, mock.Anything, mock.Anything).Return(nil, services_user.ErrInvalidPassword)
+
+	c, rec := accountRequest(http.MethodPost, `{"current_password":"guess","new_password":"New-passw0rd"}`)
+	h.ChangePassword(c)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	c, rec = accountRequest(http.MethodPost, `{"current_password":"old-pass","new_password":"short"}`)
+	h.ChangePassword(c)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	accountMock.AssertNumberOfCalls(t, "ChangePassword", 1)
+}
+
+func TestDeleteAccount(t *testing.T) {
+	h, accountMock, user := newAccountHandler()
+	req := models.DeleteAccountRequest{CurrentPassword: "secret"}
+	accountMock.On("DeleteAccount", user, req, "sid").Return(nil).Once()
+
+	c, rec := accountRequest(http.MethodDelete, `{"current_password":"secret"}`)
+	c.Set("sessionID", "sid")
+	h.DeleteAccount(c)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	accountMock.On("DeleteAccount", user, req, "sid").Return(errors.New("db down"))
+	c, rec = accountRequest(http.MethodDelete, `{"current_password":"secret"}`)
+	c.Set("sessionID", "sid")
+	h.DeleteAccount(c)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestDeleteAccount_Errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{"missing password", `{}`, nil, http.StatusBadRequest},
+		{"wrong password", `{"current_password":"guess"}`, services_user.ErrInvalidPassword, http.StatusUnprocessableEntity},
+		{"impersonating", `{"current_password":"secret"}`, services_user.ErrImpersonating, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, accountMock, _ := newAccountHandler()
+			accountMock.On("DeleteAccount", mock.Anything, mock.Anything, mock.Anything).Return(tc.err)
+
+			c, rec := accountRequest(http.MethodDelete, tc.body)
+			h.DeleteAccount(c)
+
+			assert.Equal(t, tc.status, rec.Code)
+			if tc.err == nil {
+				accountMock.AssertNotCalled(t, "DeleteAccount", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
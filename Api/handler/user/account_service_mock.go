@@ -0,0 +1,33 @@
+package user
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AccountServiceMock is a mock implementation of
+// services_user.AccountService for handler tests.
+type AccountServiceMock struct {
+	mock.Mock
+}
+
+func (m *AccountServiceMock) UpdateProfile(user *models.User, req models.ProfileUpdateRequest, client models.ClientInfo) (*models.User, error) {
+	args := m.Called(user, req, client)
+	if res := args.Get(0); res != nil {
+		return res.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AccountServiceMock) ChangePassword(user *models.User, req models.ChangePasswordRequest, client models.ClientInfo) (*models.User, error) {
+	args := m.Called(user, req, client)
+	if res := args.Get(0); res != nil {
+		return res.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AccountServiceMock) DeleteAccount(user *models.User, req models.DeleteAccountRequest, tokenID string) error {
+	return m.Called(user, req, tokenID).Error(0)
+}
@@ -0,0 +1,66 @@
+package user
+
+import (
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AdminUserServiceMock is a mock implementation of
+// services_user.AdminUserService for handler tests.
+type AdminUserServiceMock struct {
+	mock.Mock
+}
+
+func (m *AdminUserServiceMock) SearchUsers(filter models.UserFilter) (*dto.AdminUserListResponse, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.AdminUserListResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AdminUserServiceMock) GetUser(id uint) (*models.User, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AdminUserServiceMock) SetDisabled(admin *models.User, id uint, disabled bool, client models.ClientInfo) (*models.User, error) {
+	args := m.Called(admin, id, disabled, client)
+	if res := args.Get(0); res != nil {
+		return res.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AdminUserServiceMock) ChangeRole(admin *models.User, id uint, role string, client models.ClientInfo) (*models.User, error) {
+	args := m.Called(admin, id, role, client)
+	if res := args.Get(0); res != nil {
+		return res.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AdminUserServiceMock) ForcePasswordReset(admin *models.User, id uint, client models.ClientInfo) error {
+	return m.Called(admin, id, client).Error(0)
+}
+
+func (m *AdminUserServiceMock) Impersonate(admin *models.User, id uint, client models.ClientInfo) (*dto.ImpersonationResponse, error) {
+	args := m.Called(admin, id, client)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.ImpersonationResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AdminUserServiceMock) ListAuditLog(filter models.AdminAuditFilter) (*dto.AdminAuditLogResponse, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.AdminAuditLogResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,290 @@
+package user
+
+import (
+	"net/http"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminUsersHandler struct {
+	adminService services_user.AdminUserService
+	userService  services_user.UserService
+	logger       *logrus.Logger
+}
+
+func NewAdminUsersHandler(adminService services_user.AdminUserService, userService services_user.UserService, logger *logrus.Logger) *AdminUsersHandler {
+	return &AdminUsersHandler{
+		adminService: adminService,
+		userService:  userService,
+		logger:       logger,
+	}
+}
+
+// SearchUsers godoc
+// @Summary Buscar usuarios
+// @Description Lista los usuarios paginados, buscando por parte del email o del nombre de usuario. Solo administradores
+// @Tags Admin
+// @Produce json
+// @Param q query string false "Texto a buscar en email o nombre de usuario"
+// @Param role query string false "Rol (customer, warehouse, admin)"
+// @Param status query string false "Estado de la cuenta (active, disabled)"
+// @Param page query int false "Número de página (por defecto 1)"
+// @Param page_size query int false "Tamaño de página (por defecto 20, máximo 100)"
+// @Success 200 {object} dto.AdminUserListResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/admin/users [get]
+func (h *AdminUsersHandler) SearchUsers(c *gin.Context) {
+	var filter models.UserFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: SearchUsers, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	users, err := h.adminService.SearchUsers(filter)
+	if err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: SearchUsers, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetUser godoc
+// @Summary Ver un usuario
+// @Description Devuelve la ficha de un usuario sin credenciales. Solo administradores
+// @Tags Admin
+// @Produce json
+// @Param id path int true "ID del usuario"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/admin/users/{id} [get]
+func (h *AdminUsersHandler) GetUser(c *gin.Context) {
+	id, ok := h.parseID(c, "GetUser")
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(id)
+	if err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: GetUser, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// SetUserStatus godoc
+// @Summary Bloquear o desbloquear una cuenta
+// @Description Bloquea o desbloquea la cuenta. Al bloquearla se cierran todas sus sesiones y se rechazan sus tokens y API keys. Queda registrado en la auditoría. Solo administradores
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del usuario"
+// @Param request body models.UserStatusRequest true "Nuevo estado"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/status [put]
+func (h *AdminUsersHandler) SetUserStatus(c *gin.Context) {
+	id, ok := h.parseID(c, "SetUserStatus")
+	if !ok {
+		return
+	}
+	var req models.UserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: SetUserStatus, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	admin, ok := h.currentUser(c, "SetUserStatus")
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.SetDisabled(admin, id, *req.Disabled, clientInfo(c))
+	if err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: SetUserStatus, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// ChangeUserRole godoc
+// @Summary Cambiar el rol de un usuario
+// @Description Asigna el rol customer, warehouse o admin. Queda registrado en la auditoría. Solo administradores
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del usuario"
+// @Param request body models.UserRoleRequest true "Nuevo rol"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/role [put]
+func (h *AdminUsersHandler) ChangeUserRole(c *gin.Context) {
+	id, ok := h.parseID(c, "ChangeUserRole")
+	if !ok {
+		return
+	}
+	var req models.UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: ChangeUserRole, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	admin, ok := h.currentUser(c, "ChangeUserRole")
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.ChangeRole(admin, id, req.Role, clientInfo(c))
+	if err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: ChangeUserRole, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// ForcePasswordReset godoc
+// @Summary Forzar el cambio de contraseña
+// @Description Invalida la contraseña actual, todas las sesiones, API keys y clientes OAuth del usuario y le envía un enlace para elegir una nueva. Queda registrado en la auditoría. Solo administradores
+// @Tags Admin
+// @Produce json
+// @Param id path int true "ID del usuario"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/password-reset [post]
+func (h *AdminUsersHandler) ForcePasswordReset(c *gin.Context) {
+	id, ok := h.parseID(c, "ForcePasswordReset")
+	if !ok {
+		return
+	}
+	admin, ok := h.currentUser(c, "ForcePasswordReset")
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.ForcePasswordReset(admin, id, clientInfo(c)); err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: ForcePasswordReset, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Password invalidated, a reset link has been sent to the user"})
+}
+
+// Impersonate godoc
+// @Summary Suplantar a un usuario
+// @Description Abre una sesión como el usuario para dar soporte y devuelve sus tokens. La sesión indica qué administrador la abrió y queda registrada en la auditoría. No se puede suplantar a administradores ni a cuentas bloqueadas. Solo administradores
+// @Tags Admin
+// @Produce json
+// @Param id path int true "ID del usuario"
+// @Success 200 {object} dto.ImpersonationResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/impersonate [post]
+func (h *AdminUsersHandler) Impersonate(c *gin.Context) {
+	id, ok := h.parseID(c, "Impersonate")
+	if !ok {
+		return
+	}
+	admin, ok := h.currentUser(c, "Impersonate")
+	if !ok {
+		return
+	}
+
+	res, err := h.adminService.Impersonate(admin, id, clientInfo(c))
+	if err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: Impersonate, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, res)
+}
+
+// ListAuditLog godoc
+// @Summary Auditoría de administración
+// @Description Lista las acciones de los administradores sobre cuentas de usuario, de la más reciente a la más antigua. Solo administradores
+// @Tags Admin
+// @Produce json
+// @Param user_id query int false "ID del usuario afectado"
+// @Param action query string false "Acción (user.disable, user.enable, user.change_role, user.force_password_reset, user.impersonate)"
+// @Param page query int false "Número de página (por defecto 1)"
+// @Param page_size query int false "Tamaño de página (por defecto 20, máximo 100)"
+// @Success 200 {object} dto.AdminAuditLogResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/admin/audit-log [get]
+func (h *AdminUsersHandler) ListAuditLog(c *gin.Context) {
+	var filter models.AdminAuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: ListAuditLog, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	entries, err := h.adminService.ListAuditLog(filter)
+	if err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: ListAuditLog, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *AdminUsersHandler) parseID(c *gin.Context, method string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: "+method+", Error: invalid user ID:", err)
+		problem.Respond(c, problem.InvalidID("user"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *AdminUsersHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: adminUsersHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,144 @@
+package user
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var adminUser = &models.User{Model: gorm.Model{ID: 1}, Email: "admin@example.com", Role: models.RoleAdmin}
+
+func newAdminUsersHandler() (*AdminUsersHandler, *AdminUserServiceMock) {
+	userMock := &UserServiceMock{}
+	userMock.On("GetUserByEmail", "admin@example.com").Return(adminUser, nil)
+	adminMock := &AdminUserServiceMock{}
+	return NewAdminUsersHandler(adminMock, userMock, logrus.New()), adminMock
+}
+
+func adminRequest(method, target, id, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if id != "" {
+		c.Params = gin.Params{{Key: "id", Value: id}}
+	}
+	c.Set("userEmail", "admin@example.com")
+	return c, rec
+}
+
+func TestSearchUsers_BindsQuery(t *testing.T) {
+	h, adminMock := newAdminUsersHandler()
+	adminMock.On("SearchUsers", models.UserFilter{Query: "ana", Status: "disabled", Page: 2}).
+		Return(&dto.AdminUserListResponse{Items: []dto.AdminUserResponse{{ID: 7, Email: "ana@example.com"}}, Page: 2}, nil)
+
+	c, rec := adminRequest(http.MethodGet, "/admin/users?q=ana&status=disabled&page=2", "", "")
+	h.SearchUsers(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"email":"ana@example.com"`)
+}
+
+func TestGetUser_HidesCredentials(t *testing.T) {
+	h, adminMock := newAdminUsersHandler()
+	adminMock.On("GetUser", uint(7)).Return(&models.User{Model: gorm.Model{ID: 7}, Email: "ana@example.com", Password: "hashed", Token: "tok"}, nil)
+
+	c, rec := adminRequest(http.MethodGet, "/admin/users/7", "7", "")
+	h.GetUser(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "hashed")
+	assert.NotContains(t, rec.Body.String(), "tok")
+}
+
+func TestSetUserStatus(t *testing.T) {
+	cases := []struct {
+		name   string
+		id     string
+		body   string
+		err    error
+		status int
+	}{
+		{"disabled", "7", `{"disabled":true}`, nil, http.StatusOK},
+		{"missing flag", "7", `{}`, nil, http.StatusBadRequest},
+		{"invalid id", "x", `{"disabled":true}`, nil, http.StatusBadRequest},
+		{"self", "7", `{"disabled":true}`, services_user.ErrCannotModifySelf, http.StatusConflict},
+		{"unknown", "7", `{"disabled":true}`, services_user.ErrUserNotFound, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, adminMock := newAdminUsersHandler()
+			var user *models.User
+			if tc.err == nil {
+				user = &models.User{Model: gorm.Model{ID: 7}}
+			}
+			adminMock.On("SetDisabled", adminUser, uint(7), true, mock.Anything).Return(user, tc.err)
+
+			c, rec := adminRequest(http.MethodPut, "/admin/users/"+tc.id+"/status", tc.id, tc.body)
+			h.SetUserStatus(c)
+
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestChangeUserRole_RejectsUnknownRole(t *testing.T) {
+	h, adminMock := newAdminUsersHandler()
+
+	c, rec := adminRequest(http.MethodPut, "/admin/users/7/role", "7", `{"role":"root"}`)
+	h.ChangeUserRole(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	adminMock.AssertNotCalled(t, "ChangeRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	h, adminMock := newAdminUsersHandler()
+	adminMock.On("ForcePasswordReset", adminUser, uint(7), mock.Anything).Return(nil)
+
+	c, rec := adminRequest(http.MethodPost, "/admin/users/7/password-reset", "7", "")
+	h.ForcePasswordReset(c)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func TestImpersonate(t *testing.T) {
+	h, adminMock := newAdminUsersHandler()
+	adminMock.On("Impersonate", adminUser, uint(7), mock.Anything).
+		Return(&dto.ImpersonationResponse{Token: "tok", RefreshToken: "ref", User: dto.AdminUserResponse{ID: 7}}, nil)
+	adminMock.On("Impersonate", adminUser, uint(8), mock.Anything).Return(nil, services_user.ErrCannotImpersonateAdmin)
+
+	c, rec := adminRequest(http.MethodPost, "/admin/users/7/impersonate", "7", "")
+	h.Impersonate(c)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `"token":"tok"`)
+
+	c, rec = adminRequest(http.MethodPost, "/admin/users/8/impersonate", "8", "")
+	h.Impersonate(c)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestListAuditLog(t *testing.T) {
+	h, adminMock := newAdminUsersHandler()
+	adminMock.On("ListAuditLog", models.AdminAuditFilter{UserID: 7}).
+		Return(&dto.AdminAuditLogResponse{Items: []models.AdminAuditEntry{{ID: 1, Action: models.AdminActionImpersonate}}}, nil)
+
+	c, rec := adminRequest(http.MethodGet, "/admin/audit-log?user_id=7", "", "")
+	h.ListAuditLog(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), models.AdminActionImpersonate)
+}
@@ -0,0 +1,89 @@
+package user
+
+import (
+	"net/http"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/problem"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+var errInvalidActiveFilter = apperr.BadRequest("invalid_lockout_filter", "Invalid active filter")
+
+type LockoutHandler struct {
+	throttle services_user.LoginThrottle
+	logger   *logrus.Logger
+}
+
+func NewLockoutHandler(throttle services_user.LoginThrottle, logger *logrus.Logger) *LockoutHandler {
+	return &LockoutHandler{
+		throttle: throttle,
+		logger:   logger,
+	}
+}
+
+// ListLockouts godoc
+// @Summary Listar bloqueos de login
+// @Description Devuelve los contadores de intentos fallidos por cuenta e IP. Con active=true solo los que siguen bloqueados. Requiere rol admin
+// @Tags Users
+// @Produce json
+// @Param active query bool false "Solo bloqueos vigentes"
+// @Success 200 {array} models.LoginAttempt
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/login-lockouts [get]
+func (h *LockoutHandler) ListLockouts(c *gin.Context) {
+	activeOnly := false
+	if value := c.Query("active"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			problem.Respond(c, errInvalidActiveFilter)
+			return
+		}
+		activeOnly = parsed
+	}
+
+	lockouts, err := h.throttle.ListLockouts(activeOnly)
+	if err != nil {
+		h.logger.Error("Layer: lockoutHandler, Method: ListLockouts, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lockouts)
+}
+
+// ClearLockout godoc
+// @Summary Eliminar bloqueo de login
+// @Description Borra el contador de intentos fallidos y desbloquea la cuenta o IP. Requiere rol admin
+// @Tags Users
+// @Produce json
+// @Param id path int true "ID del contador"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/login-lockouts/{id} [delete]
+func (h *LockoutHandler) ClearLockout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		problem.Respond(c, problem.InvalidID("lockout"))
+		return
+	}
+
+	if err := h.throttle.ClearLockout(uint(id)); err != nil {
+		h.logger.Error("Layer: lockoutHandler, Method: ClearLockout, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	h.logger.Infof("Layer: lockoutHandler, Method: ClearLockout, lockout %d cleared by %s", id, c.GetString("userEmail"))
+	c.JSON(http.StatusOK, gin.H{"message": "Lockout cleared"})
+}
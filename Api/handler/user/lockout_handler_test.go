@@ -0,0 +1,80 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginUser_LockedOut(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &UserServiceMock{}
+	serviceMock.On("Login", "u@e.com", "pwd", models.ClientInfo{IP: "10.0.0.1"}).Return(nil, &services_user.LoginLockedError{RetryAfter: 30 * time.Second})
+	h := NewUserHandler(serviceMock, logrus.New())
+
+	body, _ := json.Marshal(models.User{Email: "u@e.com", Password: "pwd"})
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+	c.Request.RemoteAddr = "10.0.0.1:51234"
+
+	h.LoginUser(c)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	serviceMock.AssertExpectations(t)
+}
+
+func TestListLockouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lockouts := []models.LoginAttempt{{ID: 1, Scope: models.LoginScopeAccount, Identifier: "ana@example.com", Failures: 10}}
+	throttle := &LoginThrottleMock{}
+	throttle.On("ListLockouts", true).Return(lockouts, nil)
+	h := NewLockoutHandler(throttle, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/admin/login-lockouts?active=true", nil)
+
+	h.ListLockouts(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp []models.LoginAttempt
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "ana@example.com", resp[0].Identifier)
+
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/admin/login-lockouts?active=maybe", nil)
+	h.ListLockouts(c)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestClearLockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	throttle := &LoginThrottleMock{}
+	throttle.On("ClearLockout", uint(1)).Return(nil)
+	throttle.On("ClearLockout", uint(2)).Return(fmt.Errorf("%w: 2", services_user.ErrLockoutNotFound))
+	h := NewLockoutHandler(throttle, logrus.New())
+
+	for id, code := range map[string]int{"1": http.StatusOK, "2": http.StatusNotFound, "x": http.StatusBadRequest} {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/admin/login-lockouts/"+id, nil)
+
+		h.ClearLockout(c)
+
+		assert.Equal(t, code, rec.Code, id)
+	}
+}
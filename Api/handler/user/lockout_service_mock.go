@@ -0,0 +1,37 @@
+package user
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// LoginThrottleMock is a mock implementation of services_user.LoginThrottle
+// for handler tests.
+type LoginThrottleMock struct {
+	mock.Mock
+}
+
+func (m *LoginThrottleMock) Check(email, ip string) error {
+	return m.Called(email, ip).Error(0)
+}
+
+func (m *LoginThrottleMock) RecordFailure(email, ip string) error {
+	return m.Called(email, ip).Error(0)
+}
+
+func (m *LoginThrottleMock) RecordSuccess(email, ip string) error {
+	return m.Called(email, ip).Error(0)
+}
+
+func (m *LoginThrottleMock) ListLockouts(activeOnly bool) ([]models.LoginAttempt, error) {
+	args := m.Called(activeOnly)
+	if res := args.Get(0); res != nil {
+		return res.([]models.LoginAttempt), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *LoginThrottleMock) ClearLockout(id uint) error {
+	return m.Called(id).Error(0)
+}
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PasswordHandler struct {
+	resetService services_user.PasswordResetService
+	logger       *logrus.Logger
+}
+
+func NewPasswordHandler(resetService services_user.PasswordResetService, logger *logrus.Logger) *PasswordHandler {
+	return &PasswordHandler{
+		resetService: resetService,
+		logger:       logger,
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Solicitar restablecimiento de contraseña
+// @Description Envía por correo un enlace de un solo uso para restablecer la contraseña. La respuesta es la misma exista o no la cuenta
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Email de la cuenta"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/auth/password/forgot [post]
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: passwordHandler, Method: ForgotPassword, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	if err := h.resetService.RequestPasswordReset(req.Email); err != nil {
+		h.logger.Error("Layer: passwordHandler, Method: ForgotPassword, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the email is registered, a reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary Restablecer contraseña
+// @Description Cambia la contraseña usando el token recibido por correo y cierra todas las sesiones abiertas
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Token y nueva contraseña"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/auth/password/reset [post]
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: passwordHandler, Method: ResetPassword, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	if err := h.resetService.ResetPassword(req.Token, req.Password); err != nil {
+		h.logger.Error("Layer: passwordHandler, Method: ResetPassword, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
@@ -0,0 +1,74 @@
+package user
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	services_user "pruebaVertice/Api/services/user"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func postJSON(body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/password", bytes.NewReader([]byte(body)))
+	return c, rec
+}
+
+func TestForgotPassword_Accepted(t *testing.T) {
+	serviceMock := &PasswordResetServiceMock{}
+	serviceMock.On("RequestPasswordReset", "ana@example.com").Return(nil)
+	h := NewPasswordHandler(serviceMock, logrus.New())
+
+	c, rec := postJSON(`{"email":"ana@example.com"}`)
+	h.ForgotPassword(c)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestForgotPassword_InvalidEmail(t *testing.T) {
+	h := NewPasswordHandler(&PasswordResetServiceMock{}, logrus.New())
+
+	c, rec := postJSON(`{"email":"no-es-un-email"}`)
+	h.ForgotPassword(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestForgotPassword_SendFails(t *testing.T) {
+	serviceMock := &PasswordResetServiceMock{}
+	serviceMock.On("RequestPasswordReset", "ana@example.com").Return(errors.New("smtp down"))
+	h := NewPasswordHandler(serviceMock, logrus.New())
+
+	c, rec := postJSON(`{"email":"ana@example.com"}`)
+	h.ForgotPassword(c)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "smtp")
+}
+
+func TestResetPassword(t *testing.T) {
+	serviceMock := &PasswordResetServiceMock{}
+	serviceMock.On("ResetPassword", "good", "Nueva-Clave1").Return(nil)
+	serviceMock.On("ResetPassword", "bad", "Nueva-Clave1").Return(services_user.ErrInvalidResetToken)
+	h := NewPasswordHandler(serviceMock, logrus.New())
+
+	c, rec := postJSON(`{"token":"good","password":"Nueva-Clave1"}`)
+	h.ResetPassword(c)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	c, rec = postJSON(`{"token":"bad","password":"Nueva-Clave1"}`)
+	h.ResetPassword(c)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	c, rec = postJSON(`{"token":"good","password":"corta"}`)
+	h.ResetPassword(c)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
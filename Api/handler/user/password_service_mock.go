@@ -0,0 +1,17 @@
+package user
+
+import "github.com/stretchr/testify/mock"
+
+// PasswordResetServiceMock is a mock implementation of
+// services_user.PasswordResetService for handler tests.
+type PasswordResetServiceMock struct {
+	mock.Mock
+}
+
+func (m *PasswordResetServiceMock) RequestPasswordReset(email string) error {
+	return m.Called(email).Error(0)
+}
+
+func (m *PasswordResetServiceMock) ResetPassword(token, newPassword string) error {
+	return m.Called(token, newPassword).Error(0)
+}
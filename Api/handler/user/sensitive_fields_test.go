@@ -0,0 +1,189 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const (
+	leakedHash    = "$2a$10$leaked.bcrypt.hash"
+	leakedToken   = "leaked-access-token"
+	leakedRefresh = "leaked-refresh-token"
+	leakedTOTP    = "LEAKEDTOTPSECRET"
+)
+
+// sensitiveKeys may never appear anywhere in a response body.
+var sensitiveKeys = []string{"password", "totp_secret", "totp_last_step", "sessions_revoked_at", "verification_sent_at"}
+
+// leakyUser has every secret a stored user can carry filled in.
+func leakyUser() *models.User {
+	now := time.Now()
+	return &models.User{
+		Model:              gorm.Model{ID: 9, CreatedAt: now},
+		Username:           "ana",
+		Email:              "ana@example.com",
+		Password:           leakedHash,
+		Token:              leakedToken,
+		RefreshToken:       leakedRefresh,
+		Role:               models.RoleCustomer,
+		SessionsRevokedAt:  &now,
+		VerificationSentAt: &now,
+		TOTPSecret:         leakedTOTP,
+		TOTPLastStep:       42,
+		TwoFactorEnabledAt: &now,
+	}
+}
+
+func sensitiveRequest(method, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(method, "/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	c.Set("userEmail", "admin@example.com")
+	return c, rec
+}
+
+// TestResponsesNeverExposeSensitiveFields runs every handler that returns a
+// user and fails if the body carries the password hash, the TOTP secret or
+// any internal bookkeeping field. Stored tokens may only appear in responses
+// that open a session, where they are the credentials just issued.
+func TestResponsesNeverExposeSensitiveFields(t *testing.T) {
+	cases := []struct {
+		name         string
+		issuesTokens bool
+		run          func(user *models.User) *httptest.ResponseRecorder
+	}{
+		{"register", true, func(user *models.User) *httptest.ResponseRecorder {
+			userMock := &UserServiceMock{}
+			userMock.On("CreateUser", mock.Anything, mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodPost, `{"username":"ana","email":"ana@example.com","password":"Secret123"}`)
+			NewUserHandler(userMock, logrus.New()).CreateUser(c)
+			return rec
+		}},
+		{"login", true, func(user *models.User) *httptest.ResponseRecorder {
+			userMock := &UserServiceMock{}
+			userMock.On("Login", mock.Anything, mock.Anything, mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodPost, `{"email":"ana@example.com","password":"Secret123"}`)
+			NewUserHandler(userMock, logrus.New()).LoginUser(c)
+			return rec
+		}},
+		{"me", false, func(user *models.User) *httptest.ResponseRecorder {
+			userMock := &UserServiceMock{}
+			userMock.On("GetUserByEmail", mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodGet, "")
+			NewUserHandler(userMock, logrus.New()).GetLoggedInUser(c)
+			return rec
+		}},
+		{"get user by id", false, func(user *models.User) *httptest.ResponseRecorder {
+			userMock := &UserServiceMock{}
+			userMock.On("GetUserByID", "9").Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodGet, "")
+			NewUserHandler(userMock, logrus.New()).GetUserByID(c)
+			return rec
+		}},
+		{"update user", false, func(user *models.User) *httptest.ResponseRecorder {
+			userMock := &UserServiceMock{}
+			userMock.On("UpdateUser", mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodPut, `{"username":"ana"}`)
+			NewUserHandler(userMock, logrus.New()).UpdateUser(c)
+			return rec
+		}},
+		{"update profile", true, func(user *models.User) *httptest.ResponseRecorder {
+			userMock := &UserServiceMock{}
+			userMock.On("GetUserByEmail", mock.Anything).Return(leakyUser(), nil)
+			accountMock := &AccountServiceMock{}
+			accountMock.On("UpdateProfile", mock.Anything, mock.Anything, mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodPatch, `{"email":"new@example.com","current_password":"secret"}`)
+			NewAccountHandler(accountMock, userMock, logrus.New()).UpdateProfile(c)
+			return rec
+		}},
+		{"change password", true, func(user *models.User) *httptest.ResponseRecorder {
+			userMock := &UserServiceMock{}
+			userMock.On("GetUserByEmail", mock.Anything).Return(leakyUser(), nil)
+			accountMock := &AccountServiceMock{}
+			accountMock.On("ChangePassword", mock.Anything, mock.Anything, mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodPost, `{"current_password":"secret123","new_password":"New-secret1"}`)
+			NewAccountHandler(accountMock, userMock, logrus.New()).ChangePassword(c)
+			return rec
+		}},
+		{"complete two-factor login", true, func(user *models.User) *httptest.ResponseRecorder {
+			twoFactorMock := &TwoFactorServiceMock{}
+			twoFactorMock.On("CompleteLogin", mock.Anything, mock.Anything, mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodPost, `{"challenge_token":"challenge","code":"123456"}`)
+			NewTwoFactorHandler(twoFactorMock, &UserServiceMock{}, logrus.New()).CompleteLogin(c)
+			return rec
+		}},
+		{"admin get user", false, func(user *models.User) *httptest.ResponseRecorder {
+			h, adminMock := newAdminUsersHandler()
+			adminMock.On("GetUser", uint(9)).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodGet, "")
+			h.GetUser(c)
+			return rec
+		}},
+		{"admin set status", false, func(user *models.User) *httptest.ResponseRecorder {
+			h, adminMock := newAdminUsersHandler()
+			adminMock.On("SetDisabled", mock.Anything, uint(9), true, mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodPut, `{"disabled":true}`)
+			h.SetUserStatus(c)
+			return rec
+		}},
+		{"admin change role", false, func(user *models.User) *httptest.ResponseRecorder {
+			h, adminMock := newAdminUsersHandler()
+			adminMock.On("ChangeRole", mock.Anything, uint(9), models.RoleWarehouse, mock.Anything).Return(user, nil)
+			c, rec := sensitiveRequest(http.MethodPut, `{"role":"warehouse"}`)
+			h.ChangeUserRole(c)
+			return rec
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := tc.run(leakyUser())
+			require.Less(t, rec.Code, 300, rec.Body.String())
+
+			body := rec.Body.String()
+			assert.NotContains(t, body, leakedHash)
+			assert.NotContains(t, body, leakedTOTP)
+			if !tc.issuesTokens {
+				assert.NotContains(t, body, leakedToken)
+				assert.NotContains(t, body, leakedRefresh)
+			}
+
+			var decoded interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+			for _, key := range jsonKeys(decoded) {
+				assert.NotContains(t, sensitiveKeys, key)
+			}
+		})
+	}
+}
+
+func jsonKeys(v interface{}) []string {
+	var keys []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			keys = append(keys, key)
+			keys = append(keys, jsonKeys(value)...)
+		}
+	case []interface{}:
+		for _, value := range v {
+			keys = append(keys, jsonKeys(value)...)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type SessionHandler struct {
+	sessionService services_user.SessionService
+	userService    services_user.UserService
+	logger         *logrus.Logger
+}
+
+func NewSessionHandler(sessionService services_user.SessionService, userService services_user.UserService, logger *logrus.Logger) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		userService:    userService,
+		logger:         logger,
+	}
+}
+
+// ListSessions godoc
+// @Summary Listar sesiones activas
+// @Description Devuelve los dispositivos en los que el usuario tiene la sesión iniciada, con la IP y la última actividad. La sesión actual se marca con current
+// @Tags Users
+// @Produce json
+// @Success 200 {array} models.Session
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	user, ok := h.currentUser(c, "ListSessions")
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(user, c.GetString("sessionID"))
+	if err != nil {
+		h.logger.Error("Layer: sessionHandler, Method: ListSessions, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary Cerrar una sesión
+// @Description Revoca una sesión del usuario; sus tokens dejan de aceptarse de inmediato. Revocar la sesión actual equivale a cerrar sesión
+// @Tags Users
+// @Produce json
+// @Param id path int true "ID de la sesión"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: sessionHandler, Method: RevokeSession, Error: invalid session ID:", err)
+		problem.Respond(c, problem.InvalidID("session"))
+		return
+	}
+	user, ok := h.currentUser(c, "RevokeSession")
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(user, uint(id)); err != nil {
+		h.logger.Error("Layer: sessionHandler, Method: RevokeSession, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+func (h *SessionHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: sessionHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestListSessions_PassesCurrentSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{Model: gorm.Model{ID: 4}, Email: "ana@example.com"}
+	userMock := &UserServiceMock{}
+	userMock.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+	sessionMock := &SessionServiceMock{}
+	sessionMock.On("ListSessions", user, "sid").Return([]models.Session{
+		{ID: 1, Device: "Chrome on Windows", TokenID: "sid", Current: true},
+	}, nil)
+	h := NewSessionHandler(sessionMock, userMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me/sessions", nil)
+	c.Set("userEmail", "ana@example.com")
+	c.Set("sessionID", "sid")
+
+	h.ListSessions(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "token_id")
+	var resp []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, true, resp[0]["current"])
+}
+
+func TestRevokeSession(t *testing.T) {
+	cases := []struct {
+		name   string
+		id     string
+		err    error
+		status int
+	}{
+		{"revoked", "3", nil, http.StatusOK},
+		{"not found", "3", services_user.ErrSessionNotFound, http.StatusNotFound},
+		{"invalid id", "abc", nil, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			user := &models.User{Model: gorm.Model{ID: 4}, Email: "ana@example.com"}
+			userMock := &UserServiceMock{}
+			userMock.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+			sessionMock := &SessionServiceMock{}
+			sessionMock.On("RevokeSession", user, uint(3)).Return(tc.err)
+			h := NewSessionHandler(sessionMock, userMock, logrus.New())
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request, _ = http.NewRequest(http.MethodDelete, "/me/sessions/"+tc.id, nil)
+			c.Params = gin.Params{{Key: "id", Value: tc.id}}
+			c.Set("userEmail", "ana@example.com")
+
+			h.RevokeSession(c)
+
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
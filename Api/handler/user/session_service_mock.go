@@ -0,0 +1,45 @@
+package user
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// SessionServiceMock is a mock implementation of
+// services_user.SessionService for handler tests.
+type SessionServiceMock struct {
+	mock.Mock
+}
+
+func (m *SessionServiceMock) Start(user *models.User, client models.ClientInfo) (*models.Session, error) {
+	args := m.Called(user, client)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Session), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SessionServiceMock) ListSessions(user *models.User, currentTokenID string) ([]models.Session, error) {
+	args := m.Called(user, currentTokenID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Session), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SessionServiceMock) RevokeSession(user *models.User, id uint) error {
+	return m.Called(user, id).Error(0)
+}
+
+func (m *SessionServiceMock) CheckSession(tokenID string, userID uint) error {
+	return m.Called(tokenID, userID).Error(0)
+}
+
+func (m *SessionServiceMock) GetSession(tokenID string) (*models.Session, error) {
+	args := m.Called(tokenID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Session), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,183 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// errLoginCodeRejected answers a wrong code at login, where it is a failed
+// authentication rather than an invalid field.
+var errLoginCodeRejected = apperr.Unauthorized("invalid_two_factor_code", "invalid two-factor code")
+
+type TwoFactorHandler struct {
+	twoFactorService services_user.TwoFactorService
+	userService      services_user.UserService
+	logger           *logrus.Logger
+}
+
+func NewTwoFactorHandler(twoFactorService services_user.TwoFactorService, userService services_user.UserService, logger *logrus.Logger) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		userService:      userService,
+		logger:           logger,
+	}
+}
+
+// EnrollTwoFactor godoc
+// @Summary Iniciar activación de 2FA
+// @Description Genera un secreto TOTP y la URI otpauth:// para mostrar como código QR. El 2FA no se exige hasta confirmarlo
+// @Tags Users
+// @Produce json
+// @Success 200 {object} dto.TwoFactorEnrollmentResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/2fa/enroll [post]
+func (h *TwoFactorHandler) EnrollTwoFactor(c *gin.Context) {
+	user, ok := h.currentUser(c, "EnrollTwoFactor")
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.twoFactorService.Enroll(user)
+	if err != nil {
+		h.logger.Error("Layer: twoFactorHandler, Method: EnrollTwoFactor, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirmar activación de 2FA
+// @Description Activa el 2FA con un código de la aplicación autenticadora y devuelve los códigos de recuperación. Solo se muestran una vez
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodeRequest true "Código TOTP"
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/2fa/confirm [post]
+func (h *TwoFactorHandler) ConfirmTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: twoFactorHandler, Method: ConfirmTwoFactor, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "ConfirmTwoFactor")
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(user, req.Code)
+	if err != nil {
+		h.logger.Error("Layer: twoFactorHandler, Method: ConfirmTwoFactor, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor godoc
+// @Summary Desactivar 2FA
+// @Description Desactiva el 2FA con un código TOTP o un código de recuperación
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodeRequest true "Código TOTP o de recuperación"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/2fa/disable [post]
+func (h *TwoFactorHandler) DisableTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: twoFactorHandler, Method: DisableTwoFactor, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "DisableTwoFactor")
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(user, req.Code); err != nil {
+		h.logger.Error("Layer: twoFactorHandler, Method: DisableTwoFactor, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// CompleteLogin godoc
+// @Summary Completar login con 2FA
+// @Description Canjea el challenge_token devuelto por el login y un código TOTP o de recuperación por los tokens de sesión
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorLoginRequest true "Challenge y código"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Router /api/auth/login/2fa [post]
+func (h *TwoFactorHandler) CompleteLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: twoFactorHandler, Method: CompleteLogin, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	logged, err := h.twoFactorService.CompleteLogin(req.ChallengeToken, req.Code, clientInfo(c))
+	if lockedOut(c, err) {
+		h.logger.Warn("Layer: twoFactorHandler, Method: CompleteLogin, Error:", err)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Layer: twoFactorHandler, Method: CompleteLogin, Error:", err)
+		if errors.Is(err, services_user.ErrInvalidTwoFactorCode) {
+			err = errLoginCodeRejected
+		}
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LoginResponse{Token: logged.Token, RefreshToken: logged.RefreshToken})
+}
+
+func (h *TwoFactorHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: twoFactorHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
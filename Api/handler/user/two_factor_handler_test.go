@@ -0,0 +1,141 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginUser_TwoFactorChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &UserServiceMock{}
+	serviceMock.On("Login", "u@e.com", "pwd", models.ClientInfo{}).Return(nil, &services_user.TwoFactorRequiredError{ChallengeToken: "challenge"})
+	h := NewUserHandler(serviceMock, logrus.New())
+
+	body, _ := json.Marshal(models.User{Email: "u@e.com", Password: "pwd"})
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+
+	h.LoginUser(c)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	var resp dto.TwoFactorChallengeResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, dto.TwoFactorChallengeResponse{TwoFactorRequired: true, ChallengeToken: "challenge"}, resp)
+	assert.NotContains(t, rec.Body.String(), `"token"`)
+}
+
+func TestCompleteLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name string
+		user *models.User
+		err  error
+		code int
+	}{
+		{"valid code", &models.User{Token: "t1", RefreshToken: "r1"}, nil, http.StatusOK},
+		{"wrong code", nil, services_user.ErrInvalidTwoFactorCode, http.StatusUnauthorized},
+		{"expired challenge", nil, services_user.ErrInvalidChallenge, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceMock := &TwoFactorServiceMock{}
+			serviceMock.On("CompleteLogin", "challenge", "123456", models.ClientInfo{}).Return(tc.user, tc.err)
+			h := NewTwoFactorHandler(serviceMock, &UserServiceMock{}, logrus.New())
+
+			body, _ := json.Marshal(models.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "123456"})
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/login/2fa", bytes.NewReader(body))
+
+			h.CompleteLogin(c)
+
+			assert.Equal(t, tc.code, rec.Code)
+			if tc.err == nil {
+				var resp dto.LoginResponse
+				_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+				assert.Equal(t, dto.LoginResponse{Token: "t1", RefreshToken: "r1"}, resp)
+			}
+		})
+	}
+}
+
+func TestEnrollTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{Email: "ana@example.com"}
+	userMock := &UserServiceMock{}
+	userMock.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+	enrollment := &dto.TwoFactorEnrollmentResponse{Secret: "SECRET", ProvisioningURI: "otpauth://totp/x"}
+	serviceMock := &TwoFactorServiceMock{}
+	serviceMock.On("Enroll", user).Return(enrollment, nil)
+	h := NewTwoFactorHandler(serviceMock, userMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/2fa/enroll", nil)
+	c.Set("userEmail", "ana@example.com")
+
+	h.EnrollTwoFactor(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp dto.TwoFactorEnrollmentResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, *enrollment, resp)
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{Email: "ana@example.com"}
+	cases := []struct {
+		name  string
+		codes []string
+		err   error
+		code  int
+	}{
+		{"confirmed", []string{"AAAAA-BBBBB"}, nil, http.StatusOK},
+		{"wrong code", nil, services_user.ErrInvalidTwoFactorCode, http.StatusUnprocessableEntity},
+		{"already enabled", nil, services_user.ErrTwoFactorAlreadyEnabled, http.StatusConflict},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userMock := &UserServiceMock{}
+			userMock.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+			serviceMock := &TwoFactorServiceMock{}
+			serviceMock.On("Confirm", user, "123456").Return(tc.codes, tc.err)
+			h := NewTwoFactorHandler(serviceMock, userMock, logrus.New())
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/2fa/confirm", bytes.NewReader([]byte(`{"code":"123456"}`)))
+			c.Set("userEmail", "ana@example.com")
+
+			h.ConfirmTwoFactor(c)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+func TestDisableTwoFactor_MissingCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewTwoFactorHandler(&TwoFactorServiceMock{}, &UserServiceMock{}, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/2fa/disable", bytes.NewReader([]byte(`{}`)))
+	c.Set("userEmail", "ana@example.com")
+
+	h.DisableTwoFactor(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
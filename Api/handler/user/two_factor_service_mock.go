@@ -0,0 +1,42 @@
+package user
+
+import (
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// TwoFactorServiceMock is a mock implementation of
+// services_user.TwoFactorService for handler tests.
+type TwoFactorServiceMock struct {
+	mock.Mock
+}
+
+func (m *TwoFactorServiceMock) Enroll(user *models.User) (*dto.TwoFactorEnrollmentResponse, error) {
+	args := m.Called(user)
+	if res := args.Get(0); res != nil {
+		return res.(*dto.TwoFactorEnrollmentResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *TwoFactorServiceMock) Confirm(user *models.User, code string) ([]string, error) {
+	args := m.Called(user, code)
+	if res := args.Get(0); res != nil {
+		return res.([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *TwoFactorServiceMock) Disable(user *models.User, code string) error {
+	return m.Called(user, code).Error(0)
+}
+
+func (m *TwoFactorServiceMock) CompleteLogin(challengeToken, code string, client models.ClientInfo) (*models.User, error) {
+	args := m.Called(challengeToken, code, client)
+	if res := args.Get(0); res != nil {
+		return res.(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
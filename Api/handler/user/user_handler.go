@@ -1,10 +1,14 @@
 package user
 
 import (
+	"errors"
 	"net/http"
 	"pruebaVertice/Api/dto"
 	"pruebaVertice/Api/models"
 	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -28,26 +32,28 @@ func NewUserHandler(userService services_user.UserService, logger *logrus.Logger
 // @Tags Users
 // @Accept json
 // @Produce json
-// @Param user body models.User true "Datos del usuario"
-// @Success 201 {object} models.User
-// @Failure 400 {object} map[string]string
+// @Param user body models.RegisterRequest true "Datos del usuario"
+// @Success 201 {object} dto.UserSessionResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
 // @Router /api/auth/register/ [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req models.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Layer: userHandler, Method: CreateUser, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		validation.Respond(c, err)
 		return
 	}
 
-	created, err := h.userService.CreateUser(&user)
+	user := models.User{Username: req.Username, Email: req.Email, Password: req.Password}
+	created, err := h.userService.CreateUser(&user, clientInfo(c))
 	if err != nil {
 		h.logger.Error("Layer: userHandler, Method: CreateUser, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, created)
+	c.JSON(http.StatusCreated, dto.NewUserSessionResponse(created))
 }
 
 func (h *UserHandler) GetUserByID(c *gin.Context) {
@@ -55,35 +61,35 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	user, err := h.userService.GetUserByID(id)
 	if err != nil {
 		h.logger.Error("Layer: userHandler, Method: GetUserByID, Error:", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, dto.NewUserResponse(user))
 }
 
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
 		h.logger.Error("Layer: userHandler, Method: UpdateUser, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		validation.Respond(c, err)
 		return
 	}
 
 	updated, err := h.userService.UpdateUser(&user)
 	if err != nil {
 		h.logger.Error("Layer: userHandler, Method: UpdateUser, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, updated)
+	c.JSON(http.StatusOK, dto.NewUserResponse(updated))
 }
 
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 	if err := h.userService.DeleteUser(id); err != nil {
 		h.logger.Error("Layer: userHandler, Method: DeleteUser, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
@@ -92,26 +98,39 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 // LoginUser godoc
 // @Summary Login de usuario
-// @Description Inicia sesión de un usuario y devuelve tokens
+// @Description Inicia sesión de un usuario y devuelve tokens. Si la cuenta tiene 2FA activo devuelve un challenge_token que se canjea en /api/auth/login/2fa
 // @Tags Users
 // @Accept json
 // @Produce json
-// @Param credentials body models.User true "Credenciales del usuario"
+// @Param credentials body models.LoginRequest true "Credenciales del usuario"
 // @Success 200 {object} dto.LoginResponse
-// @Failure 400 {object} map[string]string
+// @Success 202 {object} dto.TwoFactorChallengeResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
 // @Router /api/auth/login [post]
 func (h *UserHandler) LoginUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Layer: userHandler, Method: LoginUser, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		validation.Respond(c, err)
 		return
 	}
 
-	logged, err := h.userService.Login(user.Email, user.Password)
+	logged, err := h.userService.Login(req.Email, req.Password, clientInfo(c))
+	var challenge *services_user.TwoFactorRequiredError
+	if errors.As(err, &challenge) {
+		c.JSON(http.StatusAccepted, dto.TwoFactorChallengeResponse{TwoFactorRequired: true, ChallengeToken: challenge.ChallengeToken})
+		return
+	}
+	if lockedOut(c, err) {
+		h.logger.Warn("Layer: userHandler, Method: LoginUser, Error:", err)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Layer: userHandler, Method: LoginUser, Error:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, err)
 		return
 	}
 
@@ -123,16 +142,16 @@ func (h *UserHandler) LoginUser(c *gin.Context) {
 // @Description Devuelve la información del usuario autenticado
 // @Tags Users
 // @Produce json
-// @Success 200 {object} models.User
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Success 200 {object} dto.UserResponse
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
 // @Security BearerAuth
 // @Router /api/auth/me [get]
 func (h *UserHandler) GetLoggedInUser(c *gin.Context) {
 	emailVal, exists := c.Get("userEmail")
 	if !exists {
 		h.logger.Error("User email not found in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		problem.Respond(c, problem.ErrUnauthorized)
 		return
 	}
 
@@ -140,9 +159,25 @@ func (h *UserHandler) GetLoggedInUser(c *gin.Context) {
 	user, err := h.userService.GetUserByEmail(email)
 	if err != nil {
 		h.logger.Error("Layer: userHandler, Method: GetLoggedInUser, Error:", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		problem.Respond(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, dto.NewUserResponse(user))
+}
+
+// clientInfo describes the client for the session a login starts.
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// lockedOut answers 429 with Retry-After when the login is throttled.
+func lockedOut(c *gin.Context, err error) bool {
+	var locked *services_user.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())))
+	problem.Respond(c, err)
+	return true
 }
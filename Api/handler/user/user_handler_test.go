@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"pruebaVertice/Api/models"
 	"pruebaVertice/Api/dto"
+	services_user "pruebaVertice/Api/services/user"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -17,10 +18,10 @@ import (
 
 func TestCreateUser_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	userInput := models.User{Username: "john", Password: "pass", Email: "john@example.com"}
+	userInput := models.User{Username: "john", Password: "Passw0rd!", Email: "john@example.com"}
 	created := &models.User{Model: gorm.Model{ID: 1}, Username: "john", Email: "john@example.com"}
 	serviceMock := &UserServiceMock{}
-	serviceMock.On("CreateUser", &userInput).Return(created, nil)
+	serviceMock.On("CreateUser", &userInput, models.ClientInfo{}).Return(created, nil)
 	logger := logrus.New()
 	h := NewUserHandler(serviceMock, logger)
 
@@ -32,9 +33,9 @@ func TestCreateUser_Success(t *testing.T) {
 	h.CreateUser(c)
 
 	assert.Equal(t, http.StatusCreated, rec.Code)
-	var resp models.User
+	var resp dto.UserSessionResponse
 	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Equal(t, *created, resp)
+	assert.Equal(t, dto.NewUserSessionResponse(created), resp)
 	serviceMock.AssertExpectations(t)
 }
 
@@ -54,16 +55,16 @@ func TestGetUserByID_Success(t *testing.T) {
 	h.GetUserByID(c)
 
 	assert.Equal(t, http.StatusOK, rec.Code)
-	var resp models.User
+	var resp dto.UserResponse
 	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Equal(t, *returned, resp)
+	assert.Equal(t, dto.NewUserResponse(returned), resp)
 	serviceMock.AssertExpectations(t)
 }
 
 func TestGetUserByID_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := &UserServiceMock{}
-	serviceMock.On("GetUserByID", "3").Return(nil, assert.AnError)
+	serviceMock.On("GetUserByID", "3").Return(nil, services_user.ErrUserNotFound)
 	logger := logrus.New()
 	h := NewUserHandler(serviceMock, logger)
 
@@ -95,9 +96,9 @@ func TestUpdateUser_Success(t *testing.T) {
 	h.UpdateUser(c)
 
 	assert.Equal(t, http.StatusOK, rec.Code)
-	var resp models.User
+	var resp dto.UserResponse
 	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Equal(t, *updated, resp)
+	assert.Equal(t, dto.NewUserResponse(updated), resp)
 	serviceMock.AssertExpectations(t)
 }
 
@@ -136,7 +137,8 @@ func TestDeleteUser_Error(t *testing.T) {
 
 	h.DeleteUser(c)
 
-	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), assert.AnError.Error())
 	serviceMock.AssertExpectations(t)
 }
 
@@ -145,7 +147,7 @@ func TestLoginUser_Success(t *testing.T) {
 	input := models.User{Email: "u@e.com", Password: "pwd"}
 	logged := &models.User{Token: "t1", RefreshToken: "r1"}
 	serviceMock := &UserServiceMock{}
-	serviceMock.On("Login", "u@e.com", "pwd").Return(logged, nil)
+	serviceMock.On("Login", "u@e.com", "pwd", models.ClientInfo{}).Return(logged, nil)
 	logger := logrus.New()
 	h := NewUserHandler(serviceMock, logger)
 
@@ -179,9 +181,9 @@ func TestGetLoggedInUser_Success(t *testing.T) {
 	h.GetLoggedInUser(c)
 
 	assert.Equal(t, http.StatusOK, rec.Code)
-	var resp models.User
+	var resp dto.UserResponse
 	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
-	assert.Equal(t, *userData, resp)
+	assert.Equal(t, dto.NewUserResponse(userData), resp)
 	serviceMock.AssertExpectations(t)
 }
 
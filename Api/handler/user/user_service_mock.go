@@ -11,8 +11,8 @@ type UserServiceMock struct {
 	mock.Mock
 }
 
-func (m *UserServiceMock) CreateUser(user *models.User) (*models.User, error) {
-	args := m.Called(user)
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	args := m.Called(user, client)
 	if res := args.Get(0); res != nil {
 		return res.(*models.User), args.Error(1)
 	}
@@ -40,8 +40,8 @@ func (m *UserServiceMock) DeleteUser(id string) error {
 	return args.Error(0)
 }
 
-func (m *UserServiceMock) Login(email, password string) (*models.User, error) {
-	args := m.Called(email, password)
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	args := m.Called(email, password, client)
 	if res := args.Get(0); res != nil {
 		return res.(*models.User), args.Error(1)
 	}
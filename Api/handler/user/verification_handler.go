@@ -0,0 +1,88 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	services_user "pruebaVertice/Api/services/user"
+	"pruebaVertice/Api/utils/problem"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type VerificationHandler struct {
+	verificationService services_user.EmailVerificationService
+	userService         services_user.UserService
+	logger              *logrus.Logger
+}
+
+func NewVerificationHandler(verificationService services_user.EmailVerificationService, userService services_user.UserService, logger *logrus.Logger) *VerificationHandler {
+	return &VerificationHandler{
+		verificationService: verificationService,
+		userService:         userService,
+		logger:              logger,
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Verificar correo electrónico
+// @Description Confirma el correo del usuario con el enlace firmado enviado al registrarse
+// @Tags Users
+// @Produce json
+// @Param token query string true "Token del enlace de verificación"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/auth/verify-email [get]
+func (h *VerificationHandler) VerifyEmail(c *gin.Context) {
+	if err := h.verificationService.VerifyEmail(c.Query("token")); err != nil {
+		h.logger.Error("Layer: verificationHandler, Method: VerifyEmail, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// ResendVerification godoc
+// @Summary Reenviar correo de verificación
+// @Description Envía un nuevo enlace de verificación al usuario autenticado. Solo se permite un envío por intervalo
+// @Tags Users
+// @Produce json
+// @Success 202 {object} map[string]string
+// @Failure 401 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/verify-email/resend [post]
+func (h *VerificationHandler) ResendVerification(c *gin.Context) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return
+	}
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: verificationHandler, Method: ResendVerification, Error fetching user:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	err = h.verificationService.ResendVerification(user)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+	case errors.Is(err, services_user.ErrEmailAlreadyVerified):
+		problem.Respond(c, err)
+	case errors.Is(err, services_user.ErrVerificationRateLimited):
+		c.Header("Retry-After", strconv.Itoa(int(h.verificationService.RetryAfter().Seconds())))
+		problem.Respond(c, err)
+	default:
+		h.logger.Error("Layer: verificationHandler, Method: ResendVerification, Error:", err)
+		problem.Respond(c, err)
+	}
+}
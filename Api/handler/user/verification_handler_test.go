@@ -0,0 +1,66 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &EmailVerificationServiceMock{}
+	serviceMock.On("VerifyEmail", "good").Return(nil)
+	serviceMock.On("VerifyEmail", "bad").Return(services_user.ErrInvalidVerificationToken)
+	h := NewVerificationHandler(serviceMock, &UserServiceMock{}, logrus.New())
+
+	for token, code := range map[string]int{"good": http.StatusOK, "bad": http.StatusUnprocessableEntity} {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/verify-email?token="+token, nil)
+
+		h.VerifyEmail(c)
+
+		assert.Equal(t, code, rec.Code, token)
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{Email: "ana@example.com"}
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"sent", nil, http.StatusAccepted},
+		{"already verified", services_user.ErrEmailAlreadyVerified, http.StatusConflict},
+		{"rate limited", services_user.ErrVerificationRateLimited, http.StatusTooManyRequests},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userMock := &UserServiceMock{}
+			userMock.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+			serviceMock := &EmailVerificationServiceMock{}
+			serviceMock.On("ResendVerification", user).Return(tc.err)
+			h := NewVerificationHandler(serviceMock, userMock, logrus.New())
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/verify-email/resend", nil)
+			c.Set("userEmail", "ana@example.com")
+
+			h.ResendVerification(c)
+
+			assert.Equal(t, tc.code, rec.Code)
+			if tc.code == http.StatusTooManyRequests {
+				assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
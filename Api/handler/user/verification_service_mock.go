@@ -0,0 +1,26 @@
+package user
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// EmailVerificationServiceMock is a mock implementation of
+// services_user.EmailVerificationService for handler tests.
+type EmailVerificationServiceMock struct {
+	mock.Mock
+}
+
+func (m *EmailVerificationServiceMock) ResendVerification(user *models.User) error {
+	return m.Called(user).Error(0)
+}
+
+func (m *EmailVerificationServiceMock) VerifyEmail(token string) error {
+	return m.Called(token).Error(0)
+}
+
+func (m *EmailVerificationServiceMock) RetryAfter() time.Duration {
+	return time.Minute
+}
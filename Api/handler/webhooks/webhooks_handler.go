@@ -0,0 +1,262 @@
+package webhooks
+
+import (
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_webhook "pruebaVertice/Api/services/webhook"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhooksHandler struct {
+	webhookService services_webhook.WebhookService
+	logger         *logrus.Logger
+}
+
+func NewWebhooksHandler(webhookService services_webhook.WebhookService, logger *logrus.Logger) *WebhooksHandler {
+	return &WebhooksHandler{
+		webhookService: webhookService,
+		logger:         logger,
+	}
+}
+
+// CreateSubscription godoc
+// @Summary Crear suscripción de webhook
+// @Description Registra una URL que recibirá los eventos indicados (order.created, order.status_changed, product.created, product.price_changed). Cada envío va firmado con HMAC-SHA256 en X-Webhook-Signature sobre "<X-Webhook-Timestamp>.<cuerpo>". Si no se envía secret se genera uno, que solo se muestra en esta respuesta. Requiere rol admin
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body models.WebhookSubscriptionRequest true "URL, secreto opcional y eventos"
+// @Success 201 {object} models.WebhookSubscription
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/webhooks [post]
+func (h *WebhooksHandler) CreateSubscription(c *gin.Context) {
+	var req models.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: CreateSubscription, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(req, c.GetString("userEmail"))
+	if err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: CreateSubscription, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// ListSubscriptions godoc
+// @Summary Listar suscripciones de webhook
+// @Description Devuelve todas las suscripciones sin su secreto. Requiere rol admin
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} models.WebhookSubscription
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/webhooks [get]
+func (h *WebhooksHandler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.webhookService.ListSubscriptions()
+	if err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: ListSubscriptions, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetSubscription godoc
+// @Summary Obtener suscripción de webhook
+// @Description Requiere rol admin
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "ID de la suscripción"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/webhooks/{id} [get]
+func (h *WebhooksHandler) GetSubscription(c *gin.Context) {
+	id, ok := h.pathID(c, "GetSubscription")
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.GetSubscription(id)
+	if err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: GetSubscription, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateSubscription godoc
+// @Summary Actualizar suscripción de webhook
+// @Description Reemplaza URL y eventos. El secreto solo cambia si se envía uno nuevo. Requiere rol admin
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la suscripción"
+// @Param subscription body models.WebhookSubscriptionRequest true "URL, secreto opcional, eventos y estado"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/webhooks/{id} [put]
+func (h *WebhooksHandler) UpdateSubscription(c *gin.Context) {
+	id, ok := h.pathID(c, "UpdateSubscription")
+	if !ok {
+		return
+	}
+	var req models.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: UpdateSubscription, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(id, req)
+	if err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: UpdateSubscription, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscription godoc
+// @Summary Eliminar suscripción de webhook
+// @Description Elimina la suscripción. Sus envíos pendientes pasan a la lista de envíos fallidos. Requiere rol admin
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "ID de la suscripción"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/webhooks/{id} [delete]
+func (h *WebhooksHandler) DeleteSubscription(c *gin.Context) {
+	id, ok := h.pathID(c, "DeleteSubscription")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(id); err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: DeleteSubscription, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook subscription deleted successfully"})
+}
+
+// ListDeliveries godoc
+// @Summary Registro de envíos de webhooks
+// @Description Devuelve los envíos más recientes primero. Con status=dead se obtiene la lista de envíos que agotaron sus reintentos. Requiere rol admin
+// @Tags Webhooks
+// @Produce json
+// @Param subscription_id query int false "Filtrar por suscripción"
+// @Param status query string false "Filtrar por estado (pending, succeeded, dead)"
+// @Param limit query int false "Cantidad máxima (por defecto 50, máximo 200)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/webhook-deliveries [get]
+func (h *WebhooksHandler) ListDeliveries(c *gin.Context) {
+	var filter models.WebhookDeliveryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: ListDeliveries, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(filter)
+	if err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: ListDeliveries, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDelivery godoc
+// @Summary Detalle de un envío de webhook
+// @Description Devuelve el envío con el registro de cada intento. Requiere rol admin
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "ID del envío"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/webhook-deliveries/{id} [get]
+func (h *WebhooksHandler) GetDelivery(c *gin.Context) {
+	id, ok := h.pathID(c, "GetDelivery")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(id)
+	if err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: GetDelivery, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// RetryDelivery godoc
+// @Summary Reintentar un envío fallido
+// @Description Vuelve a encolar un envío en estado dead con un nuevo ciclo de reintentos. Requiere rol admin
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "ID del envío"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/admin/webhook-deliveries/{id}/retry [post]
+func (h *WebhooksHandler) RetryDelivery(c *gin.Context) {
+	id, ok := h.pathID(c, "RetryDelivery")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.RetryDelivery(id)
+	if err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: RetryDelivery, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+func (h *WebhooksHandler) pathID(c *gin.Context, method string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: webhooksHandler, Method: "+method+", Error: invalid ID:", err)
+		problem.Respond(c, problem.InvalidID(""))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,106 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_webhook "pruebaVertice/Api/services/webhook"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSubscription_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	req := models.WebhookSubscriptionRequest{URL: "https://erp.local/hook", Events: []string{models.WebhookEventOrderCreated}}
+	serviceMock := &WebhookServiceMock{}
+	serviceMock.On("CreateSubscription", req, "admin@example.com").
+		Return(&models.WebhookSubscription{ID: 1, URL: req.URL, Secret: "generated", Active: true}, nil)
+	h := NewWebhooksHandler(serviceMock, logrus.New())
+
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/admin/webhooks", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userEmail", "admin@example.com")
+
+	h.CreateSubscription(c)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var resp models.WebhookSubscription
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "generated", resp.Secret)
+}
+
+func TestCreateSubscription_Invalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &WebhookServiceMock{}
+	serviceMock.On("CreateSubscription", models.WebhookSubscriptionRequest{URL: "nope"}, "").
+		Return(nil, services_webhook.ErrInvalidSubscription)
+	h := NewWebhooksHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/admin/webhooks", bytes.NewBufferString(`{"url":"nope"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.CreateSubscription(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestListDeliveries_DeadLetters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &WebhookServiceMock{}
+	serviceMock.On("ListDeliveries", models.WebhookDeliveryFilter{Status: models.WebhookDeliveryDead}).
+		Return([]models.WebhookDelivery{{ID: 3, Status: models.WebhookDeliveryDead}}, nil)
+	h := NewWebhooksHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/admin/webhook-deliveries?status=dead", nil)
+
+	h.ListDeliveries(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp []models.WebhookDelivery
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp, 1)
+}
+
+func TestRetryDelivery_NotDead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &WebhookServiceMock{}
+	serviceMock.On("RetryDelivery", uint(3)).Return(nil, services_webhook.ErrDeliveryNotDead)
+	h := NewWebhooksHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/admin/webhook-deliveries/3/retry", nil)
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	h.RetryDelivery(c)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestGetSubscription_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := &WebhookServiceMock{}
+	serviceMock.On("GetSubscription", uint(9)).Return(nil, services_webhook.ErrSubscriptionNotFound)
+	h := NewWebhooksHandler(serviceMock, logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/admin/webhooks/9", nil)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+
+	h.GetSubscription(c)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
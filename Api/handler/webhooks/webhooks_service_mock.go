@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// WebhookServiceMock is a mock implementation of services_webhook.WebhookService
+// for handler tests.
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (m *WebhookServiceMock) Publish(eventType string, data interface{}) {
+	m.Called(eventType, data)
+}
+
+func (m *WebhookServiceMock) CreateSubscription(req models.WebhookSubscriptionRequest, createdBy string) (*models.WebhookSubscription, error) {
+	args := m.Called(req, createdBy)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	args := m.Called()
+	if res := args.Get(0); res != nil {
+		return res.([]models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) UpdateSubscription(id uint, req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	args := m.Called(id, req)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) DeleteSubscription(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) ListDeliveries(filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.([]models.WebhookDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) RetryDelivery(id uint) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookServiceMock) DispatchDue() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,235 @@
+package wishlist
+
+import (
+	"net/http"
+	"pruebaVertice/Api/models"
+	services_user "pruebaVertice/Api/services/user"
+	services_wishlist "pruebaVertice/Api/services/wishlist"
+	"pruebaVertice/Api/utils/problem"
+	"pruebaVertice/Api/utils/validation"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type WishlistHandler struct {
+	wishlistService services_wishlist.WishlistService
+	userService     services_user.UserService
+	logger          *logrus.Logger
+}
+
+func NewWishlistHandler(wishlistService services_wishlist.WishlistService, userService services_user.UserService, logger *logrus.Logger) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+		userService:     userService,
+		logger:          logger,
+	}
+}
+
+// GetWishlist godoc
+// @Summary Listar la lista de deseos
+// @Description Devuelve los productos guardados por el usuario autenticado, los más recientes primero
+// @Tags Wishlist
+// @Produce json
+// @Success 200 {array} models.WishlistItem
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/wishlist [get]
+func (h *WishlistHandler) GetWishlist(c *gin.Context) {
+	user, ok := h.currentUser(c, "GetWishlist")
+	if !ok {
+		return
+	}
+
+	items, err := h.wishlistService.GetWishlist(user.ID)
+	if err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: GetWishlist, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// AddToWishlist godoc
+// @Summary Guardar un producto en la lista de deseos
+// @Description Agrega un producto a la lista de deseos del usuario. Si ya estaba guardado devuelve el mismo elemento
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param item body models.WishlistRequest true "Producto a guardar"
+// @Success 201 {object} models.WishlistItem
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/wishlist [post]
+func (h *WishlistHandler) AddToWishlist(c *gin.Context) {
+	var req models.WishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: AddToWishlist, Error:", err)
+		validation.Respond(c, err)
+		return
+	}
+	user, ok := h.currentUser(c, "AddToWishlist")
+	if !ok {
+		return
+	}
+
+	item, err := h.wishlistService.AddToWishlist(user.ID, req.ProductID)
+	if err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: AddToWishlist, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// RemoveFromWishlist godoc
+// @Summary Quitar un producto de la lista de deseos
+// @Description Elimina un producto de la lista de deseos del usuario autenticado
+// @Tags Wishlist
+// @Param productId path int true "ID del producto"
+// @Success 204
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/wishlist/{productId} [delete]
+func (h *WishlistHandler) RemoveFromWishlist(c *gin.Context) {
+	productID, ok := h.productID(c, "productId", "RemoveFromWishlist")
+	if !ok {
+		return
+	}
+	user, ok := h.currentUser(c, "RemoveFromWishlist")
+	if !ok {
+		return
+	}
+
+	if err := h.wishlistService.RemoveFromWishlist(user.ID, productID); err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: RemoveFromWishlist, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SubscribeStockAlert godoc
+// @Summary Avisarme cuando vuelva a haber stock
+// @Description Suscribe al usuario a un producto agotado. Se envía un aviso cuando una reposición deja el stock por encima de cero
+// @Tags Wishlist
+// @Produce json
+// @Param id path int true "ID del producto"
+// @Success 201 {object} models.StockAlert
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/products/{id}/stock-alerts [post]
+func (h *WishlistHandler) SubscribeStockAlert(c *gin.Context) {
+	productID, ok := h.productID(c, "id", "SubscribeStockAlert")
+	if !ok {
+		return
+	}
+	user, ok := h.currentUser(c, "SubscribeStockAlert")
+	if !ok {
+		return
+	}
+
+	alert, err := h.wishlistService.SubscribeStockAlert(user.ID, productID)
+	if err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: SubscribeStockAlert, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, alert)
+}
+
+// UnsubscribeStockAlert godoc
+// @Summary Cancelar el aviso de stock
+// @Description Cancela la suscripción del usuario a la reposición de un producto
+// @Tags Wishlist
+// @Param id path int true "ID del producto"
+// @Success 204
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/products/{id}/stock-alerts [delete]
+func (h *WishlistHandler) UnsubscribeStockAlert(c *gin.Context) {
+	productID, ok := h.productID(c, "id", "UnsubscribeStockAlert")
+	if !ok {
+		return
+	}
+	user, ok := h.currentUser(c, "UnsubscribeStockAlert")
+	if !ok {
+		return
+	}
+
+	if err := h.wishlistService.UnsubscribeStockAlert(user.ID, productID); err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: UnsubscribeStockAlert, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetStockAlerts godoc
+// @Summary Listar avisos de stock
+// @Description Devuelve las suscripciones a reposición del usuario autenticado. Las ya enviadas incluyen notified_at
+// @Tags Wishlist
+// @Produce json
+// @Success 200 {array} models.StockAlert
+// @Failure 401 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security BearerAuth
+// @Router /api/auth/me/stock-alerts [get]
+func (h *WishlistHandler) GetStockAlerts(c *gin.Context) {
+	user, ok := h.currentUser(c, "GetStockAlerts")
+	if !ok {
+		return
+	}
+
+	alerts, err := h.wishlistService.GetStockAlerts(user.ID)
+	if err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: GetStockAlerts, Error:", err)
+		problem.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+func (h *WishlistHandler) productID(c *gin.Context, param, method string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: "+method+", Error: invalid product ID:", err)
+		problem.Respond(c, problem.InvalidID("product"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *WishlistHandler) currentUser(c *gin.Context, method string) (*models.User, bool) {
+	emailVal, exists := c.Get("userEmail")
+	if !exists {
+		h.logger.Error("User email not found in context")
+		problem.Respond(c, problem.ErrUnauthorized)
+		return nil, false
+	}
+
+	user, err := h.userService.GetUserByEmail(emailVal.(string))
+	if err != nil {
+		h.logger.Error("Layer: wishlistHandler, Method: "+method+", Error fetching user:", err)
+		problem.Respond(c, err)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,155 @@
+package wishlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	services_wishlist "pruebaVertice/Api/services/wishlist"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newUserMock() *UserServiceMock {
+	return &UserServiceMock{
+		GetUserEmailFn: func(email string) (*models.User, error) {
+			return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+		},
+	}
+}
+
+func newContext(method, path string, body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(method, path, bytes.NewReader(body))
+	c.Set("userEmail", "user@example.com")
+	return c, rec
+}
+
+func TestGetWishlist_Success(t *testing.T) {
+	items := []models.WishlistItem{{ID: 1, UserID: 2, ProductID: 5}}
+	serviceMock := &WishlistServiceMock{}
+	serviceMock.On("GetWishlist", uint(2)).Return(items, nil)
+	h := NewWishlistHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodGet, "/me/wishlist", nil)
+	h.GetWishlist(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp []models.WishlistItem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, uint(5), resp[0].ProductID)
+}
+
+func TestGetWishlist_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewWishlistHandler(&WishlistServiceMock{}, newUserMock(), logrus.New())
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me/wishlist", nil)
+	h.GetWishlist(c)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAddToWishlist_Success(t *testing.T) {
+	serviceMock := &WishlistServiceMock{}
+	serviceMock.On("AddToWishlist", uint(2), uint(5)).Return(&models.WishlistItem{ID: 1, UserID: 2, ProductID: 5}, nil)
+	h := NewWishlistHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodPost, "/me/wishlist", []byte(`{"product_id":5}`))
+	h.AddToWishlist(c)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestAddToWishlist_BadRequest(t *testing.T) {
+	h := NewWishlistHandler(&WishlistServiceMock{}, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodPost, "/me/wishlist", []byte(`{}`))
+	h.AddToWishlist(c)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAddToWishlist_ProductNotFound(t *testing.T) {
+	serviceMock := &WishlistServiceMock{}
+	serviceMock.On("AddToWishlist", uint(2), uint(9)).Return(nil, services_wishlist.ErrProductNotFound)
+	h := NewWishlistHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodPost, "/me/wishlist", []byte(`{"product_id":9}`))
+	h.AddToWishlist(c)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRemoveFromWishlist(t *testing.T) {
+	serviceMock := &WishlistServiceMock{}
+	serviceMock.On("RemoveFromWishlist", uint(2), uint(5)).Return(nil)
+	serviceMock.On("RemoveFromWishlist", uint(2), uint(6)).Return(services_wishlist.ErrWishlistItemNotFound)
+	h := NewWishlistHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodDelete, "/me/wishlist/5", nil)
+	c.Params = gin.Params{{Key: "productId", Value: "5"}}
+	h.RemoveFromWishlist(c)
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	c, rec = newContext(http.MethodDelete, "/me/wishlist/6", nil)
+	c.Params = gin.Params{{Key: "productId", Value: "6"}}
+	h.RemoveFromWishlist(c)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSubscribeStockAlert(t *testing.T) {
+	serviceMock := &WishlistServiceMock{}
+	serviceMock.On("SubscribeStockAlert", uint(2), uint(5)).Return(&models.StockAlert{ID: 1, UserID: 2, ProductID: 5}, nil)
+	serviceMock.On("SubscribeStockAlert", uint(2), uint(6)).Return(nil, services_wishlist.ErrProductInStock)
+	h := NewWishlistHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodPost, "/products/5/stock-alerts", nil)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+	h.SubscribeStockAlert(c)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	c, rec = newContext(http.MethodPost, "/products/6/stock-alerts", nil)
+	c.Params = gin.Params{{Key: "id", Value: "6"}}
+	h.SubscribeStockAlert(c)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	c, rec = newContext(http.MethodPost, "/products/abc/stock-alerts", nil)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	h.SubscribeStockAlert(c)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUnsubscribeStockAlert_NotFound(t *testing.T) {
+	serviceMock := &WishlistServiceMock{}
+	serviceMock.On("UnsubscribeStockAlert", uint(2), uint(5)).Return(services_wishlist.ErrStockAlertNotFound)
+	h := NewWishlistHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodDelete, "/products/5/stock-alerts", nil)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+	h.UnsubscribeStockAlert(c)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestGetStockAlerts_Success(t *testing.T) {
+	serviceMock := &WishlistServiceMock{}
+	serviceMock.On("GetStockAlerts", uint(2)).Return([]models.StockAlert{{ID: 1, UserID: 2, ProductID: 5}}, nil)
+	h := NewWishlistHandler(serviceMock, newUserMock(), logrus.New())
+
+	c, rec := newContext(http.MethodGet, "/me/stock-alerts", nil)
+	h.GetStockAlerts(c)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"product_id":5`)
+}
@@ -0,0 +1,74 @@
+package wishlist
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// WishlistServiceMock is a mock implementation of services_wishlist.WishlistService
+// for handler tests.
+type WishlistServiceMock struct {
+	mock.Mock
+}
+
+func (m *WishlistServiceMock) GetWishlist(userID uint) ([]models.WishlistItem, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.WishlistItem), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WishlistServiceMock) AddToWishlist(userID, productID uint) (*models.WishlistItem, error) {
+	args := m.Called(userID, productID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WishlistItem), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WishlistServiceMock) RemoveFromWishlist(userID, productID uint) error {
+	args := m.Called(userID, productID)
+	return args.Error(0)
+}
+
+func (m *WishlistServiceMock) SubscribeStockAlert(userID, productID uint) (*models.StockAlert, error) {
+	args := m.Called(userID, productID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.StockAlert), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WishlistServiceMock) UnsubscribeStockAlert(userID, productID uint) error {
+	args := m.Called(userID, productID)
+	return args.Error(0)
+}
+
+func (m *WishlistServiceMock) GetStockAlerts(userID uint) ([]models.StockAlert, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.StockAlert), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// UserServiceMock is a mock implementation of services_user.UserService.
+// Only GetUserByEmail is needed here.
+type UserServiceMock struct {
+	GetUserEmailFn func(email string) (*models.User, error)
+}
+
+func (m *UserServiceMock) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByID(id string) (*models.User, error)        { return nil, nil }
+func (m *UserServiceMock) UpdateUser(user *models.User) (*models.User, error) { return nil, nil }
+func (m *UserServiceMock) DeleteUser(id string) error                         { return nil }
+func (m *UserServiceMock) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	return nil, nil
+}
+func (m *UserServiceMock) GetUserByEmail(email string) (*models.User, error) {
+	return m.GetUserEmailFn(email)
+}
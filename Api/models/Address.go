@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// PostalAddress is the destination part of an address. It is embedded in
+// Address and copied onto each Order at checkout.
+type PostalAddress struct {
+	Recipient  string `gorm:"type:varchar(255)" json:"recipient" binding:"required,notblank" example:"Juan Pérez"`
+	Line1      string `gorm:"type:varchar(255)" json:"line1" binding:"required,notblank" example:"Av. Siempre Viva 742"`
+	Line2      string `gorm:"type:varchar(255)" json:"line2"`
+	City       string `gorm:"type:varchar(100)" json:"city" binding:"required,notblank" example:"Santiago"`
+	State      string `gorm:"type:varchar(100)" json:"state"`
+	PostalCode string `gorm:"type:varchar(20)" json:"postal_code" binding:"required,notblank" example:"8320000"`
+	Country    string `gorm:"type:varchar(2)" json:"country" binding:"required,notblank" example:"CL"`
+	Phone      string `gorm:"type:varchar(30)" json:"phone"`
+}
+
+type Address struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	UserID        uint   `gorm:"index" json:"user_id"`
+	Label         string `gorm:"type:varchar(50)" json:"label"`
+	IsDefault     bool   `json:"is_default"`
+	PostalAddress `gorm:"embedded"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type AddressRequest struct {
+	Label     string `json:"label" example:"Casa"`
+	IsDefault bool   `json:"is_default"`
+	PostalAddress
+}
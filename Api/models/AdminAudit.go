@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Actions recorded in the admin audit log.
+const (
+	AdminActionDisableUser   = "user.disable"
+	AdminActionEnableUser    = "user.enable"
+	AdminActionChangeRole    = "user.change_role"
+	AdminActionPasswordReset = "user.force_password_reset"
+	AdminActionImpersonate   = "user.impersonate"
+)
+
+// AdminAuditEntry records an action an admin took on a user account.
+type AdminAuditEntry struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	AdminID      uint      `gorm:"index" json:"admin_id"`
+	AdminEmail   string    `gorm:"type:varchar(255)" json:"admin_email"`
+	Action       string    `gorm:"type:varchar(50);index" json:"action"`
+	TargetUserID uint      `gorm:"index" json:"target_user_id"`
+	Details      string    `gorm:"type:varchar(255)" json:"details,omitempty"`
+	IP           string    `gorm:"type:varchar(45)" json:"ip"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+}
+
+// AdminAuditFilter narrows the audit log listing.
+type AdminAuditFilter struct {
+	UserID   uint   `form:"user_id"`
+	Action   string `form:"action"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+// UserFilter searches the user list. Query matches part of the email or the
+// username.
+type UserFilter struct {
+	Query    string `form:"q"`
+	Role     string `form:"role"`
+	Status   string `form:"status"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+)
+
+type UserStatusRequest struct {
+	Disabled *bool `json:"disabled" binding:"required" example:"true"`
+}
+
+type UserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=customer warehouse admin" example:"warehouse"`
+}
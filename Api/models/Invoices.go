@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Invoice is the fiscal document issued for an order. Number comes from a
+// gap-free sequence and the rendered documents are stored so every download
+// returns exactly the same bytes.
+type Invoice struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	OrderID      uint      `gorm:"uniqueIndex" json:"order_id"`
+	UserID       uint      `gorm:"index" json:"user_id"`
+	Number       uint      `gorm:"uniqueIndex" json:"number"`
+	Code         string    `gorm:"type:varchar(20);uniqueIndex" json:"code"`
+	NetAmount    float64   `json:"net_amount"`
+	TaxAmount    float64   `json:"tax_amount"`
+	ShippingCost float64   `json:"shipping_cost"`
+	Total        float64   `json:"total"`
+	IssuedAt     time.Time `json:"issued_at"`
+	HTML         string    `gorm:"type:longtext" json:"-"`
+	PDF          []byte    `gorm:"type:longblob" json:"-"`
+}
+
+// InvoiceSequence keeps the last number handed out for a series.
+type InvoiceSequence struct {
+	Name       string `gorm:"primaryKey;type:varchar(50)"`
+	LastNumber uint
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	LoginScopeAccount = "account"
+	LoginScopeIP      = "ip"
+)
+
+// LoginAttempt counts recent failed logins for an account email or a client
+// IP. LockedUntil is set once the failures reach the throttling policy.
+type LoginAttempt struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Scope         string     `gorm:"type:varchar(10);uniqueIndex:idx_login_attempt_scope_identifier" json:"scope"`
+	Identifier    string     `gorm:"type:varchar(255);uniqueIndex:idx_login_attempt_scope_identifier" json:"identifier"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `gorm:"index" json:"locked_until"`
+}
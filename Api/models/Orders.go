@@ -4,22 +4,51 @@ import (
 	"time"
 )
 
+const (
+	OrderStatusPlaced           = "placed"
+	OrderStatusPartiallyShipped = "partially_shipped"
+	OrderStatusShipped          = "shipped"
+	OrderStatusDelivered        = "delivered"
+	OrderStatusCancelled        = "cancelled"
+)
+
 type Order struct {
-	ID         uint           `gorm:"primaryKey" json:"id"`
-	UserID     uint           `json:"user_id"`
-	Total      float64        `json:"total"`
-	CreatedAt  time.Time      `json:"created_at"`
-	OrderItems []OrderProduct `gorm:"foreignKey:OrderID" json:"order_items"`
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	UserID          uint           `gorm:"index" json:"user_id"`
+	Status          string         `gorm:"type:varchar(20);default:placed;index" json:"status"`
+	Subtotal        float64        `json:"subtotal"`
+	ShippingMethod  string         `gorm:"type:varchar(50)" json:"shipping_method"`
+	ShippingCost    float64        `json:"shipping_cost"`
+	Total           float64        `json:"total"`
+	ShippingAddress PostalAddress  `gorm:"embedded;embeddedPrefix:shipping_" json:"shipping_address"`
+	CreatedAt       time.Time      `json:"created_at"`
+	OrderItems      []OrderProduct `gorm:"foreignKey:OrderID" json:"order_items"`
 }
 
 type OrderProduct struct {
-	ID        uint    `gorm:"primaryKey;autoIncrement" json:"id"`
-	OrderID   uint    `json:"order_id"`
-	ProductID uint    `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	UnitPrice float64 `json:"unit_price"`
+	ID        uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderID   uint            `json:"order_id"`
+	ProductID uint            `json:"product_id" binding:"required"`
+	Quantity  int             `json:"quantity" binding:"gt=0"`
+	UnitPrice float64         `json:"unit_price"`
+	Snapshot  ProductSnapshot `gorm:"embedded;embeddedPrefix:product_" json:"product_snapshot"`
 }
 
 type CreateOrderRequest struct {
-	OrderItems []OrderProduct `json:"order_items"`
+	OrderItems     []OrderProduct `json:"order_items" binding:"required,min=1,unique_products,dive"`
+	AddressID      *uint          `json:"address_id,omitempty"`
+	ShippingMethod string         `json:"shipping_method,omitempty" example:"standard"`
+}
+
+// OrderFilter narrows and orders the order history listing.
+type OrderFilter struct {
+	Status   string     `form:"status"`
+	From     *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	MinTotal *float64   `form:"min_total"`
+	MaxTotal *float64   `form:"max_total"`
+	SortBy   string     `form:"sort_by"`
+	SortDir  string     `form:"sort_dir"`
+	Page     int        `form:"page"`
+	PageSize int        `form:"page_size"`
 }
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Domain events written to the outbox in the same transaction as the change
+// they describe.
+const (
+	EventOrderPlaced        = "OrderPlaced"
+	EventOrderCancelled     = "OrderCancelled"
+	EventOrderStatusChanged = "OrderStatusChanged"
+	EventProductCreated     = "ProductCreated"
+	EventStockChanged       = "StockChanged"
+	EventUserRegistered     = "UserRegistered"
+	// EventUserErasureRequested starts the erasure of a user's personal
+	// data. It is handled in the background by the privacy service.
+	EventUserErasureRequested = "UserErasureRequested"
+	// EventProductPriceChanged carries the PriceHistory entry of a change
+	// to the product's current price.
+	EventProductPriceChanged = "ProductPriceChanged"
+)
+
+const (
+	AggregateOrder   = "order"
+	AggregateProduct = "product"
+	AggregateUser    = "user"
+)
+
+const (
+	StockReasonOrderPlaced    = "order_placed"
+	StockReasonOrderCancelled = "order_cancelled"
+	StockReasonRestock        = "restock"
+)
+
+// OutboxEvent is a domain event waiting to be published. PublishedAt stays
+// nil until every sink has accepted it. DeadAt is set when the dispatcher
+// gives up on it after too many failed attempts.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Type          string     `gorm:"type:varchar(50);index" json:"type"`
+	AggregateType string     `gorm:"type:varchar(50)" json:"aggregate_type"`
+	AggregateID   uint       `json:"aggregate_id"`
+	Payload       string     `gorm:"type:longtext" json:"payload"`
+	OccurredAt    time.Time  `json:"occurred_at"`
+	PublishedAt   *time.Time `gorm:"index" json:"published_at,omitempty"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	DeadAt        *time.Time `gorm:"index" json:"dead_at,omitempty"`
+}
+
+// OutboxDelivery records that a sink has handled an event, so a retry only
+// goes to the sinks that failed.
+type OutboxDelivery struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	EventID     uint      `gorm:"uniqueIndex:idx_outbox_delivery_sink" json:"event_id"`
+	Sink        string    `gorm:"type:varchar(50);uniqueIndex:idx_outbox_delivery_sink" json:"sink"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// StockChange is the payload of StockChanged.
+type StockChange struct {
+	ProductID     uint   `json:"product_id"`
+	PreviousStock int    `json:"previous_stock"`
+	Stock         int    `json:"stock"`
+	Reason        string `json:"reason"`
+	OrderID       uint   `json:"order_id,omitempty"`
+}
+
+// UserRegistration is the payload of UserRegistered. It carries no
+// credentials.
+type UserRegistration struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+// NewOutboxEvent builds an event ready to be stored, due for publishing
+// straight away.
+func NewOutboxEvent(eventType, aggregateType string, aggregateID uint, payload interface{}) (OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	now := time.Now()
+	return OutboxEvent{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}, nil
+}
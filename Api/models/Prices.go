@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+const (
+	PriceChangeKindRegular = "regular"
+	PriceChangeKindSale    = "sale"
+
+	PriceChangeStatusPending   = "pending"
+	PriceChangeStatusActive    = "active"
+	PriceChangeStatusApplied   = "applied"
+	PriceChangeStatusCompleted = "completed"
+	PriceChangeStatusCancelled = "cancelled"
+
+	PriceReasonCreated     = "created"
+	PriceReasonChanged     = "price_change"
+	PriceReasonSaleStarted = "sale_started"
+	PriceReasonSaleEnded   = "sale_ended"
+	// PriceReasonRegularChanged records a new regular price set during a
+	// sale; the product keeps the sale price until the sale ends.
+	PriceReasonRegularChanged = "regular_price_change"
+)
+
+// PriceHistory is an immutable record of every price a product has had.
+type PriceHistory struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ProductID     uint      `gorm:"index" json:"product_id"`
+	Price         float64   `json:"price"`
+	PreviousPrice float64   `json:"previous_price"`
+	Reason        string    `gorm:"type:varchar(50)" json:"reason"`
+	ChangeID      *uint     `json:"change_id,omitempty"`
+	ChangedBy     string    `json:"changed_by"`
+	EffectiveAt   time.Time `gorm:"index" json:"effective_at"`
+}
+
+// ScheduledPriceChange is a future regular price change or a sale window
+// applied by the price scheduler.
+type ScheduledPriceChange struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	ProductID    uint       `gorm:"index" json:"product_id"`
+	Kind         string     `gorm:"type:varchar(20)" json:"kind"`
+	Price        float64    `json:"price"`
+	RegularPrice float64    `json:"regular_price,omitempty"`
+	StartsAt     time.Time  `gorm:"index" json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	Status       string     `gorm:"type:varchar(20);index" json:"status"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type SchedulePriceChangeRequest struct {
+	Kind     string     `json:"kind" binding:"omitempty,oneof=regular sale" example:"sale"`
+	Price    float64    `json:"price" binding:"gt=0" example:"9.99"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	ErasureStatusPending   = "pending"
+	ErasureStatusCompleted = "completed"
+)
+
+// ErasureRequest is the audit record of a data subject erasure request: who
+// asked for it, why, and when the user's personal data was pseudonymised.
+// It outlives the data it describes and only refers to the user by ID.
+type ErasureRequest struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"index" json:"user_id"`
+	RequestedBy string     `gorm:"type:varchar(255)" json:"requested_by"`
+	Reason      string     `gorm:"type:varchar(255)" json:"reason"`
+	Status      string     `gorm:"type:varchar(20);index;default:pending" json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+type ErasureRequestBody struct {
+	Reason string `json:"reason" binding:"required,max=255" example:"Solicitud del interesado por email (art. 17 RGPD)"`
+}
+
+// UserErasure is the payload of UserErasureRequested.
+type UserErasure struct {
+	RequestID uint `json:"request_id"`
+	UserID    uint `json:"user_id"`
+}
+
+// ExportedProfile is the part of User included in a personal data export.
+// Credentials and secrets are left out.
+type ExportedProfile struct {
+	ID               uint       `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+}
+
+// UserDataExport is everything stored about a user that a data export
+// returns.
+type UserDataExport struct {
+	Profile   ExportedProfile `json:"profile"`
+	Addresses []Address       `json:"addresses"`
+	Orders    []Order         `json:"orders"`
+	Sessions  []Session       `json:"sessions"`
+	Reviews   []Review        `json:"reviews"`
+}
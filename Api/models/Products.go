@@ -1,12 +1,139 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TaxCategoryStandard = "standard"
+	TaxCategoryExempt   = "exempt"
+)
 
 type Product struct {
 	gorm.Model  `json:"-" swaggerignore:"true"`
-	Name        string  `gorm:"type:varchar(255);uniqueIndex" json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
-	CreatedBy   string  `json:"created_by"`
+	Name        string     `gorm:"type:varchar(255);uniqueIndex" json:"name" binding:"required,notblank,max=255"`
+	Description string     `json:"description"`
+	SKU         string     `gorm:"type:varchar(64);index" json:"sku" binding:"max=64"`
+	TaxCategory string     `gorm:"type:varchar(50)" json:"tax_category" binding:"omitempty,oneof=standard exempt"`
+	Attributes  Attributes `gorm:"type:text" json:"attributes,omitempty"`
+	Price       float64    `json:"price" binding:"gt=0"`
+	Weight      float64    `json:"weight" binding:"gte=0"`
+	Stock       int        `json:"stock" binding:"gte=0"`
+	CreatedBy   string     `json:"created_by"`
+	// RatingAverage and RatingCount summarize the approved reviews. They are
+	// recomputed whenever a review is moderated.
+	RatingAverage float64 `gorm:"index" json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
+	// Translations hold the name and description in other languages. They
+	// can be sent when creating the product; reads answer with the text in
+	// the language of the request instead.
+	Translations []ProductTranslation `json:"translations,omitempty" binding:"omitempty,dive"`
+}
+
+// ProductTranslation is the name and description of a product in one
+// language. Empty fields fall back to the product's own text.
+type ProductTranslation struct {
+	ID          uint   `gorm:"primarykey" json:"-"`
+	ProductID   uint   `gorm:"uniqueIndex:idx_product_language" json:"-"`
+	Language    string `gorm:"type:varchar(8);uniqueIndex:idx_product_language" json:"language" binding:"required,oneof=en es"`
+	Name        string `gorm:"type:varchar(255)" json:"name" binding:"max=255"`
+	Description string `json:"description"`
+}
+
+// ProductTranslationRequest is the body to translate a product into the
+// language of the path.
+type ProductTranslationRequest struct {
+	Name        string `json:"name" binding:"required,notblank,max=255"`
+	Description string `json:"description"`
+}
+
+// ProductSort orders the product listing. An empty SortBy keeps the
+// catalogue order.
+type ProductSort struct {
+	SortBy  string `form:"sort_by"`
+	SortDir string `form:"sort_dir"`
+}
+
+// Localize replaces the name and description with their translation into
+// lang, if the product has one, and drops the translations.
+func (p *Product) Localize(lang string) {
+	for _, t := range p.Translations {
+		if t.Language != lang {
+			continue
+		}
+		if t.Name != "" {
+			p.Name = t.Name
+		}
+		if t.Description != "" {
+			p.Description = t.Description
+		}
+	}
+	p.Translations = nil
+}
+
+// Snapshot copies the descriptive fields of the product as they are now.
+func (p Product) Snapshot() ProductSnapshot {
+	attributes := make(Attributes, len(p.Attributes))
+	for k, v := range p.Attributes {
+		attributes[k] = v
+	}
+	taxCategory := p.TaxCategory
+	if taxCategory == "" {
+		taxCategory = TaxCategoryStandard
+	}
+	return ProductSnapshot{
+		Name:        p.Name,
+		Description: p.Description,
+		SKU:         p.SKU,
+		TaxCategory: taxCategory,
+		Attributes:  attributes,
+	}
+}
+
+// ProductSnapshot is the immutable copy of a product stored on an order line.
+type ProductSnapshot struct {
+	Name        string     `gorm:"type:varchar(255)" json:"name"`
+	Description string     `json:"description"`
+	SKU         string     `gorm:"type:varchar(64)" json:"sku"`
+	TaxCategory string     `gorm:"type:varchar(50)" json:"tax_category"`
+	Attributes  Attributes `gorm:"type:text" json:"attributes,omitempty"`
+}
+
+// Attributes holds free-form product properties (color, size...) stored as
+// a JSON column.
+type Attributes map[string]string
+
+func (a Attributes) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (a *Attributes) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Attributes", value)
+	}
+	if len(raw) == 0 {
+		*a = nil
+		return nil
+	}
+	return json.Unmarshal(raw, a)
 }
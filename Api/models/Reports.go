@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+)
+
+// ReportFilter selects the date range of a report. Both dates are inclusive
+// calendar days.
+type ReportFilter struct {
+	From     *time.Time `form:"from" time_format:"2006-01-02"`
+	To       *time.Time `form:"to" time_format:"2006-01-02"`
+	Interval string     `form:"interval"`
+	Limit    int        `form:"limit"`
+}
+
+// SalesPeriod aggregates the orders of one bucket. Period is the first day
+// of the bucket as YYYY-MM-DD. Revenue excludes shipping, which is summed
+// separately in Shipping.
+type SalesPeriod struct {
+	Period            string  `json:"period"`
+	Revenue           float64 `json:"revenue"`
+	Shipping          float64 `json:"shipping"`
+	Orders            int64   `json:"orders"`
+	Units             int64   `json:"units"`
+	AverageOrderValue float64 `gorm:"-" json:"average_order_value"`
+}
+
+type ProductSales struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	Units     int64   `json:"units"`
+	Revenue   float64 `json:"revenue"`
+}
+
+type CustomerSales struct {
+	UserID  uint    `json:"user_id"`
+	Email   string  `json:"email"`
+	Orders  int64   `json:"orders"`
+	Revenue float64 `json:"revenue"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// Review is a rating left by a customer who bought the product. Only
+// approved reviews are public and count towards the product rating.
+type Review struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ProductID   uint       `gorm:"uniqueIndex:idx_review_user_product;index" json:"product_id"`
+	UserID      uint       `gorm:"uniqueIndex:idx_review_user_product" json:"user_id"`
+	Author      string     `gorm:"type:varchar(255)" json:"author"`
+	Rating      int        `json:"rating"`
+	Title       string     `gorm:"type:varchar(120)" json:"title"`
+	Body        string     `gorm:"type:text" json:"body"`
+	Status      string     `gorm:"type:varchar(20);index;default:pending" json:"status"`
+	ModeratedBy string     `json:"moderated_by,omitempty"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5" example:"5"`
+	Title  string `json:"title" binding:"max=120" example:"Muy buen teclado"`
+	Body   string `json:"body" example:"Las teclas se sienten firmes y silenciosas."`
+}
+
+type ReviewModerationRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected" example:"approved"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Session is one login of a user on a device. Its TokenID is carried by the
+// session's tokens (the JWT "jti" claim), so revoking the session rejects
+// them even before they expire.
+type Session struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index" json:"-"`
+	TokenID    string     `gorm:"type:char(32);uniqueIndex" json:"-"`
+	Device     string     `gorm:"type:varchar(100)" json:"device"`
+	UserAgent  string     `gorm:"type:varchar(512)" json:"user_agent"`
+	IP         string     `gorm:"type:varchar(45)" json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	// ImpersonatedBy is the email of the admin who opened the session on
+	// the user's behalf.
+	ImpersonatedBy string `gorm:"type:varchar(255)" json:"impersonated_by,omitempty"`
+	// Current marks the session the listing request was made with.
+	Current bool `gorm:"-" json:"current"`
+}
+
+// ClientInfo describes the client a login comes from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	// ImpersonatedBy is set when an admin logs in as the user.
+	ImpersonatedBy string
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+const (
+	ShipmentStatusLabelCreated   = "label_created"
+	ShipmentStatusInTransit      = "in_transit"
+	ShipmentStatusOutForDelivery = "out_for_delivery"
+	ShipmentStatusDelivered      = "delivered"
+	ShipmentStatusException      = "exception"
+)
+
+// Shipment groups some or all of the lines of an order sent in one package.
+type Shipment struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	OrderID        uint            `gorm:"index" json:"order_id"`
+	Carrier        string          `gorm:"type:varchar(100)" json:"carrier"`
+	TrackingNumber string          `gorm:"type:varchar(100);index" json:"tracking_number"`
+	Status         string          `gorm:"type:varchar(30)" json:"status"`
+	CreatedBy      string          `json:"created_by"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Items          []ShipmentItem  `gorm:"foreignKey:ShipmentID" json:"items"`
+	Events         []ShipmentEvent `gorm:"foreignKey:ShipmentID" json:"events"`
+}
+
+type ShipmentItem struct {
+	ID             uint `gorm:"primaryKey" json:"id"`
+	ShipmentID     uint `gorm:"index" json:"shipment_id"`
+	OrderProductID uint `gorm:"index" json:"order_product_id"`
+	Quantity       int  `json:"quantity"`
+}
+
+type ShipmentEvent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ShipmentID  uint      `gorm:"index" json:"shipment_id"`
+	Status      string    `gorm:"type:varchar(30)" json:"status"`
+	Location    string    `json:"location"`
+	Description string    `json:"description"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+type CreateShipmentRequest struct {
+	Carrier        string                `json:"carrier" example:"Chilexpress"`
+	TrackingNumber string                `json:"tracking_number" example:"CX123456789"`
+	Items          []ShipmentItemRequest `json:"items" binding:"dive"`
+}
+
+type ShipmentItemRequest struct {
+	OrderProductID uint `json:"order_product_id" binding:"required"`
+	Quantity       int  `json:"quantity" binding:"gt=0"`
+}
+
+type ShipmentEventRequest struct {
+	Status      string     `json:"status" example:"in_transit"`
+	Location    string     `json:"location" example:"Centro de distribución Santiago"`
+	Description string     `json:"description"`
+	OccurredAt  *time.Time `json:"occurred_at,omitempty"`
+}
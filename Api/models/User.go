@@ -1,12 +1,70 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RoleCustomer  = "customer"
+	RoleWarehouse = "warehouse"
+	RoleAdmin     = "admin"
+)
 
 type User struct {
 	gorm.Model   `json:"-" swaggerignore:"true"`
 	Username     string `gorm:"type:varchar(255);uniqueIndex" json:"username"`
-	Password     string `validate:"required,password" json:"password" bson:"password"`
+	Password     string `json:"password" bson:"password"`
 	Email        string `gorm:"type:varchar(255);uniqueIndex" json:"email" bson:"email"`
 	RefreshToken string `json:"refresh_token"`
 	Token        string `json:"token"`
+	Role         string `gorm:"type:varchar(20);default:customer" json:"role"`
+	// SessionsRevokedAt invalidates every token issued before it, e.g. after
+	// a password reset.
+	SessionsRevokedAt *time.Time `json:"-"`
+	// EmailVerifiedAt is nil until the user opens the verification link.
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	VerificationSentAt *time.Time `json:"-"`
+	// TOTPSecret is set on enrollment; two-factor login is only required
+	// once TwoFactorEnabledAt is set by confirming a code.
+	TOTPSecret         string     `gorm:"column:totp_secret;type:varchar(64)" json:"-"`
+	TOTPLastStep       int64      `gorm:"column:totp_last_step" json:"-"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+	// DisabledAt blocks the account: it cannot log in and its tokens and
+	// API keys are refused.
+	DisabledAt *time.Time `json:"disabled_at"`
+}
+
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,notblank,max=255" example:"juanperez"`
+	Email    string `json:"email" binding:"required,email,max=255" example:"juan@example.com"`
+	Password string `json:"password" binding:"required,password" example:"Clave-Segura1"`
+}
+
+// LoginRequest does not apply the password strength rule, so accounts
+// created before it was introduced can still log in.
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email" example:"juan@example.com"`
+	Password string `json:"password" binding:"required" example:"Clave-Segura1"`
+}
+
+// ProfileUpdateRequest holds the profile fields users may change themselves.
+// Omitted fields are left as they are.
+type ProfileUpdateRequest struct {
+	Username *string `json:"username" binding:"omitempty,min=1,max=255" example:"juanperez"`
+	Email    *string `json:"email" binding:"omitempty,email,max=255" example:"juan@example.com"`
+	// CurrentPassword confirms an email change, since password resets go to
+	// the new address.
+	CurrentPassword string `json:"current_password" binding:"required_with=Email"`
+}
+
+// DeleteAccountRequest confirms an account deletion with the current password.
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,password" example:"Nueva-Clave1"`
 }
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	WebhookEventOrderCreated        = "order.created"
+	WebhookEventOrderStatusChanged  = "order.status_changed"
+	WebhookEventProductCreated      = "product.created"
+	WebhookEventProductPriceChanged = "product.price_changed"
+)
+
+// WebhookEvents lists every event type a subscription can ask for.
+var WebhookEvents = []string{
+	WebhookEventOrderCreated,
+	WebhookEventOrderStatusChanged,
+	WebhookEventProductCreated,
+	WebhookEventProductPriceChanged,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	URL       string     `gorm:"type:varchar(2048)" json:"url"`
+	Secret    string     `gorm:"type:varchar(128)" json:"secret,omitempty"`
+	Events    StringList `gorm:"type:text" json:"events"`
+	Active    bool       `gorm:"default:true" json:"active"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Wants reports whether the subscription receives eventType.
+func (s WebhookSubscription) Wants(eventType string) bool {
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one subscription. It is retried
+// until it succeeds or runs out of attempts, when it becomes dead.
+type WebhookDelivery struct {
+	ID             uint             `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint             `gorm:"index" json:"subscription_id"`
+	EventType      string           `gorm:"type:varchar(50)" json:"event_type"`
+	Payload        string           `gorm:"type:text" json:"payload"`
+	Status         string           `gorm:"type:varchar(20);index" json:"status"`
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  time.Time        `gorm:"index" json:"next_attempt_at"`
+	LastStatusCode int              `json:"last_status_code"`
+	LastError      string           `gorm:"type:text" json:"last_error"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	AttemptLog     []WebhookAttempt `gorm:"foreignKey:DeliveryID" json:"attempt_log,omitempty"`
+}
+
+// WebhookAttempt records the outcome of a single HTTP call.
+type WebhookAttempt struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	DeliveryID  uint      `gorm:"index" json:"delivery_id"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `gorm:"type:text" json:"error"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url" example:"https://erp.example.com/hooks/orders"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events" example:"order.created"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+type WebhookDeliveryFilter struct {
+	SubscriptionID uint   `form:"subscription_id"`
+	Status         string `form:"status"`
+	Limit          int    `form:"limit"`
+}
+
+// StringList is a list of strings stored as a JSON column.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+	return json.Unmarshal(raw, (*[]string)(l))
+}
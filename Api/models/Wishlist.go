@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type WishlistItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_wishlist_user_product" json:"user_id"`
+	ProductID uint      `gorm:"uniqueIndex:idx_wishlist_user_product" json:"product_id"`
+	Product   Product   `gorm:"foreignKey:ProductID" json:"product"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WishlistRequest struct {
+	ProductID uint `json:"product_id" binding:"required" example:"1"`
+}
+
+// StockAlert asks for an email when an out of stock product is restocked.
+// It fires once; NotifiedAt is set when it has been sent.
+type StockAlert struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"uniqueIndex:idx_stock_alert_user_product" json:"user_id"`
+	ProductID  uint       `gorm:"uniqueIndex:idx_stock_alert_user_product;index" json:"product_id"`
+	User       User       `gorm:"foreignKey:UserID" json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+}
+
+type RestockRequest struct {
+	Quantity int `json:"quantity" binding:"required,gt=0" example:"10"`
+}
@@ -0,0 +1,144 @@
+package address_repo
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AddressRepository interface {
+	CreateAddress(address *models.Address) (*models.Address, error)
+	GetAddressByID(id uint) (*models.Address, error)
+	GetAddressesByUserID(userID uint) ([]models.Address, error)
+	GetDefaultAddress(userID uint) (*models.Address, error)
+	UpdateAddress(address *models.Address) (*models.Address, error)
+	DeleteAddress(id uint) error
+	SetDefaultAddress(userID, id uint) error
+}
+
+type addressRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewAddressRepository(db *gorm.DB, logger *logrus.Logger) AddressRepository {
+	return &addressRepository{db: db, logger: logger}
+}
+
+func (r *addressRepository) CreateAddress(address *models.Address) (*models.Address, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefault(tx, address.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: address_repo, Method: CreateAddress, Error:", err)
+		return nil, err
+	}
+	return address, nil
+}
+
+func (r *addressRepository) GetAddressByID(id uint) (*models.Address, error) {
+	var address models.Address
+	err := r.db.First(&address, id).Error
+	if err != nil {
+		r.logger.Errorln("Layer: address_repo, Method: GetAddressByID, Error:", err)
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) GetAddressesByUserID(userID uint) ([]models.Address, error) {
+	var addresses []models.Address
+	err := r.db.Where("user_id = ?", userID).Order("is_default desc").Order("id asc").Find(&addresses).Error
+	if err != nil {
+		r.logger.Errorln("Layer: address_repo, Method: GetAddressesByUserID, Error:", err)
+		return nil, err
+	}
+	return addresses, nil
+}
+
+func (r *addressRepository) GetDefaultAddress(userID uint) (*models.Address, error) {
+	var address models.Address
+	err := r.db.Where("user_id = ? AND is_default = ?", userID, true).First(&address).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) UpdateAddress(address *models.Address) (*models.Address, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefault(tx, address.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Save(address).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: address_repo, Method: UpdateAddress, Error:", err)
+		return nil, err
+	}
+	return address, nil
+}
+
+// DeleteAddress removes the address. Deleting the default address makes the
+// user's oldest remaining address the default, so checkout keeps working.
+func (r *addressRepository) DeleteAddress(id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var address models.Address
+		if err := tx.First(&address, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next models.Address
+		err := tx.Where("user_id = ?", address.UserID).Order("id asc").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: address_repo, Method: DeleteAddress, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// SetDefaultAddress makes id the only default address of userID.
+func (r *addressRepository) SetDefaultAddress(userID, id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefault(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&models.Address{}).
+			Where("id = ? AND user_id = ?", id, userID).
+			Update("is_default", true).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: address_repo, Method: SetDefaultAddress, Error:", err)
+		return err
+	}
+	return nil
+}
+
+func clearDefault(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.Address{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
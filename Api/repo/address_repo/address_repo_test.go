@@ -0,0 +1,109 @@
+package address_repo
+
+import (
+	"testing"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.Address{})
+	require.NoError(t, err)
+	return db
+}
+
+func newAddress(userID uint, label string, isDefault bool) *models.Address {
+	return &models.Address{
+		UserID:    userID,
+		Label:     label,
+		IsDefault: isDefault,
+		PostalAddress: models.PostalAddress{
+			Recipient:  "Ana",
+			Line1:      "Calle 1",
+			City:       "Santiago",
+			PostalCode: "8320000",
+			Country:    "CL",
+		},
+	}
+}
+
+func TestCreateAddress_DefaultIsUnique(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewAddressRepository(db, logrus.New())
+
+	first, err := repo.CreateAddress(newAddress(1, "Casa", true))
+	require.NoError(t, err)
+	second, err := repo.CreateAddress(newAddress(1, "Oficina", true))
+	require.NoError(t, err)
+
+	def, err := repo.GetDefaultAddress(1)
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID, def.ID)
+
+	reloaded, err := repo.GetAddressByID(first.ID)
+	assert.NoError(t, err)
+	assert.False(t, reloaded.IsDefault)
+	assert.Equal(t, "Calle 1", reloaded.Line1)
+}
+
+func TestSetDefaultAddress(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewAddressRepository(db, logrus.New())
+
+	first, _ := repo.CreateAddress(newAddress(1, "Casa", true))
+	second, _ := repo.CreateAddress(newAddress(1, "Oficina", false))
+	other, _ := repo.CreateAddress(newAddress(2, "Otra", true))
+
+	require.NoError(t, repo.SetDefaultAddress(1, second.ID))
+
+	addresses, err := repo.GetAddressesByUserID(1)
+	assert.NoError(t, err)
+	require.Len(t, addresses, 2)
+	assert.Equal(t, second.ID, addresses[0].ID)
+	assert.True(t, addresses[0].IsDefault)
+	assert.Equal(t, first.ID, addresses[1].ID)
+	assert.False(t, addresses[1].IsDefault)
+
+	otherDefault, err := repo.GetDefaultAddress(2)
+	assert.NoError(t, err)
+	assert.Equal(t, other.ID, otherDefault.ID)
+}
+
+func TestDeleteAddress(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewAddressRepository(db, logrus.New())
+
+	address, _ := repo.CreateAddress(newAddress(1, "Casa", false))
+	require.NoError(t, repo.DeleteAddress(address.ID))
+
+	_, err := repo.GetAddressByID(address.ID)
+	assert.Error(t, err)
+}
+
+func TestDeleteAddress_PromotesAnotherDefault(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewAddressRepository(db, logrus.New())
+
+	home, _ := repo.CreateAddress(newAddress(1, "Casa", true))
+	work, _ := repo.CreateAddress(newAddress(1, "Trabajo", false))
+	_, _ = repo.CreateAddress(newAddress(2, "Otro", false))
+	require.NoError(t, repo.DeleteAddress(home.ID))
+
+	def, err := repo.GetDefaultAddress(1)
+	require.NoError(t, err)
+	assert.Equal(t, work.ID, def.ID)
+	_, err = repo.GetDefaultAddress(2)
+	assert.Error(t, err, "other users' addresses are untouched")
+
+	require.NoError(t, repo.DeleteAddress(work.ID))
+	_, err = repo.GetDefaultAddress(1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package admin_audit_repo
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AdminAuditRepository interface {
+	CreateEntry(entry *models.AdminAuditEntry) error
+	ListEntries(filter models.AdminAuditFilter) ([]models.AdminAuditEntry, int64, error)
+}
+
+type adminAuditRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewAdminAuditRepository(db *gorm.DB, logger *logrus.Logger) AdminAuditRepository {
+	return &adminAuditRepository{db: db, logger: logger}
+}
+
+func (r *adminAuditRepository) CreateEntry(entry *models.AdminAuditEntry) error {
+	err := r.db.Create(entry).Error
+	if err != nil {
+		r.logger.Errorln("Layer: admin_audit_repo, Method: CreateEntry, Error:", err)
+	}
+	return err
+}
+
+// ListEntries returns a page of entries, newest first, with the total number
+// of matches.
+func (r *adminAuditRepository) ListEntries(filter models.AdminAuditFilter) ([]models.AdminAuditEntry, int64, error) {
+	query := r.db.Model(&models.AdminAuditEntry{})
+	if filter.UserID != 0 {
+		query = query.Where("target_user_id = ?", filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Errorln("Layer: admin_audit_repo, Method: ListEntries, Error:", err)
+		return nil, 0, err
+	}
+
+	var entries []models.AdminAuditEntry
+	err := query.Order("id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&entries).Error
+	if err != nil {
+		r.logger.Errorln("Layer: admin_audit_repo, Method: ListEntries, Error:", err)
+		return nil, 0, err
+	}
+	return entries, count, nil
+}
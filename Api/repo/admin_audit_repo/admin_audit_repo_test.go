@@ -0,0 +1,46 @@
+package admin_audit_repo
+
+import (
+	"testing"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.AdminAuditEntry{}))
+	return db
+}
+
+func TestListEntries_FiltersAndPages(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewAdminAuditRepository(db, logrus.New())
+
+	for _, entry := range []models.AdminAuditEntry{
+		{AdminID: 1, Action: models.AdminActionDisableUser, TargetUserID: 5},
+		{AdminID: 1, Action: models.AdminActionEnableUser, TargetUserID: 5},
+		{AdminID: 1, Action: models.AdminActionImpersonate, TargetUserID: 5},
+		{AdminID: 1, Action: models.AdminActionImpersonate, TargetUserID: 6},
+	} {
+		entry := entry
+		require.NoError(t, repo.CreateEntry(&entry))
+	}
+
+	entries, count, err := repo.ListEntries(models.AdminAuditFilter{UserID: 5, Page: 1, PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.AdminActionImpersonate, entries[0].Action, "newest first")
+
+	entries, count, err = repo.ListEntries(models.AdminAuditFilter{Action: models.AdminActionImpersonate, Page: 1, PageSize: 20})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,125 @@
+package api_keys_repo
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ApiKeysRepository stores the machine credentials: API keys and OAuth
+// clients.
+type ApiKeysRepository interface {
+	CreateKey(key *models.ApiKey) error
+	GetKeyByPrefix(prefix string) (*models.ApiKey, error)
+	ListUserKeys(userID uint) ([]models.ApiKey, error)
+	RevokeKey(userID, id uint, at time.Time) error
+	TouchKey(id uint, at, notBefore time.Time) error
+
+	CreateClient(client *models.OAuthClient) error
+	GetClientByClientID(clientID string) (*models.OAuthClient, error)
+	ListUserClients(userID uint) ([]models.OAuthClient, error)
+	RevokeClient(userID, id uint, at time.Time) error
+}
+
+type apiKeysRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewApiKeysRepository(db *gorm.DB, logger *logrus.Logger) ApiKeysRepository {
+	return &apiKeysRepository{db: db, logger: logger}
+}
+
+func (r *apiKeysRepository) CreateKey(key *models.ApiKey) error {
+	err := r.db.Omit("User").Create(key).Error
+	if err != nil {
+		r.logger.Errorln("Layer: api_keys_repo, Method: CreateKey, Error:", err)
+	}
+	return err
+}
+
+// GetKeyByPrefix loads the key with its owner, who the request acts as.
+func (r *apiKeysRepository) GetKeyByPrefix(prefix string) (*models.ApiKey, error) {
+	var key models.ApiKey
+	err := r.db.Preload("User").Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeysRepository) ListUserKeys(userID uint) ([]models.ApiKey, error) {
+	var keys []models.ApiKey
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error
+	if err != nil {
+		r.logger.Errorln("Layer: api_keys_repo, Method: ListUserKeys, Error:", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeKey revokes a key of the user. It returns gorm.ErrRecordNotFound if
+// the user has no such active key.
+func (r *apiKeysRepository) RevokeKey(userID, id uint, at time.Time) error {
+	return r.revoke(&models.ApiKey{}, "RevokeKey", userID, id, at)
+}
+
+// TouchKey records when a key was last used, at most once per interval so
+// every request does not write.
+func (r *apiKeysRepository) TouchKey(id uint, at, notBefore time.Time) error {
+	err := r.db.Model(&models.ApiKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, notBefore).
+		Update("last_used_at", at).Error
+	if err != nil {
+		r.logger.Errorln("Layer: api_keys_repo, Method: TouchKey, Error:", err)
+	}
+	return err
+}
+
+func (r *apiKeysRepository) CreateClient(client *models.OAuthClient) error {
+	err := r.db.Omit("User").Create(client).Error
+	if err != nil {
+		r.logger.Errorln("Layer: api_keys_repo, Method: CreateClient, Error:", err)
+	}
+	return err
+}
+
+func (r *apiKeysRepository) GetClientByClientID(clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.Preload("User").Where("client_id = ?", clientID).First(&client).Error
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *apiKeysRepository) ListUserClients(userID uint) ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&clients).Error
+	if err != nil {
+		r.logger.Errorln("Layer: api_keys_repo, Method: ListUserClients, Error:", err)
+		return nil, err
+	}
+	return clients, nil
+}
+
+func (r *apiKeysRepository) RevokeClient(userID, id uint, at time.Time) error {
+	return r.revoke(&models.OAuthClient{}, "RevokeClient", userID, id, at)
+}
+
+func (r *apiKeysRepository) revoke(model interface{}, method string, userID, id uint, at time.Time) error {
+	result := r.db.Model(model).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		r.logger.Errorln("Layer: api_keys_repo, Method: "+method+", Error:", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package api_keys_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.User{}, &models.ApiKey{}, &models.OAuthClient{})
+	require.NoError(t, err)
+	return db
+}
+
+func createUser(t *testing.T, db *gorm.DB, email string) *models.User {
+	user := &models.User{Username: email, Email: email, Password: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+func TestApiKey_LookupPreloadsOwnerAndRevokeIsScopedToOwner(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewApiKeysRepository(db, logrus.New())
+	owner := createUser(t, db, "ana@example.com")
+	other := createUser(t, db, "bob@example.com")
+
+	key := &models.ApiKey{UserID: owner.ID, Name: "ERP", Prefix: "vk_abcd1234", KeyHash: "hash", Scopes: "products:read"}
+	require.NoError(t, repo.CreateKey(key))
+
+	stored, err := repo.GetKeyByPrefix("vk_abcd1234")
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", stored.User.Email)
+
+	assert.ErrorIs(t, repo.RevokeKey(other.ID, key.ID, time.Now()), gorm.ErrRecordNotFound)
+	require.NoError(t, repo.RevokeKey(owner.ID, key.ID, time.Now()))
+	assert.ErrorIs(t, repo.RevokeKey(owner.ID, key.ID, time.Now()), gorm.ErrRecordNotFound)
+
+	keys, err := repo.ListUserKeys(owner.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].RevokedAt)
+}
+
+func TestTouchKey_WritesAtMostOncePerInterval(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewApiKeysRepository(db, logrus.New())
+	owner := createUser(t, db, "ana@example.com")
+	key := &models.ApiKey{UserID: owner.ID, Prefix: "vk_abcd1234", KeyHash: "hash"}
+	require.NoError(t, repo.CreateKey(key))
+
+	first := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.TouchKey(key.ID, first, first.Add(-time.Minute)))
+	second := first.Add(10 * time.Second)
+	require.NoError(t, repo.TouchKey(key.ID, second, second.Add(-time.Minute)))
+
+	stored, err := repo.GetKeyByPrefix("vk_abcd1234")
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastUsedAt)
+	assert.True(t, stored.LastUsedAt.Equal(first))
+}
+
+func TestOAuthClient_CreateLookupRevoke(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewApiKeysRepository(db, logrus.New())
+	owner := createUser(t, db, "ana@example.com")
+
+	client := &models.OAuthClient{UserID: owner.ID, Name: "Robot", ClientID: "vc_0123456789abcdef", SecretHash: "hash", Scopes: "warehouse:read"}
+	require.NoError(t, repo.CreateClient(client))
+
+	stored, err := repo.GetClientByClientID("vc_0123456789abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, owner.ID, stored.User.ID)
+
+	require.NoError(t, repo.RevokeClient(owner.ID, client.ID, time.Now()))
+	clients, err := repo.ListUserClients(owner.ID)
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	assert.NotNil(t, clients[0].RevokedAt)
+}
@@ -0,0 +1,84 @@
+package invoices_repo
+
+import (
+	"fmt"
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InvoiceSeries is the sequence every invoice number is drawn from.
+const InvoiceSeries = "invoice"
+
+type InvoicesRepository interface {
+	GetInvoiceByOrderID(orderID uint) (*models.Invoice, error)
+	IssueInvoice(invoice *models.Invoice, render func(*models.Invoice) error) (*models.Invoice, error)
+}
+
+type invoicesRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewInvoicesRepository(db *gorm.DB, logger *logrus.Logger) InvoicesRepository {
+	return &invoicesRepository{db: db, logger: logger}
+}
+
+func (r *invoicesRepository) GetInvoiceByOrderID(orderID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Where("order_id = ?", orderID).First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// IssueInvoice allocates the next invoice number, lets render fill in the
+// documents and stores the invoice, all in one transaction. If anything
+// fails the number is released with the rollback, so the series has no gaps.
+func (r *invoicesRepository) IssueInvoice(invoice *models.Invoice, render func(*models.Invoice) error) (*models.Invoice, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		number, err := nextNumber(tx, InvoiceSeries)
+		if err != nil {
+			return err
+		}
+		invoice.Number = number
+		invoice.Code = fmt.Sprintf("F-%06d", number)
+		if err := render(invoice); err != nil {
+			return err
+		}
+		return tx.Create(invoice).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: invoices_repo, Method: IssueInvoice, Error:", err)
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// nextNumber increments the series in place. A missing series row is
+// inserted first with an upsert that ignores conflicts, so concurrent first
+// invoices of a series cannot fail on the primary key. The UPDATE then holds
+// the row lock until the surrounding transaction ends, serialising
+// concurrent issuers.
+func nextNumber(tx *gorm.DB, series string) (uint, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.InvoiceSequence{Name: series}).Error
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Model(&models.InvoiceSequence{}).
+		Where("name = ?", series).
+		Update("last_number", gorm.Expr("last_number + 1")).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var seq models.InvoiceSequence
+	if err := tx.Where("name = ?", series).First(&seq).Error; err != nil {
+		return 0, err
+	}
+	return seq.LastNumber, nil
+}
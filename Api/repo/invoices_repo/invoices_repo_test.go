@@ -0,0 +1,114 @@
+package invoices_repo
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.Invoice{}, &models.InvoiceSequence{})
+	require.NoError(t, err)
+	return db
+}
+
+func render(invoice *models.Invoice) error {
+	invoice.HTML = "<html></html>"
+	invoice.PDF = []byte("%PDF-1.4")
+	return nil
+}
+
+func TestIssueInvoice_SequentialNumbers(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewInvoicesRepository(db, logrus.New())
+
+	first, err := repo.IssueInvoice(&models.Invoice{OrderID: 1}, render)
+	require.NoError(t, err)
+	second, err := repo.IssueInvoice(&models.Invoice{OrderID: 2}, render)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint(1), first.Number)
+	assert.Equal(t, uint(2), second.Number)
+
+	stored, err := repo.GetInvoiceByOrderID(2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-1.4"), stored.PDF)
+	assert.Equal(t, "F-000002", stored.Code)
+}
+
+func TestIssueInvoice_FailureLeavesNoGap(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewInvoicesRepository(db, logrus.New())
+
+	_, err := repo.IssueInvoice(&models.Invoice{OrderID: 1}, render)
+	require.NoError(t, err)
+
+	_, err = repo.IssueInvoice(&models.Invoice{OrderID: 2}, func(*models.Invoice) error {
+		return errors.New("render failed")
+	})
+	assert.Error(t, err)
+
+	// A second invoice for the same order violates the unique index.
+	_, err = repo.IssueInvoice(&models.Invoice{OrderID: 1}, render)
+	assert.Error(t, err)
+
+	next, err := repo.IssueInvoice(&models.Invoice{OrderID: 3}, render)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), next.Number)
+
+	_, err = repo.GetInvoiceByOrderID(2)
+	assert.Error(t, err)
+}
+
+func TestIssueInvoice_ConcurrentFirstInvoices(t *testing.T) {
+	db := setupInMemoryDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// The in-memory database lives on a single connection.
+	sqlDB.SetMaxOpenConns(1)
+	repo := NewInvoicesRepository(db, logrus.New())
+
+	var wg sync.WaitGroup
+	numbers := make(chan uint, 4)
+	for i := uint(1); i <= 4; i++ {
+		wg.Add(1)
+		go func(orderID uint) {
+			defer wg.Done()
+			invoice, err := repo.IssueInvoice(&models.Invoice{OrderID: orderID}, render)
+			if assert.NoError(t, err) {
+				numbers <- invoice.Number
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(numbers)
+
+	seen := map[uint]bool{}
+	for n := range numbers {
+		seen[n] = true
+	}
+	assert.Equal(t, map[uint]bool{1: true, 2: true, 3: true, 4: true}, seen)
+}
+
+func TestNextNumber_SeriesCreatedConcurrently(t *testing.T) {
+	db := setupInMemoryDB(t)
+	// Another issuer inserted the series row after this one looked for it.
+	require.NoError(t, db.Create(&models.InvoiceSequence{Name: InvoiceSeries}).Error)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		number, err := nextNumber(tx, InvoiceSeries)
+		assert.Equal(t, uint(1), number)
+		return err
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,115 @@
+package login_attempts_repo
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptsRepository interface {
+	GetAttempt(scope, identifier string) (*models.LoginAttempt, error)
+	RecordFailure(scope, identifier string, at, windowStart time.Time) (*models.LoginAttempt, error)
+	ExtendLock(id uint, until time.Time) error
+	ResetAttempts(scope, identifier string) error
+	ListAttempts(lockedAfter *time.Time) ([]models.LoginAttempt, error)
+	DeleteAttempt(id uint) error
+}
+
+type loginAttemptsRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewLoginAttemptsRepository(db *gorm.DB, logger *logrus.Logger) LoginAttemptsRepository {
+	return &loginAttemptsRepository{db: db, logger: logger}
+}
+
+func (r *loginAttemptsRepository) GetAttempt(scope, identifier string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := r.db.Where("scope = ? AND identifier = ?", scope, identifier).First(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// RecordFailure counts one more failure. Failures older than windowStart are
+// forgotten, so the count restarts at one. The increment is a single
+// statement so concurrent failures are all counted.
+func (r *loginAttemptsRepository) RecordFailure(scope, identifier string, at, windowStart time.Time) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginAttempt{Scope: scope, Identifier: identifier, LastFailureAt: at}).Error
+		if err != nil {
+			return err
+		}
+		// failures is assigned before last_failure_at, so the CASE still
+		// sees the previous failure time.
+		err = tx.Model(&models.LoginAttempt{}).
+			Where("scope = ? AND identifier = ?", scope, identifier).
+			Updates(map[string]interface{}{
+				"failures":        gorm.Expr("CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END", windowStart),
+				"last_failure_at": at,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("scope = ? AND identifier = ?", scope, identifier).First(&attempt).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: login_attempts_repo, Method: RecordFailure, Error:", err)
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// ExtendLock sets locked_until unless a longer lock is already in place.
+func (r *loginAttemptsRepository) ExtendLock(id uint, until time.Time) error {
+	err := r.db.Model(&models.LoginAttempt{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", id, until).
+		Update("locked_until", until).Error
+	if err != nil {
+		r.logger.Errorln("Layer: login_attempts_repo, Method: ExtendLock, Error:", err)
+	}
+	return err
+}
+
+func (r *loginAttemptsRepository) ResetAttempts(scope, identifier string) error {
+	err := r.db.Where("scope = ? AND identifier = ?", scope, identifier).Delete(&models.LoginAttempt{}).Error
+	if err != nil {
+		r.logger.Errorln("Layer: login_attempts_repo, Method: ResetAttempts, Error:", err)
+	}
+	return err
+}
+
+// ListAttempts returns the tracked counters, most recent first. When
+// lockedAfter is set only entries still locked at that time are returned.
+func (r *loginAttemptsRepository) ListAttempts(lockedAfter *time.Time) ([]models.LoginAttempt, error) {
+	query := r.db.Order("last_failure_at DESC")
+	if lockedAfter != nil {
+		query = query.Where("locked_until > ?", *lockedAfter)
+	}
+	var attempts []models.LoginAttempt
+	if err := query.Find(&attempts).Error; err != nil {
+		r.logger.Errorln("Layer: login_attempts_repo, Method: ListAttempts, Error:", err)
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *loginAttemptsRepository) DeleteAttempt(id uint) error {
+	result := r.db.Delete(&models.LoginAttempt{}, id)
+	if result.Error != nil {
+		r.logger.Errorln("Layer: login_attempts_repo, Method: DeleteAttempt, Error:", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
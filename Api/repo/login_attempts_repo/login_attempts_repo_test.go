@@ -0,0 +1,83 @@
+package login_attempts_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.LoginAttempt{}))
+	return db
+}
+
+func TestRecordFailure_CountsWithinWindow(t *testing.T) {
+	repo := NewLoginAttemptsRepository(setupInMemoryDB(t), logrus.New())
+	start := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= 3; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+		attempt, err := repo.RecordFailure(models.LoginScopeAccount, "ana@example.com", at, at.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, i, attempt.Failures)
+	}
+
+	// A failure after the window starts the count again.
+	later := start.Add(3 * time.Hour)
+	attempt, err := repo.RecordFailure(models.LoginScopeAccount, "ana@example.com", later, later.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempt.Failures)
+
+	// Scopes are tracked separately.
+	attempt, err = repo.RecordFailure(models.LoginScopeIP, "ana@example.com", later, later.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempt.Failures)
+}
+
+func TestExtendLock_NeverShortens(t *testing.T) {
+	repo := NewLoginAttemptsRepository(setupInMemoryDB(t), logrus.New())
+	now := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	attempt, err := repo.RecordFailure(models.LoginScopeIP, "10.0.0.1", now, now.Add(-time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.ExtendLock(attempt.ID, now.Add(15*time.Minute)))
+	require.NoError(t, repo.ExtendLock(attempt.ID, now.Add(time.Minute)))
+
+	stored, err := repo.GetAttempt(models.LoginScopeIP, "10.0.0.1")
+	require.NoError(t, err)
+	require.NotNil(t, stored.LockedUntil)
+	assert.True(t, stored.LockedUntil.Equal(now.Add(15*time.Minute)))
+
+	locked, err := repo.ListAttempts(&now)
+	require.NoError(t, err)
+	assert.Len(t, locked, 1)
+	afterLock := now.Add(time.Hour)
+	locked, err = repo.ListAttempts(&afterLock)
+	require.NoError(t, err)
+	assert.Empty(t, locked)
+}
+
+func TestResetAndDeleteAttempts(t *testing.T) {
+	repo := NewLoginAttemptsRepository(setupInMemoryDB(t), logrus.New())
+	now := time.Now()
+	_, err := repo.RecordFailure(models.LoginScopeAccount, "ana@example.com", now, now.Add(-time.Hour))
+	require.NoError(t, err)
+	ip, err := repo.RecordFailure(models.LoginScopeIP, "10.0.0.1", now, now.Add(-time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.ResetAttempts(models.LoginScopeAccount, "ana@example.com"))
+	_, err = repo.GetAttempt(models.LoginScopeAccount, "ana@example.com")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, repo.DeleteAttempt(ip.ID))
+	assert.ErrorIs(t, repo.DeleteAttempt(ip.ID), gorm.ErrRecordNotFound)
+}
@@ -2,14 +2,24 @@ package orders_repo
 
 import (
 	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/utils/apperr"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInsufficientStock   = apperr.Conflict("insufficient_stock", "insufficient stock")
+	ErrOrderNotCancellable = apperr.Conflict("order_not_cancellable", "order can no longer be cancelled")
+)
+
 type OrdersRepository interface {
 	CreateOrder(order *models.Order) (*models.Order, error)
 	GetOrdersByUserID(userID uint) ([]models.Order, error)
+	FindUserOrders(userID uint, filter models.OrderFilter) ([]models.Order, int64, error)
+	GetOrderByID(id uint) (*models.Order, error)
+	UpdateOrderStatus(id uint, status string) error
+	CancelOrder(order *models.Order) error
 }
 
 type ordersRepository struct {
@@ -21,8 +31,28 @@ func NewOrdersRepository(db *gorm.DB, logger *logrus.Logger) OrdersRepository {
 	return &ordersRepository{db: db, logger: logger}
 }
 
+// CreateOrder takes the ordered units out of stock and stores the order in
+// one transaction, together with its OrderPlaced and StockChanged events.
 func (r *ordersRepository) CreateOrder(order *models.Order) (*models.Order, error) {
-	err := r.db.Create(order).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+
+		placed, err := models.NewOutboxEvent(models.EventOrderPlaced, models.AggregateOrder, order.ID, order)
+		if err != nil {
+			return err
+		}
+		events := []models.OutboxEvent{placed}
+		for _, item := range order.OrderItems {
+			event, err := adjustStock(tx, item.ProductID, -item.Quantity, models.StockReasonOrderPlaced, order.ID)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return tx.Create(&events).Error
+	})
 	if err != nil {
 		r.logger.Errorln("Layer: orders_repo, Method: CreateOrder, Error:", err)
 		return nil, err
@@ -38,3 +68,194 @@ func (r *ordersRepository) GetOrdersByUserID(userID uint) ([]models.Order, error
 	}
 	return orders, nil
 }
+
+// FindUserOrders returns one page of the user's orders matching filter and
+// the total number of matching orders. The filter is expected to be
+// normalised by the caller.
+func (r *ordersRepository) FindUserOrders(userID uint, filter models.OrderFilter) ([]models.Order, int64, error) {
+	query := r.db.Model(&models.Order{}).Where("user_id = ?", userID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	if filter.MinTotal != nil {
+		query = query.Where("total >= ?", *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		query = query.Where("total <= ?", *filter.MaxTotal)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Errorln("Layer: orders_repo, Method: FindUserOrders, Error:", err)
+		return nil, 0, err
+	}
+
+	var orders []models.Order
+	err := query.
+		Preload("OrderItems").
+		Order(filter.SortBy + " " + filter.SortDir).Order("id " + filter.SortDir).
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&orders).Error
+	if err != nil {
+		r.logger.Errorln("Layer: orders_repo, Method: FindUserOrders, Error:", err)
+		return nil, 0, err
+	}
+
+	refs := make([]*models.Order, len(orders))
+	for i := range orders {
+		refs[i] = &orders[i]
+	}
+	if err := r.fillMissingSnapshots(refs...); err != nil {
+		r.logger.Errorln("Layer: orders_repo, Method: FindUserOrders, Error:", err)
+		return nil, 0, err
+	}
+	return orders, count, nil
+}
+
+func (r *ordersRepository) GetOrderByID(id uint) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Preload("OrderItems").First(&order, id).Error
+	if err != nil {
+		r.logger.Errorln("Layer: orders_repo, Method: GetOrderByID, Error:", err)
+		return nil, err
+	}
+	if err := r.fillMissingSnapshots(&order); err != nil {
+		r.logger.Errorln("Layer: orders_repo, Method: GetOrderByID, Error:", err)
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateOrderStatus stores the new status together with its
+// OrderStatusChanged event.
+func (r *ordersRepository) UpdateOrderStatus(id uint, status string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Preload("OrderItems").First(&order, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&order).Update("status", status).Error; err != nil {
+			return err
+		}
+		order.Status = status
+		event, err := models.NewOutboxEvent(models.EventOrderStatusChanged, models.AggregateOrder, order.ID, order)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: orders_repo, Method: UpdateOrderStatus, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// CancelOrder cancels an order that has not started shipping and puts its
+// units back in stock, together with its OrderCancelled and StockChanged
+// events.
+func (r *ordersRepository) CancelOrder(order *models.Order) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Order{}).
+			Where("id = ? AND status = ?", order.ID, models.OrderStatusPlaced).
+			Update("status", models.OrderStatusCancelled)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrOrderNotCancellable
+		}
+		order.Status = models.OrderStatusCancelled
+
+		cancelled, err := models.NewOutboxEvent(models.EventOrderCancelled, models.AggregateOrder, order.ID, order)
+		if err != nil {
+			return err
+		}
+		events := []models.OutboxEvent{cancelled}
+		for _, item := range order.OrderItems {
+			event, err := adjustStock(tx, item.ProductID, item.Quantity, models.StockReasonOrderCancelled, order.ID)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return tx.Create(&events).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: orders_repo, Method: CancelOrder, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// adjustStock moves the stock of a product by delta and returns the
+// matching StockChanged event. Stock never goes below zero; units coming
+// back are returned even to products removed from the catalogue.
+func adjustStock(tx *gorm.DB, productID uint, delta int, reason string, orderID uint) (models.OutboxEvent, error) {
+	query := tx.Unscoped().Model(&models.Product{}).Where("id = ?", productID)
+	if delta < 0 {
+		query = query.Where("stock >= ? AND deleted_at IS NULL", -delta)
+	}
+	res := query.Update("stock", gorm.Expr("stock + ?", delta))
+	if res.Error != nil {
+		return models.OutboxEvent{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.OutboxEvent{}, ErrInsufficientStock.Detailf("insufficient stock for product ID %d", productID)
+	}
+
+	var product models.Product
+	if err := tx.Unscoped().Select("id", "stock").First(&product, productID).Error; err != nil {
+		return models.OutboxEvent{}, err
+	}
+	return models.NewOutboxEvent(models.EventStockChanged, models.AggregateProduct, productID, models.StockChange{
+		ProductID:     productID,
+		PreviousStock: product.Stock - delta,
+		Stock:         product.Stock,
+		Reason:        reason,
+		OrderID:       orderID,
+	})
+}
+
+// fillMissingSnapshots backfills the product snapshot of lines stored
+// before snapshots existed, using the product row even if soft-deleted.
+// Lines that already carry a snapshot are never touched.
+func (r *ordersRepository) fillMissingSnapshots(orders ...*models.Order) error {
+	var ids []uint
+	for _, order := range orders {
+		for _, item := range order.OrderItems {
+			if item.Snapshot.Name == "" {
+				ids = append(ids, item.ProductID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var products []models.Product
+	if err := r.db.Unscoped().Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	for _, order := range orders {
+		for i := range order.OrderItems {
+			item := &order.OrderItems[i]
+			if product, ok := byID[item.ProductID]; ok && item.Snapshot.Name == "" {
+				item.Snapshot = product.Snapshot()
+			}
+		}
+	}
+	return nil
+}
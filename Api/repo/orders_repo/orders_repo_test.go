@@ -1,6 +1,7 @@
 package orders_repo
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -16,7 +17,7 @@ import (
 func setupInMemoryDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	require.NoError(t, err)
-	err = db.AutoMigrate(&models.Order{}, &models.OrderProduct{})
+	err = db.AutoMigrate(&models.Order{}, &models.OrderProduct{}, &models.Product{}, &models.OutboxEvent{})
 	require.NoError(t, err)
 	return db
 }
@@ -72,3 +73,202 @@ func TestGetOrdersByUserID_Empty(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, fetched)
 }
+
+func TestFindUserOrders_FiltersAndPaginates(t *testing.T) {
+	db := setupInMemoryDB(t)
+	logger := logrus.New()
+	repo := NewOrdersRepository(db, logger)
+
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	seed := []models.Order{
+		{UserID: 10, Status: models.OrderStatusPlaced, Total: 10, CreatedAt: base},
+		{UserID: 10, Status: models.OrderStatusPlaced, Total: 30, CreatedAt: base.Add(24 * time.Hour)},
+		{UserID: 10, Status: models.OrderStatusCancelled, Total: 20, CreatedAt: base.Add(48 * time.Hour)},
+		{UserID: 10, Status: models.OrderStatusPlaced, Total: 40, CreatedAt: base.Add(72 * time.Hour)},
+		{UserID: 11, Status: models.OrderStatusPlaced, Total: 50, CreatedAt: base},
+	}
+	for i := range seed {
+		_, err := repo.CreateOrder(&seed[i])
+		require.NoError(t, err)
+	}
+
+	minTotal := 15.0
+	filter := models.OrderFilter{Status: models.OrderStatusPlaced, MinTotal: &minTotal, SortBy: "total", SortDir: "desc", Page: 1, PageSize: 1}
+	orders, count, err := repo.FindUserOrders(10, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	require.Len(t, orders, 1)
+	assert.Equal(t, 40.0, orders[0].Total)
+
+	filter.Page = 2
+	orders, _, err = repo.FindUserOrders(10, filter)
+	assert.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, 30.0, orders[0].Total)
+
+	to := base.Add(36 * time.Hour)
+	orders, count, err = repo.FindUserOrders(10, models.OrderFilter{To: &to, SortBy: "created_at", SortDir: "asc", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, 10.0, orders[0].Total)
+}
+
+func TestGetOrderByID_BackfillsLegacySnapshot(t *testing.T) {
+	db := setupInMemoryDB(t)
+	logger := logrus.New()
+	repo := NewOrdersRepository(db, logger)
+
+	product := &models.Product{Name: "Teclado-orders-repo", Price: 25}
+	require.NoError(t, db.Create(product).Error)
+	require.NoError(t, db.Delete(product).Error)
+
+	order := &models.Order{
+		UserID:     12,
+		Total:      25,
+		CreatedAt:  time.Now(),
+		OrderItems: []models.OrderProduct{{ProductID: product.ID, Quantity: 1, UnitPrice: 25}},
+	}
+	// Stored directly, as orders placed before snapshots existed were.
+	require.NoError(t, db.Create(order).Error)
+
+	fetched, err := repo.GetOrderByID(order.ID)
+	assert.NoError(t, err)
+	require.Len(t, fetched.OrderItems, 1)
+	assert.Equal(t, "Teclado-orders-repo", fetched.OrderItems[0].Snapshot.Name)
+}
+
+func TestGetOrderByID_NotFound(t *testing.T) {
+	db := setupInMemoryDB(t)
+	logger := logrus.New()
+	repo := NewOrdersRepository(db, logger)
+
+	_, err := repo.GetOrderByID(99999)
+	assert.Error(t, err)
+}
+
+func TestGetOrderByID_KeepsStoredSnapshot(t *testing.T) {
+	db := setupInMemoryDB(t)
+	logger := logrus.New()
+	repo := NewOrdersRepository(db, logger)
+
+	product := &models.Product{Name: "Monitor-orders-repo", SKU: "MON-1", Price: 100, Stock: 5}
+	require.NoError(t, db.Create(product).Error)
+
+	order := &models.Order{
+		UserID:    13,
+		Total:     100,
+		CreatedAt: time.Now(),
+		OrderItems: []models.OrderProduct{{
+			ProductID: product.ID,
+			Quantity:  1,
+			UnitPrice: 100,
+			Snapshot:  models.ProductSnapshot{Name: "Monitor-orders-repo", SKU: "MON-1", TaxCategory: "standard", Attributes: models.Attributes{"size": "27"}},
+		}},
+	}
+	_, err := repo.CreateOrder(order)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(product).Updates(map[string]interface{}{"name": "Monitor renombrado", "sku": "MON-2"}).Error)
+
+	fetched, err := repo.GetOrderByID(order.ID)
+	assert.NoError(t, err)
+	require.Len(t, fetched.OrderItems, 1)
+	snapshot := fetched.OrderItems[0].Snapshot
+	assert.Equal(t, "Monitor-orders-repo", snapshot.Name)
+	assert.Equal(t, "MON-1", snapshot.SKU)
+	assert.Equal(t, models.Attributes{"size": "27"}, snapshot.Attributes)
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	db := setupInMemoryDB(t)
+	logger := logrus.New()
+	repo := NewOrdersRepository(db, logger)
+
+	order := &models.Order{UserID: 14, Status: models.OrderStatusPlaced, Total: 1, CreatedAt: time.Now()}
+	_, err := repo.CreateOrder(order)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.UpdateOrderStatus(order.ID, models.OrderStatusShipped))
+
+	fetched, err := repo.GetOrderByID(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusShipped, fetched.Status)
+
+	var event models.OutboxEvent
+	require.NoError(t, db.Where("type = ? AND aggregate_id = ?", models.EventOrderStatusChanged, order.ID).First(&event).Error)
+	assert.Contains(t, event.Payload, `"status":"shipped"`)
+}
+
+func TestCreateOrder_TakesStockAndWritesEvents(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewOrdersRepository(db, logrus.New())
+
+	product := &models.Product{Name: "Teclado-outbox", SKU: "KEY-OUT", Price: 20, Stock: 3}
+	require.NoError(t, db.Create(product).Error)
+
+	order := &models.Order{UserID: 15, Status: models.OrderStatusPlaced, Total: 40, OrderItems: []models.OrderProduct{
+		{ProductID: product.ID, Quantity: 2, UnitPrice: 20},
+	}}
+	_, err := repo.CreateOrder(order)
+	require.NoError(t, err)
+
+	var stored models.Product
+	require.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 1, stored.Stock)
+
+	var events []models.OutboxEvent
+	require.NoError(t, db.
+		Where("type = ? AND aggregate_id = ?", models.EventOrderPlaced, order.ID).
+		Or("type = ? AND aggregate_id = ?", models.EventStockChanged, product.ID).
+		Order("id").Find(&events).Error)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.EventOrderPlaced, events[0].Type)
+	assert.Equal(t, order.ID, events[0].AggregateID)
+	assert.Equal(t, models.EventStockChanged, events[1].Type)
+	assert.JSONEq(t, fmt.Sprintf(`{"product_id":%d,"previous_stock":3,"stock":1,"reason":"order_placed","order_id":%d}`, product.ID, order.ID), events[1].Payload)
+}
+
+func TestCreateOrder_InsufficientStockRollsBack(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewOrdersRepository(db, logrus.New())
+
+	product := &models.Product{Name: "Raton-outbox", SKU: "MOU-OUT", Price: 10, Stock: 1}
+	require.NoError(t, db.Create(product).Error)
+
+	order := &models.Order{UserID: 16, Status: models.OrderStatusPlaced, OrderItems: []models.OrderProduct{
+		{ProductID: product.ID, Quantity: 2, UnitPrice: 10},
+	}}
+	_, err := repo.CreateOrder(order)
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Order{}).Where("user_id = ?", 16).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("type = ? AND aggregate_id = ?", models.EventStockChanged, product.ID).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestCancelOrder_RestocksOnce(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewOrdersRepository(db, logrus.New())
+
+	product := &models.Product{Name: "Cable-outbox", SKU: "CAB-OUT", Price: 5, Stock: 4}
+	require.NoError(t, db.Create(product).Error)
+	order := &models.Order{UserID: 17, Status: models.OrderStatusPlaced, OrderItems: []models.OrderProduct{
+		{ProductID: product.ID, Quantity: 3, UnitPrice: 5},
+	}}
+	_, err := repo.CreateOrder(order)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.CancelOrder(order))
+	assert.Equal(t, models.OrderStatusCancelled, order.Status)
+	assert.ErrorIs(t, repo.CancelOrder(order), ErrOrderNotCancellable)
+
+	var stored models.Product
+	require.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 4, stored.Stock)
+
+	var count int64
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("type = ? AND aggregate_id = ?", models.EventOrderCancelled, order.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
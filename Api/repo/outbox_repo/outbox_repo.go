@@ -0,0 +1,105 @@
+package outbox_repo
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository reads and settles events written by the other
+// repositories. Events are appended inside their own transactions, never
+// through this repository.
+type OutboxRepository interface {
+	GetPendingEvents(now time.Time, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(id uint, publishedAt time.Time) error
+	UpdateEvent(event *models.OutboxEvent) error
+	GetDeliveredSinks(eventID uint) ([]string, error)
+	MarkDelivered(eventID uint, sink string, deliveredAt time.Time) error
+	GetEventsAfter(afterID uint, types []string, limit int) ([]models.OutboxEvent, error)
+}
+
+type outboxRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewOutboxRepository(db *gorm.DB, logger *logrus.Logger) OutboxRepository {
+	return &outboxRepository{db: db, logger: logger}
+}
+
+// GetPendingEvents returns unpublished events that are due, oldest first.
+// Dead events are left out.
+func (r *outboxRepository) GetPendingEvents(now time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.
+		Where("published_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		r.logger.Errorln("Layer: outbox_repo, Method: GetPendingEvents, Error:", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkPublished(id uint, publishedAt time.Time) error {
+	err := r.db.Model(&models.OutboxEvent{}).Where("id = ?", id).Update("published_at", publishedAt).Error
+	if err != nil {
+		r.logger.Errorln("Layer: outbox_repo, Method: MarkPublished, Error:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *outboxRepository) UpdateEvent(event *models.OutboxEvent) error {
+	err := r.db.Save(event).Error
+	if err != nil {
+		r.logger.Errorln("Layer: outbox_repo, Method: UpdateEvent, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// GetDeliveredSinks returns the names of the sinks that already handled the
+// event.
+func (r *outboxRepository) GetDeliveredSinks(eventID uint) ([]string, error) {
+	var sinks []string
+	err := r.db.Model(&models.OutboxDelivery{}).Where("event_id = ?", eventID).Pluck("sink", &sinks).Error
+	if err != nil {
+		r.logger.Errorln("Layer: outbox_repo, Method: GetDeliveredSinks, Error:", err)
+		return nil, err
+	}
+	return sinks, nil
+}
+
+// MarkDelivered records that sink handled the event. Recording it twice is
+// not an error.
+func (r *outboxRepository) MarkDelivered(eventID uint, sink string, deliveredAt time.Time) error {
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.OutboxDelivery{EventID: eventID, Sink: sink, DeliveredAt: deliveredAt}).Error
+	if err != nil {
+		r.logger.Errorln("Layer: outbox_repo, Method: MarkDelivered, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// GetEventsAfter returns events of the given types written after afterID,
+// published or not, oldest first.
+func (r *outboxRepository) GetEventsAfter(afterID uint, types []string, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.
+		Where("id > ? AND type IN ?", afterID, types).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		r.logger.Errorln("Layer: outbox_repo, Method: GetEventsAfter, Error:", err)
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,90 @@
+package outbox_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.OutboxEvent{}, &models.OutboxDelivery{}))
+	return db
+}
+
+func TestGetPendingEvents(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewOutboxRepository(db, logrus.New())
+	now := time.Now()
+	published := now.Add(-time.Minute)
+
+	events := []models.OutboxEvent{
+		{Type: models.EventOrderPlaced, NextAttemptAt: now.Add(-time.Second)},
+		{Type: models.EventOrderPlaced, NextAttemptAt: now.Add(-time.Second), PublishedAt: &published},
+		{Type: models.EventStockChanged, NextAttemptAt: now.Add(time.Hour)},
+		{Type: models.EventUserRegistered, NextAttemptAt: now.Add(-time.Second)},
+		{Type: models.EventOrderPlaced, NextAttemptAt: now.Add(-time.Second), DeadAt: &published},
+	}
+	require.NoError(t, db.Create(&events).Error)
+
+	pending, err := repo.GetPendingEvents(now, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, events[0].ID, pending[0].ID)
+	assert.Equal(t, events[3].ID, pending[1].ID)
+
+	require.NoError(t, repo.MarkPublished(events[0].ID, now))
+	pending[1].Attempts = 1
+	pending[1].NextAttemptAt = now.Add(time.Minute)
+	require.NoError(t, repo.UpdateEvent(&pending[1]))
+
+	pending, err = repo.GetPendingEvents(now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestGetEventsAfter(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewOutboxRepository(db, logrus.New())
+
+	events := []models.OutboxEvent{
+		{Type: models.EventOrderPlaced},
+		{Type: models.EventUserRegistered},
+		{Type: models.EventStockChanged},
+		{Type: models.EventOrderStatusChanged},
+	}
+	require.NoError(t, db.Create(&events).Error)
+
+	after, err := repo.GetEventsAfter(events[0].ID, []string{models.EventOrderPlaced, models.EventStockChanged, models.EventOrderStatusChanged}, 10)
+	require.NoError(t, err)
+	require.Len(t, after, 2)
+	assert.Equal(t, events[2].ID, after[0].ID)
+	assert.Equal(t, events[3].ID, after[1].ID)
+}
+
+func TestMarkDelivered(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewOutboxRepository(db, logrus.New())
+	now := time.Now()
+
+	require.NoError(t, repo.MarkDelivered(1, "webhooks", now))
+	require.NoError(t, repo.MarkDelivered(1, "webhooks", now))
+	require.NoError(t, repo.MarkDelivered(1, "log", now))
+	require.NoError(t, repo.MarkDelivered(2, "log", now))
+
+	sinks, err := repo.GetDeliveredSinks(1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"webhooks", "log"}, sinks)
+
+	sinks, err = repo.GetDeliveredSinks(3)
+	require.NoError(t, err)
+	assert.Empty(t, sinks)
+}
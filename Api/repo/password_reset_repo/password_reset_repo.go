@@ -0,0 +1,83 @@
+package password_reset_repo
+
+import (
+	"errors"
+	"time"
+
+	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrTokenAlreadyUsed is returned when a reset token was consumed between
+// being read and being redeemed.
+var ErrTokenAlreadyUsed = apperr.Invalid("invalid_reset_token", "password reset token already used")
+
+type PasswordResetRepository interface {
+	CreateToken(token *models.PasswordResetToken) error
+	GetTokenByHash(hash string) (*models.PasswordResetToken, error)
+	ResetPassword(token *models.PasswordResetToken, passwordHash string, at time.Time) error
+}
+
+type passwordResetRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewPasswordResetRepository(db *gorm.DB, logger *logrus.Logger) PasswordResetRepository {
+	return &passwordResetRepository{db: db, logger: logger}
+}
+
+func (r *passwordResetRepository) CreateToken(token *models.PasswordResetToken) error {
+	err := r.db.Create(token).Error
+	if err != nil {
+		r.logger.Errorln("Layer: password_reset_repo, Method: CreateToken, Error:", err)
+	}
+	return err
+}
+
+func (r *passwordResetRepository) GetTokenByHash(hash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ResetPassword redeems the token, stores the new password and revokes every
+// session of the user. All other outstanding reset tokens of the user are
+// consumed too, so an older email cannot be used afterwards.
+func (r *passwordResetRepository) ResetPassword(token *models.PasswordResetToken, passwordHash string, at time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTokenAlreadyUsed
+		}
+
+		err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", at).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"password":            passwordHash,
+			"token":               "",
+			"refresh_token":       "",
+			"sessions_revoked_at": at,
+		}).Error
+	})
+	if err != nil && !errors.Is(err, ErrTokenAlreadyUsed) {
+		r.logger.Errorln("Layer: password_reset_repo, Method: ResetPassword, Error:", err)
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package password_reset_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.User{}, &models.PasswordResetToken{})
+	require.NoError(t, err)
+	return db
+}
+
+func TestResetPassword_SingleUseAndRevokesSessions(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewPasswordResetRepository(db, logrus.New())
+
+	user := &models.User{Username: "ana", Email: "ana@example.com", Password: "old", Token: "t", RefreshToken: "r"}
+	require.NoError(t, db.Create(user).Error)
+
+	expires := time.Now().Add(time.Hour)
+	first := &models.PasswordResetToken{UserID: user.ID, TokenHash: "hash-1", ExpiresAt: expires}
+	second := &models.PasswordResetToken{UserID: user.ID, TokenHash: "hash-2", ExpiresAt: expires}
+	require.NoError(t, repo.CreateToken(first))
+	require.NoError(t, repo.CreateToken(second))
+
+	stored, err := repo.GetTokenByHash("hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, stored.ID)
+
+	at := time.Now()
+	require.NoError(t, repo.ResetPassword(stored, "new-hash", at))
+	assert.ErrorIs(t, repo.ResetPassword(stored, "other-hash", at), ErrTokenAlreadyUsed)
+
+	other, err := repo.GetTokenByHash("hash-2")
+	require.NoError(t, err)
+	assert.NotNil(t, other.UsedAt)
+
+	var updated models.User
+	require.NoError(t, db.First(&updated, user.ID).Error)
+	assert.Equal(t, "new-hash", updated.Password)
+	assert.Empty(t, updated.Token)
+	assert.Empty(t, updated.RefreshToken)
+	require.NotNil(t, updated.SessionsRevokedAt)
+	assert.WithinDuration(t, at, *updated.SessionsRevokedAt, time.Second)
+}
+
+func TestGetTokenByHash_NotFound(t *testing.T) {
+	repo := NewPasswordResetRepository(setupInMemoryDB(t), logrus.New())
+
+	_, err := repo.GetTokenByHash("missing")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
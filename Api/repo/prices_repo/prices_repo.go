@@ -0,0 +1,158 @@
+package prices_repo
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrChangeClaimed means another writer moved the scheduled change out of
+// the expected status first. The whole transition is rolled back.
+var ErrChangeClaimed = errors.New("scheduled price change was already processed")
+
+// ChangeUpdate saves a scheduled change provided it is still in status From,
+// so the same change cannot be applied twice.
+type ChangeUpdate struct {
+	Change *models.ScheduledPriceChange
+	From   string
+}
+
+type PricesRepository interface {
+	CreateScheduledChange(change *models.ScheduledPriceChange) (*models.ScheduledPriceChange, error)
+	GetScheduledChangeByID(id uint) (*models.ScheduledPriceChange, error)
+	GetScheduledChanges(productID uint) ([]models.ScheduledPriceChange, error)
+	GetDueChanges(now time.Time) ([]models.ScheduledPriceChange, error)
+	GetActiveSale(productID uint) (*models.ScheduledPriceChange, error)
+	GetPriceHistory(productID uint) ([]models.PriceHistory, error)
+	ApplyTransition(product *models.Product, entry *models.PriceHistory, changes ...ChangeUpdate) error
+}
+
+type pricesRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewPricesRepository(db *gorm.DB, logger *logrus.Logger) PricesRepository {
+	return &pricesRepository{db: db, logger: logger}
+}
+
+func (r *pricesRepository) CreateScheduledChange(change *models.ScheduledPriceChange) (*models.ScheduledPriceChange, error) {
+	err := r.db.Create(change).Error
+	if err != nil {
+		r.logger.Errorln("Layer: prices_repo, Method: CreateScheduledChange, Error:", err)
+		return nil, err
+	}
+	return change, nil
+}
+
+func (r *pricesRepository) GetScheduledChangeByID(id uint) (*models.ScheduledPriceChange, error) {
+	var change models.ScheduledPriceChange
+	err := r.db.First(&change, id).Error
+	if err != nil {
+		r.logger.Errorln("Layer: prices_repo, Method: GetScheduledChangeByID, Error:", err)
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *pricesRepository) GetScheduledChanges(productID uint) ([]models.ScheduledPriceChange, error) {
+	var changes []models.ScheduledPriceChange
+	err := r.db.Where("product_id = ?", productID).Order("starts_at asc").Find(&changes).Error
+	if err != nil {
+		r.logger.Errorln("Layer: prices_repo, Method: GetScheduledChanges, Error:", err)
+		return nil, err
+	}
+	return changes, nil
+}
+
+// GetDueChanges returns pending changes whose start time has passed and
+// active sales whose end time has passed, oldest first.
+func (r *pricesRepository) GetDueChanges(now time.Time) ([]models.ScheduledPriceChange, error) {
+	var changes []models.ScheduledPriceChange
+	err := r.db.
+		Where("status = ? AND starts_at <= ?", models.PriceChangeStatusPending, now).
+		Or("status = ? AND ends_at IS NOT NULL AND ends_at <= ?", models.PriceChangeStatusActive, now).
+		Order("starts_at asc").Order("id asc").
+		Find(&changes).Error
+	if err != nil {
+		r.logger.Errorln("Layer: prices_repo, Method: GetDueChanges, Error:", err)
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *pricesRepository) GetActiveSale(productID uint) (*models.ScheduledPriceChange, error) {
+	var change models.ScheduledPriceChange
+	err := r.db.
+		Where("product_id = ? AND kind = ? AND status = ?", productID, models.PriceChangeKindSale, models.PriceChangeStatusActive).
+		First(&change).Error
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *pricesRepository) GetPriceHistory(productID uint) ([]models.PriceHistory, error) {
+	var history []models.PriceHistory
+	err := r.db.Where("product_id = ?", productID).Order("effective_at asc").Order("id asc").Find(&history).Error
+	if err != nil {
+		r.logger.Errorln("Layer: prices_repo, Method: GetPriceHistory, Error:", err)
+		return nil, err
+	}
+	return history, nil
+}
+
+// ApplyTransition saves the product price, the history entry and the
+// scheduled changes involved in a single transaction, together with a
+// ProductPriceChanged event when the product price moves. It fails with
+// ErrChangeClaimed when a change is no longer in the status it was read in.
+func (r *pricesRepository) ApplyTransition(product *models.Product, entry *models.PriceHistory, changes ...ChangeUpdate) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, update := range changes {
+			res := tx.Model(&models.ScheduledPriceChange{}).
+				Where("id = ? AND status = ?", update.Change.ID, update.From).
+				Updates(map[string]interface{}{
+					"status":        update.Change.Status,
+					"regular_price": update.Change.RegularPrice,
+					"ends_at":       update.Change.EndsAt,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return ErrChangeClaimed
+			}
+		}
+		if product != nil {
+			if err := tx.Model(product).Update("price", product.Price).Error; err != nil {
+				return err
+			}
+		}
+		if entry != nil {
+			if err := tx.Create(entry).Error; err != nil {
+				return err
+			}
+		}
+		if product != nil && entry != nil {
+			event, err := models.NewOutboxEvent(models.EventProductPriceChanged, models.AggregateProduct, product.ID, entry)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&event).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrChangeClaimed) {
+		return err
+	}
+	if err != nil {
+		r.logger.Errorln("Layer: prices_repo, Method: ApplyTransition, Error:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package prices_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.Product{}, &models.PriceHistory{}, &models.ScheduledPriceChange{}, &models.OutboxEvent{})
+	require.NoError(t, err)
+	return db
+}
+
+func TestGetDueChanges(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewPricesRepository(db, logrus.New())
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	changes := []models.ScheduledPriceChange{
+		{ProductID: 1, Kind: models.PriceChangeKindRegular, Price: 1, StartsAt: past, Status: models.PriceChangeStatusPending},
+		{ProductID: 1, Kind: models.PriceChangeKindRegular, Price: 2, StartsAt: future, Status: models.PriceChangeStatusPending},
+		{ProductID: 2, Kind: models.PriceChangeKindSale, Price: 3, StartsAt: past, EndsAt: &past, Status: models.PriceChangeStatusActive},
+		{ProductID: 3, Kind: models.PriceChangeKindSale, Price: 4, StartsAt: past, EndsAt: &future, Status: models.PriceChangeStatusActive},
+		{ProductID: 4, Kind: models.PriceChangeKindRegular, Price: 5, StartsAt: past, Status: models.PriceChangeStatusCancelled},
+	}
+	for i := range changes {
+		_, err := repo.CreateScheduledChange(&changes[i])
+		require.NoError(t, err)
+	}
+
+	due, err := repo.GetDueChanges(now)
+	assert.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, 1.0, due[0].Price)
+	assert.Equal(t, 3.0, due[1].Price)
+}
+
+func TestApplyTransition(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewPricesRepository(db, logrus.New())
+
+	product := &models.Product{Name: "P", Price: 10}
+	require.NoError(t, db.Create(product).Error)
+	change := &models.ScheduledPriceChange{ProductID: product.ID, Kind: models.PriceChangeKindRegular, Price: 8, StartsAt: time.Now(), Status: models.PriceChangeStatusPending}
+	_, err := repo.CreateScheduledChange(change)
+	require.NoError(t, err)
+
+	product.Price = 8
+	change.Status = models.PriceChangeStatusApplied
+	entry := &models.PriceHistory{ProductID: product.ID, Price: 8, PreviousPrice: 10, Reason: models.PriceReasonChanged, ChangeID: &change.ID, EffectiveAt: time.Now()}
+	err = repo.ApplyTransition(product, entry, ChangeUpdate{Change: change, From: models.PriceChangeStatusPending})
+	assert.NoError(t, err)
+
+	var stored models.Product
+	require.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 8.0, stored.Price)
+
+	history, err := repo.GetPriceHistory(product.ID)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	fetched, err := repo.GetScheduledChangeByID(change.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.PriceChangeStatusApplied, fetched.Status)
+
+	var events []models.OutboxEvent
+	require.NoError(t, db.Find(&events).Error)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.EventProductPriceChanged, events[0].Type)
+	assert.Equal(t, product.ID, events[0].AggregateID)
+	assert.Contains(t, events[0].Payload, `"previous_price":10`)
+}
+
+func TestApplyTransition_ClaimedChangeRollsBack(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewPricesRepository(db, logrus.New())
+
+	product := &models.Product{Name: "P", Price: 10}
+	require.NoError(t, db.Create(product).Error)
+	change := &models.ScheduledPriceChange{ProductID: product.ID, Kind: models.PriceChangeKindRegular, Price: 8, StartsAt: time.Now(), Status: models.PriceChangeStatusApplied}
+	_, err := repo.CreateScheduledChange(change)
+	require.NoError(t, err)
+
+	product.Price = 8
+	entry := &models.PriceHistory{ProductID: product.ID, Price: 8, PreviousPrice: 10, Reason: models.PriceReasonChanged, ChangeID: &change.ID, EffectiveAt: time.Now()}
+	err = repo.ApplyTransition(product, entry, ChangeUpdate{Change: change, From: models.PriceChangeStatusPending})
+	assert.ErrorIs(t, err, ErrChangeClaimed)
+
+	var stored models.Product
+	require.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 10.0, stored.Price)
+	history, err := repo.GetPriceHistory(product.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+	var events int64
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Count(&events).Error)
+	assert.Zero(t, events)
+}
+
+func TestGetActiveSale_None(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewPricesRepository(db, logrus.New())
+
+	_, err := repo.GetActiveSale(1)
+	assert.Error(t, err)
+}
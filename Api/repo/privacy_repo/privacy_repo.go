@@ -0,0 +1,126 @@
+package privacy_repo
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PrivacyRepository interface {
+	GetUserData(userID uint) (*models.UserDataExport, error)
+	UserExists(userID uint) (bool, error)
+	HasPendingErasure(userID uint) (bool, error)
+	CreateErasureRequest(request *models.ErasureRequest) error
+	GetErasureRequests() ([]models.ErasureRequest, error)
+	CompleteErasure(requestID uint, at time.Time) error
+}
+
+type privacyRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewPrivacyRepository(db *gorm.DB, logger *logrus.Logger) PrivacyRepository {
+	return &privacyRepository{db: db, logger: logger}
+}
+
+// GetUserData collects the records that belong to the user, oldest first.
+// The profile is left for the caller to fill in.
+func (r *privacyRepository) GetUserData(userID uint) (*models.UserDataExport, error) {
+	data := &models.UserDataExport{
+		Addresses: []models.Address{},
+		Orders:    []models.Order{},
+		Sessions:  []models.Session{},
+		Reviews:   []models.Review{},
+	}
+	queries := []struct {
+		db   *gorm.DB
+		dest interface{}
+	}{
+		{r.db.Order("id"), &data.Addresses},
+		{r.db.Preload("OrderItems").Order("created_at, id"), &data.Orders},
+		{r.db.Order("created_at, id"), &data.Sessions},
+		{r.db.Order("created_at, id"), &data.Reviews},
+	}
+	for _, q := range queries {
+		if err := q.db.Where("user_id = ?", userID).Find(q.dest).Error; err != nil {
+			r.logger.Errorln("Layer: privacy_repo, Method: GetUserData, Error:", err)
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// UserExists also finds users that deleted their account, since their
+// remaining data can still be erased.
+func (r *privacyRepository) UserExists(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.User{}).Where("id = ?", userID).Count(&count).Error
+	if err != nil {
+		r.logger.Errorln("Layer: privacy_repo, Method: UserExists, Error:", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *privacyRepository) HasPendingErasure(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ErasureRequest{}).
+		Where("user_id = ? AND status = ?", userID, models.ErasureStatusPending).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Errorln("Layer: privacy_repo, Method: HasPendingErasure, Error:", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateErasureRequest stores the request together with the
+// UserErasureRequested event that carries it out.
+func (r *privacyRepository) CreateErasureRequest(request *models.ErasureRequest) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(request).Error; err != nil {
+			return err
+		}
+		event, err := models.NewOutboxEvent(models.EventUserErasureRequested, models.AggregateUser, request.UserID, models.UserErasure{
+			RequestID: request.ID,
+			UserID:    request.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: privacy_repo, Method: CreateErasureRequest, Error:", err)
+	}
+	return err
+}
+
+func (r *privacyRepository) GetErasureRequests() ([]models.ErasureRequest, error) {
+	var requests []models.ErasureRequest
+	err := r.db.Order("id DESC").Find(&requests).Error
+	if err != nil {
+		r.logger.Errorln("Layer: privacy_repo, Method: GetErasureRequests, Error:", err)
+		return nil, err
+	}
+	return requests, nil
+}
+
+// CompleteErasure marks a pending request as completed. Completing it again
+// leaves the first completion time in place.
+func (r *privacyRepository) CompleteErasure(requestID uint, at time.Time) error {
+	err := r.db.Model(&models.ErasureRequest{}).
+		Where("id = ? AND status = ?", requestID, models.ErasureStatusPending).
+		Updates(map[string]interface{}{
+			"status":       models.ErasureStatusCompleted,
+			"completed_at": at,
+		}).Error
+	if err != nil {
+		r.logger.Errorln("Layer: privacy_repo, Method: CompleteErasure, Error:", err)
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package privacy_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.User{}, &models.Address{}, &models.Order{}, &models.OrderProduct{},
+		&models.Session{}, &models.Review{}, &models.ErasureRequest{}, &models.OutboxEvent{})
+	require.NoError(t, err)
+	return db
+}
+
+func TestGetUserData_OnlyReturnsTheUsersRecords(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewPrivacyRepository(db, logrus.New())
+
+	require.NoError(t, db.Create(&models.Order{UserID: 1, OrderItems: []models.OrderProduct{{ProductID: 10, Quantity: 2}}}).Error)
+	require.NoError(t, db.Create(&models.Order{UserID: 2}).Error)
+	require.NoError(t, db.Create(&models.Session{UserID: 1, TokenID: "sid", IP: "203.0.113.7"}).Error)
+	require.NoError(t, db.Create(&models.Review{UserID: 1, ProductID: 10, Rating: 4}).Error)
+
+	data, err := repo.GetUserData(1)
+	require.NoError(t, err)
+	require.Len(t, data.Orders, 1)
+	assert.Len(t, data.Orders[0].OrderItems, 1)
+	assert.Len(t, data.Sessions, 1)
+	assert.Len(t, data.Reviews, 1)
+	assert.NotNil(t, data.Addresses, "empty sections export as [] rather than null")
+}
+
+func TestErasureRequestLifecycle(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewPrivacyRepository(db, logrus.New())
+
+	user := &models.User{Username: "u1", Email: "e1@e.com"}
+	require.NoError(t, db.Create(user).Error)
+	require.NoError(t, db.Delete(user).Error)
+	exists, err := repo.UserExists(user.ID)
+	require.NoError(t, err)
+	assert.True(t, exists, "deleted accounts can still be erased")
+
+	request := &models.ErasureRequest{UserID: user.ID, RequestedBy: "admin@example.com", Reason: "art. 17", Status: models.ErasureStatusPending}
+	require.NoError(t, repo.CreateErasureRequest(request))
+	var event models.OutboxEvent
+	require.NoError(t, db.Where("type = ?", models.EventUserErasureRequested).First(&event).Error)
+	assert.Equal(t, user.ID, event.AggregateID)
+
+	pending, err := repo.HasPendingErasure(user.ID)
+	require.NoError(t, err)
+	assert.True(t, pending)
+
+	first := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CompleteErasure(request.ID, first))
+	require.NoError(t, repo.CompleteErasure(request.ID, first.Add(time.Hour)))
+
+	requests, err := repo.GetErasureRequests()
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, models.ErasureStatusCompleted, requests[0].Status)
+	assert.True(t, requests[0].CompletedAt.Equal(first))
+
+	pending, err = repo.HasPendingErasure(user.ID)
+	require.NoError(t, err)
+	assert.False(t, pending)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type productsRepository struct {
@@ -20,16 +21,28 @@ func NewProductsRepository(db *gorm.DB, logger *logrus.Logger) ProductsRepositor
 }
 
 type ProductsRepository interface {
-	GetAllProducts() ([]models.Product, error)
+	GetAllProducts(sort models.ProductSort) ([]models.Product, error)
 	CreateProduct(product *models.Product, createdBy string) (*models.Product, error)
 	CreateProducts(products []models.Product) ([]models.Product, error) // <-- Agrega esto
 	GetProductByID(id uint) (*models.Product, error)
 	UpdateProduct(product *models.Product) error
+	RestockProduct(id uint, quantity int) (*models.Product, error)
+	SaveTranslation(translation *models.ProductTranslation) error
 }
 
-func (r *productsRepository) GetAllProducts() ([]models.Product, error) {
+// GetAllProducts expects sort to be validated by the caller. Products with
+// the same rating are ordered by how many reviews back it.
+func (r *productsRepository) GetAllProducts(sort models.ProductSort) ([]models.Product, error) {
+	query := r.db
+	switch sort.SortBy {
+	case "":
+	case "rating":
+		query = query.Order("rating_average " + sort.SortDir).Order("rating_count desc")
+	default:
+		query = query.Order(sort.SortBy + " " + sort.SortDir)
+	}
 	var products []models.Product
-	err := r.db.Find(&products).Error
+	err := query.Preload("Translations").Order("id").Find(&products).Error
 	if err != nil {
 		r.logger.Errorln("Layer: products_repo, Method: GetAllProducts, Error:", err)
 		return nil, err
@@ -38,7 +51,34 @@ func (r *productsRepository) GetAllProducts() ([]models.Product, error) {
 }
 
 func (r *productsRepository) CreateProducts(products []models.Product) ([]models.Product, error) {
-	err := r.db.Create(&products).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&products).Error; err != nil {
+			return err
+		}
+		history := make([]models.PriceHistory, 0, len(products))
+		events := make([]models.OutboxEvent, 0, len(products))
+		for _, p := range products {
+			history = append(history, models.PriceHistory{
+				ProductID:   p.ID,
+				Price:       p.Price,
+				Reason:      models.PriceReasonCreated,
+				ChangedBy:   p.CreatedBy,
+				EffectiveAt: p.CreatedAt,
+			})
+			event, err := models.NewOutboxEvent(models.EventProductCreated, models.AggregateProduct, p.ID, p)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		if len(history) == 0 {
+			return nil
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		return tx.Create(&events).Error
+	})
 	if err != nil {
 		r.logger.Errorln("Layer: products_repo, Method: CreateProducts, Error:", err)
 		return nil, err
@@ -47,7 +87,7 @@ func (r *productsRepository) CreateProducts(products []models.Product) ([]models
 }
 func (r *productsRepository) GetProductByID(id uint) (*models.Product, error) {
 	var product models.Product
-	err := r.db.First(&product, id).Error
+	err := r.db.Preload("Translations").First(&product, id).Error
 	if err != nil {
 		r.logger.Errorln("Layer: products_repo, Method: GetProductByID, Error:", err)
 		return nil, err
@@ -66,10 +106,77 @@ func (r *productsRepository) UpdateProduct(product *models.Product) error {
 
 func (r *productsRepository) CreateProduct(product *models.Product, createdBy string) (*models.Product, error) {
 	product.CreatedBy = createdBy
-	err := r.db.Create(product).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		err := tx.Create(&models.PriceHistory{
+			ProductID:   product.ID,
+			Price:       product.Price,
+			Reason:      models.PriceReasonCreated,
+			ChangedBy:   createdBy,
+			EffectiveAt: product.CreatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		event, err := models.NewOutboxEvent(models.EventProductCreated, models.AggregateProduct, product.ID, product)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&event).Error
+	})
 	if err != nil {
 		r.logger.Errorln("Layer: products_repo, Method: CreateProduct, Error:", err)
 		return nil, err
 	}
 	return product, nil
 }
+
+// RestockProduct adds quantity units to the product stock together with the
+// matching StockChanged event.
+func (r *productsRepository) RestockProduct(id uint, quantity int) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Product{}).Where("id = ?", id).Update("stock", gorm.Expr("stock + ?", quantity))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.First(&product, id).Error; err != nil {
+			return err
+		}
+
+		event, err := models.NewOutboxEvent(models.EventStockChanged, models.AggregateProduct, id, models.StockChange{
+			ProductID:     id,
+			PreviousStock: product.Stock - quantity,
+			Stock:         product.Stock,
+			Reason:        models.StockReasonRestock,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: products_repo, Method: RestockProduct, Error:", err)
+		return nil, err
+	}
+	return &product, nil
+}
+
+// SaveTranslation creates the translation of the product into its language
+// or replaces the existing one.
+func (r *productsRepository) SaveTranslation(translation *models.ProductTranslation) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description"}),
+	}).Create(translation).Error
+	if err != nil {
+		r.logger.Errorln("Layer: products_repo, Method: SaveTranslation, Error:", err)
+		return err
+	}
+	return nil
+}
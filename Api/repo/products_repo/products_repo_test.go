@@ -1,6 +1,7 @@
 package products_repo
 
 import (
+	"fmt"
 	"testing"
 
 	"pruebaVertice/Api/models"
@@ -15,7 +16,7 @@ import (
 func setupInMemoryDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
 	require.NoError(t, err)
-	err = db.AutoMigrate(&models.Product{})
+	err = db.AutoMigrate(&models.Product{}, &models.ProductTranslation{}, &models.PriceHistory{}, &models.OutboxEvent{})
 	require.NoError(t, err)
 	return db
 }
@@ -34,6 +35,12 @@ func TestCreateProduct_Success(t *testing.T) {
 	assert.Equal(t, 9.99, created.Price)
 	assert.Equal(t, 5, created.Stock)
 	assert.Equal(t, "user1", created.CreatedBy)
+
+	var history []models.PriceHistory
+	require.NoError(t, db.Where("product_id = ?", created.ID).Find(&history).Error)
+	require.Len(t, history, 1)
+	assert.Equal(t, models.PriceReasonCreated, history[0].Reason)
+	assert.Equal(t, 9.99, history[0].Price)
 }
 
 func TestCreateProducts_Success(t *testing.T) {
@@ -62,12 +69,32 @@ func TestGetAllProducts_Success(t *testing.T) {
 	// seed
 	repo.CreateProducts([]models.Product{{Name: "A", Description: "D", Price: 3.3, Stock: 3}})
 	// action
-	all, err := repo.GetAllProducts()
+	all, err := repo.GetAllProducts(models.ProductSort{})
 	assert.NoError(t, err)
 	assert.Len(t, all, 1)
 	assert.Equal(t, "A", all[0].Name)
 }
 
+func TestGetAllProducts_SortByRating(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewProductsRepository(db, logrus.New())
+
+	require.NoError(t, db.Create(&[]models.Product{
+		{Name: "Sin reseñas"},
+		{Name: "Buena", RatingAverage: 4.5, RatingCount: 2},
+		{Name: "Excelente", RatingAverage: 5, RatingCount: 1},
+		{Name: "Buena y popular", RatingAverage: 4.5, RatingCount: 10},
+	}).Error)
+
+	all, err := repo.GetAllProducts(models.ProductSort{SortBy: "rating", SortDir: "desc"})
+	require.NoError(t, err)
+	names := make([]string, len(all))
+	for i, p := range all {
+		names[i] = p.Name
+	}
+	assert.Equal(t, []string{"Excelente", "Buena y popular", "Buena", "Sin reseñas"}, names)
+}
+
 func TestGetProductByID_Success(t *testing.T) {
 	db := setupInMemoryDB(t)
 	logger := logrus.New()
@@ -101,3 +128,77 @@ func TestUpdateProduct_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 6.6, fetched.Price)
 }
+
+func TestCreateProducts_RecordsInitialPrice(t *testing.T) {
+	db := setupInMemoryDB(t)
+	logger := logrus.New()
+	repo := NewProductsRepository(db, logger)
+
+	created, err := repo.CreateProducts([]models.Product{{Name: "H", Description: "D", Price: 7.5, Stock: 1, CreatedBy: "user4"}})
+	require.NoError(t, err)
+
+	var history []models.PriceHistory
+	require.NoError(t, db.Where("product_id = ?", created[0].ID).Find(&history).Error)
+	assert.Len(t, history, 1)
+	assert.Equal(t, 7.5, history[0].Price)
+	assert.Equal(t, "created", history[0].Reason)
+	assert.Equal(t, "user4", history[0].ChangedBy)
+
+	var events []models.OutboxEvent
+	require.NoError(t, db.Where("type = ? AND aggregate_id = ?", models.EventProductCreated, created[0].ID).Find(&events).Error)
+	assert.Len(t, events, 1)
+}
+
+func TestRestockProduct(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewProductsRepository(db, logrus.New())
+
+	created, err := repo.CreateProducts([]models.Product{{Name: "Agotado", Price: 3, Stock: 0}})
+	require.NoError(t, err)
+
+	product, err := repo.RestockProduct(created[0].ID, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, product.Stock)
+
+	var event models.OutboxEvent
+	require.NoError(t, db.Where("type = ? AND aggregate_id = ?", models.EventStockChanged, product.ID).First(&event).Error)
+	assert.JSONEq(t, fmt.Sprintf(`{"product_id":%d,"previous_stock":0,"stock":5,"reason":"restock"}`, product.ID), event.Payload)
+
+	_, err = repo.RestockProduct(9999, 1)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestSaveTranslation(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewProductsRepository(db, logrus.New())
+
+	created, err := repo.CreateProducts([]models.Product{{
+		Name:         "Chair",
+		Description:  "Wooden chair",
+		Price:        20,
+		Translations: []models.ProductTranslation{{Language: "es", Name: "Silla"}},
+	}})
+	require.NoError(t, err)
+	id := created[0].ID
+
+	fetched, err := repo.GetProductByID(id)
+	require.NoError(t, err)
+	require.Len(t, fetched.Translations, 1)
+	assert.Equal(t, "Silla", fetched.Translations[0].Name)
+
+	// Saving the same language again replaces the translation.
+	require.NoError(t, repo.SaveTranslation(&models.ProductTranslation{ProductID: id, Language: "es", Name: "Silla de madera", Description: "Silla de roble"}))
+	require.NoError(t, repo.SaveTranslation(&models.ProductTranslation{ProductID: id, Language: "en", Name: "Oak chair"}))
+
+	all, err := repo.GetAllProducts(models.ProductSort{})
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	translations := map[string]models.ProductTranslation{}
+	for _, tr := range all[0].Translations {
+		translations[tr.Language] = tr
+	}
+	assert.Len(t, translations, 2)
+	assert.Equal(t, "Silla de madera", translations["es"].Name)
+	assert.Equal(t, "Silla de roble", translations["es"].Description)
+	assert.Equal(t, "Oak chair", translations["en"].Name)
+}
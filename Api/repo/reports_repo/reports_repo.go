@@ -0,0 +1,146 @@
+package reports_repo
+
+import (
+	"fmt"
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Every report ignores cancelled orders. Ranges are [from, to).
+//
+// Revenue is merchandise only: order total minus shipping, which matches the
+// per-product revenue summed from the order lines. Shipping is reported on
+// its own by SalesByPeriod. Orders placed before shipping costs existed have
+// no subtotal, so the total is used rather than orders.subtotal.
+type ReportsRepository interface {
+	SalesByPeriod(from, to time.Time, interval string) ([]models.SalesPeriod, error)
+	ProductSales(from, to time.Time, limit int) ([]models.ProductSales, error)
+	TopCustomers(from, to time.Time, limit int) ([]models.CustomerSales, error)
+}
+
+const revenueExpr = "SUM(orders.total - orders.shipping_cost)"
+
+type reportsRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewReportsRepository(db *gorm.DB, logger *logrus.Logger) ReportsRepository {
+	return &reportsRepository{db: db, logger: logger}
+}
+
+func (r *reportsRepository) SalesByPeriod(from, to time.Time, interval string) ([]models.SalesPeriod, error) {
+	period, err := periodExpr(r.db.Dialector.Name(), interval, "orders.created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	var periods []models.SalesPeriod
+	err = r.orders(from, to).
+		Select(period + " AS period, " + revenueExpr + " AS revenue, " +
+			"SUM(orders.shipping_cost) AS shipping, COUNT(*) AS orders").
+		Group("period").Order("period").
+		Scan(&periods).Error
+	if err != nil {
+		r.logger.Errorln("Layer: reports_repo, Method: SalesByPeriod, Error:", err)
+		return nil, err
+	}
+
+	var units []struct {
+		Period string
+		Units  int64
+	}
+	err = r.orders(from, to).
+		Joins("JOIN order_products ON order_products.order_id = orders.id").
+		Select(period + " AS period, SUM(order_products.quantity) AS units").
+		Group("period").
+		Scan(&units).Error
+	if err != nil {
+		r.logger.Errorln("Layer: reports_repo, Method: SalesByPeriod, Error:", err)
+		return nil, err
+	}
+	byPeriod := make(map[string]int64, len(units))
+	for _, u := range units {
+		byPeriod[u.Period] = u.Units
+	}
+	for i := range periods {
+		periods[i].Units = byPeriod[periods[i].Period]
+	}
+	return periods, nil
+}
+
+// ProductSales ranks products by units sold. Lines stored before product
+// snapshots existed fall back to the current product name.
+func (r *reportsRepository) ProductSales(from, to time.Time, limit int) ([]models.ProductSales, error) {
+	var rows []models.ProductSales
+	err := r.orders(from, to).
+		Joins("JOIN order_products ON order_products.order_id = orders.id").
+		Joins("LEFT JOIN products ON products.id = order_products.product_id").
+		Select("order_products.product_id AS product_id, " +
+			"COALESCE(NULLIF(MAX(order_products.product_name), ''), MAX(products.name), '') AS name, " +
+			"SUM(order_products.quantity) AS units, " +
+			"SUM(order_products.quantity * order_products.unit_price) AS revenue").
+		Group("order_products.product_id").
+		Order("units DESC").Order("revenue DESC").Order("product_id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Errorln("Layer: reports_repo, Method: ProductSales, Error:", err)
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *reportsRepository) TopCustomers(from, to time.Time, limit int) ([]models.CustomerSales, error) {
+	var rows []models.CustomerSales
+	err := r.orders(from, to).
+		Joins("LEFT JOIN users ON users.id = orders.user_id").
+		Select("orders.user_id AS user_id, COALESCE(MAX(users.email), '') AS email, " +
+			"COUNT(*) AS orders, " + revenueExpr + " AS revenue").
+		Group("orders.user_id").
+		Order("revenue DESC").Order("user_id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Errorln("Layer: reports_repo, Method: TopCustomers, Error:", err)
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *reportsRepository) orders(from, to time.Time) *gorm.DB {
+	return r.db.Table("orders").
+		Where("orders.created_at >= ? AND orders.created_at < ?", from, to).
+		Where("orders.status <> ?", models.OrderStatusCancelled)
+}
+
+// periodExpr returns the SQL expression giving the first day of the bucket
+// containing column as YYYY-MM-DD. Weeks start on Monday.
+func periodExpr(dialect, interval, column string) (string, error) {
+	switch dialect {
+	case "mysql":
+		switch interval {
+		case models.ReportIntervalDay:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", column), nil
+		case models.ReportIntervalWeek:
+			return fmt.Sprintf("DATE_FORMAT(DATE_SUB(%[1]s, INTERVAL WEEKDAY(%[1]s) DAY), '%%Y-%%m-%%d')", column), nil
+		case models.ReportIntervalMonth:
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-01')", column), nil
+		}
+	case "sqlite":
+		switch interval {
+		case models.ReportIntervalDay:
+			return fmt.Sprintf("date(%s)", column), nil
+		case models.ReportIntervalWeek:
+			return fmt.Sprintf("date(%s, 'weekday 0', '-6 days')", column), nil
+		case models.ReportIntervalMonth:
+			return fmt.Sprintf("strftime('%%Y-%%m-01', %s)", column), nil
+		}
+	default:
+		return "", fmt.Errorf("reports are not supported on %s", dialect)
+	}
+	return "", fmt.Errorf("unknown report interval %q", interval)
+}
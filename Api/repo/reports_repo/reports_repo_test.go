@@ -0,0 +1,130 @@
+package reports_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.User{}, &models.Product{}, &models.Order{}, &models.OrderProduct{})
+	require.NoError(t, err)
+	return db
+}
+
+func day(d int) time.Time {
+	return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC)
+}
+
+func seed(t *testing.T, db *gorm.DB) {
+	require.NoError(t, db.Create(&models.User{Username: "ana", Email: "ana@example.com"}).Error)
+	require.NoError(t, db.Create(&models.User{Username: "luis", Email: "luis@example.com"}).Error)
+	require.NoError(t, db.Create(&models.Product{Name: "Teclado", Price: 10}).Error)
+
+	orders := []models.Order{
+		// Monday 3 and Wednesday 5 March share a week.
+		{UserID: 1, Subtotal: 30, Total: 30, Status: models.OrderStatusPlaced, CreatedAt: day(3), OrderItems: []models.OrderProduct{
+			{ProductID: 1, Quantity: 3, UnitPrice: 10},
+		}},
+		{UserID: 2, Subtotal: 50, ShippingCost: 8.5, Total: 58.5, Status: models.OrderStatusShipped, CreatedAt: day(5), OrderItems: []models.OrderProduct{
+			{ProductID: 2, Quantity: 1, UnitPrice: 50, Snapshot: models.ProductSnapshot{Name: "Monitor"}},
+		}},
+		// Placed before shipping costs were stored: no subtotal, no shipping.
+		{UserID: 1, Total: 20, Status: models.OrderStatusDelivered, CreatedAt: day(10), OrderItems: []models.OrderProduct{
+			{ProductID: 1, Quantity: 2, UnitPrice: 10, Snapshot: models.ProductSnapshot{Name: "Teclado"}},
+		}},
+		{UserID: 2, Total: 999, Status: models.OrderStatusCancelled, CreatedAt: day(10), OrderItems: []models.OrderProduct{
+			{ProductID: 2, Quantity: 9, UnitPrice: 111},
+		}},
+		{UserID: 2, Subtotal: 70, ShippingCost: 5, Total: 75, Status: models.OrderStatusPlaced, CreatedAt: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	require.NoError(t, db.Create(&orders).Error)
+}
+
+func TestSalesByPeriod(t *testing.T) {
+	db := setupInMemoryDB(t)
+	seed(t, db)
+	repo := NewReportsRepository(db, logrus.New())
+	from, to := day(1), time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	weeks, err := repo.SalesByPeriod(from, to, models.ReportIntervalWeek)
+	require.NoError(t, err)
+	assert.Equal(t, []models.SalesPeriod{
+		{Period: "2025-03-03", Revenue: 80, Shipping: 8.5, Orders: 2, Units: 4},
+		{Period: "2025-03-10", Revenue: 20, Orders: 1, Units: 2},
+	}, weeks)
+
+	days, err := repo.SalesByPeriod(from, to, models.ReportIntervalDay)
+	require.NoError(t, err)
+	assert.Len(t, days, 3)
+
+	months, err := repo.SalesByPeriod(from, to.AddDate(0, 0, 1), models.ReportIntervalMonth)
+	require.NoError(t, err)
+	assert.Equal(t, []models.SalesPeriod{
+		{Period: "2025-03-01", Revenue: 100, Shipping: 8.5, Orders: 3, Units: 6},
+		{Period: "2025-04-01", Revenue: 70, Shipping: 5, Orders: 1, Units: 0},
+	}, months)
+}
+
+func TestProductSales(t *testing.T) {
+	db := setupInMemoryDB(t)
+	seed(t, db)
+	repo := NewReportsRepository(db, logrus.New())
+
+	rows, err := repo.ProductSales(day(1), day(31), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.ProductSales{
+		{ProductID: 1, Name: "Teclado", Units: 5, Revenue: 50},
+		{ProductID: 2, Name: "Monitor", Units: 1, Revenue: 50},
+	}, rows)
+
+	rows, err = repo.ProductSales(day(1), day(31), 1)
+	require.NoError(t, err)
+	assert.Len(t, rows, 1)
+}
+
+func TestTopCustomers(t *testing.T) {
+	db := setupInMemoryDB(t)
+	seed(t, db)
+	repo := NewReportsRepository(db, logrus.New())
+
+	rows, err := repo.TopCustomers(day(1), day(31), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.CustomerSales{
+		{UserID: 1, Email: "ana@example.com", Orders: 2, Revenue: 50},
+		{UserID: 2, Email: "luis@example.com", Orders: 1, Revenue: 50},
+	}, rows)
+}
+
+func TestReports_RevenueExcludesShipping(t *testing.T) {
+	db := setupInMemoryDB(t)
+	seed(t, db)
+	repo := NewReportsRepository(db, logrus.New())
+
+	periods, err := repo.SalesByPeriod(day(1), day(31), models.ReportIntervalMonth)
+	require.NoError(t, err)
+	products, err := repo.ProductSales(day(1), day(31), 10)
+	require.NoError(t, err)
+	customers, err := repo.TopCustomers(day(1), day(31), 10)
+	require.NoError(t, err)
+
+	var productRevenue, customerRevenue float64
+	for _, p := range products {
+		productRevenue += p.Revenue
+	}
+	for _, c := range customers {
+		customerRevenue += c.Revenue
+	}
+	require.Len(t, periods, 1)
+	assert.Equal(t, productRevenue, periods[0].Revenue)
+	assert.Equal(t, productRevenue, customerRevenue)
+}
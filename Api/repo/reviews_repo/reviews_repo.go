@@ -0,0 +1,135 @@
+package reviews_repo
+
+import (
+	"math"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ReviewsRepository interface {
+	HasPurchased(userID, productID uint) (bool, error)
+	GetUserReview(userID, productID uint) (*models.Review, error)
+	CreateReview(review *models.Review) error
+	GetReviewByID(id uint) (*models.Review, error)
+	GetProductReviews(productID uint, status string) ([]models.Review, error)
+	GetReviews(status string) ([]models.Review, error)
+	ModerateReview(review *models.Review, status, moderatedBy string, at time.Time) error
+}
+
+type reviewsRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewReviewsRepository(db *gorm.DB, logger *logrus.Logger) ReviewsRepository {
+	return &reviewsRepository{db: db, logger: logger}
+}
+
+// HasPurchased reports whether the user has an order line for the product
+// in an order that was not cancelled.
+func (r *reviewsRepository) HasPurchased(userID, productID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.OrderProduct{}).
+		Joins("JOIN orders ON orders.id = order_products.order_id").
+		Where("orders.user_id = ? AND order_products.product_id = ? AND orders.status <> ?", userID, productID, models.OrderStatusCancelled).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Errorln("Layer: reviews_repo, Method: HasPurchased, Error:", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *reviewsRepository) GetUserReview(userID, productID uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.Where("user_id = ? AND product_id = ?", userID, productID).First(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewsRepository) CreateReview(review *models.Review) error {
+	err := r.db.Create(review).Error
+	if err != nil {
+		r.logger.Errorln("Layer: reviews_repo, Method: CreateReview, Error:", err)
+	}
+	return err
+}
+
+func (r *reviewsRepository) GetReviewByID(id uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewsRepository) GetProductReviews(productID uint, status string) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.db.Where("product_id = ? AND status = ?", productID, status).Order("created_at desc").Order("id desc").Find(&reviews).Error
+	if err != nil {
+		r.logger.Errorln("Layer: reviews_repo, Method: GetProductReviews, Error:", err)
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// GetReviews lists reviews for moderation, oldest first. An empty status
+// returns every review.
+func (r *reviewsRepository) GetReviews(status string) ([]models.Review, error) {
+	query := r.db.Order("created_at asc").Order("id asc")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var reviews []models.Review
+	if err := query.Find(&reviews).Error; err != nil {
+		r.logger.Errorln("Layer: reviews_repo, Method: GetReviews, Error:", err)
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// ModerateReview changes the review status and recomputes the rating summary
+// of its product in the same transaction.
+func (r *reviewsRepository) ModerateReview(review *models.Review, status, moderatedBy string, at time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(review).Updates(map[string]interface{}{
+			"status":       status,
+			"moderated_by": moderatedBy,
+			"moderated_at": at,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		var summary struct {
+			Average float64
+			Count   int
+		}
+		err = tx.Model(&models.Review{}).
+			Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+			Where("product_id = ? AND status = ?", review.ProductID, models.ReviewStatusApproved).
+			Scan(&summary).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Product{}).Where("id = ?", review.ProductID).Updates(map[string]interface{}{
+			"rating_average": math.Round(summary.Average*100) / 100,
+			"rating_count":   summary.Count,
+		}).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: reviews_repo, Method: ModerateReview, Error:", err)
+		return err
+	}
+	review.Status = status
+	review.ModeratedBy = moderatedBy
+	review.ModeratedAt = &at
+	return nil
+}
@@ -0,0 +1,98 @@
+package reviews_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.Product{}, &models.Order{}, &models.OrderProduct{}, &models.Review{})
+	require.NoError(t, err)
+	return db
+}
+
+func TestHasPurchased(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewReviewsRepository(db, logrus.New())
+
+	require.NoError(t, db.Create(&models.Order{UserID: 1, Status: models.OrderStatusDelivered, OrderItems: []models.OrderProduct{{ProductID: 10, Quantity: 1}}}).Error)
+	require.NoError(t, db.Create(&models.Order{UserID: 2, Status: models.OrderStatusCancelled, OrderItems: []models.OrderProduct{{ProductID: 10, Quantity: 1}}}).Error)
+
+	bought, err := repo.HasPurchased(1, 10)
+	require.NoError(t, err)
+	assert.True(t, bought)
+
+	bought, err = repo.HasPurchased(1, 11)
+	require.NoError(t, err)
+	assert.False(t, bought)
+
+	bought, err = repo.HasPurchased(2, 10)
+	require.NoError(t, err)
+	assert.False(t, bought, "cancelled orders do not count")
+}
+
+func TestModerateReview_UpdatesProductRating(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewReviewsRepository(db, logrus.New())
+
+	product := &models.Product{Name: "Teclado-reviews"}
+	require.NoError(t, db.Create(product).Error)
+
+	reviews := []*models.Review{
+		{ProductID: product.ID, UserID: 1, Rating: 5, Status: models.ReviewStatusPending},
+		{ProductID: product.ID, UserID: 2, Rating: 4, Status: models.ReviewStatusPending},
+		{ProductID: product.ID, UserID: 3, Rating: 4, Status: models.ReviewStatusPending},
+		{ProductID: product.ID, UserID: 4, Rating: 1, Status: models.ReviewStatusPending},
+	}
+	for _, review := range reviews {
+		require.NoError(t, repo.CreateReview(review))
+	}
+
+	now := time.Now()
+	for _, review := range reviews[:3] {
+		require.NoError(t, repo.ModerateReview(review, models.ReviewStatusApproved, "admin@example.com", now))
+	}
+	require.NoError(t, repo.ModerateReview(reviews[3], models.ReviewStatusRejected, "admin@example.com", now))
+
+	var stored models.Product
+	require.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 4.33, stored.RatingAverage)
+	assert.Equal(t, 3, stored.RatingCount)
+
+	public, err := repo.GetProductReviews(product.ID, models.ReviewStatusApproved)
+	require.NoError(t, err)
+	assert.Len(t, public, 3)
+
+	rejected, err := repo.GetReviews(models.ReviewStatusRejected)
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "admin@example.com", rejected[0].ModeratedBy)
+
+	require.NoError(t, repo.ModerateReview(reviews[0], models.ReviewStatusRejected, "admin@example.com", now))
+	require.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 4.0, stored.RatingAverage)
+	assert.Equal(t, 2, stored.RatingCount)
+}
+
+func TestCreateReview_OnePerUserAndProduct(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewReviewsRepository(db, logrus.New())
+
+	require.NoError(t, repo.CreateReview(&models.Review{ProductID: 1, UserID: 1, Rating: 3}))
+	assert.Error(t, repo.CreateReview(&models.Review{ProductID: 1, UserID: 1, Rating: 5}))
+
+	review, err := repo.GetUserReview(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, review.Rating)
+	assert.Equal(t, models.ReviewStatusPending, review.Status)
+}
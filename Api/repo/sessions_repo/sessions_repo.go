@@ -0,0 +1,87 @@
+package sessions_repo
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SessionsRepository interface {
+	CreateSession(session *models.Session) error
+	GetSessionByTokenID(tokenID string) (*models.Session, error)
+	ListActiveSessions(userID uint, since, now time.Time) ([]models.Session, error)
+	RevokeSession(userID, id uint, at time.Time) error
+	TouchSession(id uint, at, notBefore time.Time) error
+}
+
+type sessionsRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewSessionsRepository(db *gorm.DB, logger *logrus.Logger) SessionsRepository {
+	return &sessionsRepository{db: db, logger: logger}
+}
+
+func (r *sessionsRepository) CreateSession(session *models.Session) error {
+	err := r.db.Create(session).Error
+	if err != nil {
+		r.logger.Errorln("Layer: sessions_repo, Method: CreateSession, Error:", err)
+	}
+	return err
+}
+
+func (r *sessionsRepository) GetSessionByTokenID(tokenID string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.Where("token_id = ?", tokenID).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActiveSessions returns the user's sessions created after since that
+// are neither revoked nor expired, most recently used first.
+func (r *sessionsRepository) ListActiveSessions(userID uint, since, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND created_at >= ? AND expires_at > ?", userID, since, now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		r.logger.Errorln("Layer: sessions_repo, Method: ListActiveSessions, Error:", err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of the user. It returns
+// gorm.ErrRecordNotFound if the user has no such active session.
+func (r *sessionsRepository) RevokeSession(userID, id uint, at time.Time) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		r.logger.Errorln("Layer: sessions_repo, Method: RevokeSession, Error:", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchSession records activity on a session, at most once per interval so
+// every request does not write.
+func (r *sessionsRepository) TouchSession(id uint, at, notBefore time.Time) error {
+	err := r.db.Model(&models.Session{}).
+		Where("id = ? AND last_seen_at < ?", id, notBefore).
+		Update("last_seen_at", at).Error
+	if err != nil {
+		r.logger.Errorln("Layer: sessions_repo, Method: TouchSession, Error:", err)
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package sessions_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.Session{})
+	require.NoError(t, err)
+	return db
+}
+
+var now = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func newSession(t *testing.T, repo SessionsRepository, userID uint, tokenID string, createdAt time.Time) *models.Session {
+	session := &models.Session{
+		UserID:     userID,
+		TokenID:    tokenID,
+		CreatedAt:  createdAt,
+		LastSeenAt: createdAt,
+		ExpiresAt:  createdAt.Add(24 * time.Hour),
+	}
+	require.NoError(t, repo.CreateSession(session))
+	return session
+}
+
+func TestListActiveSessions_SkipsRevokedExpiredAndOlderThanSince(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewSessionsRepository(db, logrus.New())
+
+	laptop := newSession(t, repo, 1, "laptop", now.Add(-time.Hour))
+	phone := newSession(t, repo, 1, "phone", now.Add(-2*time.Hour))
+	newSession(t, repo, 1, "expired", now.Add(-48*time.Hour))
+	newSession(t, repo, 1, "before-logout-everywhere", now.Add(-3*time.Hour))
+	newSession(t, repo, 2, "other-user", now.Add(-time.Hour))
+	revoked := newSession(t, repo, 1, "revoked", now.Add(-time.Hour))
+	require.NoError(t, repo.RevokeSession(1, revoked.ID, now))
+
+	sessions, err := repo.ListActiveSessions(1, now.Add(-150*time.Minute), now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, laptop.ID, sessions[0].ID)
+	assert.Equal(t, phone.ID, sessions[1].ID)
+}
+
+func TestRevokeSession_IsScopedToOwner(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewSessionsRepository(db, logrus.New())
+	session := newSession(t, repo, 1, "laptop", now)
+
+	assert.ErrorIs(t, repo.RevokeSession(2, session.ID, now), gorm.ErrRecordNotFound)
+	require.NoError(t, repo.RevokeSession(1, session.ID, now))
+	assert.ErrorIs(t, repo.RevokeSession(1, session.ID, now), gorm.ErrRecordNotFound)
+
+	stored, err := repo.GetSessionByTokenID("laptop")
+	require.NoError(t, err)
+	assert.NotNil(t, stored.RevokedAt)
+}
+
+func TestTouchSession_WritesAtMostOncePerInterval(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewSessionsRepository(db, logrus.New())
+	session := newSession(t, repo, 1, "laptop", now)
+
+	first := now.Add(5 * time.Minute)
+	require.NoError(t, repo.TouchSession(session.ID, first, first.Add(-time.Minute)))
+	second := first.Add(10 * time.Second)
+	require.NoError(t, repo.TouchSession(session.ID, second, second.Add(-time.Minute)))
+
+	stored, err := repo.GetSessionByTokenID("laptop")
+	require.NoError(t, err)
+	assert.True(t, stored.LastSeenAt.Equal(first))
+}
@@ -0,0 +1,142 @@
+package shipments_repo
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PickItems chooses the items of a new shipment from the order and the
+// quantities already shipped per order line.
+type PickItems func(order *models.Order, shipped map[uint]int) ([]models.ShipmentItem, error)
+
+type ShipmentsRepository interface {
+	CreateShipment(shipment *models.Shipment, pick PickItems) (*models.Shipment, error)
+	GetShipmentByID(id uint) (*models.Shipment, error)
+	GetShipmentsByOrderID(orderID uint) ([]models.Shipment, error)
+	GetShippedQuantities(orderID uint) (map[uint]int, error)
+	AddEvent(shipment *models.Shipment, event *models.ShipmentEvent) error
+}
+
+type shipmentsRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewShipmentsRepository(db *gorm.DB, logger *logrus.Logger) ShipmentsRepository {
+	return &shipmentsRepository{db: db, logger: logger}
+}
+
+// CreateShipment stores the shipment together with its items and events.
+// The order row stays locked while pick chooses the items and the shipment
+// is inserted, so concurrent shipments of one order cannot over-ship it.
+func (r *shipmentsRepository) CreateShipment(shipment *models.Shipment, pick PickItems) (*models.Shipment, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, shipment.OrderID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("order_id = ?", order.ID).Order("id asc").Find(&order.OrderItems).Error; err != nil {
+			return err
+		}
+		shipped, err := shippedQuantities(tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		items, err := pick(&order, shipped)
+		if err != nil {
+			return err
+		}
+		shipment.Items = items
+		return tx.Create(shipment).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: shipments_repo, Method: CreateShipment, Error:", err)
+		return nil, err
+	}
+	return shipment, nil
+}
+
+func (r *shipmentsRepository) GetShipmentByID(id uint) (*models.Shipment, error) {
+	var shipment models.Shipment
+	err := r.preloaded().First(&shipment, id).Error
+	if err != nil {
+		r.logger.Errorln("Layer: shipments_repo, Method: GetShipmentByID, Error:", err)
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+func (r *shipmentsRepository) GetShipmentsByOrderID(orderID uint) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := r.preloaded().Where("order_id = ?", orderID).Order("id asc").Find(&shipments).Error
+	if err != nil {
+		r.logger.Errorln("Layer: shipments_repo, Method: GetShipmentsByOrderID, Error:", err)
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// GetShippedQuantities returns, per order line, how many units are already
+// part of a shipment.
+func (r *shipmentsRepository) GetShippedQuantities(orderID uint) (map[uint]int, error) {
+	shipped, err := shippedQuantities(r.db, orderID)
+	if err != nil {
+		r.logger.Errorln("Layer: shipments_repo, Method: GetShippedQuantities, Error:", err)
+		return nil, err
+	}
+	return shipped, nil
+}
+
+func shippedQuantities(db *gorm.DB, orderID uint) (map[uint]int, error) {
+	var rows []struct {
+		OrderProductID uint
+		Quantity       int
+	}
+	err := db.Model(&models.ShipmentItem{}).
+		Select("shipment_items.order_product_id, SUM(shipment_items.quantity) AS quantity").
+		Joins("JOIN shipments ON shipments.id = shipment_items.shipment_id").
+		Where("shipments.order_id = ?", orderID).
+		Group("shipment_items.order_product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	shipped := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		shipped[row.OrderProductID] = row.Quantity
+	}
+	return shipped, nil
+}
+
+// AddEvent records a tracking event and moves the shipment to its status in
+// a single transaction.
+func (r *shipmentsRepository) AddEvent(shipment *models.Shipment, event *models.ShipmentEvent) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		event.ShipmentID = shipment.ID
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		shipment.Status = event.Status
+		return tx.Model(shipment).Update("status", event.Status).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: shipments_repo, Method: AddEvent, Error:", err)
+		return err
+	}
+	shipment.Events = append(shipment.Events, *event)
+	return nil
+}
+
+func (r *shipmentsRepository) preloaded() *gorm.DB {
+	return r.db.
+		Preload("Items").
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Order("occurred_at asc").Order("id asc")
+		})
+}
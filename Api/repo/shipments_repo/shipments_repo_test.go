@@ -0,0 +1,135 @@
+package shipments_repo
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.Order{}, &models.OrderProduct{}, &models.Shipment{}, &models.ShipmentItem{}, &models.ShipmentEvent{})
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&[]models.Order{{ID: 1, UserID: 1}, {ID: 2, UserID: 1}}).Error)
+	return db
+}
+
+// items picks the given items regardless of what was shipped before.
+func items(picked ...models.ShipmentItem) PickItems {
+	return func(*models.Order, map[uint]int) ([]models.ShipmentItem, error) {
+		return picked, nil
+	}
+}
+
+func TestCreateShipment_WithItemsAndEvents(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewShipmentsRepository(db, logrus.New())
+
+	shipment := &models.Shipment{
+		OrderID:        1,
+		Carrier:        "Chilexpress",
+		TrackingNumber: "CX1",
+		Status:         models.ShipmentStatusLabelCreated,
+		Events:         []models.ShipmentEvent{{Status: models.ShipmentStatusLabelCreated, OccurredAt: time.Now()}},
+	}
+	created, err := repo.CreateShipment(shipment, items(models.ShipmentItem{OrderProductID: 10, Quantity: 2}))
+	require.NoError(t, err)
+
+	fetched, err := repo.GetShipmentByID(created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "CX1", fetched.TrackingNumber)
+	assert.Len(t, fetched.Items, 1)
+	assert.Len(t, fetched.Events, 1)
+}
+
+func TestGetShippedQuantities(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewShipmentsRepository(db, logrus.New())
+
+	_, err := repo.CreateShipment(&models.Shipment{OrderID: 1}, items(models.ShipmentItem{OrderProductID: 10, Quantity: 1}, models.ShipmentItem{OrderProductID: 11, Quantity: 3}))
+	require.NoError(t, err)
+	_, err = repo.CreateShipment(&models.Shipment{OrderID: 1}, items(models.ShipmentItem{OrderProductID: 10, Quantity: 2}))
+	require.NoError(t, err)
+	_, err = repo.CreateShipment(&models.Shipment{OrderID: 2}, items(models.ShipmentItem{OrderProductID: 20, Quantity: 5}))
+	require.NoError(t, err)
+
+	shipped, err := repo.GetShippedQuantities(1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint]int{10: 3, 11: 3}, shipped)
+}
+
+func TestAddEvent_UpdatesStatus(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewShipmentsRepository(db, logrus.New())
+
+	shipment, err := repo.CreateShipment(&models.Shipment{OrderID: 1, Status: models.ShipmentStatusLabelCreated}, items())
+	require.NoError(t, err)
+
+	err = repo.AddEvent(shipment, &models.ShipmentEvent{Status: models.ShipmentStatusInTransit, Location: "Santiago", OccurredAt: time.Now()})
+	assert.NoError(t, err)
+
+	shipments, err := repo.GetShipmentsByOrderID(1)
+	assert.NoError(t, err)
+	require.Len(t, shipments, 1)
+	assert.Equal(t, models.ShipmentStatusInTransit, shipments[0].Status)
+	assert.Len(t, shipments[0].Events, 1)
+}
+
+func TestCreateShipment_ConcurrentShipmentsCannotOverShip(t *testing.T) {
+	db := setupInMemoryDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// The in-memory database lives on a single connection.
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.Create(&models.OrderProduct{ID: 30, OrderID: 1, ProductID: 1, Quantity: 2}).Error)
+	repo := NewShipmentsRepository(db, logrus.New())
+
+	errNothingLeft := errors.New("nothing left")
+	remainder := func(order *models.Order, shipped map[uint]int) ([]models.ShipmentItem, error) {
+		var picked []models.ShipmentItem
+		for _, line := range order.OrderItems {
+			if left := line.Quantity - shipped[line.ID]; left > 0 {
+				picked = append(picked, models.ShipmentItem{OrderProductID: line.ID, Quantity: left})
+			}
+		}
+		if len(picked) == 0 {
+			return nil, errNothingLeft
+		}
+		return picked, nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.CreateShipment(&models.Shipment{OrderID: 1}, remainder)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			assert.ErrorIs(t, err, errNothingLeft)
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed)
+	shipped, err := repo.GetShippedQuantities(1)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]int{30: 2}, shipped)
+}
@@ -0,0 +1,127 @@
+package two_factor_repo
+
+import (
+	"errors"
+	"time"
+
+	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrAlreadyEnabled is returned when enrolling or confirming an account that
+// already has two-factor authentication enabled.
+var ErrAlreadyEnabled = apperr.Conflict("two_factor_already_enabled", "two-factor authentication already enabled")
+
+type TwoFactorRepository interface {
+	SetPendingSecret(userID uint, secret string) error
+	Enable(userID uint, step int64, codeHashes []string, at time.Time) error
+	ClaimStep(userID uint, step int64) (bool, error)
+	UseRecoveryCode(userID uint, codeHash string, at time.Time) (bool, error)
+	Disable(userID uint) error
+}
+
+type twoFactorRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewTwoFactorRepository(db *gorm.DB, logger *logrus.Logger) TwoFactorRepository {
+	return &twoFactorRepository{db: db, logger: logger}
+}
+
+// SetPendingSecret stores a secret that is not enforced until Enable is
+// called. Enrolling again replaces a pending secret.
+func (r *twoFactorRepository) SetPendingSecret(userID uint, secret string) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND two_factor_enabled_at IS NULL", userID).
+		Update("totp_secret", secret)
+	if result.Error != nil {
+		r.logger.Errorln("Layer: two_factor_repo, Method: SetPendingSecret, Error:", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyEnabled
+	}
+	return nil
+}
+
+// Enable turns on two-factor login and replaces the recovery codes. step is
+// the time step of the confirmation code, so that code cannot be replayed
+// to log in.
+func (r *twoFactorRepository) Enable(userID uint, step int64, codeHashes []string, at time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND two_factor_enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"two_factor_enabled_at": at,
+				"totp_last_step":        step,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyEnabled
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil && !errors.Is(err, ErrAlreadyEnabled) {
+		r.logger.Errorln("Layer: two_factor_repo, Method: Enable, Error:", err)
+	}
+	return err
+}
+
+// ClaimStep records step as the last accepted code. It reports false when a
+// code from that step or a later one was already used.
+func (r *twoFactorRepository) ClaimStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		r.logger.Errorln("Layer: two_factor_repo, Method: ClaimStep, Error:", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UseRecoveryCode consumes an unused recovery code of the user. It reports
+// whether a code was consumed.
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, codeHash string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	if result.Error != nil {
+		r.logger.Errorln("Layer: two_factor_repo, Method: UseRecoveryCode, Error:", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Disable removes the secret and every recovery code of the user.
+func (r *twoFactorRepository) Disable(userID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_secret":           "",
+			"totp_last_step":        0,
+			"two_factor_enabled_at": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: two_factor_repo, Method: Disable, Error:", err)
+	}
+	return err
+}
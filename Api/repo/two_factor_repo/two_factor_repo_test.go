@@ -0,0 +1,102 @@
+package two_factor_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.User{}, &models.RecoveryCode{})
+	require.NoError(t, err)
+	return db
+}
+
+func createUser(t *testing.T, db *gorm.DB) *models.User {
+	user := &models.User{Username: "ana", Email: "ana@example.com", Password: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+func TestEnable_StoresCodesAndRejectsSecondEnrollment(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewTwoFactorRepository(db, logrus.New())
+	user := createUser(t, db)
+
+	require.NoError(t, repo.SetPendingSecret(user.ID, "SECRET"))
+	require.NoError(t, repo.Enable(user.ID, 100, []string{"h1", "h2"}, time.Now()))
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.Equal(t, "SECRET", stored.TOTPSecret)
+	assert.Equal(t, int64(100), stored.TOTPLastStep)
+	assert.NotNil(t, stored.TwoFactorEnabledAt)
+
+	var count int64
+	db.Model(&models.RecoveryCode{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	assert.ErrorIs(t, repo.SetPendingSecret(user.ID, "OTHER"), ErrAlreadyEnabled)
+	assert.ErrorIs(t, repo.Enable(user.ID, 101, []string{"h3"}, time.Now()), ErrAlreadyEnabled)
+}
+
+func TestClaimStep_RejectsReplay(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewTwoFactorRepository(db, logrus.New())
+	user := createUser(t, db)
+
+	claimed, err := repo.ClaimStep(user.ID, 10)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repo.ClaimStep(user.ID, 10)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	claimed, err = repo.ClaimStep(user.ID, 9)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestUseRecoveryCode_SingleUse(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewTwoFactorRepository(db, logrus.New())
+	user := createUser(t, db)
+	require.NoError(t, repo.SetPendingSecret(user.ID, "SECRET"))
+	require.NoError(t, repo.Enable(user.ID, 1, []string{"h1"}, time.Now()))
+
+	used, err := repo.UseRecoveryCode(user.ID, "h1", time.Now())
+	require.NoError(t, err)
+	assert.True(t, used)
+
+	used, err = repo.UseRecoveryCode(user.ID, "h1", time.Now())
+	require.NoError(t, err)
+	assert.False(t, used)
+}
+
+func TestDisable_ClearsSecretAndCodes(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewTwoFactorRepository(db, logrus.New())
+	user := createUser(t, db)
+	require.NoError(t, repo.SetPendingSecret(user.ID, "SECRET"))
+	require.NoError(t, repo.Enable(user.ID, 1, []string{"h1"}, time.Now()))
+
+	require.NoError(t, repo.Disable(user.ID))
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.Empty(t, stored.TOTPSecret)
+	assert.Nil(t, stored.TwoFactorEnabledAt)
+	var count int64
+	db.Model(&models.RecoveryCode{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Zero(t, count)
+}
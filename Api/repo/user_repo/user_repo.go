@@ -1,14 +1,23 @@
 package user_repo
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/utils/apperr"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"gorm.io/gorm"
 )
 
+// ErrProfileTaken is returned when another account already uses the
+// username or email a registration or profile update asks for.
+var ErrProfileTaken = apperr.Conflict("profile_taken", "username or email already in use")
+
 type userRepository struct {
 	db     *gorm.DB
 	logger *logrus.Logger
@@ -28,12 +37,49 @@ type UserRepository interface {
 	DeleteUser(id string) error
 	UpdateUserToken(user *models.User) (*models.User, error)
 	GetUserByEmail(email string) (models.User, error)
+	MarkEmailVerified(userID uint, email string, at time.Time) (bool, error)
+	ClaimVerificationSend(userID uint, at, notBefore time.Time) (bool, error)
+	UpdateProfile(user *models.User) error
+	ChangePassword(userID uint, passwordHash string, at time.Time) error
+	AnonymizeUser(userID uint, at time.Time) error
+	RevokeCredentials(userID uint, at time.Time) error
+	SearchUsers(filter models.UserFilter) ([]models.User, int64, error)
+	SetDisabled(userID uint, disabled bool, at time.Time) error
+	UpdateRole(userID uint, role string) error
 }
 
+// CreateUser stores the user together with its UserRegistered event.
 func (r *userRepository) CreateUser(user *models.User) (*models.User, error) {
-	err := r.db.Create(user).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		err := tx.Model(&models.User{}).
+			Where("username = ? OR email = ?", user.Username, user.Email).
+			Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrProfileTaken
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		event, err := models.NewOutboxEvent(models.EventUserRegistered, models.AggregateUser, user.ID, models.UserRegistration{
+			UserID:   user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+			Role:     user.Role,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Create(&event).Error
+	})
 	if err != nil {
-		r.logger.Error("Layer: userRepo, method: CreateUser, error:", err)
+		if !errors.Is(err, ErrProfileTaken) {
+			r.logger.Error("Layer: userRepo, method: CreateUser, error:", err)
+		}
 		return nil, err
 	}
 	return user, nil
@@ -93,3 +139,292 @@ func (r *userRepository) GetUserByEmail(email string) (models.User, error) {
 	}
 	return userModel, nil
 }
+
+// MarkEmailVerified verifies the user only if the email is still the one the
+// link was issued for. It reports whether a user was updated.
+func (r *userRepository) MarkEmailVerified(userID uint, email string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND email = ?", userID, email).
+		Update("email_verified_at", at)
+	if result.Error != nil {
+		r.logger.Errorln("Layer: user_repository, Method: MarkEmailVerified, Error:", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimVerificationSend records that a verification email is being sent at
+// "at", unless one was already sent after notBefore. The check and update are
+// a single statement so concurrent resends cannot both pass.
+func (r *userRepository) ClaimVerificationSend(userID uint, at, notBefore time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND (verification_sent_at IS NULL OR verification_sent_at <= ?)", userID, notBefore).
+		Update("verification_sent_at", at)
+	if result.Error != nil {
+		r.logger.Errorln("Layer: user_repository, Method: ClaimVerificationSend, Error:", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateProfile writes the self-service profile fields of user, together
+// with the verification and session state that depends on the email. Unlike
+// UpdateUser it never touches the password, tokens or role. When the email
+// changes, the user's sessions, API keys and OAuth clients are revoked at
+// user.SessionsRevokedAt, so nothing issued to the old address outlives it.
+func (r *userRepository) UpdateProfile(user *models.User) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		err := tx.Model(&models.User{}).
+			Where("id <> ? AND (username = ? OR email = ?)", user.ID, user.Username, user.Email).
+			Count(&taken).Error
+		if err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrProfileTaken
+		}
+
+		var current models.User
+		if err := tx.Select("email").Where("id = ?", user.ID).First(&current).Error; err != nil {
+			return err
+		}
+		if current.Email != user.Email {
+			at := time.Now()
+			if user.SessionsRevokedAt != nil {
+				at = *user.SessionsRevokedAt
+			}
+			if err := revokeAccess(tx, user.ID, at); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"username":             user.Username,
+			"email":                user.Email,
+			"email_verified_at":    user.EmailVerifiedAt,
+			"verification_sent_at": user.VerificationSentAt,
+			"sessions_revoked_at":  user.SessionsRevokedAt,
+		}).Error
+	})
+	if err != nil && !errors.Is(err, ErrProfileTaken) {
+		r.logger.Errorln("Layer: user_repository, Method: UpdateProfile, Error:", err)
+	}
+	return err
+}
+
+// ChangePassword stores the new password hash and revokes every session of
+// the user. Outstanding password reset links are consumed as well.
+func (r *userRepository) ChangePassword(userID uint, passwordHash string, at time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", at).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":            passwordHash,
+			"token":               "",
+			"refresh_token":       "",
+			"sessions_revoked_at": at,
+		}).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: user_repository, Method: ChangePassword, Error:", err)
+	}
+	return err
+}
+
+// RevokeCredentials locks the user out until they reset their password: the
+// password is cleared, pending reset links are consumed and every session,
+// API key and OAuth client is revoked, all in one transaction.
+func (r *userRepository) RevokeCredentials(userID uint, at time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", at).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":            "",
+			"token":               "",
+			"refresh_token":       "",
+			"sessions_revoked_at": at,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return revokeAccess(tx, userID, at)
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: user_repository, Method: RevokeCredentials, Error:", err)
+	}
+	return err
+}
+
+// revokeAccess revokes the user's sessions, API keys and OAuth clients.
+func revokeAccess(tx *gorm.DB, userID uint, at time.Time) error {
+	for _, model := range []interface{}{&models.Session{}, &models.ApiKey{}, &models.OAuthClient{}} {
+		err := tx.Model(model).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", at).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AnonymizeUser closes the account: personal data is replaced by a
+// pseudonym on the user row and wherever it was copied (review authors,
+// session IPs, registration events), every credential is revoked, data that
+// only serves the account (addresses, wishlist, alerts, 2FA codes) is removed
+// and the user is soft deleted. Orders and invoices keep their user_id, so
+// order history and accounting stay intact. Already deleted users are
+// scrubbed again, which makes it safe to repeat.
+func (r *userRepository) AnonymizeUser(userID uint, at time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Unscoped().Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+		pseudonym := fmt.Sprintf("deleted-%d", userID)
+
+		err := tx.Unscoped().Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":              pseudonym,
+			"email":                 pseudonym + "@invalid",
+			"password":              "",
+			"token":                 "",
+			"refresh_token":         "",
+			"email_verified_at":     nil,
+			"verification_sent_at":  nil,
+			"totp_secret":           "",
+			"totp_last_step":        0,
+			"two_factor_enabled_at": nil,
+			"sessions_revoked_at":   at,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&models.Address{}, &models.WishlistItem{}, &models.StockAlert{},
+			&models.RecoveryCode{}, &models.PasswordResetToken{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		err = tx.Where("scope = ? AND identifier = ?", models.LoginScopeAccount, user.Email).
+			Delete(&models.LoginAttempt{}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := revokeAccess(tx, userID, at); err != nil {
+			return err
+		}
+		err = tx.Model(&models.Session{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"ip":         "",
+			"user_agent": "",
+			"device":     "",
+		}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Review{}).Where("user_id = ?", userID).Update("author", pseudonym).Error
+		if err != nil {
+			return err
+		}
+
+		registration, err := json.Marshal(models.UserRegistration{
+			UserID:   userID,
+			Username: pseudonym,
+			Email:    pseudonym + "@invalid",
+			Role:     user.Role,
+		})
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.OutboxEvent{}).
+			Where("type = ? AND aggregate_type = ? AND aggregate_id = ?", models.EventUserRegistered, models.AggregateUser, userID).
+			Update("payload", string(registration)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.User{}, userID).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: user_repository, Method: AnonymizeUser, Error:", err)
+	}
+	return err
+}
+
+// SearchUsers returns a page of users matching the filter, oldest first,
+// with the total number of matches. The filter must already be validated.
+func (r *userRepository) SearchUsers(filter models.UserFilter) ([]models.User, int64, error) {
+	query := r.db.Model(&models.User{})
+	if filter.Query != "" {
+		like := "%" + strings.ToLower(filter.Query) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR LOWER(username) LIKE ?", like, like)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	switch filter.Status {
+	case models.UserStatusActive:
+		query = query.Where("disabled_at IS NULL")
+	case models.UserStatusDisabled:
+		query = query.Where("disabled_at IS NOT NULL")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Errorln("Layer: user_repository, Method: SearchUsers, Error:", err)
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := query.Order("id").
+		Offset((filter.Page - 1) * filter.PageSize).
+		Limit(filter.PageSize).
+		Find(&users).Error
+	if err != nil {
+		r.logger.Errorln("Layer: user_repository, Method: SearchUsers, Error:", err)
+		return nil, 0, err
+	}
+	return users, count, nil
+}
+
+// SetDisabled blocks or unblocks the user. Blocking also revokes every
+// session, so the user's tokens stop working even if unblocked later.
+func (r *userRepository) SetDisabled(userID uint, disabled bool, at time.Time) error {
+	updates := map[string]interface{}{"disabled_at": nil}
+	if disabled {
+		updates = map[string]interface{}{
+			"disabled_at":         at,
+			"token":               "",
+			"refresh_token":       "",
+			"sessions_revoked_at": at,
+		}
+	}
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error
+	if err != nil {
+		r.logger.Errorln("Layer: user_repository, Method: SetDisabled, Error:", err)
+	}
+	return err
+}
+
+func (r *userRepository) UpdateRole(userID uint, role string) error {
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error
+	if err != nil {
+		r.logger.Errorln("Layer: user_repository, Method: UpdateRole, Error:", err)
+	}
+	return err
+}
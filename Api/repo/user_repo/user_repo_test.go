@@ -2,6 +2,7 @@ package user_repo
 
 import (
 	"testing"
+	"time"
 
 	"pruebaVertice/Api/models"
 
@@ -15,7 +16,7 @@ import (
 func setupInMemoryDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
 	require.NoError(t, err)
-	err = db.AutoMigrate(&models.User{})
+	err = db.AutoMigrate(&models.User{}, &models.OutboxEvent{})
 	require.NoError(t, err)
 	return db
 }
@@ -31,6 +32,27 @@ func TestCreateUser_Success(t *testing.T) {
 	assert.NotZero(t, created.ID)
 	assert.Equal(t, "u1", created.Username)
 	assert.Equal(t, "e1@e.com", created.Email)
+
+	var event models.OutboxEvent
+	require.NoError(t, db.Where("type = ?", models.EventUserRegistered).First(&event).Error)
+	assert.Equal(t, created.ID, event.AggregateID)
+	assert.NotContains(t, event.Payload, "pwd")
+}
+
+func TestCreateUser_Taken(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewUserRepository(db, logrus.New())
+	_, err := repo.CreateUser(&models.User{Username: "u1", Email: "e1@e.com", Password: "pwd"})
+	require.NoError(t, err)
+
+	_, err = repo.CreateUser(&models.User{Username: "u2", Email: "e1@e.com", Password: "pwd"})
+	assert.ErrorIs(t, err, ErrProfileTaken)
+	_, err = repo.CreateUser(&models.User{Username: "u1", Email: "e2@e.com", Password: "pwd"})
+	assert.ErrorIs(t, err, ErrProfileTaken)
+
+	var events int64
+	db.Model(&models.OutboxEvent{}).Count(&events)
+	assert.Equal(t, int64(1), events)
 }
 
 func TestGetUserByID_Success(t *testing.T) {
@@ -116,3 +138,281 @@ func TestGetUserByEmail_Error(t *testing.T) {
 	_, err := repo.GetUserByEmail("not@found")
 	assert.Error(t, err)
 }
+
+func TestMarkEmailVerified(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewUserRepository(db, logrus.New())
+
+	u := &models.User{Username: "u7", Email: "e7@e.com", Password: "pwd"}
+	_, err := repo.CreateUser(u)
+	require.NoError(t, err)
+
+	ok, err := repo.MarkEmailVerified(u.ID, "old@e.com", time.Now())
+	require.NoError(t, err)
+	assert.False(t, ok, "links issued for a previous email must not verify the new one")
+
+	ok, err = repo.MarkEmailVerified(u.ID, "e7@e.com", time.Now())
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	res, err := repo.GetUserByEmail("e7@e.com")
+	require.NoError(t, err)
+	assert.NotNil(t, res.EmailVerifiedAt)
+}
+
+func TestClaimVerificationSend(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewUserRepository(db, logrus.New())
+
+	u := &models.User{Username: "u8", Email: "e8@e.com", Password: "pwd"}
+	_, err := repo.CreateUser(u)
+	require.NoError(t, err)
+
+	first := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	ok, err := repo.ClaimVerificationSend(u.ID, first, first.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	soon := first.Add(30 * time.Second)
+	ok, err = repo.ClaimVerificationSend(u.ID, soon, soon.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	later := first.Add(2 * time.Minute)
+	ok, err = repo.ClaimVerificationSend(u.ID, later, later.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	db := setupInMemoryDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Session{}, &models.ApiKey{}, &models.OAuthClient{}))
+	repo := NewUserRepository(db, logrus.New())
+
+	u := &models.User{Username: "u9", Email: "e9@e.com", Password: "pwd", Token: "tok"}
+	_, err := repo.CreateUser(u)
+	require.NoError(t, err)
+	other := &models.User{Username: "u10", Email: "e10@e.com", Password: "pwd"}
+	_, err = repo.CreateUser(other)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.Session{UserID: u.ID, TokenID: "sid", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+	require.NoError(t, db.Create(&models.ApiKey{UserID: u.ID, Prefix: "vk_abcd1234"}).Error)
+	require.NoError(t, db.Create(&models.OAuthClient{UserID: u.ID, ClientID: "client"}).Error)
+
+	taken := *u
+	taken.Email = "e10@e.com"
+	assert.ErrorIs(t, repo.UpdateProfile(&taken), ErrProfileTaken)
+
+	renamed := *u
+	renamed.Username = "u9-renamed"
+	require.NoError(t, repo.UpdateProfile(&renamed))
+	var session models.Session
+	require.NoError(t, db.First(&session).Error)
+	assert.Nil(t, session.RevokedAt, "only an email change revokes access")
+
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	changed := *u
+	changed.Username = "u9-new"
+	changed.Email = "new9@e.com"
+	changed.Password = "ignored"
+	changed.Role = models.RoleAdmin
+	changed.SessionsRevokedAt = &at
+	require.NoError(t, repo.UpdateProfile(&changed))
+
+	res, err := repo.GetUserByEmail("new9@e.com")
+	require.NoError(t, err)
+	assert.Equal(t, "u9-new", res.Username)
+	assert.Equal(t, "pwd", res.Password, "the password is not a profile field")
+	assert.Equal(t, "tok", res.Token)
+	assert.NotEqual(t, models.RoleAdmin, res.Role)
+
+	require.NoError(t, db.First(&session).Error)
+	require.NotNil(t, session.RevokedAt)
+	assert.True(t, session.RevokedAt.Equal(at))
+	var key models.ApiKey
+	require.NoError(t, db.First(&key).Error)
+	assert.NotNil(t, key.RevokedAt)
+	var client models.OAuthClient
+	require.NoError(t, db.First(&client).Error)
+	assert.NotNil(t, client.RevokedAt)
+}
+
+func TestChangePassword(t *testing.T) {
+	db := setupInMemoryDB(t)
+	require.NoError(t, db.AutoMigrate(&models.PasswordResetToken{}))
+	repo := NewUserRepository(db, logrus.New())
+
+	u := &models.User{Username: "u11", Email: "e11@e.com", Password: "pwd", Token: "tok", RefreshToken: "ref"}
+	_, err := repo.CreateUser(u)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.PasswordResetToken{UserID: u.ID, TokenHash: "h", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.ChangePassword(u.ID, "new-hash", at))
+
+	res, err := repo.GetUserByEmail("e11@e.com")
+	require.NoError(t, err)
+	assert.Equal(t, "new-hash", res.Password)
+	assert.Empty(t, res.Token)
+	assert.Empty(t, res.RefreshToken)
+	require.NotNil(t, res.SessionsRevokedAt)
+	assert.True(t, res.SessionsRevokedAt.Equal(at))
+
+	var reset models.PasswordResetToken
+	require.NoError(t, db.First(&reset).Error)
+	assert.NotNil(t, reset.UsedAt, "pending reset links stop working")
+}
+
+func TestRevokeCredentials(t *testing.T) {
+	db := setupInMemoryDB(t)
+	require.NoError(t, db.AutoMigrate(&models.PasswordResetToken{}, &models.Session{}, &models.ApiKey{}, &models.OAuthClient{}))
+	repo := NewUserRepository(db, logrus.New())
+
+	u := &models.User{Username: "u13", Email: "e13@e.com", Password: "pwd", Token: "tok", RefreshToken: "ref"}
+	_, err := repo.CreateUser(u)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.PasswordResetToken{UserID: u.ID, TokenHash: "h", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+	require.NoError(t, db.Create(&models.Session{UserID: u.ID, TokenID: "sid", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+	require.NoError(t, db.Create(&models.ApiKey{UserID: u.ID, Prefix: "vk_abcd1234"}).Error)
+	require.NoError(t, db.Create(&models.OAuthClient{UserID: u.ID, ClientID: "client"}).Error)
+
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.RevokeCredentials(u.ID, at))
+
+	res, err := repo.GetUserByEmail("e13@e.com")
+	require.NoError(t, err)
+	assert.Empty(t, res.Password)
+	assert.Empty(t, res.Token)
+	require.NotNil(t, res.SessionsRevokedAt)
+	assert.True(t, res.SessionsRevokedAt.Equal(at))
+
+	var reset models.PasswordResetToken
+	require.NoError(t, db.First(&reset).Error)
+	assert.NotNil(t, reset.UsedAt)
+	var session models.Session
+	require.NoError(t, db.First(&session).Error)
+	assert.NotNil(t, session.RevokedAt)
+	var key models.ApiKey
+	require.NoError(t, db.First(&key).Error)
+	assert.NotNil(t, key.RevokedAt)
+	var client models.OAuthClient
+	require.NoError(t, db.First(&client).Error)
+	assert.NotNil(t, client.RevokedAt)
+}
+
+func TestAnonymizeUser_KeepsOrders(t *testing.T) {
+	db := setupInMemoryDB(t)
+	require.NoError(t, db.AutoMigrate(
+		&models.Order{}, &models.Address{}, &models.WishlistItem{}, &models.StockAlert{},
+		&models.RecoveryCode{}, &models.PasswordResetToken{}, &models.LoginAttempt{},
+		&models.Session{}, &models.ApiKey{}, &models.OAuthClient{}, &models.Review{},
+	))
+	repo := NewUserRepository(db, logrus.New())
+
+	u := &models.User{Username: "u12", Email: "e12@e.com", Password: "pwd", TOTPSecret: "SECRET"}
+	_, err := repo.CreateUser(u)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.Order{UserID: u.ID, Total: 10}).Error)
+	require.NoError(t, db.Create(&models.Address{UserID: u.ID, Label: "Casa"}).Error)
+	require.NoError(t, db.Create(&models.Session{UserID: u.ID, TokenID: "sid", IP: "203.0.113.7", ExpiresAt: time.Now().Add(time.Hour)}).Error)
+	require.NoError(t, db.Create(&models.Review{UserID: u.ID, ProductID: 1, Author: "u12", Rating: 5}).Error)
+	require.NoError(t, db.Create(&models.ApiKey{UserID: u.ID, Prefix: "vk_abcd1234"}).Error)
+	require.NoError(t, db.Create(&models.LoginAttempt{Scope: models.LoginScopeAccount, Identifier: "e12@e.com", Failures: 2}).Error)
+
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.AnonymizeUser(u.ID, at))
+
+	_, err = repo.GetUserByEmail("e12@e.com")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	var deleted models.User
+	require.NoError(t, db.Unscoped().First(&deleted, u.ID).Error)
+	assert.True(t, deleted.DeletedAt.Valid)
+	assert.Equal(t, "deleted-1@invalid", deleted.Email)
+	assert.Empty(t, deleted.Password)
+	assert.Empty(t, deleted.TOTPSecret)
+
+	var orders, addresses, attempts int64
+	db.Model(&models.Order{}).Where("user_id = ?", u.ID).Count(&orders)
+	db.Model(&models.Address{}).Where("user_id = ?", u.ID).Count(&addresses)
+	db.Model(&models.LoginAttempt{}).Count(&attempts)
+	assert.Equal(t, int64(1), orders)
+	assert.Zero(t, addresses)
+	assert.Zero(t, attempts)
+
+	var session models.Session
+	require.NoError(t, db.First(&session).Error)
+	assert.NotNil(t, session.RevokedAt)
+	assert.Empty(t, session.IP)
+	var review models.Review
+	require.NoError(t, db.First(&review).Error)
+	assert.Equal(t, "deleted-1", review.Author)
+	var event models.OutboxEvent
+	require.NoError(t, db.Where("type = ?", models.EventUserRegistered).First(&event).Error)
+	assert.NotContains(t, event.Payload, "e12@e.com")
+	var key models.ApiKey
+	require.NoError(t, db.First(&key).Error)
+	assert.NotNil(t, key.RevokedAt)
+
+	assert.NoError(t, repo.AnonymizeUser(u.ID, at), "erasing a deleted account again succeeds")
+
+	// The original email can be registered again.
+	_, err = repo.CreateUser(&models.User{Username: "u12", Email: "e12@e.com", Password: "pwd"})
+	assert.NoError(t, err)
+}
+
+func TestSearchUsers(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewUserRepository(db, logrus.New())
+
+	for _, u := range []*models.User{
+		{Username: "ana", Email: "ana@shop.com", Role: models.RoleCustomer},
+		{Username: "bob", Email: "bob@shop.com", Role: models.RoleWarehouse},
+		{Username: "Anabel", Email: "belle@example.com", Role: models.RoleCustomer},
+	} {
+		_, err := repo.CreateUser(u)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.SetDisabled(3, true, time.Now()))
+
+	users, count, err := repo.SearchUsers(models.UserFilter{Query: "ANA", Page: 1, PageSize: 20})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Len(t, users, 2)
+
+	users, count, err = repo.SearchUsers(models.UserFilter{Query: "ana", Status: models.UserStatusActive, Page: 1, PageSize: 20})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, "ana", users[0].Username)
+
+	users, count, err = repo.SearchUsers(models.UserFilter{Role: models.RoleCustomer, Page: 2, PageSize: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	require.Len(t, users, 1)
+	assert.Equal(t, "Anabel", users[0].Username)
+}
+
+func TestSetDisabledAndUpdateRole(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewUserRepository(db, logrus.New())
+
+	u := &models.User{Username: "u13", Email: "e13@e.com", Password: "pwd", Token: "tok"}
+	_, err := repo.CreateUser(u)
+	require.NoError(t, err)
+
+	at := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.SetDisabled(u.ID, true, at))
+	res, err := repo.GetUserByEmail("e13@e.com")
+	require.NoError(t, err)
+	require.NotNil(t, res.DisabledAt)
+	require.NotNil(t, res.SessionsRevokedAt)
+	assert.Empty(t, res.Token)
+
+	require.NoError(t, repo.SetDisabled(u.ID, false, at))
+	require.NoError(t, repo.UpdateRole(u.ID, models.RoleWarehouse))
+	res, err = repo.GetUserByEmail("e13@e.com")
+	require.NoError(t, err)
+	assert.Nil(t, res.DisabledAt)
+	assert.NotNil(t, res.SessionsRevokedAt, "unblocking does not bring old tokens back")
+	assert.Equal(t, models.RoleWarehouse, res.Role)
+}
@@ -0,0 +1,186 @@
+package webhooks_repo
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type WebhooksRepository interface {
+	CreateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	GetSubscriptionByID(id uint) (*models.WebhookSubscription, error)
+	GetSubscriptions() ([]models.WebhookSubscription, error)
+	GetActiveSubscriptions() ([]models.WebhookSubscription, error)
+	UpdateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	GetDeliveryByID(id uint) (*models.WebhookDelivery, error)
+	GetDeliveries(filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+	GetDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error
+}
+
+type webhooksRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewWebhooksRepository(db *gorm.DB, logger *logrus.Logger) WebhooksRepository {
+	return &webhooksRepository{db: db, logger: logger}
+}
+
+func (r *webhooksRepository) CreateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	err := r.db.Create(subscription).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: CreateSubscription, Error:", err)
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (r *webhooksRepository) GetSubscriptionByID(id uint) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := r.db.First(&subscription, id).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: GetSubscriptionByID, Error:", err)
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *webhooksRepository) GetSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Order("id").Find(&subscriptions).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: GetSubscriptions, Error:", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *webhooksRepository) GetActiveSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Where("active = ?", true).Order("id").Find(&subscriptions).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: GetActiveSubscriptions, Error:", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *webhooksRepository) UpdateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	err := r.db.Save(subscription).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: UpdateSubscription, Error:", err)
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// DeleteSubscription removes the subscription. Its pending deliveries are
+// marked dead so they are kept in the log but never sent.
+func (r *webhooksRepository) DeleteSubscription(id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("subscription_id = ? AND status = ?", id, models.WebhookDeliveryPending).
+			Updates(map[string]interface{}{
+				"status":     models.WebhookDeliveryDead,
+				"last_error": "subscription deleted",
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookSubscription{}, id).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: DeleteSubscription, Error:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhooksRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := r.db.Create(&deliveries).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: CreateDeliveries, Error:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhooksRepository) GetDeliveryByID(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Preload("AttemptLog", func(db *gorm.DB) *gorm.DB {
+		return db.Order("attempted_at, id")
+	}).First(&delivery, id).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: GetDeliveryByID, Error:", err)
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// GetDeliveries returns the newest deliveries first. The filter is expected
+// to be normalised by the caller.
+func (r *webhooksRepository) GetDeliveries(filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	query := r.db.Model(&models.WebhookDelivery{})
+	if filter.SubscriptionID != 0 {
+		query = query.Where("subscription_id = ?", filter.SubscriptionID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Order("id desc").Limit(filter.Limit).Find(&deliveries).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: GetDeliveries, Error:", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhooksRepository) GetDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: GetDueDeliveries, Error:", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhooksRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	err := r.db.Omit("AttemptLog").Save(delivery).Error
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: UpdateDelivery, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// RecordAttempt stores the attempt and the resulting delivery state together.
+func (r *webhooksRepository) RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		attempt.DeliveryID = delivery.ID
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		return tx.Omit("AttemptLog").Save(delivery).Error
+	})
+	if err != nil {
+		r.logger.Errorln("Layer: webhooks_repo, Method: RecordAttempt, Error:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package webhooks_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}, &models.WebhookAttempt{})
+	require.NoError(t, err)
+	return db
+}
+
+func TestSubscriptions(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewWebhooksRepository(db, logrus.New())
+
+	active, err := repo.CreateSubscription(&models.WebhookSubscription{
+		URL: "http://erp.local/hook", Secret: "s", Active: true,
+		Events: models.StringList{models.WebhookEventOrderCreated},
+	})
+	require.NoError(t, err)
+	inactive, err := repo.CreateSubscription(&models.WebhookSubscription{URL: "http://wms.local/hook", Active: true})
+	require.NoError(t, err)
+	inactive.Active = false
+	_, err = repo.UpdateSubscription(inactive)
+	require.NoError(t, err)
+
+	subscriptions, err := repo.GetActiveSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, active.ID, subscriptions[0].ID)
+	assert.Equal(t, models.StringList{models.WebhookEventOrderCreated}, subscriptions[0].Events)
+
+	all, err := repo.GetSubscriptions()
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestDueDeliveriesAndAttempts(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewWebhooksRepository(db, logrus.New())
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.CreateDeliveries([]models.WebhookDelivery{
+		{SubscriptionID: 1, EventType: "order.created", Status: models.WebhookDeliveryPending, NextAttemptAt: now.Add(-time.Minute)},
+		{SubscriptionID: 1, EventType: "order.created", Status: models.WebhookDeliveryPending, NextAttemptAt: now.Add(time.Minute)},
+		{SubscriptionID: 2, EventType: "order.created", Status: models.WebhookDeliveryDead, NextAttemptAt: now.Add(-time.Hour)},
+	}))
+
+	due, err := repo.GetDueDeliveries(now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+
+	delivery := due[0]
+	delivery.Attempts = 1
+	delivery.Status = models.WebhookDeliverySucceeded
+	require.NoError(t, repo.RecordAttempt(&delivery, &models.WebhookAttempt{StatusCode: 200, AttemptedAt: now}))
+
+	stored, err := repo.GetDeliveryByID(delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliverySucceeded, stored.Status)
+	require.Len(t, stored.AttemptLog, 1)
+	assert.Equal(t, 200, stored.AttemptLog[0].StatusCode)
+
+	dead, err := repo.GetDeliveries(models.WebhookDeliveryFilter{Status: models.WebhookDeliveryDead, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, uint(2), dead[0].SubscriptionID)
+}
+
+func TestDeleteSubscription_KillsPendingDeliveries(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewWebhooksRepository(db, logrus.New())
+
+	subscription, err := repo.CreateSubscription(&models.WebhookSubscription{URL: "http://erp.local/hook", Active: true})
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateDeliveries([]models.WebhookDelivery{
+		{SubscriptionID: subscription.ID, Status: models.WebhookDeliveryPending, NextAttemptAt: time.Now()},
+	}))
+
+	require.NoError(t, repo.DeleteSubscription(subscription.ID))
+
+	_, err = repo.GetSubscriptionByID(subscription.ID)
+	assert.Error(t, err)
+	deliveries, err := repo.GetDeliveries(models.WebhookDeliveryFilter{SubscriptionID: subscription.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.WebhookDeliveryDead, deliveries[0].Status)
+}
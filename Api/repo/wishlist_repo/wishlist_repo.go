@@ -0,0 +1,125 @@
+package wishlist_repo
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type WishlistRepository interface {
+	AddItem(userID, productID uint) (*models.WishlistItem, error)
+	RemoveItem(userID, productID uint) error
+	GetItems(userID uint) ([]models.WishlistItem, error)
+	CreateStockAlert(userID, productID uint) (*models.StockAlert, error)
+	DeleteStockAlert(userID, productID uint) error
+	GetUserStockAlerts(userID uint) ([]models.StockAlert, error)
+	GetPendingStockAlerts(productID uint) ([]models.StockAlert, error)
+	MarkStockAlertNotified(id uint, at time.Time) error
+}
+
+type wishlistRepository struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+func NewWishlistRepository(db *gorm.DB, logger *logrus.Logger) WishlistRepository {
+	return &wishlistRepository{db: db, logger: logger}
+}
+
+// AddItem is idempotent: adding a product that is already in the wishlist
+// returns the existing item.
+func (r *wishlistRepository) AddItem(userID, productID uint) (*models.WishlistItem, error) {
+	item := models.WishlistItem{UserID: userID, ProductID: productID}
+	err := r.db.Where(&item).FirstOrCreate(&item).Error
+	if err == nil {
+		err = r.db.Preload("Product").First(&item, item.ID).Error
+	}
+	if err != nil {
+		r.logger.Errorln("Layer: wishlist_repo, Method: AddItem, Error:", err)
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *wishlistRepository) RemoveItem(userID, productID uint) error {
+	result := r.db.Where("user_id = ? AND product_id = ?", userID, productID).Delete(&models.WishlistItem{})
+	if result.Error != nil {
+		r.logger.Errorln("Layer: wishlist_repo, Method: RemoveItem, Error:", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *wishlistRepository) GetItems(userID uint) ([]models.WishlistItem, error) {
+	var items []models.WishlistItem
+	err := r.db.Preload("Product").Where("user_id = ?", userID).Order("created_at desc").Order("id desc").Find(&items).Error
+	if err != nil {
+		r.logger.Errorln("Layer: wishlist_repo, Method: GetItems, Error:", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// CreateStockAlert subscribes the user to the product. Subscribing again to an
+// alert that already fired re-arms it.
+func (r *wishlistRepository) CreateStockAlert(userID, productID uint) (*models.StockAlert, error) {
+	alert := models.StockAlert{UserID: userID, ProductID: productID}
+	err := r.db.Where(&alert).FirstOrCreate(&alert).Error
+	if err == nil && alert.NotifiedAt != nil {
+		alert.NotifiedAt = nil
+		err = r.db.Model(&alert).Update("notified_at", nil).Error
+	}
+	if err != nil {
+		r.logger.Errorln("Layer: wishlist_repo, Method: CreateStockAlert, Error:", err)
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *wishlistRepository) DeleteStockAlert(userID, productID uint) error {
+	result := r.db.Where("user_id = ? AND product_id = ?", userID, productID).Delete(&models.StockAlert{})
+	if result.Error != nil {
+		r.logger.Errorln("Layer: wishlist_repo, Method: DeleteStockAlert, Error:", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *wishlistRepository) GetUserStockAlerts(userID uint) ([]models.StockAlert, error) {
+	var alerts []models.StockAlert
+	err := r.db.Where("user_id = ?", userID).Order("id desc").Find(&alerts).Error
+	if err != nil {
+		r.logger.Errorln("Layer: wishlist_repo, Method: GetUserStockAlerts, Error:", err)
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// GetPendingStockAlerts returns the alerts for a product that have not fired
+// yet, with the subscribed user loaded.
+func (r *wishlistRepository) GetPendingStockAlerts(productID uint) ([]models.StockAlert, error) {
+	var alerts []models.StockAlert
+	err := r.db.Preload("User").Where("product_id = ? AND notified_at IS NULL", productID).Order("id asc").Find(&alerts).Error
+	if err != nil {
+		r.logger.Errorln("Layer: wishlist_repo, Method: GetPendingStockAlerts, Error:", err)
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *wishlistRepository) MarkStockAlertNotified(id uint, at time.Time) error {
+	err := r.db.Model(&models.StockAlert{}).Where("id = ?", id).Update("notified_at", at).Error
+	if err != nil {
+		r.logger.Errorln("Layer: wishlist_repo, Method: MarkStockAlertNotified, Error:", err)
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package wishlist_repo
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupInMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.User{}, &models.Product{}, &models.WishlistItem{}, &models.StockAlert{})
+	require.NoError(t, err)
+	return db
+}
+
+func TestWishlistItems(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewWishlistRepository(db, logrus.New())
+
+	product := &models.Product{Name: "Teclado", Price: 20}
+	require.NoError(t, db.Create(product).Error)
+
+	item, err := repo.AddItem(1, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Teclado", item.Product.Name)
+
+	again, err := repo.AddItem(1, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, item.ID, again.ID)
+
+	items, err := repo.GetItems(1)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Teclado", items[0].Product.Name)
+
+	items, err = repo.GetItems(2)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	require.NoError(t, repo.RemoveItem(1, product.ID))
+	assert.ErrorIs(t, repo.RemoveItem(1, product.ID), gorm.ErrRecordNotFound)
+}
+
+func TestStockAlerts(t *testing.T) {
+	db := setupInMemoryDB(t)
+	repo := NewWishlistRepository(db, logrus.New())
+
+	user := &models.User{Username: "ana", Email: "ana@example.com"}
+	require.NoError(t, db.Create(user).Error)
+
+	alert, err := repo.CreateStockAlert(user.ID, 7)
+	require.NoError(t, err)
+	_, err = repo.CreateStockAlert(2, 8)
+	require.NoError(t, err)
+
+	pending, err := repo.GetPendingStockAlerts(7)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "ana@example.com", pending[0].User.Email)
+
+	require.NoError(t, repo.MarkStockAlertNotified(alert.ID, time.Now()))
+	pending, err = repo.GetPendingStockAlerts(7)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	rearmed, err := repo.CreateStockAlert(user.ID, 7)
+	require.NoError(t, err)
+	assert.Equal(t, alert.ID, rearmed.ID)
+	assert.Nil(t, rearmed.NotifiedAt)
+	pending, err = repo.GetPendingStockAlerts(7)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	alerts, err := repo.GetUserStockAlerts(user.ID)
+	require.NoError(t, err)
+	assert.Len(t, alerts, 1)
+
+	require.NoError(t, repo.DeleteStockAlert(user.ID, 7))
+	assert.ErrorIs(t, repo.DeleteStockAlert(user.ID, 7), gorm.ErrRecordNotFound)
+}
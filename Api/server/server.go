@@ -1,25 +1,71 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"os"
+	address_handler "pruebaVertice/Api/handler/address"
+	apikeys_handler "pruebaVertice/Api/handler/apikeys"
+	invoices_handler "pruebaVertice/Api/handler/invoices"
 	order_handler "pruebaVertice/Api/handler/order"
+	prices_handler "pruebaVertice/Api/handler/prices"
+	privacy_handler "pruebaVertice/Api/handler/privacy"
 	products_handler "pruebaVertice/Api/handler/products"
+	realtime_handler "pruebaVertice/Api/handler/realtime"
+	reports_handler "pruebaVertice/Api/handler/reports"
+	reviews_handler "pruebaVertice/Api/handler/reviews"
+	shipments_handler "pruebaVertice/Api/handler/shipments"
 	user_handler "pruebaVertice/Api/handler/user"
+	webhooks_handler "pruebaVertice/Api/handler/webhooks"
+	wishlist_handler "pruebaVertice/Api/handler/wishlist"
 	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/repo/address_repo"
+	"pruebaVertice/Api/repo/admin_audit_repo"
+	"pruebaVertice/Api/repo/api_keys_repo"
+	"pruebaVertice/Api/repo/invoices_repo"
+	"pruebaVertice/Api/repo/login_attempts_repo"
 	"pruebaVertice/Api/repo/orders_repo"
+	"pruebaVertice/Api/repo/outbox_repo"
+	"pruebaVertice/Api/repo/password_reset_repo"
+	"pruebaVertice/Api/repo/prices_repo"
+	"pruebaVertice/Api/repo/privacy_repo"
 	"pruebaVertice/Api/repo/products_repo"
+	"pruebaVertice/Api/repo/reports_repo"
+	"pruebaVertice/Api/repo/reviews_repo"
+	"pruebaVertice/Api/repo/sessions_repo"
+	"pruebaVertice/Api/repo/shipments_repo"
+	"pruebaVertice/Api/repo/two_factor_repo"
 	user_repo "pruebaVertice/Api/repo/user_repo"
+	"pruebaVertice/Api/repo/webhooks_repo"
+	"pruebaVertice/Api/repo/wishlist_repo"
+	services_address "pruebaVertice/Api/services/address"
+	services_apikey "pruebaVertice/Api/services/apikey"
+	services_invoice "pruebaVertice/Api/services/invoice"
+	services_notifier "pruebaVertice/Api/services/notifier"
 	services_order "pruebaVertice/Api/services/order"
+	services_outbox "pruebaVertice/Api/services/outbox"
+	services_price "pruebaVertice/Api/services/price"
+	services_privacy "pruebaVertice/Api/services/privacy"
 	services_product "pruebaVertice/Api/services/product"
+	services_realtime "pruebaVertice/Api/services/realtime"
+	services_report "pruebaVertice/Api/services/report"
+	services_review "pruebaVertice/Api/services/review"
+	services_shipment "pruebaVertice/Api/services/shipment"
+	services_shipping "pruebaVertice/Api/services/shipping"
 	services_user "pruebaVertice/Api/services/user"
+	services_webhook "pruebaVertice/Api/services/webhook"
+	services_wishlist "pruebaVertice/Api/services/wishlist"
 	"pruebaVertice/Api/utils"
+	"pruebaVertice/Api/utils/i18n"
+	"strconv"
+	"strings"
 	"time"
 
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	jwtUtils "pruebaVertice/Api/utils/jwt"
+	"pruebaVertice/Api/utils/problem"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -28,13 +74,28 @@ import (
 )
 
 type Server struct {
-	router *gin.Engine
-	db     *gorm.DB
-	logger *logrus.Logger
+	router            *gin.Engine
+	db                *gorm.DB
+	logger            *logrus.Logger
+	priceScheduler    *services_price.Scheduler
+	webhookDispatcher *services_webhook.Dispatcher
+	outboxDispatcher  *services_outbox.Dispatcher
 }
 
 func NewServer(db *gorm.DB, logger *logrus.Logger) *Server {
 	router := gin.Default()
+	// Login throttling keys on the client IP, so X-Forwarded-For is only
+	// honoured from the proxies listed in TRUSTED_PROXIES.
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		logger.Errorln("Layer: server, Method: NewServer, Error: invalid TRUSTED_PROXIES:", err)
+	}
+	// Messages and product texts are answered in the language negotiated
+	// from Accept-Language.
+	router.Use(i18n.Middleware())
+	// Every error answer, including unknown routes, is a problem document
+	// carrying the request's trace ID.
+	router.Use(problem.Middleware(logger))
+	router.NoRoute(problem.NoRoute)
 	server := &Server{
 		router: router,
 		db:     db,
@@ -46,51 +107,323 @@ func NewServer(db *gorm.DB, logger *logrus.Logger) *Server {
 func (s *Server) setupRoutes() {
 	hasher := utils.BcryptHasher{}
 	tokenGen := jwtUtils.JWTGenerator{}
+	userRepo := user_repo.NewUserRepository(s.db, s.logger)
+	loginThrottle := services_user.NewLoginThrottle(
+		login_attempts_repo.NewLoginAttemptsRepository(s.db, s.logger),
+		loginThrottleConfig(),
+		s.logger,
+	)
+	sessionService := services_user.NewSessionService(
+		sessions_repo.NewSessionsRepository(s.db, s.logger),
+		services_user.SessionConfig{TTL: jwtUtils.RefreshTokenTTL()},
+		s.logger,
+	)
 	userService := services_user.NewUserService(
-		user_repo.NewUserRepository(s.db, s.logger),
+		userRepo,
 		hasher,
 		tokenGen,
+		loginThrottle,
+		sessionService,
 		s.logger,
 	)
+	notifier := s.notifier()
 
 	userHandler := user_handler.NewUserHandler(userService, s.logger)
+	verificationService := services_user.NewEmailVerificationService(userRepo, notifier, emailVerificationConfig(), s.logger)
+	verificationHandler := user_handler.NewVerificationHandler(verificationService, userService, s.logger)
+	accountHandler := user_handler.NewAccountHandler(
+		services_user.NewAccountService(userRepo, hasher, tokenGen, sessionService, verificationService, notifier, s.logger),
+		userService,
+		s.logger,
+	)
+	twoFactorHandler := user_handler.NewTwoFactorHandler(
+		services_user.NewTwoFactorService(
+			userRepo,
+			two_factor_repo.NewTwoFactorRepository(s.db, s.logger),
+			tokenGen,
+			loginThrottle,
+			sessionService,
+			services_user.TwoFactorConfig{Issuer: os.Getenv("TOTP_ISSUER")},
+			s.logger,
+		),
+		userService,
+		s.logger,
+	)
+	lockoutHandler := user_handler.NewLockoutHandler(loginThrottle, s.logger)
+	sessionHandler := user_handler.NewSessionHandler(sessionService, userService, s.logger)
+	apiKeyService := services_apikey.NewApiKeyService(
+		api_keys_repo.NewApiKeysRepository(s.db, s.logger),
+		tokenGen,
+		apiKeyConfig(),
+		s.logger,
+	)
+	apiKeysHandler := apikeys_handler.NewApiKeysHandler(apiKeyService, userService, s.logger)
+	passwordResetService := services_user.NewPasswordResetService(
+		userRepo,
+		password_reset_repo.NewPasswordResetRepository(s.db, s.logger),
+		hasher,
+		notifier,
+		passwordResetConfig(),
+		s.logger,
+	)
+	passwordHandler := user_handler.NewPasswordHandler(passwordResetService, s.logger)
+	adminUsersHandler := user_handler.NewAdminUsersHandler(
+		services_user.NewAdminUserService(
+			userRepo,
+			admin_audit_repo.NewAdminAuditRepository(s.db, s.logger),
+			passwordResetService,
+			tokenGen,
+			sessionService,
+			s.logger,
+		),
+		userService,
+		s.logger,
+	)
+	webhookService := services_webhook.NewWebhookService(
+		webhooks_repo.NewWebhooksRepository(s.db, s.logger),
+		s.logger,
+	)
+	webhooksHandler := webhooks_handler.NewWebhooksHandler(webhookService, s.logger)
+	s.webhookDispatcher = services_webhook.NewDispatcher(webhookService, webhookDispatchInterval(), s.logger)
+	outboxRepo := outbox_repo.NewOutboxRepository(s.db, s.logger)
+	realtimeHub := services_realtime.NewHub(outboxRepo, s.logger)
+	realtimeHandler := realtime_handler.NewRealtimeHandler(realtimeHub, userService, s.logger)
+	wishlistService := services_wishlist.NewWishlistService(
+		wishlist_repo.NewWishlistRepository(s.db, s.logger),
+		products_repo.NewProductsRepository(s.db, s.logger),
+		notifier,
+		s.logger,
+	)
+	wishlistHandler := wishlist_handler.NewWishlistHandler(wishlistService, userService, s.logger)
+	privacyService := services_privacy.NewPrivacyService(
+		privacy_repo.NewPrivacyRepository(s.db, s.logger),
+		userRepo,
+		s.logger,
+	)
+	privacyHandler := privacy_handler.NewPrivacyHandler(privacyService, userService, s.logger)
+	s.outboxDispatcher = services_outbox.NewDispatcher(
+		outboxRepo,
+		[]services_outbox.Sink{services_outbox.LogSink{Logger: s.logger}, webhookService, realtimeHub, wishlistService, verificationService, privacyService},
+		outboxDispatchInterval(),
+		s.logger,
+	)
+
 	productsService := services_product.NewProductsService(
 		products_repo.NewProductsRepository(s.db, s.logger),
 		s.logger,
 	)
 
 	productsHandler := products_handler.NewProductsHandler(productsService, s.logger)
+	reviewsHandler := reviews_handler.NewReviewsHandler(
+		services_review.NewReviewService(
+			reviews_repo.NewReviewsRepository(s.db, s.logger),
+			products_repo.NewProductsRepository(s.db, s.logger),
+			s.logger,
+		),
+		userService,
+		s.logger,
+	)
+	addressRepo := address_repo.NewAddressRepository(s.db, s.logger)
+	addressHandler := address_handler.NewAddressHandler(
+		services_address.NewAddressService(addressRepo, s.logger),
+		userService,
+		s.logger,
+	)
+
+	ordersRepo := orders_repo.NewOrdersRepository(s.db, s.logger)
 	ordersService := services_order.NewOrdersService(
-		orders_repo.NewOrdersRepository(s.db, s.logger),
+		ordersRepo,
 		products_repo.NewProductsRepository(s.db, s.logger),
+		addressRepo,
+		shippingRegistry(),
 		s.logger,
 	)
 	ordersHandler := order_handler.NewOrdersHandler(ordersService, userService, s.logger)
 
+	shipmentsHandler := shipments_handler.NewShipmentsHandler(
+		services_shipment.NewShipmentService(
+			shipments_repo.NewShipmentsRepository(s.db, s.logger),
+			ordersRepo,
+			s.logger,
+		),
+		userService,
+		s.logger,
+	)
+
+	invoicesHandler := invoices_handler.NewInvoicesHandler(
+		services_invoice.NewInvoiceService(
+			invoices_repo.NewInvoicesRepository(s.db, s.logger),
+			ordersRepo,
+			invoiceConfig(),
+			s.logger,
+		),
+		userService,
+		s.logger,
+	)
+
+	reportsHandler := reports_handler.NewReportsHandler(
+		services_report.NewReportService(reports_repo.NewReportsRepository(s.db, s.logger), s.logger),
+		s.logger,
+	)
+
+	priceService := services_price.NewPriceService(
+		prices_repo.NewPricesRepository(s.db, s.logger),
+		products_repo.NewProductsRepository(s.db, s.logger),
+		s.logger,
+	)
+	pricesHandler := prices_handler.NewPricesHandler(priceService, s.logger)
+	s.priceScheduler = services_price.NewScheduler(priceService, schedulerInterval(), s.logger)
+
 	api := s.router.Group("/api")
 	{
+		api.POST("/oauth/token", apiKeysHandler.IssueToken)
+
 		user := api.Group("/auth")
 		user.POST("/register", userHandler.CreateUser)
 		user.POST("/login", userHandler.LoginUser)
+		user.POST("/login/2fa", twoFactorHandler.CompleteLogin)
+		user.POST("/password/forgot", passwordHandler.ForgotPassword)
+		user.POST("/password/reset", passwordHandler.ResetPassword)
+		user.GET("/verify-email", verificationHandler.VerifyEmail)
 
+		// EventSource and browser WebSockets cannot send headers, so these
+		// also take the token from the query string.
+		streams := user.Group("/orders")
+		streams.Use(jwtUtils.AllowQueryToken(), jwtUtils.GinJWTMiddleware(tokenGen, s.logger), jwtUtils.RejectRevokedSessions(userService, s.logger), jwtUtils.RejectEndedSessions(userService, sessionService, s.logger))
+		{
+			streams.GET("/stream", realtimeHandler.OrdersStream)
+			streams.GET("/ws", realtimeHandler.OrdersSocket)
+		}
+
+		// Besides user tokens, API keys and OAuth client tokens are accepted
+		// here, but only on the routes machineRouteScopes opens to them.
+		authenticated := []gin.HandlerFunc{
+			jwtUtils.GinAuthMiddleware(tokenGen, apiKeyService, machineRouteScopes(), s.logger),
+			jwtUtils.RejectRevokedSessions(userService, s.logger),
+			jwtUtils.RejectEndedSessions(userService, sessionService, s.logger),
+		}
 		protected := user.Group("/")
-		protected.Use(jwtUtils.GinJWTMiddleware(tokenGen, s.logger))
+		protected.Use(authenticated...)
 		{
 			protected.GET("/me", userHandler.GetLoggedInUser)
+			protected.PATCH("/me", accountHandler.UpdateProfile)
+			protected.DELETE("/me", accountHandler.DeleteAccount)
+			protected.POST("/me/password", accountHandler.ChangePassword)
+			protected.GET("/me/export", privacyHandler.ExportData)
+			protected.POST("/verify-email/resend", verificationHandler.ResendVerification)
+			protected.POST("/2fa/enroll", twoFactorHandler.EnrollTwoFactor)
+			protected.POST("/2fa/confirm", twoFactorHandler.ConfirmTwoFactor)
+			protected.POST("/2fa/disable", twoFactorHandler.DisableTwoFactor)
+
+			protected.GET("/me/sessions", sessionHandler.ListSessions)
+			protected.DELETE("/me/sessions/:id", sessionHandler.RevokeSession)
+
+			protected.GET("/me/api-keys", apiKeysHandler.ListApiKeys)
+			protected.POST("/me/api-keys", apiKeysHandler.CreateApiKey)
+			protected.DELETE("/me/api-keys/:id", apiKeysHandler.RevokeApiKey)
+			protected.GET("/me/oauth-clients", apiKeysHandler.ListOAuthClients)
+			protected.POST("/me/oauth-clients", apiKeysHandler.CreateOAuthClient)
+			protected.DELETE("/me/oauth-clients/:id", apiKeysHandler.RevokeOAuthClient)
+
+			addresses := protected.Group("/me/addresses")
+			{
+				addresses.GET("/", addressHandler.ListAddresses)
+				addresses.POST("/", addressHandler.CreateAddress)
+				addresses.GET("/:id", addressHandler.GetAddress)
+				addresses.PUT("/:id", addressHandler.UpdateAddress)
+				addresses.DELETE("/:id", addressHandler.DeleteAddress)
+				addresses.POST("/:id/default", addressHandler.SetDefaultAddress)
+			}
+
+			protected.GET("/me/wishlist", wishlistHandler.GetWishlist)
+			protected.POST("/me/wishlist", wishlistHandler.AddToWishlist)
+			protected.DELETE("/me/wishlist/:productId", wishlistHandler.RemoveFromWishlist)
+			protected.GET("/me/stock-alerts", wishlistHandler.GetStockAlerts)
 
 			products := protected.Group("/products")
 			{
 				products.GET("/", productsHandler.GetAllProducts)
 				products.GET("/:id", productsHandler.GetProductByID)
 				products.POST("/", productsHandler.CreateProducts)
+				products.GET("/:id/prices", pricesHandler.GetPriceTimeline)
+				products.GET("/:id/reviews", reviewsHandler.GetProductReviews)
+				products.POST("/:id/reviews", reviewsHandler.CreateReview)
+				products.POST("/:id/stock-alerts", wishlistHandler.SubscribeStockAlert)
+				products.DELETE("/:id/stock-alerts", wishlistHandler.UnsubscribeStockAlert)
+			}
+			// Catalogue management keeps the product URLs but is for admins only.
+			catalog := protected.Group("/products")
+			catalog.Use(jwtUtils.RequireRole(userService, s.logger, models.RoleAdmin))
+			{
+				catalog.POST("/:id/prices", pricesHandler.SchedulePriceChange)
+				catalog.DELETE("/:id/prices/:changeId", pricesHandler.CancelScheduledChange)
+				catalog.PUT("/:id/translations/:language", productsHandler.SetTranslation)
 			}
 			orders := protected.Group("/orders")
 			{
-				orders.POST("/", ordersHandler.CreateOrder)
+				placeOrder := []gin.HandlerFunc{ordersHandler.CreateOrder}
+				if requireVerifiedEmailForOrders() {
+					placeOrder = append([]gin.HandlerFunc{jwtUtils.RequireVerifiedEmail(userService, s.logger)}, placeOrder...)
+				}
+				orders.POST("/", placeOrder...)
 				orders.GET("/", ordersHandler.GetUserOrders)
+				orders.GET("/shipping-methods", ordersHandler.ListShippingMethods)
+				orders.GET("/:id", ordersHandler.GetOrderByID)
+				orders.POST("/:id/cancel", ordersHandler.CancelOrder)
+				orders.GET("/:id/shipments", shipmentsHandler.GetUserOrderTracking)
+				orders.GET("/:id/invoice", invoicesHandler.GetInvoice)
+			}
+
+			warehouse := protected.Group("/warehouse")
+			warehouse.Use(jwtUtils.RequireRole(userService, s.logger, models.RoleWarehouse, models.RoleAdmin))
+			{
+				warehouse.POST("/orders/:id/shipments", shipmentsHandler.CreateShipment)
+				warehouse.GET("/orders/:id/shipments", shipmentsHandler.GetOrderShipments)
+				warehouse.POST("/shipments/:id/events", shipmentsHandler.AddTrackingEvent)
+				warehouse.POST("/products/:id/restock", productsHandler.RestockProduct)
+			}
+
+			admin := protected.Group("/admin")
+			admin.Use(jwtUtils.RequireRole(userService, s.logger, models.RoleAdmin))
+			{
+				admin.GET("/reports/sales", reportsHandler.SalesReport)
+				admin.GET("/reports/products", reportsHandler.ProductSalesReport)
+				admin.GET("/reports/customers", reportsHandler.CustomerSalesReport)
+
+				admin.GET("/webhooks", webhooksHandler.ListSubscriptions)
+				admin.POST("/webhooks", webhooksHandler.CreateSubscription)
+				admin.GET("/webhooks/:id", webhooksHandler.GetSubscription)
+				admin.PUT("/webhooks/:id", webhooksHandler.UpdateSubscription)
+				admin.DELETE("/webhooks/:id", webhooksHandler.DeleteSubscription)
+				admin.GET("/webhook-deliveries", webhooksHandler.ListDeliveries)
+				admin.GET("/webhook-deliveries/:id", webhooksHandler.GetDelivery)
+				admin.POST("/webhook-deliveries/:id/retry", webhooksHandler.RetryDelivery)
+
+				admin.GET("/login-lockouts", lockoutHandler.ListLockouts)
+				admin.DELETE("/login-lockouts/:id", lockoutHandler.ClearLockout)
+
+				admin.GET("/reviews", reviewsHandler.ListReviews)
+				admin.PUT("/reviews/:id/status", reviewsHandler.ModerateReview)
+
+				admin.POST("/users/:id/erasure", privacyHandler.RequestErasure)
+				admin.GET("/erasure-requests", privacyHandler.ListErasureRequests)
 			}
 		}
 
+		// User administration is mounted at /api/admin, outside /api/auth.
+		adminUsers := api.Group("/admin")
+		adminUsers.Use(authenticated...)
+		adminUsers.Use(jwtUtils.RequireRole(userService, s.logger, models.RoleAdmin))
+		{
+			adminUsers.GET("/users", adminUsersHandler.SearchUsers)
+			adminUsers.GET("/users/:id", adminUsersHandler.GetUser)
+			adminUsers.GET("/users/:id/orders", ordersHandler.GetOrdersForUser)
+			adminUsers.PUT("/users/:id/status", adminUsersHandler.SetUserStatus)
+			adminUsers.PUT("/users/:id/role", adminUsersHandler.ChangeUserRole)
+			adminUsers.POST("/users/:id/password-reset", adminUsersHandler.ForcePasswordReset)
+			adminUsers.POST("/users/:id/impersonate", adminUsersHandler.Impersonate)
+			adminUsers.GET("/audit-log", adminUsersHandler.ListAuditLog)
+		}
 	}
 	s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
@@ -119,13 +452,212 @@ func InitDB(logger *logrus.Logger) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	if err = db.AutoMigrate(&models.User{}, &models.Product{}, &models.Order{}, &models.OrderProduct{}); err != nil {
+	if err = migrate(db); err != nil {
 		return nil, err
 	}
 
 	return db, nil
 }
 
+// migrate creates or updates the tables of every model.
+func migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&models.User{}, &models.PasswordResetToken{}, &models.RecoveryCode{}, &models.LoginAttempt{}, &models.Session{}, &models.ApiKey{}, &models.OAuthClient{}, &models.Product{}, &models.Order{}, &models.OrderProduct{},
+		&models.ProductTranslation{},
+		&models.PriceHistory{}, &models.ScheduledPriceChange{},
+		&models.Address{},
+		&models.Shipment{}, &models.ShipmentItem{}, &models.ShipmentEvent{},
+		&models.Invoice{}, &models.InvoiceSequence{},
+		&models.WebhookSubscription{}, &models.WebhookDelivery{}, &models.WebhookAttempt{},
+		&models.OutboxEvent{}, &models.OutboxDelivery{},
+		&models.WishlistItem{}, &models.StockAlert{},
+		&models.Review{},
+		&models.ErasureRequest{},
+		&models.AdminAuditEntry{},
+	)
+}
+
 func (s *Server) Run() error {
+	s.priceScheduler.Start(context.Background())
+	s.webhookDispatcher.Start(context.Background())
+	s.outboxDispatcher.Start(context.Background())
 	return s.router.Run(":8080")
 }
+
+// schedulerInterval reads PRICE_SCHEDULER_INTERVAL in seconds.
+func schedulerInterval() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("PRICE_SCHEDULER_INTERVAL"))
+	if err != nil || seconds <= 0 {
+		return services_price.DefaultSchedulerInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// webhookDispatchInterval reads WEBHOOK_DISPATCH_INTERVAL in seconds.
+func webhookDispatchInterval() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("WEBHOOK_DISPATCH_INTERVAL"))
+	if err != nil || seconds <= 0 {
+		return services_webhook.DefaultDispatchInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// outboxDispatchInterval reads OUTBOX_DISPATCH_INTERVAL in seconds.
+func outboxDispatchInterval() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("OUTBOX_DISPATCH_INTERVAL"))
+	if err != nil || seconds <= 0 {
+		return services_outbox.DefaultDispatchInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// notifier sends user notifications through the mail server configured with
+// SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM. Without
+// SMTP_HOST they are appended to the MAIL_FILE file for local development,
+// or only logged if that is not set either.
+func (s *Server) notifier() services_notifier.Notifier {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		if path := os.Getenv("MAIL_FILE"); path != "" {
+			return services_notifier.NewFileNotifier(path)
+		}
+		return services_notifier.LogNotifier{Logger: s.logger}
+	}
+	port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	return services_notifier.NewSMTPNotifier(services_notifier.SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	})
+}
+
+// passwordResetConfig reads PASSWORD_RESET_TTL in minutes and the frontend
+// page the reset token is appended to from PASSWORD_RESET_URL.
+func passwordResetConfig() services_user.PasswordResetConfig {
+	config := services_user.PasswordResetConfig{ResetURL: os.Getenv("PASSWORD_RESET_URL")}
+	if minutes, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL")); err == nil && minutes > 0 {
+		config.TTL = time.Duration(minutes) * time.Minute
+	}
+	return config
+}
+
+// emailVerificationConfig signs verification links with SECRET_KEY. The link
+// points to EMAIL_VERIFICATION_URL, is valid for EMAIL_VERIFICATION_TTL hours
+// and can be resent once every EMAIL_VERIFICATION_RESEND_COOLDOWN seconds.
+func emailVerificationConfig() services_user.EmailVerificationConfig {
+	config := services_user.EmailVerificationConfig{
+		Secret:    []byte(os.Getenv("SECRET_KEY")),
+		VerifyURL: os.Getenv("EMAIL_VERIFICATION_URL"),
+	}
+	if config.VerifyURL == "" {
+		config.VerifyURL = "http://localhost:8080/api/auth/verify-email?token="
+	}
+	if hours, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_TTL")); err == nil && hours > 0 {
+		config.TTL = time.Duration(hours) * time.Hour
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_RESEND_COOLDOWN")); err == nil && seconds > 0 {
+		config.Cooldown = time.Duration(seconds) * time.Second
+	}
+	return config
+}
+
+// loginThrottleConfig locks an account after LOGIN_MAX_FAILURES failed
+// logins and a client IP after LOGIN_IP_MAX_FAILURES, for
+// LOGIN_LOCKOUT_MINUTES.
+func loginThrottleConfig() services_user.LoginThrottleConfig {
+	config := services_user.DefaultLoginThrottleConfig()
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && n > config.Account.FreeAttempts {
+		config.Account.MaxFailures = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_IP_MAX_FAILURES")); err == nil && n > config.IP.FreeAttempts {
+		config.IP.MaxFailures = n
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES")); err == nil && minutes > 0 {
+		config.LockoutDuration = time.Duration(minutes) * time.Minute
+	}
+	return config
+}
+
+// trustedProxies reads a comma separated list of proxy IPs or CIDRs. With
+// none set the client IP is always the connection address.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// requireVerifiedEmailForOrders reads REQUIRE_VERIFIED_EMAIL_FOR_ORDERS. It is
+// off by default so accounts created before verification existed can still
+// place orders.
+func requireVerifiedEmailForOrders() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_EMAIL_FOR_ORDERS"))
+	return enabled
+}
+
+// shippingRegistry builds the available shipping methods. Amounts can be
+// tuned with SHIPPING_FLAT_RATE, FREE_SHIPPING_THRESHOLD,
+// SHIPPING_EXPRESS_BASE and SHIPPING_EXPRESS_PER_KG.
+func shippingRegistry() *services_shipping.Registry {
+	flat := services_shipping.FlatRate{Amount: envFloat("SHIPPING_FLAT_RATE", 5)}
+	return services_shipping.NewRegistry("standard").
+		Register("standard", services_shipping.FreeOverThreshold{
+			Threshold: envFloat("FREE_SHIPPING_THRESHOLD", 100),
+			Fallback:  flat,
+		}).
+		Register("flat", flat).
+		Register("express", services_shipping.WeightBased{
+			Base:  envFloat("SHIPPING_EXPRESS_BASE", 10),
+			PerKg: envFloat("SHIPPING_EXPRESS_PER_KG", 2),
+		})
+}
+
+// invoiceConfig reads the issuer printed on invoices from INVOICE_ISSUER_NAME,
+// INVOICE_ISSUER_TAX_ID and INVOICE_ISSUER_ADDRESS, and the standard VAT
+// rate from INVOICE_VAT_RATE.
+func invoiceConfig() services_invoice.Config {
+	return services_invoice.Config{
+		IssuerName:    os.Getenv("INVOICE_ISSUER_NAME"),
+		IssuerTaxID:   os.Getenv("INVOICE_ISSUER_TAX_ID"),
+		IssuerAddress: os.Getenv("INVOICE_ISSUER_ADDRESS"),
+		TaxRates:      services_invoice.DefaultTaxRates(envFloat("INVOICE_VAT_RATE", 0.19)),
+	}
+}
+
+func envFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// apiKeyConfig reads the lifetime of OAuth client-credentials tokens from
+// OAUTH_TOKEN_TTL in minutes.
+func apiKeyConfig() services_apikey.ApiKeyConfig {
+	var config services_apikey.ApiKeyConfig
+	if minutes, err := strconv.Atoi(os.Getenv("OAUTH_TOKEN_TTL")); err == nil && minutes > 0 {
+		config.TokenTTL = time.Duration(minutes) * time.Minute
+	}
+	return config
+}
+
+// machineRouteScopes lists the routes API keys and OAuth clients may call
+// and the scope each needs. Role checks still apply to the key's owner.
+// Entries without scopes carve user-only routes out of a wider prefix.
+func machineRouteScopes() []jwtUtils.RouteScope {
+	return []jwtUtils.RouteScope{
+		{Prefix: "/api/auth/products", Read: models.ScopeProductsRead, Write: models.ScopeProductsWrite},
+		{Prefix: "/api/auth/products/:id/reviews"},
+		{Prefix: "/api/auth/products/:id/stock-alerts"},
+		{Prefix: "/api/auth/orders", Read: models.ScopeOrdersRead, Write: models.ScopeOrdersWrite},
+		{Prefix: "/api/auth/warehouse", Read: models.ScopeWarehouseRead, Write: models.ScopeWarehouseWrite},
+		{Prefix: "/api/auth/admin/reports", Read: models.ScopeReportsRead},
+		{Prefix: "/api/auth/admin/webhooks", Read: models.ScopeWebhooksRead, Write: models.ScopeWebhooksWrite},
+		{Prefix: "/api/auth/admin/webhook-deliveries", Read: models.ScopeWebhooksRead, Write: models.ScopeWebhooksWrite},
+	}
+}
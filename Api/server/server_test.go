@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupServer(t *testing.T) (*Server, *gorm.DB) {
+	t.Setenv("SECRET_KEY", "route-test-secret")
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, migrate(db))
+	return NewServer(db, logrus.New()), db
+}
+
+func call(s *Server, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// loginAs registers a user with the given role and returns an access token.
+func loginAs(t *testing.T, s *Server, db *gorm.DB, username, role string) string {
+	email := username + "@example.com"
+	rec := call(s, http.MethodPost, "/api/auth/register", "", models.RegisterRequest{Username: username, Email: email, Password: "Clave-Segura1"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	require.NoError(t, db.Model(&models.User{}).Where("email = ?", email).Update("role", role).Error)
+
+	rec = call(s, http.MethodPost, "/api/auth/login", "", models.LoginRequest{Email: email, Password: "Clave-Segura1"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Token)
+	return resp.Token
+}
+
+func TestCatalogManagementRoutes_RequireAdmin(t *testing.T) {
+	s, db := setupServer(t)
+	product := models.Product{Name: "Keyboard", Price: 50, Stock: 3}
+	require.NoError(t, db.Create(&product).Error)
+	customer := loginAs(t, s, db, "customer", models.RoleCustomer)
+	admin := loginAs(t, s, db, "admin", models.RoleAdmin)
+
+	routes := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{http.MethodPost, "/api/auth/products/1/prices", map[string]interface{}{"price": 0.01}},
+		{http.MethodDelete, "/api/auth/products/1/prices/1", nil},
+		{http.MethodPut, "/api/auth/products/1/translations/es", map[string]interface{}{"name": "Teclado"}},
+	}
+	for _, route := range routes {
+		rec := call(s, route.method, route.path, customer, route.body)
+		assert.Equal(t, http.StatusForbidden, rec.Code, route.path)
+
+		rec = call(s, route.method, route.path, admin, route.body)
+		assert.NotEqual(t, http.StatusForbidden, rec.Code, route.path)
+	}
+
+	var stored models.Product
+	require.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 0.01, stored.Price)
+	var translations int64
+	db.Model(&models.ProductTranslation{}).Where("product_id = ?", product.ID).Count(&translations)
+	assert.Equal(t, int64(1), translations, "only the admin's translation is stored")
+
+	// Reading the catalogue stays open to customers.
+	assert.Equal(t, http.StatusOK, call(s, http.MethodGet, "/api/auth/products/1/prices", customer, nil).Code)
+}
+
+func TestUserAdministrationRoutes_MountedUnderAPIAdmin(t *testing.T) {
+	s, db := setupServer(t)
+	customer := loginAs(t, s, db, "customer", models.RoleCustomer)
+	admin := loginAs(t, s, db, "admin", models.RoleAdmin)
+
+	assert.Equal(t, http.StatusOK, call(s, http.MethodGet, "/api/admin/users", admin, nil).Code)
+	assert.Equal(t, http.StatusOK, call(s, http.MethodGet, "/api/admin/audit-log", admin, nil).Code)
+	assert.Equal(t, http.StatusForbidden, call(s, http.MethodGet, "/api/admin/users", customer, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, call(s, http.MethodGet, "/api/admin/users", "", nil).Code)
+	assert.Equal(t, http.StatusNotFound, call(s, http.MethodGet, "/api/auth/admin/users", admin, nil).Code)
+}
@@ -0,0 +1,62 @@
+package services_address
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AddressRepoMock mocks repo.AddressRepository for service tests.
+type AddressRepoMock struct {
+	mock.Mock
+}
+
+func (m *AddressRepoMock) CreateAddress(address *models.Address) (*models.Address, error) {
+	args := m.Called(address)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressRepoMock) GetAddressByID(id uint) (*models.Address, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressRepoMock) GetAddressesByUserID(userID uint) ([]models.Address, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressRepoMock) GetDefaultAddress(userID uint) (*models.Address, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressRepoMock) UpdateAddress(address *models.Address) (*models.Address, error) {
+	args := m.Called(address)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressRepoMock) DeleteAddress(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *AddressRepoMock) SetDefaultAddress(userID, id uint) error {
+	args := m.Called(userID, id)
+	return args.Error(0)
+}
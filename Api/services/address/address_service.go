@@ -0,0 +1,148 @@
+package services_address
+
+import (
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/address_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrAddressNotFound = apperr.NotFound("address_not_found", "address not found")
+	ErrInvalidAddress  = apperr.Invalid("invalid_address", "recipient, line1, city, postal_code and country are required")
+)
+
+type AddressService interface {
+	ListAddresses(userID uint) ([]models.Address, error)
+	GetAddress(userID, id uint) (*models.Address, error)
+	CreateAddress(userID uint, req models.AddressRequest) (*models.Address, error)
+	UpdateAddress(userID, id uint, req models.AddressRequest) (*models.Address, error)
+	DeleteAddress(userID, id uint) error
+	SetDefaultAddress(userID, id uint) (*models.Address, error)
+}
+
+type addressService struct {
+	repo   repo.AddressRepository
+	logger *logrus.Logger
+}
+
+func NewAddressService(repo repo.AddressRepository, logger *logrus.Logger) *addressService {
+	return &addressService{repo: repo, logger: logger}
+}
+
+func (s *addressService) ListAddresses(userID uint) ([]models.Address, error) {
+	addresses, err := s.repo.GetAddressesByUserID(userID)
+	if err != nil {
+		s.logger.Errorln("Layer: address_service, Method: ListAddresses, Error:", err)
+		return nil, err
+	}
+	if addresses == nil {
+		addresses = []models.Address{}
+	}
+	return addresses, nil
+}
+
+// GetAddress returns the address only if it belongs to userID.
+func (s *addressService) GetAddress(userID, id uint) (*models.Address, error) {
+	address, err := s.repo.GetAddressByID(id)
+	if err != nil || address.UserID != userID {
+		return nil, ErrAddressNotFound
+	}
+	return address, nil
+}
+
+// CreateAddress stores a new address. The first address of a user always
+// becomes the default one.
+func (s *addressService) CreateAddress(userID uint, req models.AddressRequest) (*models.Address, error) {
+	if err := validate(req.PostalAddress); err != nil {
+		return nil, err
+	}
+
+	address := &models.Address{
+		UserID:        userID,
+		Label:         req.Label,
+		IsDefault:     req.IsDefault,
+		PostalAddress: normalize(req.PostalAddress),
+	}
+	if !address.IsDefault {
+		if _, err := s.repo.GetDefaultAddress(userID); err != nil {
+			address.IsDefault = true
+		}
+	}
+
+	created, err := s.repo.CreateAddress(address)
+	if err != nil {
+		s.logger.Errorln("Layer: address_service, Method: CreateAddress, Error:", err)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *addressService) UpdateAddress(userID, id uint, req models.AddressRequest) (*models.Address, error) {
+	if err := validate(req.PostalAddress); err != nil {
+		return nil, err
+	}
+	address, err := s.GetAddress(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	address.Label = req.Label
+	address.PostalAddress = normalize(req.PostalAddress)
+	// A default address can only stop being default by promoting another.
+	address.IsDefault = address.IsDefault || req.IsDefault
+
+	updated, err := s.repo.UpdateAddress(address)
+	if err != nil {
+		s.logger.Errorln("Layer: address_service, Method: UpdateAddress, Error:", err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *addressService) DeleteAddress(userID, id uint) error {
+	if _, err := s.GetAddress(userID, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteAddress(id); err != nil {
+		s.logger.Errorln("Layer: address_service, Method: DeleteAddress, Error:", err)
+		return err
+	}
+	return nil
+}
+
+func (s *addressService) SetDefaultAddress(userID, id uint) (*models.Address, error) {
+	address, err := s.GetAddress(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetDefaultAddress(userID, id); err != nil {
+		s.logger.Errorln("Layer: address_service, Method: SetDefaultAddress, Error:", err)
+		return nil, err
+	}
+	address.IsDefault = true
+	return address, nil
+}
+
+func validate(address models.PostalAddress) error {
+	for _, field := range []string{address.Recipient, address.Line1, address.City, address.PostalCode, address.Country} {
+		if strings.TrimSpace(field) == "" {
+			return ErrInvalidAddress
+		}
+	}
+	return nil
+}
+
+func normalize(address models.PostalAddress) models.PostalAddress {
+	address.Recipient = strings.TrimSpace(address.Recipient)
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.City = strings.TrimSpace(address.City)
+	address.State = strings.TrimSpace(address.State)
+	address.PostalCode = strings.TrimSpace(address.PostalCode)
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+	address.Phone = strings.TrimSpace(address.Phone)
+	return address
+}
@@ -0,0 +1,112 @@
+package services_address
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var validRequest = models.AddressRequest{
+	Label: "Casa",
+	PostalAddress: models.PostalAddress{
+		Recipient:  " Ana ",
+		Line1:      "Calle 1",
+		City:       "Santiago",
+		PostalCode: "8320000",
+		Country:    "cl",
+	},
+}
+
+func TestCreateAddress_FirstBecomesDefault(t *testing.T) {
+	repoMock := new(AddressRepoMock)
+	svc := NewAddressService(repoMock, logrus.New())
+
+	repoMock.On("GetDefaultAddress", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	repoMock.On("CreateAddress", mock.MatchedBy(func(a *models.Address) bool {
+		return a.UserID == 1 && a.IsDefault && a.Recipient == "Ana" && a.Country == "CL"
+	})).Return(&models.Address{ID: 3, UserID: 1, IsDefault: true}, nil)
+
+	res, err := svc.CreateAddress(1, validRequest)
+	assert.NoError(t, err)
+	assert.True(t, res.IsDefault)
+	repoMock.AssertExpectations(t)
+}
+
+func TestCreateAddress_KeepsExistingDefault(t *testing.T) {
+	repoMock := new(AddressRepoMock)
+	svc := NewAddressService(repoMock, logrus.New())
+
+	repoMock.On("GetDefaultAddress", uint(1)).Return(&models.Address{ID: 1, UserID: 1, IsDefault: true}, nil)
+	repoMock.On("CreateAddress", mock.MatchedBy(func(a *models.Address) bool {
+		return !a.IsDefault
+	})).Return(&models.Address{ID: 4, UserID: 1}, nil)
+
+	_, err := svc.CreateAddress(1, validRequest)
+	assert.NoError(t, err)
+	repoMock.AssertExpectations(t)
+}
+
+func TestCreateAddress_Invalid(t *testing.T) {
+	svc := NewAddressService(new(AddressRepoMock), logrus.New())
+
+	_, err := svc.CreateAddress(1, models.AddressRequest{PostalAddress: models.PostalAddress{Recipient: "Ana"}})
+	assert.Equal(t, ErrInvalidAddress, err)
+}
+
+func TestGetAddress_OtherUser(t *testing.T) {
+	repoMock := new(AddressRepoMock)
+	svc := NewAddressService(repoMock, logrus.New())
+
+	repoMock.On("GetAddressByID", uint(5)).Return(&models.Address{ID: 5, UserID: 2}, nil)
+	_, err := svc.GetAddress(1, 5)
+	assert.Equal(t, ErrAddressNotFound, err)
+}
+
+func TestUpdateAddress(t *testing.T) {
+	repoMock := new(AddressRepoMock)
+	svc := NewAddressService(repoMock, logrus.New())
+
+	existing := &models.Address{ID: 5, UserID: 1, IsDefault: true}
+	repoMock.On("GetAddressByID", uint(5)).Return(existing, nil)
+	repoMock.On("UpdateAddress", existing).Return(existing, nil)
+
+	res, err := svc.UpdateAddress(1, 5, validRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, "Casa", res.Label)
+	assert.True(t, res.IsDefault)
+}
+
+func TestDeleteAddress_NotFound(t *testing.T) {
+	repoMock := new(AddressRepoMock)
+	svc := NewAddressService(repoMock, logrus.New())
+
+	repoMock.On("GetAddressByID", uint(5)).Return(nil, gorm.ErrRecordNotFound)
+	assert.Equal(t, ErrAddressNotFound, svc.DeleteAddress(1, 5))
+	repoMock.AssertNotCalled(t, "DeleteAddress", mock.Anything)
+}
+
+func TestSetDefaultAddress(t *testing.T) {
+	repoMock := new(AddressRepoMock)
+	svc := NewAddressService(repoMock, logrus.New())
+
+	repoMock.On("GetAddressByID", uint(5)).Return(&models.Address{ID: 5, UserID: 1}, nil)
+	repoMock.On("SetDefaultAddress", uint(1), uint(5)).Return(nil)
+
+	res, err := svc.SetDefaultAddress(1, 5)
+	assert.NoError(t, err)
+	assert.True(t, res.IsDefault)
+}
+
+func TestListAddresses_Error(t *testing.T) {
+	repoMock := new(AddressRepoMock)
+	svc := NewAddressService(repoMock, logrus.New())
+
+	repoMock.On("GetAddressesByUserID", uint(1)).Return(nil, errors.New("db err"))
+	_, err := svc.ListAddresses(1)
+	assert.EqualError(t, err, "db err")
+}
@@ -0,0 +1,85 @@
+package services_apikey
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ApiKeysRepoMock mocks repo.ApiKeysRepository for service tests.
+type ApiKeysRepoMock struct {
+	mock.Mock
+}
+
+func (m *ApiKeysRepoMock) CreateKey(key *models.ApiKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *ApiKeysRepoMock) GetKeyByPrefix(prefix string) (*models.ApiKey, error) {
+	args := m.Called(prefix)
+	if res := args.Get(0); res != nil {
+		return res.(*models.ApiKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeysRepoMock) ListUserKeys(userID uint) ([]models.ApiKey, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.ApiKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeysRepoMock) RevokeKey(userID, id uint, at time.Time) error {
+	args := m.Called(userID, id, at)
+	return args.Error(0)
+}
+
+func (m *ApiKeysRepoMock) TouchKey(id uint, at, notBefore time.Time) error {
+	args := m.Called(id, at, notBefore)
+	return args.Error(0)
+}
+
+func (m *ApiKeysRepoMock) CreateClient(client *models.OAuthClient) error {
+	args := m.Called(client)
+	return args.Error(0)
+}
+
+func (m *ApiKeysRepoMock) GetClientByClientID(clientID string) (*models.OAuthClient, error) {
+	args := m.Called(clientID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.OAuthClient), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeysRepoMock) ListUserClients(userID uint) ([]models.OAuthClient, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.OAuthClient), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ApiKeysRepoMock) RevokeClient(userID, id uint, at time.Time) error {
+	args := m.Called(userID, id, at)
+	return args.Error(0)
+}
+
+// ClientTokensMock mocks ClientTokens.
+type ClientTokensMock struct {
+	mock.Mock
+}
+
+func (m *ClientTokensMock) GenerateClientToken(clientID, scope string, ttl time.Duration) (string, error) {
+	args := m.Called(clientID, scope, ttl)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ClientTokensMock) ParseClientToken(token string) (string, string, time.Time, error) {
+	args := m.Called(token)
+	return args.String(0), args.String(1), args.Get(2).(time.Time), args.Error(3)
+}
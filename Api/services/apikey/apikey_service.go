@@ -0,0 +1,345 @@
+package services_apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/api_keys_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/jwt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// GrantClientCredentials is the only OAuth2 grant the token endpoint
+	// supports.
+	GrantClientCredentials = "client_credentials"
+	TokenTypeBearer        = "Bearer"
+
+	defaultTokenTTL = time.Hour
+	// touchInterval limits how often a key's last_used_at is written.
+	touchInterval = time.Minute
+	prefixLength  = 8
+)
+
+var (
+	ErrApiKeyNotFound       = apperr.NotFound("api_key_not_found", "api key not found")
+	ErrOAuthClientNotFound  = apperr.NotFound("oauth_client_not_found", "oauth client not found")
+	ErrInvalidScope         = apperr.Invalid("invalid_scope", "invalid scope")
+	ErrInvalidCredentials   = apperr.Unauthorized("invalid_credentials", "invalid or expired credentials")
+	ErrInvalidClient        = apperr.Unauthorized("invalid_client", "invalid client credentials")
+	ErrUnsupportedGrantType = apperr.BadRequest("unsupported_grant_type", "unsupported grant type")
+)
+
+// ApiKeyService manages a user's API keys and OAuth clients, issues
+// client-credentials tokens and resolves both kinds of credential for the
+// authentication middleware.
+type ApiKeyService interface {
+	CreateKey(user *models.User, req models.ApiKeyRequest) (*dto.ApiKeyCreatedResponse, error)
+	ListKeys(user *models.User) ([]models.ApiKey, error)
+	RevokeKey(user *models.User, id uint) error
+
+	CreateClient(user *models.User, req models.OAuthClientRequest) (*dto.OAuthClientCreatedResponse, error)
+	ListClients(user *models.User) ([]models.OAuthClient, error)
+	RevokeClient(user *models.User, id uint) error
+	IssueToken(req models.OAuthTokenRequest) (*dto.OAuthTokenResponse, error)
+
+	AuthenticateAPIKey(key string) (*jwt.Principal, error)
+	AuthenticateClientToken(token string) (*jwt.Principal, error)
+}
+
+// ClientTokens signs and parses client-credentials access tokens.
+type ClientTokens interface {
+	GenerateClientToken(clientID, scope string, ttl time.Duration) (string, error)
+	ParseClientToken(token string) (string, string, time.Time, error)
+}
+
+type ApiKeyConfig struct {
+	// TokenTTL is the lifetime of client-credentials access tokens.
+	TokenTTL time.Duration
+}
+
+type apiKeyService struct {
+	repo     repo.ApiKeysRepository
+	tokens   ClientTokens
+	tokenTTL time.Duration
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+func NewApiKeyService(repo repo.ApiKeysRepository, tokens ClientTokens, config ApiKeyConfig, logger *logrus.Logger) *apiKeyService {
+	if config.TokenTTL <= 0 {
+		config.TokenTTL = defaultTokenTTL
+	}
+	return &apiKeyService{
+		repo:     repo,
+		tokens:   tokens,
+		tokenTTL: config.TokenTTL,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// CreateKey returns the full key, which is never shown again: only its
+// prefix and hash are stored.
+func (s *apiKeyService) CreateKey(user *models.User, req models.ApiKeyRequest) (*dto.ApiKeyCreatedResponse, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := models.ApiKeyPrefix + randomString(prefixLength)
+	key := prefix + "_" + randomString(32)
+	apiKey := &models.ApiKey{
+		UserID:  user.ID,
+		Name:    strings.TrimSpace(req.Name),
+		Prefix:  prefix,
+		KeyHash: hashSecret(key),
+		Scopes:  scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := s.now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.CreateKey(apiKey); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("User %d created API key %s", user.ID, prefix)
+	return &dto.ApiKeyCreatedResponse{ApiKey: *apiKey, Key: key}, nil
+}
+
+func (s *apiKeyService) ListKeys(user *models.User) ([]models.ApiKey, error) {
+	return s.repo.ListUserKeys(user.ID)
+}
+
+func (s *apiKeyService) RevokeKey(user *models.User, id uint) error {
+	err := s.repo.RevokeKey(user.ID, id, s.now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrApiKeyNotFound
+	}
+	return err
+}
+
+// CreateClient returns the client secret, which is never shown again.
+func (s *apiKeyService) CreateClient(user *models.User, req models.OAuthClientRequest) (*dto.OAuthClientCreatedResponse, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := randomString(32)
+	client := &models.OAuthClient{
+		UserID:     user.ID,
+		Name:       strings.TrimSpace(req.Name),
+		ClientID:   models.OAuthClientIDPrefix + randomHex(16),
+		SecretHash: hashSecret(secret),
+		Scopes:     scopes,
+	}
+	if err := s.repo.CreateClient(client); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("User %d created OAuth client %s", user.ID, client.ClientID)
+	return &dto.OAuthClientCreatedResponse{OAuthClient: *client, ClientSecret: secret}, nil
+}
+
+func (s *apiKeyService) ListClients(user *models.User) ([]models.OAuthClient, error) {
+	return s.repo.ListUserClients(user.ID)
+}
+
+// RevokeClient also invalidates the client's outstanding access tokens,
+// since every request re-checks the client.
+func (s *apiKeyService) RevokeClient(user *models.User, id uint) error {
+	err := s.repo.RevokeClient(user.ID, id, s.now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOAuthClientNotFound
+	}
+	return err
+}
+
+// IssueToken implements the client-credentials grant. Without a scope the
+// token carries every scope of the client; a requested scope must be a
+// subset of them.
+func (s *apiKeyService) IssueToken(req models.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	if req.GrantType != GrantClientCredentials {
+		return nil, ErrUnsupportedGrantType
+	}
+	if req.ClientID == "" || req.ClientSecret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := s.repo.GetClientByClientID(req.ClientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.RevokedAt != nil || !secretMatches(req.ClientSecret, client.SecretHash) {
+		return nil, ErrInvalidClient
+	}
+
+	scope := client.Scopes
+	if requested := strings.Fields(req.Scope); len(requested) > 0 {
+		allowed := strings.Fields(client.Scopes)
+		for _, r := range requested {
+			if !contains(allowed, r) {
+				return nil, ErrInvalidScope.Detailf("invalid scope: %s", r)
+			}
+		}
+		scope = strings.Join(requested, " ")
+	}
+
+	token, err := s.tokens.GenerateClientToken(client.ClientID, scope, s.tokenTTL)
+	if err != nil {
+		s.logger.Errorln("Layer: apikey_service, Method: IssueToken, Error:", err)
+		return nil, err
+	}
+	return &dto.OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   TokenTypeBearer,
+		ExpiresIn:   int(s.tokenTTL.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// AuthenticateAPIKey resolves a key to its owner. Keys are looked up by
+// prefix and then compared by hash in constant time.
+func (s *apiKeyService) AuthenticateAPIKey(key string) (*jwt.Principal, error) {
+	prefix, ok := keyPrefix(key)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	apiKey, err := s.repo.GetKeyByPrefix(prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if !secretMatches(key, apiKey.KeyHash) || apiKey.RevokedAt != nil ||
+		(apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.repo.TouchKey(apiKey.ID, now, now.Add(-touchInterval)); err != nil {
+		s.logger.Warnln("Layer: apikey_service, Method: AuthenticateAPIKey, Error:", err)
+	}
+	// Keys are checked on every request, so a password change or logout
+	// everywhere never invalidates them: only revocation does.
+	return &jwt.Principal{
+		Email:    apiKey.User.Email,
+		Scopes:   strings.Fields(apiKey.Scopes),
+		IssuedAt: now.Unix(),
+	}, nil
+}
+
+// AuthenticateClientToken resolves an access token to the client's owner,
+// rejecting tokens of clients revoked since they were issued.
+func (s *apiKeyService) AuthenticateClientToken(token string) (*jwt.Principal, error) {
+	clientID, scope, _, err := s.tokens.ParseClientToken(token)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	client, err := s.repo.GetClientByClientID(clientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.RevokedAt != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &jwt.Principal{
+		Email:    client.User.Email,
+		Scopes:   strings.Fields(scope),
+		IssuedAt: s.now().Unix(),
+	}, nil
+}
+
+// normalizeScopes validates scopes and returns them de-duplicated and space
+// separated, in the order of models.AllScopes.
+func normalizeScopes(scopes []string) (string, error) {
+	requested := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !contains(models.AllScopes, scope) {
+			return "", ErrInvalidScope.Detailf("invalid scope: %q", scope)
+		}
+		requested[scope] = true
+	}
+
+	var ordered []string
+	for _, scope := range models.AllScopes {
+		if requested[scope] {
+			ordered = append(ordered, scope)
+		}
+	}
+	if len(ordered) == 0 {
+		return "", ErrInvalidScope
+	}
+	return strings.Join(ordered, " "), nil
+}
+
+// keyPrefix extracts the "vk_xxxxxxxx" part of "vk_xxxxxxxx_<secret>".
+func keyPrefix(key string) (string, bool) {
+	if !strings.HasPrefix(key, models.ApiKeyPrefix) {
+		return "", false
+	}
+	end := len(models.ApiKeyPrefix) + prefixLength
+	if len(key) <= end+1 || key[end] != '_' {
+		return "", false
+	}
+	return key[:end], true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func secretMatches(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(hash)) == 1
+}
+
+// randomString returns n URL-safe random characters without '_', so the
+// key separator stays unambiguous.
+func randomString(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(buf)
+	return strings.ReplaceAll(encoded, "_", "-")[:n]
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,179 @@
+package services_apikey
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var fixedNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func newService(repoMock *ApiKeysRepoMock, tokens *ClientTokensMock) *apiKeyService {
+	svc := NewApiKeyService(repoMock, tokens, ApiKeyConfig{}, logrus.New())
+	svc.now = func() time.Time { return fixedNow }
+	return svc
+}
+
+func TestCreateKey_StoresOnlyHashAndNormalizesScopes(t *testing.T) {
+	repoMock := new(ApiKeysRepoMock)
+	svc := newService(repoMock, new(ClientTokensMock))
+
+	var stored *models.ApiKey
+	repoMock.On("CreateKey", mock.AnythingOfType("*models.ApiKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*models.ApiKey) }).
+		Return(nil)
+
+	created, err := svc.CreateKey(&models.User{Model: gorm.Model{ID: 7}}, models.ApiKeyRequest{
+		Name:          " ERP ",
+		Scopes:        []string{models.ScopeOrdersRead, models.ScopeProductsRead, models.ScopeOrdersRead},
+		ExpiresInDays: 30,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(created.Key, stored.Prefix+"_"))
+	assert.Len(t, stored.Prefix, len(models.ApiKeyPrefix)+prefixLength)
+	assert.Equal(t, hashSecret(created.Key), stored.KeyHash)
+	assert.NotContains(t, stored.KeyHash, created.Key)
+	assert.Equal(t, "products:read orders:read", stored.Scopes)
+	assert.Equal(t, "ERP", stored.Name)
+	assert.Equal(t, uint(7), stored.UserID)
+	require.NotNil(t, stored.ExpiresAt)
+	assert.True(t, stored.ExpiresAt.Equal(fixedNow.AddDate(0, 0, 30)))
+}
+
+func TestCreateKey_RejectsUnknownScope(t *testing.T) {
+	repoMock := new(ApiKeysRepoMock)
+	svc := newService(repoMock, new(ClientTokensMock))
+
+	_, err := svc.CreateKey(&models.User{Model: gorm.Model{ID: 7}}, models.ApiKeyRequest{Name: "ERP", Scopes: []string{"admin"}})
+	assert.ErrorIs(t, err, ErrInvalidScope)
+	repoMock.AssertNotCalled(t, "CreateKey", mock.Anything)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	key := "vk_abcd1234_secretsecretsecret"
+	expired := fixedNow.Add(-time.Second)
+	revoked := fixedNow.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		key     string
+		stored  *models.ApiKey
+		wantErr bool
+	}{
+		{name: "valid", key: key, stored: &models.ApiKey{ID: 1, KeyHash: hashSecret(key)}},
+		{name: "wrong secret", key: "vk_abcd1234_other", stored: &models.ApiKey{ID: 1, KeyHash: hashSecret(key)}, wantErr: true},
+		{name: "expired", key: key, stored: &models.ApiKey{ID: 1, KeyHash: hashSecret(key), ExpiresAt: &expired}, wantErr: true},
+		{name: "revoked", key: key, stored: &models.ApiKey{ID: 1, KeyHash: hashSecret(key), RevokedAt: &revoked}, wantErr: true},
+		{name: "malformed", key: "vk_short", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoMock := new(ApiKeysRepoMock)
+			svc := newService(repoMock, new(ClientTokensMock))
+			if tt.stored != nil {
+				tt.stored.Scopes = "products:read orders:read"
+				tt.stored.User = models.User{Email: "ana@example.com"}
+				repoMock.On("GetKeyByPrefix", "vk_abcd1234").Return(tt.stored, nil)
+				repoMock.On("TouchKey", uint(1), fixedNow, fixedNow.Add(-touchInterval)).Return(nil)
+			}
+
+			principal, err := svc.AuthenticateAPIKey(tt.key)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCredentials)
+				repoMock.AssertNotCalled(t, "TouchKey", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "ana@example.com", principal.Email)
+			assert.Equal(t, []string{"products:read", "orders:read"}, principal.Scopes)
+			repoMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIssueToken(t *testing.T) {
+	client := &models.OAuthClient{ClientID: "vc_1", SecretHash: hashSecret("s3cret"), Scopes: "warehouse:read warehouse:write"}
+
+	t.Run("grants requested subset", func(t *testing.T) {
+		repoMock := new(ApiKeysRepoMock)
+		tokens := new(ClientTokensMock)
+		svc := newService(repoMock, tokens)
+		repoMock.On("GetClientByClientID", "vc_1").Return(client, nil)
+		tokens.On("GenerateClientToken", "vc_1", "warehouse:read", defaultTokenTTL).Return("tok", nil)
+
+		resp, err := svc.IssueToken(models.OAuthTokenRequest{GrantType: GrantClientCredentials, ClientID: "vc_1", ClientSecret: "s3cret", Scope: "warehouse:read"})
+		require.NoError(t, err)
+		assert.Equal(t, "tok", resp.AccessToken)
+		assert.Equal(t, TokenTypeBearer, resp.TokenType)
+		assert.Equal(t, 3600, resp.ExpiresIn)
+		assert.Equal(t, "warehouse:read", resp.Scope)
+	})
+
+	t.Run("rejects scope beyond the client", func(t *testing.T) {
+		repoMock := new(ApiKeysRepoMock)
+		svc := newService(repoMock, new(ClientTokensMock))
+		repoMock.On("GetClientByClientID", "vc_1").Return(client, nil)
+
+		_, err := svc.IssueToken(models.OAuthTokenRequest{GrantType: GrantClientCredentials, ClientID: "vc_1", ClientSecret: "s3cret", Scope: "orders:write"})
+		assert.ErrorIs(t, err, ErrInvalidScope)
+	})
+
+	t.Run("rejects wrong secret and unknown client", func(t *testing.T) {
+		repoMock := new(ApiKeysRepoMock)
+		svc := newService(repoMock, new(ClientTokensMock))
+		repoMock.On("GetClientByClientID", "vc_1").Return(client, nil)
+		repoMock.On("GetClientByClientID", "vc_2").Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.IssueToken(models.OAuthTokenRequest{GrantType: GrantClientCredentials, ClientID: "vc_1", ClientSecret: "nope"})
+		assert.ErrorIs(t, err, ErrInvalidClient)
+		_, err = svc.IssueToken(models.OAuthTokenRequest{GrantType: GrantClientCredentials, ClientID: "vc_2", ClientSecret: "s3cret"})
+		assert.ErrorIs(t, err, ErrInvalidClient)
+	})
+
+	t.Run("rejects other grants", func(t *testing.T) {
+		svc := newService(new(ApiKeysRepoMock), new(ClientTokensMock))
+		_, err := svc.IssueToken(models.OAuthTokenRequest{GrantType: "password"})
+		assert.ErrorIs(t, err, ErrUnsupportedGrantType)
+	})
+}
+
+func TestAuthenticateClientToken_RejectsRevokedClient(t *testing.T) {
+	repoMock := new(ApiKeysRepoMock)
+	tokens := new(ClientTokensMock)
+	svc := newService(repoMock, tokens)
+	revoked := fixedNow.Add(-time.Minute)
+
+	tokens.On("ParseClientToken", "good").Return("vc_1", "warehouse:read", fixedNow, nil)
+	tokens.On("ParseClientToken", "revoked").Return("vc_2", "warehouse:read", fixedNow, nil)
+	tokens.On("ParseClientToken", "forged").Return("", "", time.Time{}, errors.New("bad signature"))
+	repoMock.On("GetClientByClientID", "vc_1").Return(&models.OAuthClient{User: models.User{Email: "ana@example.com"}}, nil)
+	repoMock.On("GetClientByClientID", "vc_2").Return(&models.OAuthClient{RevokedAt: &revoked}, nil)
+
+	principal, err := svc.AuthenticateClientToken("good")
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", principal.Email)
+	assert.Equal(t, []string{"warehouse:read"}, principal.Scopes)
+
+	_, err = svc.AuthenticateClientToken("revoked")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = svc.AuthenticateClientToken("forged")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestRevokeKey_MapsNotFound(t *testing.T) {
+	repoMock := new(ApiKeysRepoMock)
+	svc := newService(repoMock, new(ClientTokensMock))
+	repoMock.On("RevokeKey", uint(7), uint(3), fixedNow).Return(gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, svc.RevokeKey(&models.User{Model: gorm.Model{ID: 7}}, 3), ErrApiKeyNotFound)
+}
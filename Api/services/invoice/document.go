@@ -0,0 +1,131 @@
+package services_invoice
+
+import (
+	"math"
+	"pruebaVertice/Api/models"
+	"sort"
+	"time"
+)
+
+// Config describes the issuer printed on every invoice and the tax rate of
+// each product tax category. Prices are tax inclusive, so the tax is broken
+// out of the amounts the customer paid.
+type Config struct {
+	IssuerName    string
+	IssuerTaxID   string
+	IssuerAddress string
+	TaxRates      map[string]float64
+}
+
+// DefaultTaxRates applies the standard VAT rate to everything that is not
+// explicitly exempt.
+func DefaultTaxRates(standard float64) map[string]float64 {
+	return map[string]float64{
+		models.TaxCategoryStandard: standard,
+		models.TaxCategoryExempt:   0,
+	}
+}
+
+type invoiceLine struct {
+	Description string
+	SKU         string
+	Quantity    int
+	UnitPrice   float64
+	TaxRate     float64
+	Net         float64
+	Tax         float64
+	Total       float64
+}
+
+type taxBreakdown struct {
+	Rate float64
+	Net  float64
+	Tax  float64
+}
+
+type invoiceDocument struct {
+	Issuer         Config
+	Code           string
+	IssuedAt       time.Time
+	OrderID        uint
+	OrderDate      time.Time
+	Customer       models.PostalAddress
+	Lines          []invoiceLine
+	ShippingMethod string
+	Shipping       invoiceLine
+	Taxes          []taxBreakdown
+	NetAmount      float64
+	TaxAmount      float64
+	Total          float64
+}
+
+// buildDocument computes every figure shown on the invoice from the order.
+func buildDocument(config Config, order *models.Order, invoice *models.Invoice) invoiceDocument {
+	doc := invoiceDocument{
+		Issuer:         config,
+		Code:           invoice.Code,
+		IssuedAt:       invoice.IssuedAt,
+		OrderID:        order.ID,
+		OrderDate:      order.CreatedAt,
+		Customer:       order.ShippingAddress,
+		ShippingMethod: order.ShippingMethod,
+	}
+
+	byRate := map[float64]*taxBreakdown{}
+	add := func(line invoiceLine) {
+		b, ok := byRate[line.TaxRate]
+		if !ok {
+			b = &taxBreakdown{Rate: line.TaxRate}
+			byRate[line.TaxRate] = b
+		}
+		b.Net = round2(b.Net + line.Net)
+		b.Tax = round2(b.Tax + line.Tax)
+		doc.NetAmount = round2(doc.NetAmount + line.Net)
+		doc.TaxAmount = round2(doc.TaxAmount + line.Tax)
+		doc.Total = round2(doc.Total + line.Total)
+	}
+
+	for _, item := range order.OrderItems {
+		line := newLine(item.Quantity, item.UnitPrice, config.rate(item.Snapshot.TaxCategory))
+		line.Description = item.Snapshot.Name
+		line.SKU = item.Snapshot.SKU
+		doc.Lines = append(doc.Lines, line)
+		add(line)
+	}
+	if order.ShippingCost > 0 {
+		doc.Shipping = newLine(1, order.ShippingCost, config.rate(models.TaxCategoryStandard))
+		doc.Shipping.Description = "Envío " + order.ShippingMethod
+		add(doc.Shipping)
+	}
+
+	for _, b := range byRate {
+		doc.Taxes = append(doc.Taxes, *b)
+	}
+	sort.Slice(doc.Taxes, func(i, j int) bool { return doc.Taxes[i].Rate > doc.Taxes[j].Rate })
+	return doc
+}
+
+func newLine(quantity int, unitPrice, rate float64) invoiceLine {
+	total := round2(unitPrice * float64(quantity))
+	net := round2(total / (1 + rate))
+	return invoiceLine{
+		Quantity:  quantity,
+		UnitPrice: unitPrice,
+		TaxRate:   rate,
+		Net:       net,
+		Tax:       round2(total - net),
+		Total:     total,
+	}
+}
+
+// rate falls back to the standard rate for unknown categories.
+func (c Config) rate(category string) float64 {
+	if rate, ok := c.TaxRates[category]; ok {
+		return rate
+	}
+	return c.TaxRates[models.TaxCategoryStandard]
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,102 @@
+package services_invoice
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/invoices_repo"
+	ordersRepo "pruebaVertice/Api/repo/orders_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotFound       = apperr.NotFound("order_not_found", "order not found")
+	ErrOrderNotInvoiceable = apperr.Conflict("order_not_invoiceable", "cancelled orders cannot be invoiced")
+)
+
+type InvoiceService interface {
+	GetUserInvoice(userID, orderID uint) (*models.Invoice, error)
+}
+
+type invoiceService struct {
+	repo      repo.InvoicesRepository
+	orderRepo ordersRepo.OrdersRepository
+	config    Config
+	logger    *logrus.Logger
+	now       func() time.Time
+}
+
+func NewInvoiceService(repo repo.InvoicesRepository, orderRepo ordersRepo.OrdersRepository, config Config, logger *logrus.Logger) *invoiceService {
+	return &invoiceService{
+		repo:      repo,
+		orderRepo: orderRepo,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// GetUserInvoice returns the invoice of an order owned by userID, issuing it
+// on first request. Later requests return the stored documents unchanged.
+func (s *invoiceService) GetUserInvoice(userID, orderID uint) (*models.Invoice, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		s.logger.Errorln("Layer: invoice_service, Method: GetUserInvoice, Error:", err)
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+
+	invoice, err := s.repo.GetInvoiceByOrderID(orderID)
+	if err == nil {
+		return invoice, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Errorln("Layer: invoice_service, Method: GetUserInvoice, Error:", err)
+		return nil, err
+	}
+
+	if order.Status == models.OrderStatusCancelled {
+		return nil, ErrOrderNotInvoiceable
+	}
+
+	invoice, err = s.repo.IssueInvoice(&models.Invoice{
+		OrderID:  order.ID,
+		UserID:   order.UserID,
+		IssuedAt: s.now().Truncate(time.Second),
+	}, func(invoice *models.Invoice) error {
+		return s.render(order, invoice)
+	})
+	if err != nil {
+		// Another request may have issued it concurrently.
+		if existing, getErr := s.repo.GetInvoiceByOrderID(orderID); getErr == nil {
+			return existing, nil
+		}
+		s.logger.Errorln("Layer: invoice_service, Method: GetUserInvoice, Error:", err)
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// render fills the amounts and both documents once the number is known.
+func (s *invoiceService) render(order *models.Order, invoice *models.Invoice) error {
+	doc := buildDocument(s.config, order, invoice)
+	html, err := renderHTML(doc)
+	if err != nil {
+		return err
+	}
+	invoice.NetAmount = doc.NetAmount
+	invoice.TaxAmount = doc.TaxAmount
+	invoice.ShippingCost = order.ShippingCost
+	invoice.Total = doc.Total
+	invoice.HTML = html
+	invoice.PDF = renderPDF(doc)
+	return nil
+}
@@ -0,0 +1,155 @@
+package services_invoice
+
+import (
+	"bytes"
+	"errors"
+	"pruebaVertice/Api/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testConfig = Config{
+	IssuerName:  "Tienda Vértice",
+	IssuerTaxID: "76.123.456-7",
+	TaxRates:    DefaultTaxRates(0.19),
+}
+
+func testOrder() *models.Order {
+	return &models.Order{
+		ID:             7,
+		UserID:         1,
+		Status:         models.OrderStatusPlaced,
+		ShippingMethod: "flat",
+		ShippingCost:   5.95,
+		Subtotal:       259,
+		Total:          264.95,
+		ShippingAddress: models.PostalAddress{
+			Recipient: "Ana (casa)",
+			Line1:     "Calle 1",
+			City:      "Santiago",
+			Country:   "CL",
+		},
+		OrderItems: []models.OrderProduct{
+			{ID: 1, Quantity: 2, UnitPrice: 119.5, Snapshot: models.ProductSnapshot{Name: "Teclado", SKU: "KB-1", TaxCategory: models.TaxCategoryStandard}},
+			{ID: 2, Quantity: 1, UnitPrice: 20, Snapshot: models.ProductSnapshot{Name: "Libro", TaxCategory: models.TaxCategoryExempt}},
+		},
+	}
+}
+
+func newService(repoMock *InvoicesRepoMock, orderMock *OrdersRepoMock) *invoiceService {
+	svc := NewInvoiceService(repoMock, orderMock, testConfig, logrus.New())
+	svc.now = func() time.Time { return time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestGetUserInvoice_IssuesOnFirstRequest(t *testing.T) {
+	repoMock := new(InvoicesRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := newService(repoMock, orderMock)
+
+	orderMock.On("GetOrderByID", uint(7)).Return(testOrder(), nil)
+	repoMock.On("GetInvoiceByOrderID", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+	repoMock.On("IssueInvoice", mock.MatchedBy(func(i *models.Invoice) bool {
+		return i.OrderID == 7 && i.UserID == 1
+	})).Return(uint(12), nil)
+
+	invoice, err := svc.GetUserInvoice(1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "F-000012", invoice.Code)
+	assert.Equal(t, 264.95, invoice.Total)
+	// 239 + 5.95 carry 19% VAT; 20 is exempt.
+	assert.Equal(t, 39.11, invoice.TaxAmount)
+	assert.Equal(t, 225.84, invoice.NetAmount)
+	assert.Contains(t, invoice.HTML, "Factura F-000012")
+	assert.Contains(t, invoice.HTML, "Ana (casa)")
+	assert.True(t, bytes.HasPrefix(invoice.PDF, []byte("%PDF-1.4")))
+	assert.Contains(t, string(invoice.PDF), `Ana \(casa\)`)
+}
+
+func TestGetUserInvoice_ReturnsStoredDocument(t *testing.T) {
+	repoMock := new(InvoicesRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := newService(repoMock, orderMock)
+
+	stored := &models.Invoice{ID: 1, OrderID: 7, Number: 3, PDF: []byte("%PDF-stored")}
+	orderMock.On("GetOrderByID", uint(7)).Return(testOrder(), nil)
+	repoMock.On("GetInvoiceByOrderID", uint(7)).Return(stored, nil)
+
+	invoice, err := svc.GetUserInvoice(1, 7)
+	assert.NoError(t, err)
+	assert.Same(t, stored, invoice)
+	repoMock.AssertNotCalled(t, "IssueInvoice", mock.Anything)
+}
+
+func TestGetUserInvoice_OtherUser(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := newService(new(InvoicesRepoMock), orderMock)
+
+	orderMock.On("GetOrderByID", uint(7)).Return(testOrder(), nil)
+	_, err := svc.GetUserInvoice(2, 7)
+	assert.Equal(t, ErrOrderNotFound, err)
+}
+
+func TestGetUserInvoice_LookupError(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := newService(new(InvoicesRepoMock), orderMock)
+
+	dbErr := errors.New("connection refused")
+	orderMock.On("GetOrderByID", uint(7)).Return(nil, dbErr)
+	_, err := svc.GetUserInvoice(1, 7)
+	assert.Equal(t, dbErr, err)
+}
+
+func TestGetUserInvoice_CancelledOrder(t *testing.T) {
+	repoMock := new(InvoicesRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := newService(repoMock, orderMock)
+
+	order := testOrder()
+	order.Status = models.OrderStatusCancelled
+	orderMock.On("GetOrderByID", uint(7)).Return(order, nil)
+	repoMock.On("GetInvoiceByOrderID", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.GetUserInvoice(1, 7)
+	assert.Equal(t, ErrOrderNotInvoiceable, err)
+}
+
+func TestGetUserInvoice_ConcurrentIssue(t *testing.T) {
+	repoMock := new(InvoicesRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := newService(repoMock, orderMock)
+
+	stored := &models.Invoice{ID: 1, OrderID: 7, Number: 3}
+	orderMock.On("GetOrderByID", uint(7)).Return(testOrder(), nil)
+	repoMock.On("GetInvoiceByOrderID", uint(7)).Return(nil, gorm.ErrRecordNotFound).Once()
+	repoMock.On("IssueInvoice", mock.Anything).Return(uint(0), errors.New("duplicate"))
+	repoMock.On("GetInvoiceByOrderID", uint(7)).Return(stored, nil).Once()
+
+	invoice, err := svc.GetUserInvoice(1, 7)
+	assert.NoError(t, err)
+	assert.Same(t, stored, invoice)
+}
+
+func TestRenderPDF_IsDeterministic(t *testing.T) {
+	invoice := &models.Invoice{Code: "F-000001", IssuedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	doc := buildDocument(testConfig, testOrder(), invoice)
+
+	first := renderPDF(doc)
+	assert.Equal(t, first, renderPDF(doc))
+	assert.True(t, strings.HasSuffix(string(first), "%%EOF\n"))
+	// "é" of Vértice is written in WinAnsi octal form.
+	assert.Contains(t, string(first), `V\351rtice`)
+}
+
+func TestPercent(t *testing.T) {
+	assert.Equal(t, "19%", percent(0.19))
+	assert.Equal(t, "0%", percent(0))
+	assert.Equal(t, "10.5%", percent(0.105))
+}
@@ -0,0 +1,83 @@
+package services_invoice
+
+import (
+	"fmt"
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// InvoicesRepoMock mocks repo.InvoicesRepository for service tests.
+// IssueInvoice assigns the number given in Return and runs the render
+// callback like the real repository does.
+type InvoicesRepoMock struct {
+	mock.Mock
+}
+
+func (m *InvoicesRepoMock) GetInvoiceByOrderID(orderID uint) (*models.Invoice, error) {
+	args := m.Called(orderID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Invoice), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *InvoicesRepoMock) IssueInvoice(invoice *models.Invoice, render func(*models.Invoice) error) (*models.Invoice, error) {
+	args := m.Called(invoice)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	invoice.Number = args.Get(0).(uint)
+	invoice.Code = fmt.Sprintf("F-%06d", invoice.Number)
+	if err := render(invoice); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// OrdersRepoMock mocks repo.OrdersRepository for service tests.
+type OrdersRepoMock struct {
+	mock.Mock
+}
+
+func (m *OrdersRepoMock) CreateOrder(order *models.Order) (*models.Order, error) {
+	args := m.Called(order)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Order), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersRepoMock) GetOrdersByUserID(userID uint) ([]models.Order, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Order), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersRepoMock) FindUserOrders(userID uint, filter models.OrderFilter) ([]models.Order, int64, error) {
+	args := m.Called(userID, filter)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Order), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, 0, args.Error(2)
+}
+
+func (m *OrdersRepoMock) GetOrderByID(id uint) (*models.Order, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Order), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersRepoMock) UpdateOrderStatus(id uint, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *OrdersRepoMock) CancelOrder(order *models.Order) error {
+	args := m.Called(order)
+	return args.Error(0)
+}
@@ -0,0 +1,149 @@
+package services_invoice
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"pruebaVertice/Api/models"
+	"strconv"
+	"strings"
+)
+
+const dateLayout = "02-01-2006"
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money":   money,
+	"percent": percent,
+	"date":    func(t interface{ Format(string) string }) string { return t.Format(dateLayout) },
+	"address": addressLines,
+}).Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>Factura {{.Code}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 12px; margin: 40px; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 4px; border-bottom: 1px solid #ddd; text-align: left; }
+td.num, th.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Factura {{.Code}}</h1>
+<p><strong>{{.Issuer.IssuerName}}</strong><br>RUT: {{.Issuer.IssuerTaxID}}<br>{{.Issuer.IssuerAddress}}</p>
+<p>Fecha de emisión: {{date .IssuedAt}}<br>Orden #{{.OrderID}} del {{date .OrderDate}}</p>
+<h2>Cliente</h2>
+<p>{{range address .Customer}}{{.}}<br>{{end}}</p>
+<table>
+<thead><tr><th>Descripción</th><th>SKU</th><th class="num">Cant.</th><th class="num">Precio unit.</th><th class="num">IVA</th><th class="num">Total</th></tr></thead>
+<tbody>
+{{range .Lines}}<tr><td>{{.Description}}</td><td>{{.SKU}}</td><td class="num">{{.Quantity}}</td><td class="num">{{money .UnitPrice}}</td><td class="num">{{percent .TaxRate}}</td><td class="num">{{money .Total}}</td></tr>
+{{end}}{{if .Shipping.Total}}<tr><td>{{.Shipping.Description}}</td><td></td><td class="num">1</td><td class="num">{{money .Shipping.UnitPrice}}</td><td class="num">{{percent .Shipping.TaxRate}}</td><td class="num">{{money .Shipping.Total}}</td></tr>
+{{end}}</tbody>
+</table>
+<table>
+<tr><td>Neto</td><td class="num">{{money .NetAmount}}</td></tr>
+{{range .Taxes}}<tr><td>IVA {{percent .Rate}} sobre {{money .Net}}</td><td class="num">{{money .Tax}}</td></tr>
+{{end}}<tr><th>Total</th><th class="num">{{money .Total}}</th></tr>
+</table>
+</body>
+</html>
+`))
+
+func renderHTML(doc invoiceDocument) (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func renderPDF(doc invoiceDocument) []byte {
+	w := newPDFWriter()
+	w.row(18, true, pdfCell{0, "Factura " + doc.Code})
+	w.space(6)
+	w.row(10, true, pdfCell{0, doc.Issuer.IssuerName})
+	w.row(10, false, pdfCell{0, "RUT: " + doc.Issuer.IssuerTaxID})
+	w.row(10, false, pdfCell{0, doc.Issuer.IssuerAddress})
+	w.space(6)
+	w.row(10, false, pdfCell{0, "Fecha de emisión: " + doc.IssuedAt.Format(dateLayout)})
+	w.row(10, false, pdfCell{0, fmt.Sprintf("Orden #%d del %s", doc.OrderID, doc.OrderDate.Format(dateLayout))})
+	w.space(6)
+	w.row(11, true, pdfCell{0, "Cliente"})
+	for _, line := range addressLines(doc.Customer) {
+		w.row(10, false, pdfCell{0, line})
+	}
+	w.space(10)
+
+	columns := []float64{0, 210, 290, 330, 400, 440}
+	cells := func(values ...string) []pdfCell {
+		out := make([]pdfCell, len(values))
+		for i, v := range values {
+			out[i] = pdfCell{columns[i], v}
+		}
+		return out
+	}
+	w.row(10, true, cells("Descripción", "SKU", "Cant.", "Precio unit.", "IVA", "Total")...)
+	w.rule()
+	lines := doc.Lines
+	if doc.Shipping.Total > 0 {
+		lines = append(append([]invoiceLine{}, lines...), doc.Shipping)
+	}
+	for _, l := range lines {
+		w.row(10, false, cells(truncate(l.Description, 40), l.SKU, fmt.Sprint(l.Quantity),
+			money(l.UnitPrice), percent(l.TaxRate), money(l.Total))...)
+	}
+	w.rule()
+	w.space(6)
+
+	w.row(10, false, pdfCell{330, "Neto"}, pdfCell{440, money(doc.NetAmount)})
+	for _, t := range doc.Taxes {
+		w.row(10, false, pdfCell{330, "IVA " + percent(t.Rate)}, pdfCell{440, money(t.Tax)})
+	}
+	w.row(12, true, pdfCell{330, "Total"}, pdfCell{440, money(doc.Total)})
+	return w.bytes()
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
+
+func percent(rate float64) string {
+	return strconv.FormatFloat(round2(rate*100), 'f', -1, 64) + "%"
+}
+
+// addressLines formats a postal address the way it is printed on the
+// invoice, skipping empty parts.
+func addressLines(a models.PostalAddress) []string {
+	var lines []string
+	for _, line := range []string{
+		a.Recipient,
+		a.Line1,
+		a.Line2,
+		strings.TrimSpace(strings.Join(nonEmpty(a.PostalCode, a.City, a.State), " ")),
+		a.Country,
+	} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,36 @@
+package services_notifier
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileNotifier appends every notification to a plain text file, so emails
+// such as password resets can be read during local development.
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path, now: time.Now}
+}
+
+func (n *FileNotifier) Notify(notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n----\n",
+		n.now().Format(time.RFC1123Z), notification.To, notification.Subject, notification.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
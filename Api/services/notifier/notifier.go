@@ -0,0 +1,63 @@
+package services_notifier
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Notification is a message addressed to a single user.
+type Notification struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers notifications to users. Implementations must be safe for
+// concurrent use.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// LogNotifier writes notifications to the application log instead of sending
+// them. It is used when no mail server is configured.
+type LogNotifier struct {
+	Logger *logrus.Logger
+}
+
+func (n LogNotifier) Notify(notification Notification) error {
+	n.Logger.WithFields(logrus.Fields{
+		"to":      notification.To,
+		"subject": notification.Subject,
+	}).Info("Layer: log_notifier, Method: Notify, notification not sent, no mail server configured")
+	return nil
+}
+
+// MemoryNotifier keeps every notification in memory, for tests and local
+// development.
+type MemoryNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+	Err  error
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (n *MemoryNotifier) Notify(notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.Err != nil {
+		return n.Err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// Sent returns a copy of the notifications delivered so far.
+func (n *MemoryNotifier) Sent() []Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Notification(nil), n.sent...)
+}
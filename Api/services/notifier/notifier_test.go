@@ -0,0 +1,124 @@
+package services_notifier
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryNotifier_RecordsNotifications(t *testing.T) {
+	n := NewMemoryNotifier()
+	require.NoError(t, n.Notify(Notification{To: "a@b.c", Subject: "Hola"}))
+
+	sent := n.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "a@b.c", sent[0].To)
+
+	n.Err = errors.New("down")
+	assert.Error(t, n.Notify(Notification{To: "a@b.c"}))
+	assert.Len(t, n.Sent(), 1)
+}
+
+// fakeSMTPServer accepts a single mail transaction and returns what it got.
+func fakeSMTPServer(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+		var transcript strings.Builder
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				transcript.WriteString(strings.TrimSpace(line) + "\n")
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					transcript.WriteString(data)
+				}
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				received <- transcript.String()
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestSMTPNotifier_SendsMail(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	n := NewSMTPNotifier(SMTPConfig{Host: host, Port: port, From: "tienda@example.com"})
+	n.now = func() time.Time { return time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC) }
+
+	err := n.Notify(Notification{To: "cliente@example.com", Subject: "De vuelta en stock", Body: "Linea 1\nLinea 2"})
+	require.NoError(t, err)
+
+	select {
+	case got := <-received:
+		assert.Contains(t, got, "MAIL FROM:<tienda@example.com>")
+		assert.Contains(t, got, "RCPT TO:<cliente@example.com>")
+		assert.Contains(t, got, "Subject: De vuelta en stock\r\n")
+		assert.Contains(t, got, "Linea 1\r\nLinea 2\r\n")
+	case <-time.After(2 * time.Second):
+		t.Fatal("mail not received")
+	}
+}
+
+func TestSMTPNotifier_RejectsHeaderInjection(t *testing.T) {
+	n := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", From: "tienda@example.com"})
+	n.send = nil // must not be reached
+
+	err := n.Notify(Notification{To: "a@b.c\r\nBcc: x@y.z", Subject: "Hola"})
+	assert.ErrorIs(t, err, ErrInvalidNotification)
+}
+
+func TestFileNotifier_AppendsNotifications(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	n := NewFileNotifier(path)
+
+	require.NoError(t, n.Notify(Notification{To: "ana@example.com", Subject: "Uno", Body: "primero"}))
+	require.NoError(t, n.Notify(Notification{To: "luis@example.com", Subject: "Dos", Body: "segundo"}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "To: ana@example.com\nSubject: Uno\n\nprimero")
+	assert.Contains(t, string(content), "To: luis@example.com\nSubject: Dos\n\nsegundo")
+}
@@ -0,0 +1,64 @@
+package services_notifier
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidNotification = errors.New("invalid notification")
+
+// SMTPConfig holds the mail server used to send notifications. Username and
+// Password are optional; without them the server is used unauthenticated.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier sends notifications as plain text emails.
+type SMTPNotifier struct {
+	config SMTPConfig
+	send   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now    func() time.Time
+}
+
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	if config.Port == 0 {
+		config.Port = 25
+	}
+	return &SMTPNotifier{config: config, send: smtp.SendMail, now: time.Now}
+}
+
+func (n *SMTPNotifier) Notify(notification Notification) error {
+	if notification.To == "" || strings.ContainsAny(notification.To+notification.Subject, "\r\n") {
+		return fmt.Errorf("%w: recipient and subject must be single-line and non-empty", ErrInvalidNotification)
+	}
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	return n.send(addr, auth, n.config.From, []string{notification.To}, n.message(notification))
+}
+
+func (n *SMTPNotifier) message(notification Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", notification.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", notification.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", n.now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body := strings.ReplaceAll(notification.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
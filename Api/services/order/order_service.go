@@ -1,68 +1,128 @@
 package services_order
 
 import (
-	"fmt"
+	"errors"
+	"math"
+	"pruebaVertice/Api/dto"
 	"pruebaVertice/Api/models"
+	addressRepo "pruebaVertice/Api/repo/address_repo"
 	repo "pruebaVertice/Api/repo/orders_repo"
 	productsRepo "pruebaVertice/Api/repo/products_repo"
+	services_shipping "pruebaVertice/Api/services/shipping"
+	"pruebaVertice/Api/utils/apperr"
 
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var (
+	ErrOrderNotFound   = apperr.NotFound("order_not_found", "order not found")
+	ErrInvalidFilter   = apperr.BadRequest("invalid_order_filter", "invalid order filter")
+	ErrAddressRequired = apperr.Invalid("address_required", "a shipping address is required: send address_id or set a default address")
+	ErrAddressNotFound = apperr.NotFound("address_not_found", "shipping address not found")
+	ErrProductNotFound = apperr.NotFound("product_not_found", "product not found")
+	ErrUnknownShipping = services_shipping.ErrUnknownMethod
+
+	ErrInsufficientStock   = repo.ErrInsufficientStock
+	ErrOrderNotCancellable = repo.ErrOrderNotCancellable
+)
+
+var sortableColumns = map[string]bool{"created_at": true, "total": true, "status": true}
+
+var orderStatuses = map[string]bool{
+	models.OrderStatusPlaced:           true,
+	models.OrderStatusPartiallyShipped: true,
+	models.OrderStatusShipped:          true,
+	models.OrderStatusDelivered:        true,
+	models.OrderStatusCancelled:        true,
+}
+
 type OrdersService interface {
-	CreateOrder(userID uint, items []models.OrderProduct) (*models.Order, error)
-	GetUserOrders(userID uint) ([]models.Order, error)
+	CreateOrder(userID uint, req models.CreateOrderRequest) (*models.Order, error)
+	ShippingMethods() []string
+	GetUserOrders(userID uint, filter models.OrderFilter) (*dto.OrderListResponse, error)
+	GetUserOrder(userID, orderID uint) (*models.Order, error)
+	CancelOrder(userID, orderID uint) (*models.Order, error)
 }
 
 type ordersService struct {
 	orderRepo   repo.OrdersRepository
 	productRepo productsRepo.ProductsRepository
+	addressRepo addressRepo.AddressRepository
+	shipping    *services_shipping.Registry
 	logger      *logrus.Logger
 }
 
-func NewOrdersService(orderRepo repo.OrdersRepository, productRepo productsRepo.ProductsRepository, logger *logrus.Logger) *ordersService {
+func NewOrdersService(orderRepo repo.OrdersRepository, productRepo productsRepo.ProductsRepository, addressRepo addressRepo.AddressRepository, shipping *services_shipping.Registry, logger *logrus.Logger) *ordersService {
 	return &ordersService{
 		orderRepo:   orderRepo,
 		productRepo: productRepo,
+		addressRepo: addressRepo,
+		shipping:    shipping,
 		logger:      logger,
 	}
 }
 
-func (s *ordersService) CreateOrder(userID uint, items []models.OrderProduct) (*models.Order, error) {
-	var total float64
-	var orderItems []models.OrderProduct
+func (s *ordersService) CreateOrder(userID uint, req models.CreateOrderRequest) (*models.Order, error) {
+	address, err := s.resolveAddress(userID, req.AddressID)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, item := range items {
+	var subtotal, weight float64
+	products := make([]*models.Product, len(req.OrderItems))
+	for i, item := range req.OrderItems {
 		product, err := s.productRepo.GetProductByID(item.ProductID)
 		if err != nil {
-			return nil, fmt.Errorf("product with ID %d not found", item.ProductID)
+			return nil, ErrProductNotFound.Detailf("product with ID %d not found", item.ProductID)
 		}
 
 		if product.Stock < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product ID %d", item.ProductID)
+			return nil, ErrInsufficientStock.Detailf("insufficient stock for product ID %d", item.ProductID)
 		}
 
-		unitPrice := product.Price
-		total += unitPrice * float64(item.Quantity)
+		subtotal += product.Price * float64(item.Quantity)
+		weight += product.Weight * float64(item.Quantity)
+		products[i] = product
+	}
 
-		product.Stock -= item.Quantity
-		if err := s.productRepo.UpdateProduct(product); err != nil {
-			return nil, fmt.Errorf("failed to update stock for product ID %d", item.ProductID)
-		}
+	method, shippingCost, err := s.shipping.Rate(req.ShippingMethod, services_shipping.Quote{
+		Subtotal:    subtotal,
+		TotalWeight: weight,
+		Destination: address.PostalAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	var orderItems []models.OrderProduct
+	for i, item := range req.OrderItems {
 		orderItems = append(orderItems, models.OrderProduct{
 			ProductID: item.ProductID,
 			Quantity:  item.Quantity,
-			UnitPrice: unitPrice,
+			UnitPrice: products[i].Price,
+			Snapshot:  products[i].Snapshot(),
 		})
 	}
 
 	order := &models.Order{
-		UserID:     userID,
-		Total:      total,
-		OrderItems: orderItems,
+		UserID:          userID,
+		Status:          models.OrderStatusPlaced,
+		Subtotal:        subtotal,
+		ShippingMethod:  method,
+		ShippingCost:    shippingCost,
+		Total:           subtotal + shippingCost,
+		ShippingAddress: address.PostalAddress,
+		OrderItems:      orderItems,
 	}
 
+	// Stock is taken by the repository in the same transaction as the order,
+	// so a concurrent order for the last units still fails cleanly.
 	createdOrder, err := s.orderRepo.CreateOrder(order)
 	if err != nil {
 		return nil, err
@@ -70,6 +130,122 @@ func (s *ordersService) CreateOrder(userID uint, items []models.OrderProduct) (*
 	return createdOrder, nil
 }
 
-func (s *ordersService) GetUserOrders(userID uint) ([]models.Order, error) {
-	return s.orderRepo.GetOrdersByUserID(userID)
+func (s *ordersService) ShippingMethods() []string {
+	return s.shipping.Methods()
+}
+
+// resolveAddress returns the requested address if it belongs to userID, or
+// the user's default address when none is requested.
+func (s *ordersService) resolveAddress(userID uint, addressID *uint) (*models.Address, error) {
+	if addressID == nil {
+		address, err := s.addressRepo.GetDefaultAddress(userID)
+		if err != nil {
+			return nil, ErrAddressRequired
+		}
+		return address, nil
+	}
+
+	address, err := s.addressRepo.GetAddressByID(*addressID)
+	if err != nil || address.UserID != userID {
+		return nil, ErrAddressNotFound
+	}
+	return address, nil
+}
+
+func (s *ordersService) GetUserOrders(userID uint, filter models.OrderFilter) (*dto.OrderListResponse, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, count, err := s.orderRepo.FindUserOrders(userID, filter)
+	if err != nil {
+		s.logger.Errorln("Layer: order_service, Method: GetUserOrders, Error:", err)
+		return nil, err
+	}
+	if orders == nil {
+		orders = []models.Order{}
+	}
+
+	return &dto.OrderListResponse{
+		Items:      orders,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		TotalItems: count,
+		TotalPages: int(math.Ceil(float64(count) / float64(filter.PageSize))),
+	}, nil
+}
+
+// GetUserOrder returns the order only if it belongs to userID. Orders owned
+// by someone else are reported as not found so IDs cannot be probed.
+func (s *ordersService) GetUserOrder(userID, orderID uint) (*models.Order, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		s.logger.Errorln("Layer: order_service, Method: GetUserOrder, Error:", err)
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// CancelOrder cancels an order of userID that has not started shipping.
+// Its units go back into stock.
+func (s *ordersService) CancelOrder(userID, orderID uint) (*models.Order, error) {
+	order, err := s.GetUserOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderStatusPlaced {
+		return nil, ErrOrderNotCancellable
+	}
+
+	if err := s.orderRepo.CancelOrder(order); err != nil {
+		if !errors.Is(err, ErrOrderNotCancellable) {
+			s.logger.Errorln("Layer: order_service, Method: CancelOrder, Error:", err)
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+func normalizeFilter(filter models.OrderFilter) (models.OrderFilter, error) {
+	if filter.Status != "" && !orderStatuses[filter.Status] {
+		return filter, ErrInvalidFilter.Detailf("invalid order filter: unknown status %q", filter.Status)
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultPageSize
+	}
+	if filter.PageSize > maxPageSize {
+		filter.PageSize = maxPageSize
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = "created_at"
+	}
+	if !sortableColumns[filter.SortBy] {
+		return filter, ErrInvalidFilter.Detailf("invalid order filter: cannot sort by %q", filter.SortBy)
+	}
+	switch filter.SortDir {
+	case "":
+		filter.SortDir = "desc"
+	case "asc", "desc":
+	default:
+		return filter, ErrInvalidFilter.Detailf("invalid order filter: sort_dir must be 'asc' or 'desc'")
+	}
+
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return filter, ErrInvalidFilter.Detailf("invalid order filter: from must be before to")
+	}
+	if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+		return filter, ErrInvalidFilter.Detailf("invalid order filter: min_total must not exceed max_total")
+	}
+	return filter, nil
 }
@@ -2,12 +2,17 @@ package services_order
 
 import (
 	"errors"
+	"fmt"
 	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/repo/orders_repo"
+	services_shipping "pruebaVertice/Api/services/shipping"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // OrdersRepoMock mocks repo.OrdersRepository
@@ -31,6 +36,88 @@ func (m *OrdersRepoMock) GetOrdersByUserID(userID uint) ([]models.Order, error)
 	return nil, args.Error(1)
 }
 
+func (m *OrdersRepoMock) FindUserOrders(userID uint, filter models.OrderFilter) ([]models.Order, int64, error) {
+	args := m.Called(userID, filter)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Order), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, 0, args.Error(2)
+}
+
+func (m *OrdersRepoMock) GetOrderByID(id uint) (*models.Order, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Order), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersRepoMock) UpdateOrderStatus(id uint, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *OrdersRepoMock) CancelOrder(order *models.Order) error {
+	args := m.Called(order)
+	return args.Error(0)
+}
+
+// AddressRepoMock mocks repo.AddressRepository
+type AddressRepoMock struct {
+	mock.Mock
+}
+
+func (m *AddressRepoMock) GetAddressByID(id uint) (*models.Address, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AddressRepoMock) GetDefaultAddress(userID uint) (*models.Address, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Address), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// Stub methods to satisfy interface
+func (m *AddressRepoMock) CreateAddress(address *models.Address) (*models.Address, error) {
+	return nil, nil
+}
+
+func (m *AddressRepoMock) GetAddressesByUserID(userID uint) ([]models.Address, error) {
+	return nil, nil
+}
+
+func (m *AddressRepoMock) UpdateAddress(address *models.Address) (*models.Address, error) {
+	return nil, nil
+}
+
+func (m *AddressRepoMock) DeleteAddress(id uint) error {
+	return nil
+}
+
+func (m *AddressRepoMock) SetDefaultAddress(userID, id uint) error {
+	return nil
+}
+
+var testAddress = models.PostalAddress{Recipient: "Ana", Line1: "Calle 1", City: "Santiago", PostalCode: "8320000", Country: "CL"}
+
+func defaultAddressMock() *AddressRepoMock {
+	addressMock := new(AddressRepoMock)
+	addressMock.On("GetDefaultAddress", mock.Anything).Return(&models.Address{ID: 1, UserID: 1, IsDefault: true, PostalAddress: testAddress}, nil)
+	return addressMock
+}
+
+func testShipping() *services_shipping.Registry {
+	return services_shipping.NewRegistry("standard").
+		Register("standard", services_shipping.FreeOverThreshold{Threshold: 100, Fallback: services_shipping.FlatRate{Amount: 5}}).
+		Register("express", services_shipping.WeightBased{Base: 10, PerKg: 2})
+}
+
 // ProductsRepoMock mocks repo.ProductsRepository
 type ProductsRepoMock struct {
 	mock.Mock
@@ -49,8 +136,21 @@ func (m *ProductsRepoMock) UpdateProduct(product *models.Product) error {
 	return args.Error(0)
 }
 
+func (m *ProductsRepoMock) RestockProduct(id uint, quantity int) (*models.Product, error) {
+	args := m.Called(id, quantity)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) SaveTranslation(translation *models.ProductTranslation) error {
+	args := m.Called(translation)
+	return args.Error(0)
+}
+
 // Stub methods to satisfy interface
-func (m *ProductsRepoMock) GetAllProducts() ([]models.Product, error) {
+func (m *ProductsRepoMock) GetAllProducts(sort models.ProductSort) ([]models.Product, error) {
 	return nil, nil
 }
 
@@ -66,18 +166,16 @@ func TestCreateOrder_Success(t *testing.T) {
 	orderMock := new(OrdersRepoMock)
 	prodMock := new(ProductsRepoMock)
 	logger := logrus.New()
-	svc := NewOrdersService(orderMock, prodMock, logger)
+	svc := NewOrdersService(orderMock, prodMock, defaultAddressMock(), testShipping(), logger)
 
 	items := []models.OrderProduct{{ProductID: 1, Quantity: 2}}
 	product := &models.Product{Model: models.Product{}.Model, Price: 5.0, Stock: 10}
 
 	prodMock.On("GetProductByID", uint(1)).Return(product, nil)
-	// After subtraction, stock should be 8
-	prodMock.On("UpdateProduct", product).Return(nil)
 	created := &models.Order{ID: 100, UserID: 1, Total: 10.0}
 	orderMock.On("CreateOrder", mock.AnythingOfType("*models.Order")).Return(created, nil)
 
-	res, err := svc.CreateOrder(1, items)
+	res, err := svc.CreateOrder(1, models.CreateOrderRequest{OrderItems: items})
 	assert.NoError(t, err)
 	assert.Equal(t, created, res)
 
@@ -85,56 +183,261 @@ func TestCreateOrder_Success(t *testing.T) {
 	orderMock.AssertExpectations(t)
 }
 
+func TestCreateOrder_CapturesProductSnapshot(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	prodMock := new(ProductsRepoMock)
+	svc := NewOrdersService(orderMock, prodMock, defaultAddressMock(), testShipping(), logrus.New())
+
+	product := &models.Product{
+		Name:        "Silla",
+		Description: "Silla ergonómica",
+		SKU:         "SIL-01",
+		TaxCategory: "reduced",
+		Attributes:  models.Attributes{"color": "negro"},
+		Price:       80,
+		Stock:       3,
+	}
+	prodMock.On("GetProductByID", uint(4)).Return(product, nil)
+	orderMock.On("CreateOrder", mock.MatchedBy(func(o *models.Order) bool {
+		snapshot := o.OrderItems[0].Snapshot
+		return snapshot.Name == "Silla" &&
+			snapshot.Description == "Silla ergonómica" &&
+			snapshot.SKU == "SIL-01" &&
+			snapshot.TaxCategory == "reduced" &&
+			snapshot.Attributes["color"] == "negro"
+	})).Return(&models.Order{ID: 1}, nil)
+
+	_, err := svc.CreateOrder(1, models.CreateOrderRequest{OrderItems: []models.OrderProduct{{ProductID: 4, Quantity: 1, Snapshot: models.ProductSnapshot{Name: "client supplied"}}}})
+	assert.NoError(t, err)
+	orderMock.AssertExpectations(t)
+
+	// Later edits to the product must not leak into the stored snapshot.
+	product.Attributes["color"] = "rojo"
+	stored := orderMock.Calls[0].Arguments.Get(0).(*models.Order)
+	assert.Equal(t, "negro", stored.OrderItems[0].Snapshot.Attributes["color"])
+}
+
 func TestCreateOrder_ProductNotFound(t *testing.T) {
 	orderMock := new(OrdersRepoMock)
 	prodMock := new(ProductsRepoMock)
-	svc := NewOrdersService(orderMock, prodMock, logrus.New())
+	svc := NewOrdersService(orderMock, prodMock, defaultAddressMock(), testShipping(), logrus.New())
 
 	prodMock.On("GetProductByID", uint(1)).Return(nil, errors.New("not found"))
-	_, err := svc.CreateOrder(1, []models.OrderProduct{{ProductID: 1, Quantity: 1}})
+	_, err := svc.CreateOrder(1, models.CreateOrderRequest{OrderItems: []models.OrderProduct{{ProductID: 1, Quantity: 1}}})
 	assert.EqualError(t, err, "product with ID 1 not found")
+	assert.ErrorIs(t, err, ErrProductNotFound)
 }
 
 func TestCreateOrder_InsufficientStock(t *testing.T) {
 	orderMock := new(OrdersRepoMock)
 	prodMock := new(ProductsRepoMock)
-	svc := NewOrdersService(orderMock, prodMock, logrus.New())
+	svc := NewOrdersService(orderMock, prodMock, defaultAddressMock(), testShipping(), logrus.New())
 
 	prodMock.On("GetProductByID", uint(1)).Return(&models.Product{Stock: 1}, nil)
-	_, err := svc.CreateOrder(1, []models.OrderProduct{{ProductID: 1, Quantity: 2}})
+	_, err := svc.CreateOrder(1, models.CreateOrderRequest{OrderItems: []models.OrderProduct{{ProductID: 1, Quantity: 2}}})
 	assert.EqualError(t, err, "insufficient stock for product ID 1")
+	assert.ErrorIs(t, err, ErrInsufficientStock)
 }
 
-func TestCreateOrder_UpdateError(t *testing.T) {
+func TestCreateOrder_StockTakenConcurrently(t *testing.T) {
 	orderMock := new(OrdersRepoMock)
 	prodMock := new(ProductsRepoMock)
-	svc := NewOrdersService(orderMock, prodMock, logrus.New())
+	svc := NewOrdersService(orderMock, prodMock, defaultAddressMock(), testShipping(), logrus.New())
 
 	product := &models.Product{Price: 5.0, Stock: 5}
 	prodMock.On("GetProductByID", uint(1)).Return(product, nil)
-	prodMock.On("UpdateProduct", product).Return(errors.New("db err"))
-	_, err := svc.CreateOrder(1, []models.OrderProduct{{ProductID: 1, Quantity: 2}})
-	assert.EqualError(t, err, "failed to update stock for product ID 1")
+	orderMock.On("CreateOrder", mock.AnythingOfType("*models.Order")).
+		Return(nil, fmt.Errorf("%w for product ID 1", orders_repo.ErrInsufficientStock))
+	_, err := svc.CreateOrder(1, models.CreateOrderRequest{OrderItems: []models.OrderProduct{{ProductID: 1, Quantity: 2}}})
+	assert.ErrorIs(t, err, orders_repo.ErrInsufficientStock)
+	assert.Equal(t, 5, product.Stock)
+	prodMock.AssertNotCalled(t, "UpdateProduct", mock.Anything)
 }
 
 func TestGetUserOrders(t *testing.T) {
 	orderMock := new(OrdersRepoMock)
 	prodMock := new(ProductsRepoMock)
-	svc := NewOrdersService(orderMock, prodMock, logrus.New())
+	svc := NewOrdersService(orderMock, prodMock, defaultAddressMock(), testShipping(), logrus.New())
 
 	orders := []models.Order{{ID: 5, UserID: 2}}
-	orderMock.On("GetOrdersByUserID", uint(2)).Return(orders, nil)
-	res, err := svc.GetUserOrders(2)
+	expectedFilter := models.OrderFilter{SortBy: "created_at", SortDir: "desc", Page: 1, PageSize: 20}
+	orderMock.On("FindUserOrders", uint(2), expectedFilter).Return(orders, int64(41), nil)
+	res, err := svc.GetUserOrders(2, models.OrderFilter{})
 	assert.NoError(t, err)
-	assert.Equal(t, orders, res)
+	assert.Equal(t, orders, res.Items)
+	assert.Equal(t, int64(41), res.TotalItems)
+	assert.Equal(t, 3, res.TotalPages)
+	assert.Equal(t, 1, res.Page)
 }
 
 func TestGetUserOrders_Error(t *testing.T) {
 	orderMock := new(OrdersRepoMock)
 	prodMock := new(ProductsRepoMock)
-	svc := NewOrdersService(orderMock, prodMock, logrus.New())
+	svc := NewOrdersService(orderMock, prodMock, defaultAddressMock(), testShipping(), logrus.New())
 
-	orderMock.On("GetOrdersByUserID", uint(3)).Return(nil, errors.New("db err"))
-	_, err := svc.GetUserOrders(3)
+	orderMock.On("FindUserOrders", uint(3), mock.Anything).Return(nil, int64(0), errors.New("db err"))
+	_, err := svc.GetUserOrders(3, models.OrderFilter{})
 	assert.EqualError(t, err, "db err")
 }
+
+func TestGetUserOrders_InvalidFilter(t *testing.T) {
+	svc := NewOrdersService(new(OrdersRepoMock), new(ProductsRepoMock), defaultAddressMock(), testShipping(), logrus.New())
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	min, max := 10.0, 5.0
+	cases := []models.OrderFilter{
+		{SortBy: "user_id; DROP TABLE orders"},
+		{SortDir: "sideways"},
+		{Status: "lost"},
+		{From: &from, To: &to},
+		{MinTotal: &min, MaxTotal: &max},
+	}
+	for _, filter := range cases {
+		_, err := svc.GetUserOrders(1, filter)
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	}
+}
+
+func TestGetUserOrders_ClampsPageSize(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewOrdersService(orderMock, new(ProductsRepoMock), defaultAddressMock(), testShipping(), logrus.New())
+
+	orderMock.On("FindUserOrders", uint(1), mock.MatchedBy(func(f models.OrderFilter) bool {
+		return f.PageSize == 100 && f.Page == 2 && f.SortBy == "total" && f.SortDir == "asc"
+	})).Return([]models.Order{}, int64(0), nil)
+	res, err := svc.GetUserOrders(1, models.OrderFilter{Page: 2, PageSize: 1000, SortBy: "total", SortDir: "asc"})
+	assert.NoError(t, err)
+	assert.Equal(t, 100, res.PageSize)
+}
+
+func TestGetUserOrder(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewOrdersService(orderMock, new(ProductsRepoMock), defaultAddressMock(), testShipping(), logrus.New())
+
+	order := &models.Order{ID: 7, UserID: 2}
+	orderMock.On("GetOrderByID", uint(7)).Return(order, nil)
+	res, err := svc.GetUserOrder(2, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, order, res)
+}
+
+func TestGetUserOrder_NotOwner(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewOrdersService(orderMock, new(ProductsRepoMock), defaultAddressMock(), testShipping(), logrus.New())
+
+	orderMock.On("GetOrderByID", uint(7)).Return(&models.Order{ID: 7, UserID: 3}, nil)
+	_, err := svc.GetUserOrder(2, 7)
+	assert.Equal(t, ErrOrderNotFound, err)
+}
+
+func TestGetUserOrder_LookupErrors(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewOrdersService(orderMock, new(ProductsRepoMock), defaultAddressMock(), testShipping(), logrus.New())
+
+	orderMock.On("GetOrderByID", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+	_, err := svc.GetUserOrder(2, 7)
+	assert.Equal(t, ErrOrderNotFound, err)
+
+	// A failing database is not a missing order.
+	dbErr := errors.New("connection refused")
+	orderMock.On("GetOrderByID", uint(8)).Return(nil, dbErr)
+	_, err = svc.GetUserOrder(2, 8)
+	assert.Equal(t, dbErr, err)
+	_, err = svc.CancelOrder(2, 8)
+	assert.Equal(t, dbErr, err)
+}
+
+func TestCreateOrder_AddsShippingAndAddress(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	prodMock := new(ProductsRepoMock)
+	addressMock := new(AddressRepoMock)
+	svc := NewOrdersService(orderMock, prodMock, addressMock, testShipping(), logrus.New())
+
+	addressID := uint(8)
+	addressMock.On("GetAddressByID", uint(8)).Return(&models.Address{ID: 8, UserID: 1, PostalAddress: testAddress}, nil)
+	product := &models.Product{Price: 20, Weight: 1.2, Stock: 5}
+	prodMock.On("GetProductByID", uint(1)).Return(product, nil)
+	orderMock.On("CreateOrder", mock.MatchedBy(func(o *models.Order) bool {
+		return o.Subtotal == 40 &&
+			o.ShippingMethod == "express" &&
+			o.ShippingCost == 16 &&
+			o.Total == 56 &&
+			o.ShippingAddress == testAddress
+	})).Return(&models.Order{ID: 1}, nil)
+
+	_, err := svc.CreateOrder(1, models.CreateOrderRequest{
+		OrderItems:     []models.OrderProduct{{ProductID: 1, Quantity: 2}},
+		AddressID:      &addressID,
+		ShippingMethod: "express",
+	})
+	assert.NoError(t, err)
+	orderMock.AssertExpectations(t)
+}
+
+func TestCreateOrder_NoDefaultAddress(t *testing.T) {
+	addressMock := new(AddressRepoMock)
+	prodMock := new(ProductsRepoMock)
+	svc := NewOrdersService(new(OrdersRepoMock), prodMock, addressMock, testShipping(), logrus.New())
+
+	addressMock.On("GetDefaultAddress", uint(1)).Return(nil, errors.New("record not found"))
+	_, err := svc.CreateOrder(1, models.CreateOrderRequest{OrderItems: []models.OrderProduct{{ProductID: 1, Quantity: 1}}})
+	assert.Equal(t, ErrAddressRequired, err)
+	prodMock.AssertNotCalled(t, "GetProductByID", mock.Anything)
+}
+
+func TestCreateOrder_AddressOfAnotherUser(t *testing.T) {
+	addressMock := new(AddressRepoMock)
+	svc := NewOrdersService(new(OrdersRepoMock), new(ProductsRepoMock), addressMock, testShipping(), logrus.New())
+
+	addressID := uint(8)
+	addressMock.On("GetAddressByID", uint(8)).Return(&models.Address{ID: 8, UserID: 2}, nil)
+	_, err := svc.CreateOrder(1, models.CreateOrderRequest{AddressID: &addressID})
+	assert.Equal(t, ErrAddressNotFound, err)
+}
+
+func TestCreateOrder_UnknownShippingMethodLeavesStock(t *testing.T) {
+	prodMock := new(ProductsRepoMock)
+	svc := NewOrdersService(new(OrdersRepoMock), prodMock, defaultAddressMock(), testShipping(), logrus.New())
+
+	prodMock.On("GetProductByID", uint(1)).Return(&models.Product{Price: 5, Stock: 5}, nil)
+	_, err := svc.CreateOrder(1, models.CreateOrderRequest{
+		OrderItems:     []models.OrderProduct{{ProductID: 1, Quantity: 1}},
+		ShippingMethod: "teleport",
+	})
+	assert.ErrorIs(t, err, ErrUnknownShipping)
+	prodMock.AssertNotCalled(t, "UpdateProduct", mock.Anything)
+}
+
+func TestCancelOrder(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewOrdersService(orderMock, new(ProductsRepoMock), defaultAddressMock(), testShipping(), logrus.New())
+
+	order := &models.Order{ID: 7, UserID: 2, Status: models.OrderStatusPlaced}
+	orderMock.On("GetOrderByID", uint(7)).Return(order, nil)
+	orderMock.On("CancelOrder", order).Return(nil)
+
+	res, err := svc.CancelOrder(2, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, order, res)
+	orderMock.AssertExpectations(t)
+}
+
+func TestCancelOrder_AlreadyShipped(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewOrdersService(orderMock, new(ProductsRepoMock), defaultAddressMock(), testShipping(), logrus.New())
+
+	orderMock.On("GetOrderByID", uint(7)).Return(&models.Order{ID: 7, UserID: 2, Status: models.OrderStatusShipped}, nil)
+	_, err := svc.CancelOrder(2, 7)
+	assert.Equal(t, ErrOrderNotCancellable, err)
+	orderMock.AssertNotCalled(t, "CancelOrder", mock.Anything)
+}
+
+func TestCancelOrder_NotOwner(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewOrdersService(orderMock, new(ProductsRepoMock), defaultAddressMock(), testShipping(), logrus.New())
+
+	orderMock.On("GetOrderByID", uint(7)).Return(&models.Order{ID: 7, UserID: 3, Status: models.OrderStatusPlaced}, nil)
+	_, err := svc.CancelOrder(2, 7)
+	assert.Equal(t, ErrOrderNotFound, err)
+}
@@ -0,0 +1,165 @@
+package services_outbox
+
+import (
+	"context"
+	"fmt"
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/outbox_repo"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultDispatchInterval = 2 * time.Second
+	batchSize               = 100
+	baseBackoff             = 5 * time.Second
+	maxBackoff              = time.Hour
+	// MaxAttempts is how many failed deliveries an event gets before it is
+	// marked dead and no longer retried.
+	MaxAttempts = 12
+)
+
+// Sink receives published domain events. Each sink that handles an event is
+// recorded, and a retry only goes to the sinks that failed. Delivery is
+// still at least once, since a sink may fail after acting, so sinks must
+// tolerate duplicates, using the event ID to recognise them.
+type Sink interface {
+	Name() string
+	Handle(event models.OutboxEvent) error
+}
+
+// Dispatcher publishes outbox events to the registered sinks in the order
+// they were written. A failing event is retried with backoff without
+// holding back the ones after it, up to MaxAttempts times.
+type Dispatcher struct {
+	repo     repo.OutboxRepository
+	sinks    []Sink
+	interval time.Duration
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+func NewDispatcher(repo repo.OutboxRepository, sinks []Sink, interval time.Duration, logger *logrus.Logger) *Dispatcher {
+	if interval <= 0 {
+		interval = DefaultDispatchInterval
+	}
+	return &Dispatcher{
+		repo:     repo,
+		sinks:    sinks,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Start runs the dispatcher in the background until ctx is cancelled.
+func (d *Dispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		d.tick()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.tick()
+			}
+		}
+	}()
+}
+
+func (d *Dispatcher) tick() {
+	published, err := d.DispatchPending()
+	if err != nil {
+		d.logger.Errorln("Layer: outbox_dispatcher, Method: tick, Error:", err)
+		return
+	}
+	if published > 0 {
+		d.logger.Infof("Layer: outbox_dispatcher, Method: tick, published %d events", published)
+	}
+}
+
+// DispatchPending hands every due event to the sinks and returns how many
+// were published.
+func (d *Dispatcher) DispatchPending() (int, error) {
+	now := d.now()
+	events, err := d.repo.GetPendingEvents(now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i := range events {
+		event := &events[i]
+		if err := d.deliver(*event); err != nil {
+			event.Attempts++
+			event.LastError = err.Error()
+			event.NextAttemptAt = now.Add(Backoff(event.Attempts))
+			d.logger.Errorln("Layer: outbox_dispatcher, Method: DispatchPending, Error: event", event.ID, err)
+			if event.Attempts >= MaxAttempts {
+				event.DeadAt = &now
+				d.logger.Errorln("Layer: outbox_dispatcher, Method: DispatchPending, Error: giving up on event", event.ID)
+			}
+			if err := d.repo.UpdateEvent(event); err != nil {
+				d.logger.Errorln("Layer: outbox_dispatcher, Method: DispatchPending, Error:", err)
+			}
+			continue
+		}
+		if err := d.repo.MarkPublished(event.ID, d.now()); err != nil {
+			// It will be delivered again on the next run.
+			d.logger.Errorln("Layer: outbox_dispatcher, Method: DispatchPending, Error:", err)
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
+
+// deliver hands the event to the sinks that have not handled it yet and
+// records each one that succeeds.
+func (d *Dispatcher) deliver(event models.OutboxEvent) error {
+	delivered, err := d.repo.GetDeliveredSinks(event.ID)
+	if err != nil {
+		return err
+	}
+	done := make(map[string]bool, len(delivered))
+	for _, name := range delivered {
+		done[name] = true
+	}
+
+	var failures []string
+	for _, sink := range d.sinks {
+		if done[sink.Name()] {
+			continue
+		}
+		if err := sink.Handle(event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+			continue
+		}
+		if err := d.repo.MarkDelivered(event.ID, sink.Name(), d.now()); err != nil {
+			// The sink will get the event again on the next attempt.
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Backoff returns how long to wait before retrying an event that has
+// failed attempts times.
+func Backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
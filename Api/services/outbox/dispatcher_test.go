@@ -0,0 +1,130 @@
+package services_outbox
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var fixedNow = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func newTestDispatcher(repoMock *OutboxRepoMock, sinks ...Sink) *Dispatcher {
+	d := NewDispatcher(repoMock, sinks, time.Second, logrus.New())
+	d.now = func() time.Time { return fixedNow }
+	return d
+}
+
+func TestDispatchPending_PublishesToEverySink(t *testing.T) {
+	repoMock := new(OutboxRepoMock)
+	first, second := &SinkMock{SinkName: "first"}, &SinkMock{SinkName: "second"}
+	d := newTestDispatcher(repoMock, first, second)
+
+	repoMock.On("GetPendingEvents", fixedNow, batchSize).Return([]models.OutboxEvent{
+		{ID: 1, Type: models.EventOrderPlaced},
+		{ID: 2, Type: models.EventStockChanged},
+	}, nil)
+	repoMock.On("GetDeliveredSinks", mock.Anything).Return(nil, nil)
+	repoMock.On("MarkDelivered", mock.Anything, mock.Anything, fixedNow).Return(nil)
+	repoMock.On("MarkPublished", uint(1), fixedNow).Return(nil)
+	repoMock.On("MarkPublished", uint(2), fixedNow).Return(nil)
+
+	published, err := d.DispatchPending()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []uint{1, 2}, first.Received)
+	assert.Equal(t, []uint{1, 2}, second.Received)
+	repoMock.AssertNumberOfCalls(t, "MarkDelivered", 4)
+	repoMock.AssertExpectations(t)
+}
+
+func TestDispatchPending_FailureIsRetriedLater(t *testing.T) {
+	repoMock := new(OutboxRepoMock)
+	healthy := &SinkMock{SinkName: "healthy"}
+	failing := &SinkMock{Fail: map[uint]error{1: errors.New("connection refused")}}
+	d := newTestDispatcher(repoMock, healthy, failing)
+
+	repoMock.On("GetPendingEvents", fixedNow, batchSize).Return([]models.OutboxEvent{
+		{ID: 1, Type: models.EventOrderPlaced, Attempts: 1},
+		{ID: 2, Type: models.EventUserRegistered},
+	}, nil)
+	repoMock.On("GetDeliveredSinks", mock.Anything).Return(nil, nil)
+	repoMock.On("MarkDelivered", mock.Anything, mock.Anything, fixedNow).Return(nil)
+	repoMock.On("UpdateEvent", mock.MatchedBy(func(e *models.OutboxEvent) bool {
+		return e.ID == 1 &&
+			e.Attempts == 2 &&
+			e.DeadAt == nil &&
+			e.NextAttemptAt.Equal(fixedNow.Add(10*time.Second)) &&
+			e.LastError == "mock: connection refused"
+	})).Return(nil)
+	repoMock.On("MarkPublished", uint(2), fixedNow).Return(nil)
+
+	published, err := d.DispatchPending()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+	// The failed event did not hold back the next one.
+	assert.Equal(t, []uint{1, 2}, healthy.Received)
+	repoMock.AssertExpectations(t)
+	repoMock.AssertNotCalled(t, "MarkPublished", uint(1), mock.Anything)
+	repoMock.AssertNotCalled(t, "MarkDelivered", uint(1), "mock", mock.Anything)
+	repoMock.AssertCalled(t, "MarkDelivered", uint(1), "healthy", fixedNow)
+}
+
+func TestDispatchPending_RetriesOnlyFailedSinks(t *testing.T) {
+	repoMock := new(OutboxRepoMock)
+	healthy := &SinkMock{SinkName: "healthy"}
+	failing := &SinkMock{}
+	d := newTestDispatcher(repoMock, healthy, failing)
+
+	repoMock.On("GetPendingEvents", fixedNow, batchSize).Return([]models.OutboxEvent{
+		{ID: 1, Type: models.EventOrderPlaced, Attempts: 1},
+	}, nil)
+	repoMock.On("GetDeliveredSinks", uint(1)).Return([]string{"healthy"}, nil)
+	repoMock.On("MarkDelivered", uint(1), "mock", fixedNow).Return(nil)
+	repoMock.On("MarkPublished", uint(1), fixedNow).Return(nil)
+
+	published, err := d.DispatchPending()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Empty(t, healthy.Received)
+	assert.Equal(t, []uint{1}, failing.Received)
+	repoMock.AssertExpectations(t)
+}
+
+func TestDispatchPending_GivesUpAfterMaxAttempts(t *testing.T) {
+	repoMock := new(OutboxRepoMock)
+	failing := &SinkMock{Fail: map[uint]error{1: errors.New("connection refused")}}
+	d := newTestDispatcher(repoMock, failing)
+
+	repoMock.On("GetPendingEvents", fixedNow, batchSize).Return([]models.OutboxEvent{
+		{ID: 1, Type: models.EventOrderPlaced, Attempts: MaxAttempts - 1},
+	}, nil)
+	repoMock.On("GetDeliveredSinks", uint(1)).Return(nil, nil)
+	repoMock.On("UpdateEvent", mock.MatchedBy(func(e *models.OutboxEvent) bool {
+		return e.Attempts == MaxAttempts && e.DeadAt != nil && e.DeadAt.Equal(fixedNow)
+	})).Return(nil)
+
+	published, err := d.DispatchPending()
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+	repoMock.AssertExpectations(t)
+}
+
+func TestDispatchPending_RepoError(t *testing.T) {
+	repoMock := new(OutboxRepoMock)
+	d := newTestDispatcher(repoMock, &SinkMock{})
+
+	repoMock.On("GetPendingEvents", fixedNow, batchSize).Return(nil, errors.New("db err"))
+	_, err := d.DispatchPending()
+	assert.EqualError(t, err, "db err")
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, Backoff(1))
+	assert.Equal(t, 40*time.Second, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
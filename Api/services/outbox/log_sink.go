@@ -0,0 +1,25 @@
+package services_outbox
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogSink writes every event to the application log.
+type LogSink struct {
+	Logger *logrus.Logger
+}
+
+func (s LogSink) Name() string {
+	return "log"
+}
+
+func (s LogSink) Handle(event models.OutboxEvent) error {
+	s.Logger.WithFields(logrus.Fields{
+		"event_id":       event.ID,
+		"aggregate_type": event.AggregateType,
+		"aggregate_id":   event.AggregateID,
+	}).Infof("Layer: outbox_log_sink, Method: Handle, event %s", event.Type)
+	return nil
+}
@@ -0,0 +1,72 @@
+package services_outbox
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// OutboxRepoMock mocks repo.OutboxRepository
+type OutboxRepoMock struct {
+	mock.Mock
+}
+
+func (m *OutboxRepoMock) GetPendingEvents(now time.Time, limit int) ([]models.OutboxEvent, error) {
+	args := m.Called(now, limit)
+	if res := args.Get(0); res != nil {
+		return res.([]models.OutboxEvent), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OutboxRepoMock) MarkPublished(id uint, publishedAt time.Time) error {
+	args := m.Called(id, publishedAt)
+	return args.Error(0)
+}
+
+func (m *OutboxRepoMock) UpdateEvent(event *models.OutboxEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *OutboxRepoMock) GetDeliveredSinks(eventID uint) ([]string, error) {
+	args := m.Called(eventID)
+	if res := args.Get(0); res != nil {
+		return res.([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OutboxRepoMock) MarkDelivered(eventID uint, sink string, deliveredAt time.Time) error {
+	args := m.Called(eventID, sink, deliveredAt)
+	return args.Error(0)
+}
+
+func (m *OutboxRepoMock) GetEventsAfter(afterID uint, types []string, limit int) ([]models.OutboxEvent, error) {
+	args := m.Called(afterID, types, limit)
+	if res := args.Get(0); res != nil {
+		return res.([]models.OutboxEvent), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// SinkMock records the events it receives and fails for the event IDs in
+// Fail. It is called "mock" unless SinkName is set.
+type SinkMock struct {
+	SinkName string
+	Received []uint
+	Fail     map[uint]error
+}
+
+func (s *SinkMock) Name() string {
+	if s.SinkName == "" {
+		return "mock"
+	}
+	return s.SinkName
+}
+
+func (s *SinkMock) Handle(event models.OutboxEvent) error {
+	s.Received = append(s.Received, event.ID)
+	return s.Fail[event.ID]
+}
@@ -0,0 +1,291 @@
+package services_price
+
+import (
+	"errors"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/prices_repo"
+	productsRepo "pruebaVertice/Api/repo/products_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrProductNotFound  = apperr.NotFound("product_not_found", "product not found")
+	ErrChangeNotFound   = apperr.NotFound("price_change_not_found", "scheduled price change not found")
+	ErrChangeNotPending = apperr.Conflict("price_change_not_pending", "scheduled price change can no longer be cancelled")
+	ErrInvalidPrice     = apperr.Invalid("invalid_price", "price must be greater than zero")
+	ErrInvalidKind      = apperr.Invalid("invalid_price_kind", "kind must be 'regular' or 'sale'")
+	ErrInvalidWindow    = apperr.Invalid("invalid_price_window", "ends_at must be after starts_at")
+	ErrSaleWithoutEnd   = apperr.Invalid("sale_without_end", "a sale requires ends_at")
+)
+
+type PriceService interface {
+	SchedulePriceChange(productID uint, req models.SchedulePriceChangeRequest, createdBy string) (*models.ScheduledPriceChange, error)
+	CancelScheduledChange(productID, changeID uint) error
+	GetPriceTimeline(productID uint) (*dto.PriceTimelineResponse, error)
+	ApplyDueChanges() (int, error)
+}
+
+type priceService struct {
+	repo        repo.PricesRepository
+	productRepo productsRepo.ProductsRepository
+	logger      *logrus.Logger
+	now         func() time.Time
+}
+
+func NewPriceService(repo repo.PricesRepository, productRepo productsRepo.ProductsRepository, logger *logrus.Logger) *priceService {
+	return &priceService{
+		repo:        repo,
+		productRepo: productRepo,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+func (s *priceService) SchedulePriceChange(productID uint, req models.SchedulePriceChangeRequest, createdBy string) (*models.ScheduledPriceChange, error) {
+	if req.Kind == "" {
+		req.Kind = models.PriceChangeKindRegular
+	}
+	if req.Kind != models.PriceChangeKindRegular && req.Kind != models.PriceChangeKindSale {
+		return nil, ErrInvalidKind
+	}
+	if req.Price <= 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	now := s.now()
+	startsAt := now
+	if req.StartsAt != nil && req.StartsAt.After(now) {
+		startsAt = *req.StartsAt
+	}
+	if req.Kind == models.PriceChangeKindSale && req.EndsAt == nil {
+		return nil, ErrSaleWithoutEnd
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(startsAt) {
+		return nil, ErrInvalidWindow
+	}
+
+	if _, err := s.productRepo.GetProductByID(productID); err != nil {
+		return nil, ErrProductNotFound
+	}
+
+	change := &models.ScheduledPriceChange{
+		ProductID: productID,
+		Kind:      req.Kind,
+		Price:     req.Price,
+		StartsAt:  startsAt,
+		EndsAt:    req.EndsAt,
+		Status:    models.PriceChangeStatusPending,
+		CreatedBy: createdBy,
+	}
+	if req.Kind == models.PriceChangeKindRegular {
+		change.EndsAt = nil
+	}
+
+	created, err := s.repo.CreateScheduledChange(change)
+	if err != nil {
+		s.logger.Errorln("Layer: price_service, Method: SchedulePriceChange, Error:", err)
+		return nil, err
+	}
+
+	if !created.StartsAt.After(now) {
+		err := s.apply(created, now)
+		if errors.Is(err, repo.ErrChangeClaimed) {
+			// The scheduler picked the change up first and applied it.
+			s.logger.Infof("Price change %d was applied by the scheduler", created.ID)
+			return created, nil
+		}
+		if err != nil {
+			s.logger.Errorln("Layer: price_service, Method: SchedulePriceChange, Error: applying change:", err)
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// CancelScheduledChange cancels a pending change. Cancelling an active sale
+// ends it immediately and restores the regular price.
+func (s *priceService) CancelScheduledChange(productID, changeID uint) error {
+	change, err := s.repo.GetScheduledChangeByID(changeID)
+	if err != nil || change.ProductID != productID {
+		return ErrChangeNotFound
+	}
+
+	switch change.Status {
+	case models.PriceChangeStatusPending:
+		change.Status = models.PriceChangeStatusCancelled
+		err = s.repo.ApplyTransition(nil, nil, repo.ChangeUpdate{Change: change, From: models.PriceChangeStatusPending})
+	case models.PriceChangeStatusActive:
+		now := s.now()
+		change.EndsAt = &now
+		err = s.apply(change, now)
+	default:
+		return ErrChangeNotPending
+	}
+	if errors.Is(err, repo.ErrChangeClaimed) {
+		return ErrChangeNotPending
+	}
+	return err
+}
+
+func (s *priceService) GetPriceTimeline(productID uint) (*dto.PriceTimelineResponse, error) {
+	product, err := s.productRepo.GetProductByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFound
+	}
+
+	history, err := s.repo.GetPriceHistory(productID)
+	if err != nil {
+		s.logger.Errorln("Layer: price_service, Method: GetPriceTimeline, Error:", err)
+		return nil, err
+	}
+	changes, err := s.repo.GetScheduledChanges(productID)
+	if err != nil {
+		s.logger.Errorln("Layer: price_service, Method: GetPriceTimeline, Error:", err)
+		return nil, err
+	}
+
+	timeline := &dto.PriceTimelineResponse{
+		ProductID:    productID,
+		CurrentPrice: product.Price,
+		RegularPrice: product.Price,
+		History:      history,
+		Upcoming:     []models.ScheduledPriceChange{},
+	}
+	for _, change := range changes {
+		switch change.Status {
+		case models.PriceChangeStatusActive:
+			timeline.OnSale = true
+			timeline.RegularPrice = change.RegularPrice
+			timeline.Upcoming = append(timeline.Upcoming, change)
+		case models.PriceChangeStatusPending:
+			timeline.Upcoming = append(timeline.Upcoming, change)
+		}
+	}
+	return timeline, nil
+}
+
+// ApplyDueChanges applies every change whose start or end time has passed
+// and returns how many were processed. Failures are logged and retried on
+// the next run.
+func (s *priceService) ApplyDueChanges() (int, error) {
+	now := s.now()
+	due, err := s.repo.GetDueChanges(now)
+	if err != nil {
+		s.logger.Errorln("Layer: price_service, Method: ApplyDueChanges, Error:", err)
+		return 0, err
+	}
+
+	applied := 0
+	for i := range due {
+		err := s.apply(&due[i], now)
+		if errors.Is(err, repo.ErrChangeClaimed) {
+			continue
+		}
+		if err != nil {
+			s.logger.Errorln("Layer: price_service, Method: ApplyDueChanges, Error: change", due[i].ID, err)
+			continue
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// apply moves a due change to its next status. Every write claims the
+// change in the status it was read in, so a change applied concurrently by
+// SchedulePriceChange and the scheduler only takes effect once.
+func (s *priceService) apply(change *models.ScheduledPriceChange, now time.Time) error {
+	claim := repo.ChangeUpdate{Change: change, From: change.Status}
+	product, err := s.productRepo.GetProductByID(change.ProductID)
+	if err != nil {
+		change.Status = models.PriceChangeStatusCancelled
+		return s.repo.ApplyTransition(nil, nil, claim)
+	}
+
+	if change.Status == models.PriceChangeStatusActive {
+		return s.endSale(product, claim)
+	}
+
+	if change.Kind == models.PriceChangeKindSale {
+		return s.startSale(product, claim, now)
+	}
+
+	change.Status = models.PriceChangeStatusApplied
+	if active, err := s.repo.GetActiveSale(product.ID); err == nil {
+		// The sale price stays in place; the new regular price is restored
+		// when the sale ends.
+		entry := &models.PriceHistory{
+			ProductID:     product.ID,
+			Price:         change.Price,
+			PreviousPrice: active.RegularPrice,
+			Reason:        models.PriceReasonRegularChanged,
+			ChangeID:      &change.ID,
+			ChangedBy:     change.CreatedBy,
+			EffectiveAt:   change.StartsAt,
+		}
+		active.RegularPrice = change.Price
+		return s.repo.ApplyTransition(nil, entry, claim, repo.ChangeUpdate{Change: active, From: models.PriceChangeStatusActive})
+	}
+
+	entry := &models.PriceHistory{
+		ProductID:     product.ID,
+		Price:         change.Price,
+		PreviousPrice: product.Price,
+		Reason:        models.PriceReasonChanged,
+		ChangeID:      &change.ID,
+		ChangedBy:     change.CreatedBy,
+		EffectiveAt:   change.StartsAt,
+	}
+	product.Price = change.Price
+	return s.repo.ApplyTransition(product, entry, claim)
+}
+
+func (s *priceService) startSale(product *models.Product, claim repo.ChangeUpdate, now time.Time) error {
+	change := claim.Change
+	if change.EndsAt != nil && !change.EndsAt.After(now) {
+		// The whole window passed while the scheduler was not running.
+		change.Status = models.PriceChangeStatusCompleted
+		return s.repo.ApplyTransition(nil, nil, claim)
+	}
+
+	changes := []repo.ChangeUpdate{claim}
+	change.RegularPrice = product.Price
+	if active, err := s.repo.GetActiveSale(product.ID); err == nil {
+		// A newer sale replaces the running one but keeps its regular price.
+		change.RegularPrice = active.RegularPrice
+		active.Status = models.PriceChangeStatusCompleted
+		changes = append(changes, repo.ChangeUpdate{Change: active, From: models.PriceChangeStatusActive})
+	}
+	change.Status = models.PriceChangeStatusActive
+
+	entry := &models.PriceHistory{
+		ProductID:     product.ID,
+		Price:         change.Price,
+		PreviousPrice: product.Price,
+		Reason:        models.PriceReasonSaleStarted,
+		ChangeID:      &change.ID,
+		ChangedBy:     change.CreatedBy,
+		EffectiveAt:   change.StartsAt,
+	}
+	product.Price = change.Price
+	return s.repo.ApplyTransition(product, entry, changes...)
+}
+
+func (s *priceService) endSale(product *models.Product, claim repo.ChangeUpdate) error {
+	change := claim.Change
+	entry := &models.PriceHistory{
+		ProductID:     product.ID,
+		Price:         change.RegularPrice,
+		PreviousPrice: product.Price,
+		Reason:        models.PriceReasonSaleEnded,
+		ChangeID:      &change.ID,
+		ChangedBy:     change.CreatedBy,
+		EffectiveAt:   *change.EndsAt,
+	}
+	product.Price = change.RegularPrice
+	change.Status = models.PriceChangeStatusCompleted
+	return s.repo.ApplyTransition(product, entry, claim)
+}
@@ -0,0 +1,268 @@
+package services_price
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/prices_repo"
+	productsRepo "pruebaVertice/Api/repo/products_repo"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var fixedNow = time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestService(pricesMock *PricesRepoMock, prodMock *ProductsRepoMock) *priceService {
+	svc := NewPriceService(pricesMock, prodMock, logrus.New())
+	svc.now = func() time.Time { return fixedNow }
+	return svc
+}
+
+func TestSchedulePriceChange_Future(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	prodMock := new(ProductsRepoMock)
+	svc := newTestService(pricesMock, prodMock)
+
+	startsAt := fixedNow.Add(24 * time.Hour)
+	prodMock.On("GetProductByID", uint(1)).Return(&models.Product{Price: 10}, nil)
+	expected := &models.ScheduledPriceChange{
+		ProductID: 1,
+		Kind:      models.PriceChangeKindRegular,
+		Price:     12,
+		StartsAt:  startsAt,
+		Status:    models.PriceChangeStatusPending,
+		CreatedBy: "admin@e.com",
+	}
+	pricesMock.On("CreateScheduledChange", expected).Return(expected, nil)
+
+	res, err := svc.SchedulePriceChange(1, models.SchedulePriceChangeRequest{Price: 12, StartsAt: &startsAt}, "admin@e.com")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, res)
+	pricesMock.AssertExpectations(t)
+	pricesMock.AssertNotCalled(t, "ApplyTransition", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchedulePriceChange_ImmediateAppliesChange(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	prodMock := new(ProductsRepoMock)
+	svc := newTestService(pricesMock, prodMock)
+
+	product := &models.Product{Model: gorm.Model{ID: 1}, Price: 10}
+	prodMock.On("GetProductByID", uint(1)).Return(product, nil)
+	pricesMock.On("CreateScheduledChange", mock.Anything).
+		Return(&models.ScheduledPriceChange{ID: 3, ProductID: 1, Kind: models.PriceChangeKindRegular, Price: 8, StartsAt: fixedNow, Status: models.PriceChangeStatusPending}, nil)
+	pricesMock.On("GetActiveSale", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	pricesMock.On("ApplyTransition", product, mock.MatchedBy(func(e *models.PriceHistory) bool {
+		return e.Price == 8 && e.PreviousPrice == 10 && e.Reason == models.PriceReasonChanged
+	}), mock.Anything).Return(nil)
+
+	res, err := svc.SchedulePriceChange(1, models.SchedulePriceChangeRequest{Price: 8}, "admin@e.com")
+	assert.NoError(t, err)
+	assert.Equal(t, models.PriceChangeStatusApplied, res.Status)
+	assert.Equal(t, 8.0, product.Price)
+	pricesMock.AssertExpectations(t)
+}
+
+// staleDueRepo hands the scheduler the due changes read right after a change
+// was created, as a scheduler run racing SchedulePriceChange would see them.
+type staleDueRepo struct {
+	repo.PricesRepository
+	due []models.ScheduledPriceChange
+}
+
+func (r *staleDueRepo) CreateScheduledChange(change *models.ScheduledPriceChange) (*models.ScheduledPriceChange, error) {
+	created, err := r.PricesRepository.CreateScheduledChange(change)
+	if err != nil {
+		return nil, err
+	}
+	r.due, err = r.PricesRepository.GetDueChanges(created.StartsAt)
+	return created, err
+}
+
+func (r *staleDueRepo) GetDueChanges(time.Time) ([]models.ScheduledPriceChange, error) {
+	return r.due, nil
+}
+
+func TestSchedulePriceChange_SchedulerAppliesDueRowOnce(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=private"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Product{}, &models.ProductTranslation{}, &models.PriceHistory{}, &models.ScheduledPriceChange{}, &models.OutboxEvent{}))
+	product := &models.Product{Name: "P", Price: 10}
+	require.NoError(t, db.Create(product).Error)
+
+	logger := logrus.New()
+	prices := &staleDueRepo{PricesRepository: repo.NewPricesRepository(db, logger)}
+	svc := NewPriceService(prices, productsRepo.NewProductsRepository(db, logger), logger)
+	svc.now = func() time.Time { return fixedNow }
+
+	change, err := svc.SchedulePriceChange(product.ID, models.SchedulePriceChangeRequest{Price: 8}, "admin@e.com")
+	require.NoError(t, err)
+	require.Len(t, prices.due, 1)
+	assert.Equal(t, change.ID, prices.due[0].ID)
+
+	applied, err := svc.ApplyDueChanges()
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+
+	var history []models.PriceHistory
+	require.NoError(t, db.Where("product_id = ?", product.ID).Find(&history).Error)
+	assert.Len(t, history, 1)
+	var stored models.Product
+	require.NoError(t, db.First(&stored, product.ID).Error)
+	assert.Equal(t, 8.0, stored.Price)
+	var events int64
+	require.NoError(t, db.Model(&models.OutboxEvent{}).Where("type = ?", models.EventProductPriceChanged).Count(&events).Error)
+	assert.Equal(t, int64(1), events)
+}
+
+func TestSchedulePriceChange_Validation(t *testing.T) {
+	svc := newTestService(new(PricesRepoMock), new(ProductsRepoMock))
+	endsAt := fixedNow.Add(-time.Hour)
+
+	_, err := svc.SchedulePriceChange(1, models.SchedulePriceChangeRequest{Price: 0}, "a")
+	assert.Equal(t, ErrInvalidPrice, err)
+	_, err = svc.SchedulePriceChange(1, models.SchedulePriceChangeRequest{Kind: "bogus", Price: 1}, "a")
+	assert.Equal(t, ErrInvalidKind, err)
+	_, err = svc.SchedulePriceChange(1, models.SchedulePriceChangeRequest{Kind: models.PriceChangeKindSale, Price: 1}, "a")
+	assert.Equal(t, ErrSaleWithoutEnd, err)
+	_, err = svc.SchedulePriceChange(1, models.SchedulePriceChangeRequest{Kind: models.PriceChangeKindSale, Price: 1, EndsAt: &endsAt}, "a")
+	assert.Equal(t, ErrInvalidWindow, err)
+}
+
+func TestSchedulePriceChange_ProductNotFound(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	prodMock := new(ProductsRepoMock)
+	svc := newTestService(pricesMock, prodMock)
+
+	prodMock.On("GetProductByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+	_, err := svc.SchedulePriceChange(9, models.SchedulePriceChangeRequest{Price: 1}, "a")
+	assert.Equal(t, ErrProductNotFound, err)
+}
+
+func TestApplyDueChanges_StartsAndEndsSale(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	prodMock := new(ProductsRepoMock)
+	svc := newTestService(pricesMock, prodMock)
+
+	endsAt := fixedNow.Add(time.Hour)
+	endedAt := fixedNow.Add(-time.Minute)
+	due := []models.ScheduledPriceChange{
+		{ID: 1, ProductID: 1, Kind: models.PriceChangeKindSale, Price: 5, StartsAt: fixedNow, EndsAt: &endsAt, Status: models.PriceChangeStatusPending},
+		{ID: 2, ProductID: 2, Kind: models.PriceChangeKindSale, Price: 3, RegularPrice: 7, StartsAt: fixedNow.Add(-time.Hour), EndsAt: &endedAt, Status: models.PriceChangeStatusActive},
+	}
+	pricesMock.On("GetDueChanges", fixedNow).Return(due, nil)
+
+	p1 := &models.Product{Model: gorm.Model{ID: 1}, Price: 10}
+	p2 := &models.Product{Model: gorm.Model{ID: 2}, Price: 3}
+	prodMock.On("GetProductByID", uint(1)).Return(p1, nil)
+	prodMock.On("GetProductByID", uint(2)).Return(p2, nil)
+	pricesMock.On("GetActiveSale", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	pricesMock.On("ApplyTransition", p1, mock.MatchedBy(func(e *models.PriceHistory) bool {
+		return e.Reason == models.PriceReasonSaleStarted && e.Price == 5
+	}), mock.Anything).Return(nil)
+	pricesMock.On("ApplyTransition", p2, mock.MatchedBy(func(e *models.PriceHistory) bool {
+		return e.Reason == models.PriceReasonSaleEnded && e.Price == 7
+	}), mock.Anything).Return(nil)
+
+	applied, err := svc.ApplyDueChanges()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, applied)
+	assert.Equal(t, 5.0, p1.Price)
+	assert.Equal(t, 10.0, due[0].RegularPrice)
+	assert.Equal(t, models.PriceChangeStatusActive, due[0].Status)
+	assert.Equal(t, 7.0, p2.Price)
+	assert.Equal(t, models.PriceChangeStatusCompleted, due[1].Status)
+	pricesMock.AssertExpectations(t)
+}
+
+func TestApplyDueChanges_RegularChangeDuringSale(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	prodMock := new(ProductsRepoMock)
+	svc := newTestService(pricesMock, prodMock)
+
+	due := []models.ScheduledPriceChange{
+		{ID: 4, ProductID: 1, Kind: models.PriceChangeKindRegular, Price: 12, StartsAt: fixedNow, Status: models.PriceChangeStatusPending},
+	}
+	active := &models.ScheduledPriceChange{ID: 1, ProductID: 1, Kind: models.PriceChangeKindSale, Price: 5, RegularPrice: 10, Status: models.PriceChangeStatusActive}
+	pricesMock.On("GetDueChanges", fixedNow).Return(due, nil)
+	prodMock.On("GetProductByID", uint(1)).Return(&models.Product{Model: gorm.Model{ID: 1}, Price: 5}, nil)
+	pricesMock.On("GetActiveSale", uint(1)).Return(active, nil)
+	pricesMock.On("ApplyTransition", (*models.Product)(nil), mock.MatchedBy(func(e *models.PriceHistory) bool {
+		return e.Reason == models.PriceReasonRegularChanged && e.Price == 12 && e.PreviousPrice == 10
+	}), mock.Anything).Return(nil)
+
+	applied, err := svc.ApplyDueChanges()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, 12.0, active.RegularPrice)
+	assert.Equal(t, models.PriceChangeStatusApplied, due[0].Status)
+}
+
+func TestApplyDueChanges_Error(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	svc := newTestService(pricesMock, new(ProductsRepoMock))
+
+	pricesMock.On("GetDueChanges", fixedNow).Return(nil, errors.New("db err"))
+	_, err := svc.ApplyDueChanges()
+	assert.EqualError(t, err, "db err")
+}
+
+func TestCancelScheduledChange(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	svc := newTestService(pricesMock, new(ProductsRepoMock))
+
+	change := &models.ScheduledPriceChange{ID: 5, ProductID: 1, Status: models.PriceChangeStatusPending}
+	pricesMock.On("GetScheduledChangeByID", uint(5)).Return(change, nil)
+	pricesMock.On("ApplyTransition", (*models.Product)(nil), (*models.PriceHistory)(nil),
+		[]repo.ChangeUpdate{{Change: change, From: models.PriceChangeStatusPending}}).Return(nil)
+
+	assert.NoError(t, svc.CancelScheduledChange(1, 5))
+	assert.Equal(t, models.PriceChangeStatusCancelled, change.Status)
+}
+
+func TestCancelScheduledChange_AlreadyApplied(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	svc := newTestService(pricesMock, new(ProductsRepoMock))
+
+	pricesMock.On("GetScheduledChangeByID", uint(5)).Return(&models.ScheduledPriceChange{ID: 5, ProductID: 1, Status: models.PriceChangeStatusPending}, nil)
+	pricesMock.On("ApplyTransition", mock.Anything, mock.Anything, mock.Anything).Return(repo.ErrChangeClaimed)
+
+	assert.Equal(t, ErrChangeNotPending, svc.CancelScheduledChange(1, 5))
+}
+
+func TestCancelScheduledChange_WrongProduct(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	svc := newTestService(pricesMock, new(ProductsRepoMock))
+
+	pricesMock.On("GetScheduledChangeByID", uint(5)).Return(&models.ScheduledPriceChange{ID: 5, ProductID: 2}, nil)
+	assert.Equal(t, ErrChangeNotFound, svc.CancelScheduledChange(1, 5))
+}
+
+func TestGetPriceTimeline(t *testing.T) {
+	pricesMock := new(PricesRepoMock)
+	prodMock := new(ProductsRepoMock)
+	svc := newTestService(pricesMock, prodMock)
+
+	prodMock.On("GetProductByID", uint(1)).Return(&models.Product{Price: 5}, nil)
+	history := []models.PriceHistory{{ID: 1, ProductID: 1, Price: 10}, {ID: 2, ProductID: 1, Price: 5}}
+	pricesMock.On("GetPriceHistory", uint(1)).Return(history, nil)
+	pricesMock.On("GetScheduledChanges", uint(1)).Return([]models.ScheduledPriceChange{
+		{ID: 1, Kind: models.PriceChangeKindSale, Price: 5, RegularPrice: 10, Status: models.PriceChangeStatusActive},
+		{ID: 2, Kind: models.PriceChangeKindRegular, Price: 11, Status: models.PriceChangeStatusPending},
+		{ID: 3, Kind: models.PriceChangeKindRegular, Price: 9, Status: models.PriceChangeStatusCancelled},
+	}, nil)
+
+	res, err := svc.GetPriceTimeline(1)
+	assert.NoError(t, err)
+	assert.True(t, res.OnSale)
+	assert.Equal(t, 5.0, res.CurrentPrice)
+	assert.Equal(t, 10.0, res.RegularPrice)
+	assert.Equal(t, history, res.History)
+	assert.Len(t, res.Upcoming, 2)
+}
@@ -0,0 +1,110 @@
+package services_price
+
+import (
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/prices_repo"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// PricesRepoMock mocks repo.PricesRepository for service tests.
+type PricesRepoMock struct {
+	mock.Mock
+}
+
+func (m *PricesRepoMock) CreateScheduledChange(change *models.ScheduledPriceChange) (*models.ScheduledPriceChange, error) {
+	args := m.Called(change)
+	if res := args.Get(0); res != nil {
+		return res.(*models.ScheduledPriceChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PricesRepoMock) GetScheduledChangeByID(id uint) (*models.ScheduledPriceChange, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.ScheduledPriceChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PricesRepoMock) GetScheduledChanges(productID uint) ([]models.ScheduledPriceChange, error) {
+	args := m.Called(productID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.ScheduledPriceChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PricesRepoMock) GetDueChanges(now time.Time) ([]models.ScheduledPriceChange, error) {
+	args := m.Called(now)
+	if res := args.Get(0); res != nil {
+		return res.([]models.ScheduledPriceChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PricesRepoMock) GetActiveSale(productID uint) (*models.ScheduledPriceChange, error) {
+	args := m.Called(productID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.ScheduledPriceChange), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PricesRepoMock) GetPriceHistory(productID uint) ([]models.PriceHistory, error) {
+	args := m.Called(productID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.PriceHistory), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PricesRepoMock) ApplyTransition(product *models.Product, entry *models.PriceHistory, changes ...repo.ChangeUpdate) error {
+	args := m.Called(product, entry, changes)
+	return args.Error(0)
+}
+
+// ProductsRepoMock mocks repo.ProductsRepository for service tests.
+type ProductsRepoMock struct {
+	mock.Mock
+}
+
+func (m *ProductsRepoMock) GetProductByID(id uint) (*models.Product, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) UpdateProduct(product *models.Product) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *ProductsRepoMock) RestockProduct(id uint, quantity int) (*models.Product, error) {
+	args := m.Called(id, quantity)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) SaveTranslation(translation *models.ProductTranslation) error {
+	args := m.Called(translation)
+	return args.Error(0)
+}
+
+func (m *ProductsRepoMock) GetAllProducts(sort models.ProductSort) ([]models.Product, error) {
+	return nil, nil
+}
+
+func (m *ProductsRepoMock) CreateProduct(product *models.Product, createdBy string) (*models.Product, error) {
+	return nil, nil
+}
+
+func (m *ProductsRepoMock) CreateProducts(products []models.Product) ([]models.Product, error) {
+	return nil, nil
+}
@@ -0,0 +1,57 @@
+package services_price
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultSchedulerInterval = time.Minute
+
+// Scheduler periodically applies due price changes and sale windows.
+type Scheduler struct {
+	service  PriceService
+	interval time.Duration
+	logger   *logrus.Logger
+}
+
+func NewScheduler(service PriceService, interval time.Duration, logger *logrus.Logger) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultSchedulerInterval
+	}
+	return &Scheduler{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start runs the scheduler in the background until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.tick()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.tick()
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) tick() {
+	applied, err := s.service.ApplyDueChanges()
+	if err != nil {
+		s.logger.Errorln("Layer: price_scheduler, Method: tick, Error:", err)
+		return
+	}
+	if applied > 0 {
+		s.logger.Infof("Layer: price_scheduler, Method: tick, applied %d price changes", applied)
+	}
+}
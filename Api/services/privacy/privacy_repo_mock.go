@@ -0,0 +1,57 @@
+package services_privacy
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// PrivacyRepoMock mocks repo.PrivacyRepository for service tests.
+type PrivacyRepoMock struct {
+	mock.Mock
+}
+
+func (m *PrivacyRepoMock) GetUserData(userID uint) (*models.UserDataExport, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.UserDataExport), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PrivacyRepoMock) UserExists(userID uint) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *PrivacyRepoMock) HasPendingErasure(userID uint) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *PrivacyRepoMock) CreateErasureRequest(request *models.ErasureRequest) error {
+	return m.Called(request).Error(0)
+}
+
+func (m *PrivacyRepoMock) GetErasureRequests() ([]models.ErasureRequest, error) {
+	args := m.Called()
+	if res := args.Get(0); res != nil {
+		return res.([]models.ErasureRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PrivacyRepoMock) CompleteErasure(requestID uint, at time.Time) error {
+	return m.Called(requestID, at).Error(0)
+}
+
+// UserEraserMock mocks UserEraser for service tests.
+type UserEraserMock struct {
+	mock.Mock
+}
+
+func (m *UserEraserMock) AnonymizeUser(userID uint, at time.Time) error {
+	return m.Called(userID, at).Error(0)
+}
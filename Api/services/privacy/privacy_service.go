@@ -0,0 +1,166 @@
+package services_privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/privacy_repo"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrUserNotFound   = apperr.NotFound("user_not_found", "user not found")
+	ErrErasurePending = apperr.Conflict("erasure_pending", "an erasure of this user is already pending")
+)
+
+// PrivacyService answers data subject requests: users can download their
+// personal data and admins can have a user's data erased.
+type PrivacyService interface {
+	ExportUserData(user *models.User) ([]byte, error)
+	RequestErasure(userID uint, requestedBy, reason string) (*models.ErasureRequest, error)
+	ListErasureRequests() ([]models.ErasureRequest, error)
+}
+
+// UserEraser pseudonymises a user's personal data.
+type UserEraser interface {
+	AnonymizeUser(userID uint, at time.Time) error
+}
+
+type privacyService struct {
+	repo   repo.PrivacyRepository
+	users  UserEraser
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+func NewPrivacyService(repo repo.PrivacyRepository, users UserEraser, logger *logrus.Logger) *privacyService {
+	return &privacyService{repo: repo, users: users, logger: logger, now: time.Now}
+}
+
+// ExportUserData returns a ZIP archive with one JSON file per kind of
+// record: profile, addresses, orders (with their lines), sessions and
+// reviews.
+func (s *privacyService) ExportUserData(user *models.User) ([]byte, error) {
+	data, err := s.repo.GetUserData(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	data.Profile = models.ExportedProfile{
+		ID:               user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		Role:             user.Role,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	modified := s.now()
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", data.Profile},
+		{"addresses.json", data.Addresses},
+		{"orders.json", data.Orders},
+		{"sessions.json", data.Sessions},
+		{"reviews.json", data.Reviews},
+	}
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		s.logger.Errorln("Layer: privacy_service, Method: ExportUserData, Error:", err)
+		return nil, err
+	}
+
+	s.logger.Infof("User %d exported their personal data", user.ID)
+	return buf.Bytes(), nil
+}
+
+// RequestErasure records the request and queues the erasure, which runs in
+// the background through the outbox.
+func (s *privacyService) RequestErasure(userID uint, requestedBy, reason string) (*models.ErasureRequest, error) {
+	exists, err := s.repo.UserExists(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	pending, err := s.repo.HasPendingErasure(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrErasurePending
+	}
+
+	request := &models.ErasureRequest{
+		UserID:      userID,
+		RequestedBy: requestedBy,
+		Reason:      reason,
+		Status:      models.ErasureStatusPending,
+	}
+	if err := s.repo.CreateErasureRequest(request); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("Erasure %d of user %d requested by %s", request.ID, userID, requestedBy)
+	return request, nil
+}
+
+func (s *privacyService) ListErasureRequests() ([]models.ErasureRequest, error) {
+	requests, err := s.repo.GetErasureRequests()
+	if err != nil {
+		return nil, err
+	}
+	if requests == nil {
+		requests = []models.ErasureRequest{}
+	}
+	return requests, nil
+}
+
+func (s *privacyService) Name() string {
+	return "data_erasure"
+}
+
+// Handle carries out a UserErasureRequested event. Erasing is repeatable,
+// so a retried event simply erases again before completing the request.
+func (s *privacyService) Handle(event models.OutboxEvent) error {
+	if event.Type != models.EventUserErasureRequested {
+		return nil
+	}
+	var erasure models.UserErasure
+	if err := json.Unmarshal([]byte(event.Payload), &erasure); err != nil {
+		return err
+	}
+
+	now := s.now()
+	if err := s.users.AnonymizeUser(erasure.UserID, now); err != nil {
+		s.logger.Errorln("Layer: privacy_service, Method: Handle, Error:", err)
+		return err
+	}
+	if err := s.repo.CompleteErasure(erasure.RequestID, now); err != nil {
+		return err
+	}
+	s.logger.Infof("Erasure %d of user %d completed", erasure.RequestID, erasure.UserID)
+	return nil
+}
@@ -0,0 +1,128 @@
+package services_privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var testNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestService() (*privacyService, *PrivacyRepoMock, *UserEraserMock) {
+	repoMock := new(PrivacyRepoMock)
+	usersMock := new(UserEraserMock)
+	svc := NewPrivacyService(repoMock, usersMock, logrus.New())
+	svc.now = func() time.Time { return testNow }
+	return svc, repoMock, usersMock
+}
+
+func readZip(t *testing.T, archive []byte) map[string][]byte {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestExportUserData_LeavesOutSecrets(t *testing.T) {
+	svc, repoMock, _ := newTestService()
+	repoMock.On("GetUserData", uint(3)).Return(&models.UserDataExport{
+		Orders:   []models.Order{{ID: 7, UserID: 3, OrderItems: []models.OrderProduct{{ProductID: 10, Quantity: 2}}}},
+		Sessions: []models.Session{{ID: 1, TokenID: "sid", IP: "203.0.113.7"}},
+		Reviews:  []models.Review{},
+	}, nil)
+	user := &models.User{
+		Model: gorm.Model{ID: 3}, Username: "ana", Email: "ana@example.com",
+		Password: "hashed", Token: "tok", TOTPSecret: "SECRET",
+	}
+
+	archive, err := svc.ExportUserData(user)
+	require.NoError(t, err)
+
+	files := readZip(t, archive)
+	assert.Len(t, files, 5)
+	var profile map[string]interface{}
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, "ana@example.com", profile["email"])
+	for name, content := range files {
+		assert.NotContains(t, string(content), "hashed", name)
+		assert.NotContains(t, string(content), "SECRET", name)
+		assert.NotContains(t, string(content), `"sid"`, name)
+	}
+	var orders []models.Order
+	require.NoError(t, json.Unmarshal(files["orders.json"], &orders))
+	require.Len(t, orders, 1)
+	assert.Len(t, orders[0].OrderItems, 1)
+}
+
+func TestRequestErasure(t *testing.T) {
+	svc, repoMock, _ := newTestService()
+	repoMock.On("UserExists", uint(3)).Return(true, nil)
+	repoMock.On("HasPendingErasure", uint(3)).Return(false, nil)
+	repoMock.On("CreateErasureRequest", mock.MatchedBy(func(r *models.ErasureRequest) bool {
+		return r.UserID == 3 && r.RequestedBy == "admin@example.com" && r.Status == models.ErasureStatusPending
+	})).Return(nil)
+
+	request, err := svc.RequestErasure(3, "admin@example.com", "art. 17")
+	require.NoError(t, err)
+	assert.Equal(t, "art. 17", request.Reason)
+}
+
+func TestRequestErasure_Rejected(t *testing.T) {
+	svc, repoMock, _ := newTestService()
+	repoMock.On("UserExists", uint(9)).Return(false, nil)
+	repoMock.On("UserExists", uint(3)).Return(true, nil)
+	repoMock.On("HasPendingErasure", uint(3)).Return(true, nil)
+
+	_, err := svc.RequestErasure(9, "admin@example.com", "art. 17")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	_, err = svc.RequestErasure(3, "admin@example.com", "art. 17")
+	assert.ErrorIs(t, err, ErrErasurePending)
+	repoMock.AssertNotCalled(t, "CreateErasureRequest", mock.Anything)
+}
+
+func TestHandle_ErasesAndCompletes(t *testing.T) {
+	svc, repoMock, usersMock := newTestService()
+	event, err := models.NewOutboxEvent(models.EventUserErasureRequested, models.AggregateUser, 3, models.UserErasure{RequestID: 5, UserID: 3})
+	require.NoError(t, err)
+	usersMock.On("AnonymizeUser", uint(3), testNow).Return(nil)
+	repoMock.On("CompleteErasure", uint(5), testNow).Return(nil)
+
+	require.NoError(t, svc.Handle(event))
+	repoMock.AssertExpectations(t)
+}
+
+func TestHandle_FailureIsRetried(t *testing.T) {
+	svc, repoMock, usersMock := newTestService()
+	event, err := models.NewOutboxEvent(models.EventUserErasureRequested, models.AggregateUser, 3, models.UserErasure{RequestID: 5, UserID: 3})
+	require.NoError(t, err)
+	usersMock.On("AnonymizeUser", uint(3), testNow).Return(errors.New("db down"))
+
+	assert.Error(t, svc.Handle(event))
+	repoMock.AssertNotCalled(t, "CompleteErasure", mock.Anything, mock.Anything)
+}
+
+func TestHandle_IgnoresOtherEvents(t *testing.T) {
+	svc, _, usersMock := newTestService()
+	assert.NoError(t, svc.Handle(models.OutboxEvent{Type: models.EventUserRegistered}))
+	usersMock.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+}
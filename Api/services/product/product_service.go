@@ -1,16 +1,32 @@
 package services
 
 import (
+	"errors"
+
 	"pruebaVertice/Api/models"
 	repo "pruebaVertice/Api/repo/products_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/i18n"
 
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound = apperr.NotFound("product_not_found", "product not found")
+	ErrInvalidQuantity = apperr.Invalid("invalid_restock_quantity", "invalid restock quantity")
+	ErrInvalidSort     = apperr.BadRequest("invalid_product_sort", "invalid product sort")
+	ErrUnsupportedLang = apperr.Invalid("unsupported_language", "unsupported language")
 )
 
+var sortableColumns = map[string]bool{"rating": true, "price": true, "name": true, "created_at": true}
+
 type ProductService interface {
 	CreateProducts(products []models.Product) ([]models.Product, error)
 	GetProductByID(id uint) (*models.Product, error)
-	GetAllProducts() ([]models.Product, error)
+	GetAllProducts(sort models.ProductSort) ([]models.Product, error)
+	RestockProduct(id uint, quantity int) (*models.Product, error)
+	SetTranslation(id uint, lang string, req models.ProductTranslationRequest) (*models.ProductTranslation, error)
 }
 
 type productService struct {
@@ -35,13 +51,89 @@ func (s *productService) CreateProducts(products []models.Product) ([]models.Pro
 }
 
 func (s *productService) GetProductByID(id uint) (*models.Product, error) {
-	return s.repo.GetProductByID(id)
+	product, err := s.repo.GetProductByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	}
+	return product, err
 }
-func (s *productService) GetAllProducts() ([]models.Product, error) {
-	products, err := s.repo.GetAllProducts()
+func (s *productService) GetAllProducts(sort models.ProductSort) ([]models.Product, error) {
+	sort, err := normalizeSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.GetAllProducts(sort)
 	if err != nil {
 		s.logger.Errorln("Layer: product_service, Method: GetAllProducts, Error:", err)
 		return nil, err
 	}
 	return products, nil
 }
+
+// RestockProduct adds quantity units to the product's stock. The stock change
+// is recorded in the outbox so back-in-stock subscribers can be notified.
+func (s *productService) RestockProduct(id uint, quantity int) (*models.Product, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity.Detailf("invalid restock quantity: quantity must be greater than zero")
+	}
+	product, err := s.repo.RestockProduct(id, quantity)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		s.logger.Errorln("Layer: product_service, Method: RestockProduct, Error:", err)
+		return nil, err
+	}
+	return product, nil
+}
+
+// SetTranslation stores the name and description of the product in lang,
+// replacing any previous translation into it.
+func (s *productService) SetTranslation(id uint, lang string, req models.ProductTranslationRequest) (*models.ProductTranslation, error) {
+	if !i18n.Supports(lang) {
+		return nil, ErrUnsupportedLang.Detailf("unsupported language: %q", lang)
+	}
+	if _, err := s.repo.GetProductByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		s.logger.Errorln("Layer: product_service, Method: SetTranslation, Error:", err)
+		return nil, err
+	}
+	translation := &models.ProductTranslation{
+		ProductID:   id,
+		Language:    lang,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.repo.SaveTranslation(translation); err != nil {
+		s.logger.Errorln("Layer: product_service, Method: SetTranslation, Error:", err)
+		return nil, err
+	}
+	return translation, nil
+}
+
+// normalizeSort checks the requested order. Ratings default to best first,
+// every other column to ascending.
+func normalizeSort(sort models.ProductSort) (models.ProductSort, error) {
+	if sort.SortBy == "" {
+		if sort.SortDir != "" {
+			return sort, ErrInvalidSort.Detailf("invalid product sort: sort_dir requires sort_by")
+		}
+		return sort, nil
+	}
+	if !sortableColumns[sort.SortBy] {
+		return sort, ErrInvalidSort.Detailf("invalid product sort: cannot sort by %q", sort.SortBy)
+	}
+	switch sort.SortDir {
+	case "":
+		sort.SortDir = "asc"
+		if sort.SortBy == "rating" {
+			sort.SortDir = "desc"
+		}
+	case "asc", "desc":
+	default:
+		return sort, ErrInvalidSort.Detailf("invalid product sort: sort_dir must be 'asc' or 'desc'")
+	}
+	return sort, nil
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestCreateProducts_Success(t *testing.T) {
@@ -64,14 +66,24 @@ func TestGetProductByID_Error(t *testing.T) {
 	assert.Equal(t, errMock, err)
 }
 
+func TestGetProductByID_NotFound(t *testing.T) {
+	repoMock := new(ProductsRepoMock)
+	svc := NewProductsService(repoMock, logrus.New())
+
+	repoMock.On("GetProductByID", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.GetProductByID(2)
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
 func TestGetAllProducts_Success(t *testing.T) {
 	repoMock := new(ProductsRepoMock)
 	svc := NewProductsService(repoMock, logrus.New())
 
 	existing := []models.Product{{Model: models.Product{}.Model, Name: "A"}}
-	repoMock.On("GetAllProducts").Return(existing, nil)
+	repoMock.On("GetAllProducts", models.ProductSort{}).Return(existing, nil)
 
-	res, err := svc.GetAllProducts()
+	res, err := svc.GetAllProducts(models.ProductSort{})
 	assert.NoError(t, err)
 	assert.Equal(t, existing, res)
 	repoMock.AssertExpectations(t)
@@ -82,10 +94,90 @@ func TestGetAllProducts_Error(t *testing.T) {
 	svc := NewProductsService(repoMock, logrus.New())
 
 	errMock := errors.New("db error")
-	repoMock.On("GetAllProducts").Return(nil, errMock)
+	repoMock.On("GetAllProducts", models.ProductSort{}).Return(nil, errMock)
 
-	res, err := svc.GetAllProducts()
+	res, err := svc.GetAllProducts(models.ProductSort{})
 	assert.Error(t, err)
 	assert.Nil(t, res)
 	assert.Equal(t, errMock, err)
 }
+
+func TestRestockProduct_Success(t *testing.T) {
+	repoMock := new(ProductsRepoMock)
+	svc := NewProductsService(repoMock, logrus.New())
+
+	restocked := &models.Product{Name: "P3", Stock: 7}
+	repoMock.On("RestockProduct", uint(3), 7).Return(restocked, nil)
+
+	res, err := svc.RestockProduct(3, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, restocked, res)
+	repoMock.AssertExpectations(t)
+}
+
+func TestRestockProduct_InvalidQuantity(t *testing.T) {
+	repoMock := new(ProductsRepoMock)
+	svc := NewProductsService(repoMock, logrus.New())
+
+	_, err := svc.RestockProduct(3, 0)
+	assert.ErrorIs(t, err, ErrInvalidQuantity)
+	repoMock.AssertNotCalled(t, "RestockProduct", uint(3), 0)
+}
+
+func TestRestockProduct_NotFound(t *testing.T) {
+	repoMock := new(ProductsRepoMock)
+	svc := NewProductsService(repoMock, logrus.New())
+
+	repoMock.On("RestockProduct", uint(9), 1).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.RestockProduct(9, 1)
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
+func TestGetAllProducts_SortByRatingDefaultsToDesc(t *testing.T) {
+	repoMock := new(ProductsRepoMock)
+	svc := NewProductsService(repoMock, logrus.New())
+
+	repoMock.On("GetAllProducts", models.ProductSort{SortBy: "rating", SortDir: "desc"}).Return([]models.Product{}, nil)
+
+	_, err := svc.GetAllProducts(models.ProductSort{SortBy: "rating"})
+	assert.NoError(t, err)
+	repoMock.AssertExpectations(t)
+}
+
+func TestGetAllProducts_InvalidSort(t *testing.T) {
+	svc := NewProductsService(new(ProductsRepoMock), logrus.New())
+
+	_, err := svc.GetAllProducts(models.ProductSort{SortBy: "password"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+	_, err = svc.GetAllProducts(models.ProductSort{SortBy: "price", SortDir: "up"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+}
+
+func TestSetTranslation(t *testing.T) {
+	repoMock := new(ProductsRepoMock)
+	svc := NewProductsService(repoMock, logrus.New())
+
+	want := &models.ProductTranslation{ProductID: 4, Language: "es", Name: "Silla", Description: "De madera"}
+	repoMock.On("GetProductByID", uint(4)).Return(&models.Product{Name: "Chair"}, nil)
+	repoMock.On("SaveTranslation", want).Return(nil)
+
+	res, err := svc.SetTranslation(4, "es", models.ProductTranslationRequest{Name: "Silla", Description: "De madera"})
+	assert.NoError(t, err)
+	assert.Equal(t, want, res)
+	repoMock.AssertExpectations(t)
+}
+
+func TestSetTranslation_Errors(t *testing.T) {
+	repoMock := new(ProductsRepoMock)
+	svc := NewProductsService(repoMock, logrus.New())
+
+	_, err := svc.SetTranslation(4, "fr", models.ProductTranslationRequest{Name: "Chaise"})
+	assert.ErrorIs(t, err, ErrUnsupportedLang)
+	assert.EqualError(t, err, `unsupported language: "fr"`)
+
+	repoMock.On("GetProductByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.SetTranslation(9, "es", models.ProductTranslationRequest{Name: "Silla"})
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	repoMock.AssertNotCalled(t, "SaveTranslation", mock.Anything)
+}
@@ -27,8 +27,8 @@ func (m *ProductsRepoMock) GetProductByID(id uint) (*models.Product, error) {
 	return nil, args.Error(1)
 }
 
-func (m *ProductsRepoMock) GetAllProducts() ([]models.Product, error) {
-	args := m.Called()
+func (m *ProductsRepoMock) GetAllProducts(sort models.ProductSort) ([]models.Product, error) {
+	args := m.Called(sort)
 	if res := args.Get(0); res != nil {
 		return res.([]models.Product), args.Error(1)
 	}
@@ -40,6 +40,19 @@ func (m *ProductsRepoMock) UpdateProduct(product *models.Product) error {
 	return args.Error(0)
 }
 
+func (m *ProductsRepoMock) RestockProduct(id uint, quantity int) (*models.Product, error) {
+	args := m.Called(id, quantity)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) SaveTranslation(translation *models.ProductTranslation) error {
+	args := m.Called(translation)
+	return args.Error(0)
+}
+
 func (m *ProductsRepoMock) CreateProduct(product *models.Product, createdBy string) (*models.Product, error) {
 	args := m.Called(product, createdBy)
 	if res := args.Get(0); res != nil {
@@ -0,0 +1,172 @@
+package services_realtime
+
+import (
+	"encoding/json"
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/outbox_repo"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event names sent to clients.
+const (
+	EventOrderStatus = "order_status"
+	EventStock       = "stock"
+)
+
+const (
+	// bufferSize is how many messages a slow client may fall behind before
+	// it is disconnected. It reconnects and resumes with Last-Event-ID.
+	bufferSize = 256
+	// replayLimit caps how many missed events are replayed on resume.
+	replayLimit = 200
+	// seenLimit bounds the IDs remembered to drop duplicates.
+	seenLimit = 1024
+)
+
+var streamedEvents = []string{
+	models.EventOrderPlaced,
+	models.EventOrderCancelled,
+	models.EventOrderStatusChanged,
+	models.EventStockChanged,
+}
+
+// Message is one update pushed to a client. ID is the outbox event ID, so a
+// client resuming with the last ID it saw gets everything after it.
+type Message struct {
+	ID    uint        `json:"id"`
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+
+	userID    uint
+	productID uint
+}
+
+type OrderStatusUpdate struct {
+	OrderID    uint      `json:"order_id"`
+	Status     string    `json:"status"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type StockUpdate struct {
+	ProductID  uint      `json:"product_id"`
+	Stock      int       `json:"stock"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type RealtimeService interface {
+	Subscribe(userID uint, productIDs []uint, lastEventID uint) (*Subscription, error)
+	Unsubscribe(sub *Subscription)
+}
+
+// Hub fans order status changes out to their owner and stock changes to
+// the clients watching the product. It is fed by the outbox dispatcher as
+// one of its sinks.
+type Hub struct {
+	repo   repo.OutboxRepository
+	logger *logrus.Logger
+
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+func NewHub(repo repo.OutboxRepository, logger *logrus.Logger) *Hub {
+	return &Hub{
+		repo:   repo,
+		logger: logger,
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+func (h *Hub) Name() string {
+	return "realtime"
+}
+
+// Handle broadcasts an outbox event to the interested subscribers. It never
+// fails: a client that misses an event catches up when it resumes.
+func (h *Hub) Handle(event models.OutboxEvent) error {
+	msg, ok := toMessage(event)
+	if !ok {
+		return nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs {
+		sub.deliver(msg)
+	}
+	return nil
+}
+
+// Subscribe registers a client of userID watching productIDs. With a
+// lastEventID, the events it missed are queued before any live update.
+func (h *Hub) Subscribe(userID uint, productIDs []uint, lastEventID uint) (*Subscription, error) {
+	sub := &Subscription{
+		userID:    userID,
+		products:  make(map[uint]bool),
+		seen:      make(map[uint]struct{}),
+		c:         make(chan Message, bufferSize),
+		replaying: lastEventID > 0,
+	}
+	sub.Watch(productIDs...)
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	if lastEventID == 0 {
+		return sub, nil
+	}
+
+	events, err := h.repo.GetEventsAfter(lastEventID, streamedEvents, replayLimit)
+	if err != nil {
+		h.logger.Errorln("Layer: realtime_hub, Method: Subscribe, Error:", err)
+		h.Unsubscribe(sub)
+		return nil, err
+	}
+	backlog := make([]Message, 0, len(events))
+	for _, event := range events {
+		if msg, ok := toMessage(event); ok {
+			backlog = append(backlog, msg)
+		}
+	}
+	sub.finishReplay(backlog)
+	return sub, nil
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+	sub.close()
+}
+
+func toMessage(event models.OutboxEvent) (Message, bool) {
+	switch event.Type {
+	case models.EventOrderPlaced, models.EventOrderCancelled, models.EventOrderStatusChanged:
+		var order models.Order
+		if err := json.Unmarshal([]byte(event.Payload), &order); err != nil {
+			return Message{}, false
+		}
+		return Message{
+			ID:     event.ID,
+			Event:  EventOrderStatus,
+			Data:   OrderStatusUpdate{OrderID: order.ID, Status: order.Status, OccurredAt: event.OccurredAt},
+			userID: order.UserID,
+		}, true
+	case models.EventStockChanged:
+		var change models.StockChange
+		if err := json.Unmarshal([]byte(event.Payload), &change); err != nil {
+			return Message{}, false
+		}
+		return Message{
+			ID:        event.ID,
+			Event:     EventStock,
+			Data:      StockUpdate{ProductID: change.ProductID, Stock: change.Stock, OccurredAt: event.OccurredAt},
+			productID: change.ProductID,
+		}, true
+	}
+	return Message{}, false
+}
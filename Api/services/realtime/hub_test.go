@@ -0,0 +1,120 @@
+package services_realtime
+
+import (
+	"errors"
+	"fmt"
+	"pruebaVertice/Api/models"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func orderEvent(id uint, eventType string, orderID, userID uint, status string) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:      id,
+		Type:    eventType,
+		Payload: fmt.Sprintf(`{"id":%d,"user_id":%d,"status":%q}`, orderID, userID, status),
+	}
+}
+
+func stockEvent(id, productID uint, stock int) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:      id,
+		Type:    models.EventStockChanged,
+		Payload: fmt.Sprintf(`{"product_id":%d,"previous_stock":%d,"stock":%d}`, productID, stock+1, stock),
+	}
+}
+
+func drain(sub *Subscription) []uint {
+	var ids []uint
+	for {
+		select {
+		case msg, ok := <-sub.C():
+			if !ok {
+				return ids
+			}
+			ids = append(ids, msg.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestHandle_RoutesToOwnerAndWatchers(t *testing.T) {
+	hub := NewHub(new(OutboxRepoMock), logrus.New())
+	alice, err := hub.Subscribe(1, []uint{10}, 0)
+	require.NoError(t, err)
+	bob, err := hub.Subscribe(2, nil, 0)
+	require.NoError(t, err)
+
+	assert.NoError(t, hub.Handle(orderEvent(1, models.EventOrderStatusChanged, 5, 1, models.OrderStatusShipped)))
+	assert.NoError(t, hub.Handle(stockEvent(2, 10, 3)))
+	assert.NoError(t, hub.Handle(stockEvent(3, 11, 0)))
+	assert.NoError(t, hub.Handle(models.OutboxEvent{ID: 4, Type: models.EventUserRegistered, Payload: `{}`}))
+
+	bob.Watch(11)
+	assert.NoError(t, hub.Handle(stockEvent(5, 11, 4)))
+
+	msg := <-alice.C()
+	assert.Equal(t, Message{ID: 1, Event: EventOrderStatus, Data: OrderStatusUpdate{OrderID: 5, Status: models.OrderStatusShipped}, userID: 1}, msg)
+	assert.Equal(t, []uint{2}, drain(alice))
+	assert.Equal(t, []uint{5}, drain(bob))
+}
+
+func TestSubscribe_ReplaysMissedEventsWithoutDuplicates(t *testing.T) {
+	repoMock := new(OutboxRepoMock)
+	hub := NewHub(repoMock, logrus.New())
+
+	live, err := hub.Subscribe(1, nil, 0)
+	require.NoError(t, err)
+
+	repoMock.On("GetEventsAfter", uint(7), streamedEvents, replayLimit).
+		Run(func(mock.Arguments) {
+			// Published while the backlog is being read.
+			hub.Handle(orderEvent(9, models.EventOrderCancelled, 3, 1, models.OrderStatusCancelled))
+			hub.Handle(orderEvent(10, models.EventOrderPlaced, 4, 1, models.OrderStatusPlaced))
+		}).
+		Return([]models.OutboxEvent{
+			orderEvent(8, models.EventOrderPlaced, 3, 1, models.OrderStatusPlaced),
+			orderEvent(9, models.EventOrderCancelled, 3, 1, models.OrderStatusCancelled),
+			orderEvent(11, models.EventOrderPlaced, 6, 2, models.OrderStatusPlaced),
+		}, nil)
+
+	resumed, err := hub.Subscribe(1, nil, 7)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{8, 9, 10}, drain(resumed))
+	assert.Equal(t, []uint{9, 10}, drain(live))
+
+	// A redelivery by the outbox is not sent twice.
+	hub.Handle(orderEvent(10, models.EventOrderPlaced, 4, 1, models.OrderStatusPlaced))
+	assert.Empty(t, drain(resumed))
+}
+
+func TestSubscribe_ReplayError(t *testing.T) {
+	repoMock := new(OutboxRepoMock)
+	hub := NewHub(repoMock, logrus.New())
+	repoMock.On("GetEventsAfter", uint(7), streamedEvents, replayLimit).Return(nil, errors.New("db err"))
+
+	_, err := hub.Subscribe(1, nil, 7)
+	assert.EqualError(t, err, "db err")
+	assert.Empty(t, hub.subs)
+}
+
+func TestSlowClientIsDisconnected(t *testing.T) {
+	hub := NewHub(new(OutboxRepoMock), logrus.New())
+	sub, err := hub.Subscribe(1, []uint{10}, 0)
+	require.NoError(t, err)
+
+	for i := 1; i <= bufferSize+1; i++ {
+		hub.Handle(stockEvent(uint(i), 10, i))
+	}
+	assert.Len(t, drain(sub), bufferSize)
+	_, open := <-sub.C()
+	assert.False(t, open)
+
+	hub.Unsubscribe(sub)
+	assert.Empty(t, hub.subs)
+}
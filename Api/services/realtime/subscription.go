@@ -0,0 +1,116 @@
+package services_realtime
+
+import "sync"
+
+// Subscription is one connected client. Messages are read from C until it
+// is closed, either by Unsubscribe or because the client fell too far
+// behind.
+type Subscription struct {
+	userID uint
+
+	mu        sync.Mutex
+	products  map[uint]bool
+	seen      map[uint]struct{}
+	maxSeen   uint
+	c         chan Message
+	closed    bool
+	replaying bool
+	pending   []Message
+}
+
+// C returns the channel the client's messages arrive on.
+func (s *Subscription) C() <-chan Message {
+	return s.c
+}
+
+// Watch adds products whose stock changes the client wants.
+func (s *Subscription) Watch(productIDs ...uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range productIDs {
+		s.products[id] = true
+	}
+}
+
+func (s *Subscription) Unwatch(productIDs ...uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range productIDs {
+		delete(s.products, id)
+	}
+}
+
+func (s *Subscription) deliver(msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.replaying {
+		// Held back until the replayed events have been queued.
+		s.pending = append(s.pending, msg)
+		return
+	}
+	s.send(msg)
+}
+
+// finishReplay queues the backlog followed by whatever arrived live while
+// it was being read.
+func (s *Subscription) finishReplay(backlog []Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, msg := range backlog {
+		s.send(msg)
+	}
+	for _, msg := range s.pending {
+		s.send(msg)
+	}
+	s.pending = nil
+	s.replaying = false
+}
+
+// send must be called with mu held.
+func (s *Subscription) send(msg Message) {
+	if s.closed || !s.wants(msg) {
+		return
+	}
+	if _, dup := s.seen[msg.ID]; dup {
+		return
+	}
+	s.remember(msg.ID)
+
+	select {
+	case s.c <- msg:
+	default:
+		s.closed = true
+		close(s.c)
+	}
+}
+
+func (s *Subscription) wants(msg Message) bool {
+	if msg.userID != 0 {
+		return msg.userID == s.userID
+	}
+	return s.products[msg.productID]
+}
+
+func (s *Subscription) remember(id uint) {
+	s.seen[id] = struct{}{}
+	if id > s.maxSeen {
+		s.maxSeen = id
+	}
+	if len(s.seen) <= 2*seenLimit {
+		return
+	}
+	for seen := range s.seen {
+		if seen+seenLimit < s.maxSeen {
+			delete(s.seen, seen)
+		}
+	}
+}
+
+func (s *Subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.c)
+	}
+}
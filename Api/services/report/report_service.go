@@ -0,0 +1,214 @@
+package services_report
+
+import (
+	"math"
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/reports_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	dateLayout      = "2006-01-02"
+	defaultDays     = 30
+	defaultLimit    = 10
+	maxLimit        = 100
+	maxPeriodsLimit = 1000
+)
+
+var ErrInvalidReportFilter = apperr.BadRequest("invalid_report_filter", "invalid report filter")
+
+type ReportService interface {
+	SalesReport(filter models.ReportFilter) (*dto.SalesReportResponse, error)
+	ProductSalesReport(filter models.ReportFilter) (*dto.ProductSalesReportResponse, error)
+	CustomerSalesReport(filter models.ReportFilter) (*dto.CustomerSalesReportResponse, error)
+}
+
+type reportService struct {
+	repo     repo.ReportsRepository
+	logger   *logrus.Logger
+	location *time.Location
+	now      func() time.Time
+}
+
+func NewReportService(repo repo.ReportsRepository, logger *logrus.Logger) *reportService {
+	return &reportService{
+		repo:     repo,
+		logger:   logger,
+		location: time.Local,
+		now:      time.Now,
+	}
+}
+
+// SalesReport returns revenue, order count, units and average order value
+// for the range, bucketed by day, week or month. Buckets without sales are
+// included with zeros so the series has no holes.
+func (s *reportService) SalesReport(filter models.ReportFilter) (*dto.SalesReportResponse, error) {
+	from, to, err := s.normalize(&filter)
+	if err != nil {
+		return nil, err
+	}
+	starts := periodStarts(from, to, filter.Interval)
+	if len(starts) > maxPeriodsLimit {
+		return nil, ErrInvalidReportFilter
+	}
+
+	rows, err := s.repo.SalesByPeriod(from, to, filter.Interval)
+	if err != nil {
+		s.logger.Errorln("Layer: report_service, Method: SalesReport, Error:", err)
+		return nil, err
+	}
+	byPeriod := make(map[string]models.SalesPeriod, len(rows))
+	for _, row := range rows {
+		byPeriod[row.Period] = row
+	}
+
+	report := &dto.SalesReportResponse{
+		From:     from.Format(dateLayout),
+		To:       to.AddDate(0, 0, -1).Format(dateLayout),
+		Interval: filter.Interval,
+		Periods:  make([]models.SalesPeriod, 0, len(starts)),
+	}
+	for _, start := range starts {
+		period := byPeriod[start]
+		period.Period = start
+		period.Revenue = round2(period.Revenue)
+		period.Shipping = round2(period.Shipping)
+		period.AverageOrderValue = average(period.Revenue, period.Orders)
+		report.Periods = append(report.Periods, period)
+
+		report.Revenue += period.Revenue
+		report.Shipping += period.Shipping
+		report.Orders += period.Orders
+		report.Units += period.Units
+	}
+	report.Revenue = round2(report.Revenue)
+	report.Shipping = round2(report.Shipping)
+	report.AverageOrderValue = average(report.Revenue, report.Orders)
+	return report, nil
+}
+
+func (s *reportService) ProductSalesReport(filter models.ReportFilter) (*dto.ProductSalesReportResponse, error) {
+	from, to, err := s.normalize(&filter)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ProductSales(from, to, filter.Limit)
+	if err != nil {
+		s.logger.Errorln("Layer: report_service, Method: ProductSalesReport, Error:", err)
+		return nil, err
+	}
+	if items == nil {
+		items = []models.ProductSales{}
+	}
+	for i := range items {
+		items[i].Revenue = round2(items[i].Revenue)
+	}
+	return &dto.ProductSalesReportResponse{
+		From:  from.Format(dateLayout),
+		To:    to.AddDate(0, 0, -1).Format(dateLayout),
+		Items: items,
+	}, nil
+}
+
+func (s *reportService) CustomerSalesReport(filter models.ReportFilter) (*dto.CustomerSalesReportResponse, error) {
+	from, to, err := s.normalize(&filter)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.TopCustomers(from, to, filter.Limit)
+	if err != nil {
+		s.logger.Errorln("Layer: report_service, Method: CustomerSalesReport, Error:", err)
+		return nil, err
+	}
+	if items == nil {
+		items = []models.CustomerSales{}
+	}
+	for i := range items {
+		items[i].Revenue = round2(items[i].Revenue)
+	}
+	return &dto.CustomerSalesReportResponse{
+		From:  from.Format(dateLayout),
+		To:    to.AddDate(0, 0, -1).Format(dateLayout),
+		Items: items,
+	}, nil
+}
+
+// normalize applies the defaults (last 30 days, daily buckets, top 10) and
+// returns the range as [from, to) at midnight.
+func (s *reportService) normalize(filter *models.ReportFilter) (time.Time, time.Time, error) {
+	to := s.midnight(s.now())
+	if filter.To != nil {
+		to = s.midnight(*filter.To)
+	}
+	from := to.AddDate(0, 0, 1-defaultDays)
+	if filter.From != nil {
+		from = s.midnight(*filter.From)
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, ErrInvalidReportFilter
+	}
+
+	switch filter.Interval {
+	case "":
+		filter.Interval = models.ReportIntervalDay
+	case models.ReportIntervalDay, models.ReportIntervalWeek, models.ReportIntervalMonth:
+	default:
+		return time.Time{}, time.Time{}, ErrInvalidReportFilter
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxLimit {
+		return time.Time{}, time.Time{}, ErrInvalidReportFilter
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+func (s *reportService) midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location)
+}
+
+// periodStarts lists the first day of every bucket touching [from, to),
+// formatted like the repository periods.
+func periodStarts(from, to time.Time, interval string) []string {
+	start := from
+	switch interval {
+	case models.ReportIntervalWeek:
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	case models.ReportIntervalMonth:
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	}
+
+	var starts []string
+	for ; start.Before(to) && len(starts) <= maxPeriodsLimit; start = next(start, interval) {
+		starts = append(starts, start.Format(dateLayout))
+	}
+	return starts
+}
+
+func next(t time.Time, interval string) time.Time {
+	switch interval {
+	case models.ReportIntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case models.ReportIntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+func average(revenue float64, orders int64) float64 {
+	if orders == 0 {
+		return 0
+	}
+	return round2(revenue / float64(orders))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,103 @@
+package services_report
+
+import (
+	"pruebaVertice/Api/models"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newService(repoMock *ReportsRepoMock) *reportService {
+	svc := NewReportService(repoMock, logrus.New())
+	svc.location = time.UTC
+	svc.now = func() time.Time { return time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func date(month time.Month, d int) *time.Time {
+	t := time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestSalesReport_WeeklyFillsGaps(t *testing.T) {
+	repoMock := new(ReportsRepoMock)
+	svc := newService(repoMock)
+
+	from, to := *date(3, 5), *date(3, 21)
+	repoMock.On("SalesByPeriod", from, to, models.ReportIntervalWeek).Return([]models.SalesPeriod{
+		{Period: "2025-03-03", Revenue: 80, Shipping: 7.5, Orders: 2, Units: 4},
+		{Period: "2025-03-17", Revenue: 10, Shipping: 0.004, Orders: 3, Units: 3},
+	}, nil)
+
+	report, err := svc.SalesReport(models.ReportFilter{From: date(3, 5), To: date(3, 20), Interval: models.ReportIntervalWeek})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-05", report.From)
+	assert.Equal(t, "2025-03-20", report.To)
+	assert.Equal(t, []models.SalesPeriod{
+		{Period: "2025-03-03", Revenue: 80, Shipping: 7.5, Orders: 2, Units: 4, AverageOrderValue: 40},
+		{Period: "2025-03-10"},
+		{Period: "2025-03-17", Revenue: 10, Orders: 3, Units: 3, AverageOrderValue: 3.33},
+	}, report.Periods)
+	assert.Equal(t, 90.0, report.Revenue)
+	assert.Equal(t, 7.5, report.Shipping)
+	assert.Equal(t, int64(5), report.Orders)
+	assert.Equal(t, int64(7), report.Units)
+	assert.Equal(t, 18.0, report.AverageOrderValue)
+}
+
+func TestSalesReport_Defaults(t *testing.T) {
+	repoMock := new(ReportsRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("SalesByPeriod", *date(3, 2), *date(4, 1), models.ReportIntervalDay).Return([]models.SalesPeriod{}, nil)
+
+	report, err := svc.SalesReport(models.ReportFilter{})
+	require.NoError(t, err)
+	assert.Len(t, report.Periods, 30)
+	assert.Equal(t, "2025-03-02", report.Periods[0].Period)
+	assert.Equal(t, 0.0, report.AverageOrderValue)
+}
+
+func TestSalesReport_InvalidFilter(t *testing.T) {
+	svc := newService(new(ReportsRepoMock))
+	longAgo := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, filter := range []models.ReportFilter{
+		{From: date(3, 10), To: date(3, 1)},
+		{Interval: "year"},
+		{Limit: 1000},
+		// Too many daily buckets.
+		{From: &longAgo},
+	} {
+		_, err := svc.SalesReport(filter)
+		assert.Equal(t, ErrInvalidReportFilter, err)
+	}
+}
+
+func TestProductSalesReport(t *testing.T) {
+	repoMock := new(ReportsRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("ProductSales", *date(3, 1), *date(3, 2), 5).Return([]models.ProductSales{
+		{ProductID: 1, Name: "Teclado", Units: 3, Revenue: 29.999},
+	}, nil)
+
+	report, err := svc.ProductSalesReport(models.ReportFilter{From: date(3, 1), To: date(3, 1), Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, report.Items[0].Revenue)
+}
+
+func TestCustomerSalesReport_Empty(t *testing.T) {
+	repoMock := new(ReportsRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("TopCustomers", *date(3, 2), *date(4, 1), defaultLimit).Return(nil, nil)
+
+	report, err := svc.CustomerSalesReport(models.ReportFilter{})
+	require.NoError(t, err)
+	assert.NotNil(t, report.Items)
+	assert.Empty(t, report.Items)
+}
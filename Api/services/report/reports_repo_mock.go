@@ -0,0 +1,37 @@
+package services_report
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ReportsRepoMock mocks repo.ReportsRepository for service tests.
+type ReportsRepoMock struct {
+	mock.Mock
+}
+
+func (m *ReportsRepoMock) SalesByPeriod(from, to time.Time, interval string) ([]models.SalesPeriod, error) {
+	args := m.Called(from, to, interval)
+	if res := args.Get(0); res != nil {
+		return res.([]models.SalesPeriod), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReportsRepoMock) ProductSales(from, to time.Time, limit int) ([]models.ProductSales, error) {
+	args := m.Called(from, to, limit)
+	if res := args.Get(0); res != nil {
+		return res.([]models.ProductSales), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReportsRepoMock) TopCustomers(from, to time.Time, limit int) ([]models.CustomerSales, error) {
+	args := m.Called(from, to, limit)
+	if res := args.Get(0); res != nil {
+		return res.([]models.CustomerSales), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
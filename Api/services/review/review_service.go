@@ -0,0 +1,145 @@
+package services_review
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"pruebaVertice/Api/models"
+	products_repo "pruebaVertice/Api/repo/products_repo"
+	repo "pruebaVertice/Api/repo/reviews_repo"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound = apperr.NotFound("product_not_found", "product not found")
+	ErrReviewNotFound  = apperr.NotFound("review_not_found", "review not found")
+	ErrNotPurchased    = apperr.Forbidden("product_not_purchased", "only customers who bought the product can review it")
+	ErrAlreadyReviewed = apperr.Conflict("product_already_reviewed", "product already reviewed")
+	ErrInvalidReview   = apperr.Invalid("invalid_review", "invalid review")
+	ErrInvalidStatus   = apperr.Invalid("invalid_review_status", "invalid review status")
+)
+
+const maxTitleLength = 120
+
+var reviewStatuses = map[string]bool{
+	models.ReviewStatusPending:  true,
+	models.ReviewStatusApproved: true,
+	models.ReviewStatusRejected: true,
+}
+
+type ReviewService interface {
+	CreateReview(user *models.User, productID uint, req models.ReviewRequest) (*models.Review, error)
+	GetProductReviews(productID uint) ([]models.Review, error)
+	ListReviews(status string) ([]models.Review, error)
+	ModerateReview(id uint, status, moderatedBy string) (*models.Review, error)
+}
+
+type reviewService struct {
+	repo     repo.ReviewsRepository
+	products products_repo.ProductsRepository
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+func NewReviewService(repo repo.ReviewsRepository, products products_repo.ProductsRepository, logger *logrus.Logger) *reviewService {
+	return &reviewService{repo: repo, products: products, logger: logger, now: time.Now}
+}
+
+// CreateReview stores a review pending moderation. Users can review a product
+// once, and only if they have a non-cancelled order that contains it.
+func (s *reviewService) CreateReview(user *models.User, productID uint, req models.ReviewRequest) (*models.Review, error) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, ErrInvalidReview.Detailf("invalid review: rating must be between 1 and 5")
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if len(req.Title) > maxTitleLength {
+		return nil, ErrInvalidReview.Detailf("invalid review: title must be at most %d characters", maxTitleLength)
+	}
+
+	if _, err := s.products.GetProductByID(productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	bought, err := s.repo.HasPurchased(user.ID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !bought {
+		return nil, ErrNotPurchased
+	}
+
+	_, err = s.repo.GetUserReview(user.ID, productID)
+	if err == nil {
+		return nil, ErrAlreadyReviewed
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Errorln("Layer: review_service, Method: CreateReview, Error:", err)
+		return nil, err
+	}
+
+	review := &models.Review{
+		ProductID: productID,
+		UserID:    user.ID,
+		Author:    user.Username,
+		Rating:    req.Rating,
+		Title:     req.Title,
+		Body:      strings.TrimSpace(req.Body),
+		Status:    models.ReviewStatusPending,
+	}
+	if err := s.repo.CreateReview(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// GetProductReviews returns the approved reviews of a product.
+func (s *reviewService) GetProductReviews(productID uint) ([]models.Review, error) {
+	reviews, err := s.repo.GetProductReviews(productID, models.ReviewStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+	if reviews == nil {
+		reviews = []models.Review{}
+	}
+	return reviews, nil
+}
+
+func (s *reviewService) ListReviews(status string) ([]models.Review, error) {
+	if status != "" && !reviewStatuses[status] {
+		return nil, ErrInvalidStatus.Detailf("invalid review status: %q", status)
+	}
+	reviews, err := s.repo.GetReviews(status)
+	if err != nil {
+		return nil, err
+	}
+	if reviews == nil {
+		reviews = []models.Review{}
+	}
+	return reviews, nil
+}
+
+// ModerateReview approves or rejects a review. A review can be moderated
+// again later, e.g. to take down an approved review.
+func (s *reviewService) ModerateReview(id uint, status, moderatedBy string) (*models.Review, error) {
+	if status != models.ReviewStatusApproved && status != models.ReviewStatusRejected {
+		return nil, ErrInvalidStatus.Detailf("invalid review status: status must be 'approved' or 'rejected'")
+	}
+	review, err := s.repo.GetReviewByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ModerateReview(review, status, moderatedBy, s.now()); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
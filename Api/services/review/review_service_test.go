@@ -0,0 +1,110 @@
+package services_review
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var buyer = &models.User{Model: gorm.Model{ID: 4}, Username: "ana"}
+
+func newTestService() (*reviewService, *ReviewsRepoMock, *ProductsRepoMock) {
+	repoMock := new(ReviewsRepoMock)
+	productsMock := new(ProductsRepoMock)
+	svc := NewReviewService(repoMock, productsMock, logrus.New())
+	svc.now = func() time.Time { return time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC) }
+	return svc, repoMock, productsMock
+}
+
+func TestCreateReview_Success(t *testing.T) {
+	svc, repoMock, productsMock := newTestService()
+	productsMock.On("GetProductByID", uint(7)).Return(&models.Product{Name: "Teclado"}, nil)
+	repoMock.On("HasPurchased", uint(4), uint(7)).Return(true, nil)
+	repoMock.On("GetUserReview", uint(4), uint(7)).Return(nil, gorm.ErrRecordNotFound)
+	repoMock.On("CreateReview", mock.MatchedBy(func(r *models.Review) bool {
+		return r.Author == "ana" && r.Rating == 5 && r.Title == "Genial" && r.Status == models.ReviewStatusPending
+	})).Return(nil)
+
+	review, err := svc.CreateReview(buyer, 7, models.ReviewRequest{Rating: 5, Title: "  Genial "})
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), review.ProductID)
+	repoMock.AssertExpectations(t)
+}
+
+func TestCreateReview_NotPurchased(t *testing.T) {
+	svc, repoMock, productsMock := newTestService()
+	productsMock.On("GetProductByID", uint(7)).Return(&models.Product{Name: "Teclado"}, nil)
+	repoMock.On("HasPurchased", uint(4), uint(7)).Return(false, nil)
+
+	_, err := svc.CreateReview(buyer, 7, models.ReviewRequest{Rating: 5})
+	assert.ErrorIs(t, err, ErrNotPurchased)
+	repoMock.AssertNotCalled(t, "CreateReview", mock.Anything)
+}
+
+func TestCreateReview_AlreadyReviewed(t *testing.T) {
+	svc, repoMock, productsMock := newTestService()
+	productsMock.On("GetProductByID", uint(7)).Return(&models.Product{Name: "Teclado"}, nil)
+	repoMock.On("HasPurchased", uint(4), uint(7)).Return(true, nil)
+	repoMock.On("GetUserReview", uint(4), uint(7)).Return(&models.Review{ID: 1}, nil)
+
+	_, err := svc.CreateReview(buyer, 7, models.ReviewRequest{Rating: 3})
+	assert.ErrorIs(t, err, ErrAlreadyReviewed)
+}
+
+func TestCreateReview_Validation(t *testing.T) {
+	svc, _, productsMock := newTestService()
+
+	_, err := svc.CreateReview(buyer, 7, models.ReviewRequest{Rating: 6})
+	assert.ErrorIs(t, err, ErrInvalidReview)
+
+	productsMock.On("GetProductByID", uint(8)).Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.CreateReview(buyer, 8, models.ReviewRequest{Rating: 4})
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
+func TestGetProductReviews_OnlyApproved(t *testing.T) {
+	svc, repoMock, _ := newTestService()
+	repoMock.On("GetProductReviews", uint(7), models.ReviewStatusApproved).Return(nil, nil)
+
+	reviews, err := svc.GetProductReviews(7)
+	require.NoError(t, err)
+	assert.NotNil(t, reviews)
+	assert.Empty(t, reviews)
+}
+
+func TestListReviews_InvalidStatus(t *testing.T) {
+	svc, _, _ := newTestService()
+
+	_, err := svc.ListReviews("spam")
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+}
+
+func TestModerateReview(t *testing.T) {
+	svc, repoMock, _ := newTestService()
+	review := &models.Review{ID: 2, ProductID: 7, Status: models.ReviewStatusPending}
+	repoMock.On("GetReviewByID", uint(2)).Return(review, nil)
+	repoMock.On("ModerateReview", review, models.ReviewStatusApproved, "admin@example.com", svc.now()).Return(nil)
+
+	res, err := svc.ModerateReview(2, models.ReviewStatusApproved, "admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, review, res)
+	repoMock.AssertExpectations(t)
+}
+
+func TestModerateReview_Errors(t *testing.T) {
+	svc, repoMock, _ := newTestService()
+	repoMock.On("GetReviewByID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.ModerateReview(3, models.ReviewStatusRejected, "admin@example.com")
+	assert.ErrorIs(t, err, ErrReviewNotFound)
+
+	_, err = svc.ModerateReview(3, models.ReviewStatusPending, "admin@example.com")
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+}
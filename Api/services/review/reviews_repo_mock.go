@@ -0,0 +1,113 @@
+package services_review
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ReviewsRepoMock mocks repo.ReviewsRepository for service tests.
+type ReviewsRepoMock struct {
+	mock.Mock
+}
+
+func (m *ReviewsRepoMock) HasPurchased(userID, productID uint) (bool, error) {
+	args := m.Called(userID, productID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ReviewsRepoMock) GetUserReview(userID, productID uint) (*models.Review, error) {
+	args := m.Called(userID, productID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Review), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReviewsRepoMock) CreateReview(review *models.Review) error {
+	return m.Called(review).Error(0)
+}
+
+func (m *ReviewsRepoMock) GetReviewByID(id uint) (*models.Review, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Review), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReviewsRepoMock) GetProductReviews(productID uint, status string) ([]models.Review, error) {
+	args := m.Called(productID, status)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Review), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReviewsRepoMock) GetReviews(status string) ([]models.Review, error) {
+	args := m.Called(status)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Review), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReviewsRepoMock) ModerateReview(review *models.Review, status, moderatedBy string, at time.Time) error {
+	return m.Called(review, status, moderatedBy, at).Error(0)
+}
+
+// ProductsRepoMock mocks the products repository for service tests.
+type ProductsRepoMock struct {
+	mock.Mock
+}
+
+func (m *ProductsRepoMock) GetAllProducts(sort models.ProductSort) ([]models.Product, error) {
+	args := m.Called(sort)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) CreateProduct(product *models.Product, createdBy string) (*models.Product, error) {
+	args := m.Called(product, createdBy)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) CreateProducts(products []models.Product) ([]models.Product, error) {
+	args := m.Called(products)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) GetProductByID(id uint) (*models.Product, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) UpdateProduct(product *models.Product) error {
+	return m.Called(product).Error(0)
+}
+
+func (m *ProductsRepoMock) RestockProduct(id uint, quantity int) (*models.Product, error) {
+	args := m.Called(id, quantity)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) SaveTranslation(translation *models.ProductTranslation) error {
+	args := m.Called(translation)
+	return args.Error(0)
+}
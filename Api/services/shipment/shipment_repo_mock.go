@@ -0,0 +1,105 @@
+package services_shipment
+
+import (
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/shipments_repo"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ShipmentsRepoMock mocks repo.ShipmentsRepository for service tests.
+type ShipmentsRepoMock struct {
+	mock.Mock
+}
+
+// CreateShipment hands pick the order and shipped quantities registered with
+// On("CreateShipment", orderID) and returns the shipment with the picked
+// items.
+func (m *ShipmentsRepoMock) CreateShipment(shipment *models.Shipment, pick repo.PickItems) (*models.Shipment, error) {
+	args := m.Called(shipment.OrderID)
+	if err := args.Error(2); err != nil {
+		return nil, err
+	}
+	items, err := pick(args.Get(0).(*models.Order), args.Get(1).(map[uint]int))
+	if err != nil {
+		return nil, err
+	}
+	shipment.Items = items
+	return shipment, nil
+}
+
+func (m *ShipmentsRepoMock) GetShipmentByID(id uint) (*models.Shipment, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Shipment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentsRepoMock) GetShipmentsByOrderID(orderID uint) ([]models.Shipment, error) {
+	args := m.Called(orderID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Shipment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentsRepoMock) GetShippedQuantities(orderID uint) (map[uint]int, error) {
+	args := m.Called(orderID)
+	if res := args.Get(0); res != nil {
+		return res.(map[uint]int), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentsRepoMock) AddEvent(shipment *models.Shipment, event *models.ShipmentEvent) error {
+	args := m.Called(shipment, event)
+	return args.Error(0)
+}
+
+// OrdersRepoMock mocks repo.OrdersRepository for service tests.
+type OrdersRepoMock struct {
+	mock.Mock
+}
+
+func (m *OrdersRepoMock) CreateOrder(order *models.Order) (*models.Order, error) {
+	args := m.Called(order)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Order), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersRepoMock) GetOrdersByUserID(userID uint) ([]models.Order, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Order), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersRepoMock) FindUserOrders(userID uint, filter models.OrderFilter) ([]models.Order, int64, error) {
+	args := m.Called(userID, filter)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Order), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, 0, args.Error(2)
+}
+
+func (m *OrdersRepoMock) GetOrderByID(id uint) (*models.Order, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Order), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrdersRepoMock) UpdateOrderStatus(id uint, status string) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *OrdersRepoMock) CancelOrder(order *models.Order) error {
+	args := m.Called(order)
+	return args.Error(0)
+}
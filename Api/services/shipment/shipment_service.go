@@ -0,0 +1,259 @@
+package services_shipment
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+	ordersRepo "pruebaVertice/Api/repo/orders_repo"
+	repo "pruebaVertice/Api/repo/shipments_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrderNotFound        = apperr.NotFound("order_not_found", "order not found")
+	ErrShipmentNotFound     = apperr.NotFound("shipment_not_found", "shipment not found")
+	ErrOrderNotShippable    = apperr.Conflict("order_not_shippable", "order cannot be shipped in its current status")
+	ErrNothingToShip        = apperr.Conflict("nothing_to_ship", "all order lines have already been shipped")
+	ErrInvalidShipment      = apperr.Invalid("invalid_shipment", "invalid shipment")
+	ErrInvalidEventStatus   = apperr.Invalid("invalid_shipment_status", "invalid shipment status")
+	ErrShipmentAlreadyFinal = apperr.Conflict("shipment_already_delivered", "shipment has already been delivered")
+)
+
+var eventStatuses = map[string]bool{
+	models.ShipmentStatusLabelCreated:   true,
+	models.ShipmentStatusInTransit:      true,
+	models.ShipmentStatusOutForDelivery: true,
+	models.ShipmentStatusDelivered:      true,
+	models.ShipmentStatusException:      true,
+}
+
+type ShipmentService interface {
+	CreateShipment(orderID uint, req models.CreateShipmentRequest, createdBy string) (*models.Shipment, error)
+	AddTrackingEvent(shipmentID uint, req models.ShipmentEventRequest) (*models.Shipment, error)
+	GetOrderShipments(orderID uint) ([]models.Shipment, error)
+	GetUserOrderShipments(userID, orderID uint) ([]models.Shipment, error)
+}
+
+type shipmentService struct {
+	repo      repo.ShipmentsRepository
+	orderRepo ordersRepo.OrdersRepository
+	logger    *logrus.Logger
+	now       func() time.Time
+}
+
+func NewShipmentService(repo repo.ShipmentsRepository, orderRepo ordersRepo.OrdersRepository, logger *logrus.Logger) *shipmentService {
+	return &shipmentService{
+		repo:      repo,
+		orderRepo: orderRepo,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// CreateShipment ships the requested quantities of the order lines. With no
+// items, every unit not yet shipped is included.
+func (s *shipmentService) CreateShipment(orderID uint, req models.CreateShipmentRequest, createdBy string) (*models.Shipment, error) {
+	if strings.TrimSpace(req.Carrier) == "" || strings.TrimSpace(req.TrackingNumber) == "" {
+		return nil, ErrInvalidShipment.Detailf("invalid shipment: carrier and tracking_number are required")
+	}
+
+	now := s.now()
+	shipment := &models.Shipment{
+		OrderID:        orderID,
+		Carrier:        strings.TrimSpace(req.Carrier),
+		TrackingNumber: strings.TrimSpace(req.TrackingNumber),
+		Status:         models.ShipmentStatusLabelCreated,
+		CreatedBy:      createdBy,
+		Events: []models.ShipmentEvent{{
+			Status:      models.ShipmentStatusLabelCreated,
+			Description: "Shipment created",
+			OccurredAt:  now,
+		}},
+	}
+
+	// The items are picked while the repository holds the order lock, from
+	// the quantities shipped so far.
+	var order *models.Order
+	created, err := s.repo.CreateShipment(shipment, func(locked *models.Order, shipped map[uint]int) ([]models.ShipmentItem, error) {
+		order = locked
+		if order.Status == models.OrderStatusCancelled || order.Status == models.OrderStatusDelivered {
+			return nil, ErrOrderNotShippable
+		}
+		return shipmentItems(order, shipped, req.Items)
+	})
+	if err != nil {
+		if _, ok := apperr.As(err); ok {
+			return nil, err
+		}
+		return nil, s.orderLookupError("CreateShipment", err)
+	}
+
+	if err := s.syncOrderStatus(order); err != nil {
+		s.logger.Errorln("Layer: shipment_service, Method: CreateShipment, Error: syncing order status:", err)
+	}
+	return created, nil
+}
+
+func (s *shipmentService) AddTrackingEvent(shipmentID uint, req models.ShipmentEventRequest) (*models.Shipment, error) {
+	if !eventStatuses[req.Status] {
+		return nil, ErrInvalidEventStatus
+	}
+
+	shipment, err := s.repo.GetShipmentByID(shipmentID)
+	if err != nil {
+		return nil, ErrShipmentNotFound
+	}
+	if shipment.Status == models.ShipmentStatusDelivered {
+		return nil, ErrShipmentAlreadyFinal
+	}
+
+	event := &models.ShipmentEvent{
+		Status:      req.Status,
+		Location:    req.Location,
+		Description: req.Description,
+		OccurredAt:  s.now(),
+	}
+	if req.OccurredAt != nil {
+		event.OccurredAt = *req.OccurredAt
+	}
+
+	if err := s.repo.AddEvent(shipment, event); err != nil {
+		s.logger.Errorln("Layer: shipment_service, Method: AddTrackingEvent, Error:", err)
+		return nil, err
+	}
+
+	order, err := s.orderRepo.GetOrderByID(shipment.OrderID)
+	if err == nil {
+		err = s.syncOrderStatus(order)
+	}
+	if err != nil {
+		s.logger.Errorln("Layer: shipment_service, Method: AddTrackingEvent, Error: syncing order status:", err)
+	}
+	return shipment, nil
+}
+
+func (s *shipmentService) GetOrderShipments(orderID uint) ([]models.Shipment, error) {
+	if _, err := s.orderRepo.GetOrderByID(orderID); err != nil {
+		return nil, s.orderLookupError("GetOrderShipments", err)
+	}
+	return s.listShipments(orderID)
+}
+
+// GetUserOrderShipments returns the tracking of an order owned by userID.
+func (s *shipmentService) GetUserOrderShipments(userID, orderID uint) ([]models.Shipment, error) {
+	order, err := s.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, s.orderLookupError("GetUserOrderShipments", err)
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	return s.listShipments(orderID)
+}
+
+// orderLookupError reports a missing order as ErrOrderNotFound and logs any
+// other failure, which is returned as is.
+func (s *shipmentService) orderLookupError(method string, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOrderNotFound
+	}
+	s.logger.Errorln("Layer: shipment_service, Method: "+method+", Error:", err)
+	return err
+}
+
+func (s *shipmentService) listShipments(orderID uint) ([]models.Shipment, error) {
+	shipments, err := s.repo.GetShipmentsByOrderID(orderID)
+	if err != nil {
+		s.logger.Errorln("Layer: shipment_service, Method: listShipments, Error:", err)
+		return nil, err
+	}
+	if shipments == nil {
+		shipments = []models.Shipment{}
+	}
+	return shipments, nil
+}
+
+// syncOrderStatus derives the order status from what has been shipped and
+// delivered so far.
+func (s *shipmentService) syncOrderStatus(order *models.Order) error {
+	shipped, err := s.repo.GetShippedQuantities(order.ID)
+	if err != nil {
+		return err
+	}
+	shipments, err := s.repo.GetShipmentsByOrderID(order.ID)
+	if err != nil {
+		return err
+	}
+
+	anyShipped, allShipped := false, true
+	for _, line := range order.OrderItems {
+		if shipped[line.ID] > 0 {
+			anyShipped = true
+		}
+		if shipped[line.ID] < line.Quantity {
+			allShipped = false
+		}
+	}
+	allDelivered := len(shipments) > 0
+	for _, shipment := range shipments {
+		if shipment.Status != models.ShipmentStatusDelivered {
+			allDelivered = false
+		}
+	}
+
+	status := models.OrderStatusPlaced
+	switch {
+	case allShipped && allDelivered:
+		status = models.OrderStatusDelivered
+	case allShipped:
+		status = models.OrderStatusShipped
+	case anyShipped:
+		status = models.OrderStatusPartiallyShipped
+	}
+
+	if status == order.Status {
+		return nil
+	}
+	if err := s.orderRepo.UpdateOrderStatus(order.ID, status); err != nil {
+		return err
+	}
+	order.Status = status
+	return nil
+}
+
+func shipmentItems(order *models.Order, shipped map[uint]int, requested []models.ShipmentItemRequest) ([]models.ShipmentItem, error) {
+	remaining := make(map[uint]int, len(order.OrderItems))
+	for _, line := range order.OrderItems {
+		remaining[line.ID] = line.Quantity - shipped[line.ID]
+	}
+
+	var items []models.ShipmentItem
+	if len(requested) == 0 {
+		for _, line := range order.OrderItems {
+			if remaining[line.ID] > 0 {
+				items = append(items, models.ShipmentItem{OrderProductID: line.ID, Quantity: remaining[line.ID]})
+			}
+		}
+		if len(items) == 0 {
+			return nil, ErrNothingToShip
+		}
+		return items, nil
+	}
+
+	for _, item := range requested {
+		left, ok := remaining[item.OrderProductID]
+		if !ok {
+			return nil, ErrInvalidShipment.Detailf("invalid shipment: order line %d does not belong to order %d", item.OrderProductID, order.ID)
+		}
+		if item.Quantity <= 0 || item.Quantity > left {
+			return nil, ErrInvalidShipment.Detailf("invalid shipment: quantity for order line %d must be between 1 and %d", item.OrderProductID, left)
+		}
+		remaining[item.OrderProductID] -= item.Quantity
+		items = append(items, models.ShipmentItem{OrderProductID: item.OrderProductID, Quantity: item.Quantity})
+	}
+	return items, nil
+}
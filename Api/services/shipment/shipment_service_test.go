@@ -0,0 +1,201 @@
+package services_shipment
+
+import (
+	"errors"
+	"pruebaVertice/Api/models"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func testOrder() *models.Order {
+	return &models.Order{
+		ID:     7,
+		UserID: 1,
+		Status: models.OrderStatusPlaced,
+		OrderItems: []models.OrderProduct{
+			{ID: 10, OrderID: 7, ProductID: 1, Quantity: 2},
+			{ID: 11, OrderID: 7, ProductID: 2, Quantity: 1},
+		},
+	}
+}
+
+var shipmentRequest = models.CreateShipmentRequest{Carrier: "Chilexpress", TrackingNumber: "CX1"}
+
+func TestCreateShipment_PartialMarksOrderPartiallyShipped(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(repoMock, orderMock, logrus.New())
+
+	repoMock.On("CreateShipment", uint(7)).Return(testOrder(), map[uint]int{}, nil)
+	repoMock.On("GetShippedQuantities", uint(7)).Return(map[uint]int{10: 1}, nil)
+	repoMock.On("GetShipmentsByOrderID", uint(7)).Return([]models.Shipment{{ID: 3, Status: models.ShipmentStatusLabelCreated}}, nil)
+	orderMock.On("UpdateOrderStatus", uint(7), models.OrderStatusPartiallyShipped).Return(nil)
+
+	req := shipmentRequest
+	req.Items = []models.ShipmentItemRequest{{OrderProductID: 10, Quantity: 1}}
+	res, err := svc.CreateShipment(7, req, "warehouse@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ShipmentStatusLabelCreated, res.Status)
+	assert.Equal(t, []models.ShipmentItem{{OrderProductID: 10, Quantity: 1}}, res.Items)
+	assert.Len(t, res.Events, 1)
+	repoMock.AssertExpectations(t)
+	orderMock.AssertExpectations(t)
+}
+
+func TestCreateShipment_EmptyItemsShipsRemainder(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(repoMock, orderMock, logrus.New())
+
+	repoMock.On("CreateShipment", uint(7)).Return(testOrder(), map[uint]int{10: 1}, nil)
+	repoMock.On("GetShippedQuantities", uint(7)).Return(map[uint]int{10: 2, 11: 1}, nil)
+	repoMock.On("GetShipmentsByOrderID", uint(7)).Return([]models.Shipment{
+		{ID: 3, Status: models.ShipmentStatusInTransit},
+		{ID: 4, Status: models.ShipmentStatusLabelCreated},
+	}, nil)
+	orderMock.On("UpdateOrderStatus", uint(7), models.OrderStatusShipped).Return(nil)
+
+	res, err := svc.CreateShipment(7, shipmentRequest, "warehouse@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ShipmentItem{{OrderProductID: 10, Quantity: 1}, {OrderProductID: 11, Quantity: 1}}, res.Items)
+	orderMock.AssertExpectations(t)
+}
+
+func TestCreateShipment_QuantityExceedsRemaining(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(repoMock, orderMock, logrus.New())
+
+	repoMock.On("CreateShipment", uint(7)).Return(testOrder(), map[uint]int{10: 2}, nil)
+
+	req := shipmentRequest
+	req.Items = []models.ShipmentItemRequest{{OrderProductID: 10, Quantity: 1}}
+	_, err := svc.CreateShipment(7, req, "warehouse@example.com")
+	assert.True(t, errors.Is(err, ErrInvalidShipment))
+	orderMock.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything)
+}
+
+func TestCreateShipment_ForeignLine(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(repoMock, orderMock, logrus.New())
+
+	repoMock.On("CreateShipment", uint(7)).Return(testOrder(), map[uint]int{}, nil)
+
+	req := shipmentRequest
+	req.Items = []models.ShipmentItemRequest{{OrderProductID: 99, Quantity: 1}}
+	_, err := svc.CreateShipment(7, req, "warehouse@example.com")
+	assert.True(t, errors.Is(err, ErrInvalidShipment))
+}
+
+func TestCreateShipment_NothingLeft(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(repoMock, orderMock, logrus.New())
+
+	repoMock.On("CreateShipment", uint(7)).Return(testOrder(), map[uint]int{10: 2, 11: 1}, nil)
+
+	_, err := svc.CreateShipment(7, shipmentRequest, "warehouse@example.com")
+	assert.Equal(t, ErrNothingToShip, err)
+}
+
+func TestCreateShipment_CancelledOrder(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	svc := NewShipmentService(repoMock, new(OrdersRepoMock), logrus.New())
+
+	order := testOrder()
+	order.Status = models.OrderStatusCancelled
+	repoMock.On("CreateShipment", uint(7)).Return(order, map[uint]int{}, nil)
+
+	_, err := svc.CreateShipment(7, shipmentRequest, "warehouse@example.com")
+	assert.Equal(t, ErrOrderNotShippable, err)
+}
+
+func TestCreateShipment_LookupErrors(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	svc := NewShipmentService(repoMock, new(OrdersRepoMock), logrus.New())
+
+	repoMock.On("CreateShipment", uint(7)).Return(nil, nil, gorm.ErrRecordNotFound).Once()
+	_, err := svc.CreateShipment(7, shipmentRequest, "warehouse@example.com")
+	assert.Equal(t, ErrOrderNotFound, err)
+
+	dbErr := errors.New("db down")
+	repoMock.On("CreateShipment", uint(7)).Return(nil, nil, dbErr).Once()
+	_, err = svc.CreateShipment(7, shipmentRequest, "warehouse@example.com")
+	assert.Equal(t, dbErr, err)
+}
+
+func TestAddTrackingEvent_DeliveredCompletesOrder(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(repoMock, orderMock, logrus.New())
+
+	shipment := &models.Shipment{ID: 3, OrderID: 7, Status: models.ShipmentStatusOutForDelivery}
+	order := testOrder()
+	order.Status = models.OrderStatusShipped
+	repoMock.On("GetShipmentByID", uint(3)).Return(shipment, nil)
+	repoMock.On("AddEvent", shipment, mock.MatchedBy(func(e *models.ShipmentEvent) bool {
+		return e.Status == models.ShipmentStatusDelivered && !e.OccurredAt.IsZero()
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Shipment).Status = models.ShipmentStatusDelivered
+	}).Return(nil)
+	orderMock.On("GetOrderByID", uint(7)).Return(order, nil)
+	repoMock.On("GetShippedQuantities", uint(7)).Return(map[uint]int{10: 2, 11: 1}, nil)
+	repoMock.On("GetShipmentsByOrderID", uint(7)).Return([]models.Shipment{{ID: 3, Status: models.ShipmentStatusDelivered}}, nil)
+	orderMock.On("UpdateOrderStatus", uint(7), models.OrderStatusDelivered).Return(nil)
+
+	res, err := svc.AddTrackingEvent(3, models.ShipmentEventRequest{Status: models.ShipmentStatusDelivered})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ShipmentStatusDelivered, res.Status)
+	orderMock.AssertExpectations(t)
+}
+
+func TestAddTrackingEvent_InvalidStatus(t *testing.T) {
+	svc := NewShipmentService(new(ShipmentsRepoMock), new(OrdersRepoMock), logrus.New())
+
+	_, err := svc.AddTrackingEvent(3, models.ShipmentEventRequest{Status: "lost_in_space"})
+	assert.Equal(t, ErrInvalidEventStatus, err)
+}
+
+func TestAddTrackingEvent_AfterDelivery(t *testing.T) {
+	repoMock := new(ShipmentsRepoMock)
+	svc := NewShipmentService(repoMock, new(OrdersRepoMock), logrus.New())
+
+	repoMock.On("GetShipmentByID", uint(3)).Return(&models.Shipment{ID: 3, Status: models.ShipmentStatusDelivered}, nil)
+	_, err := svc.AddTrackingEvent(3, models.ShipmentEventRequest{Status: models.ShipmentStatusInTransit})
+	assert.Equal(t, ErrShipmentAlreadyFinal, err)
+}
+
+func TestGetUserOrderShipments_OtherUser(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(new(ShipmentsRepoMock), orderMock, logrus.New())
+
+	orderMock.On("GetOrderByID", uint(7)).Return(testOrder(), nil)
+	_, err := svc.GetUserOrderShipments(2, 7)
+	assert.Equal(t, ErrOrderNotFound, err)
+}
+
+func TestGetOrderShipments_NotFound(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(new(ShipmentsRepoMock), orderMock, logrus.New())
+
+	orderMock.On("GetOrderByID", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+	_, err := svc.GetOrderShipments(7)
+	assert.Equal(t, ErrOrderNotFound, err)
+}
+
+func TestGetUserOrderShipments_LookupError(t *testing.T) {
+	orderMock := new(OrdersRepoMock)
+	svc := NewShipmentService(new(ShipmentsRepoMock), orderMock, logrus.New())
+
+	dbErr := errors.New("connection refused")
+	orderMock.On("GetOrderByID", uint(7)).Return(nil, dbErr)
+	_, err := svc.GetUserOrderShipments(1, 7)
+	assert.Equal(t, dbErr, err)
+	_, err = svc.GetOrderShipments(7)
+	assert.Equal(t, dbErr, err)
+}
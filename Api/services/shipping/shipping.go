@@ -0,0 +1,105 @@
+package services_shipping
+
+import (
+	"math"
+	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/utils/apperr"
+	"sort"
+)
+
+var ErrUnknownMethod = apperr.Invalid("unknown_shipping_method", "unknown shipping method")
+
+// Quote describes what is being shipped and where.
+type Quote struct {
+	Subtotal    float64
+	TotalWeight float64
+	Destination models.PostalAddress
+}
+
+// RateCalculator computes the shipping cost of a quote.
+type RateCalculator interface {
+	Rate(quote Quote) (float64, error)
+}
+
+// FlatRate charges the same amount for every order.
+type FlatRate struct {
+	Amount float64
+}
+
+func (f FlatRate) Rate(Quote) (float64, error) {
+	return f.Amount, nil
+}
+
+// WeightBased charges a base amount plus a price per started kilogram.
+type WeightBased struct {
+	Base  float64
+	PerKg float64
+}
+
+func (w WeightBased) Rate(quote Quote) (float64, error) {
+	return round(w.Base + math.Ceil(quote.TotalWeight)*w.PerKg), nil
+}
+
+// FreeOverThreshold ships for free once the subtotal reaches Threshold and
+// otherwise delegates to Fallback.
+type FreeOverThreshold struct {
+	Threshold float64
+	Fallback  RateCalculator
+}
+
+func (f FreeOverThreshold) Rate(quote Quote) (float64, error) {
+	if quote.Subtotal >= f.Threshold {
+		return 0, nil
+	}
+	return f.Fallback.Rate(quote)
+}
+
+// Registry maps shipping method names to their calculators.
+type Registry struct {
+	calculators   map[string]RateCalculator
+	defaultMethod string
+}
+
+func NewRegistry(defaultMethod string) *Registry {
+	return &Registry{
+		calculators:   map[string]RateCalculator{},
+		defaultMethod: defaultMethod,
+	}
+}
+
+// Register adds or replaces the calculator for method.
+func (r *Registry) Register(method string, calculator RateCalculator) *Registry {
+	r.calculators[method] = calculator
+	return r
+}
+
+// Methods returns the registered method names in alphabetical order.
+func (r *Registry) Methods() []string {
+	methods := make([]string, 0, len(r.calculators))
+	for method := range r.calculators {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Rate quotes method, falling back to the default method when empty. It
+// returns the method actually used.
+func (r *Registry) Rate(method string, quote Quote) (string, float64, error) {
+	if method == "" {
+		method = r.defaultMethod
+	}
+	calculator, ok := r.calculators[method]
+	if !ok {
+		return "", 0, ErrUnknownMethod.Detailf("unknown shipping method: %q", method)
+	}
+	cost, err := calculator.Rate(quote)
+	if err != nil {
+		return "", 0, err
+	}
+	return method, round(cost), nil
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,58 @@
+package services_shipping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatRate(t *testing.T) {
+	cost, err := FlatRate{Amount: 5}.Rate(Quote{Subtotal: 1000, TotalWeight: 50})
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, cost)
+}
+
+func TestWeightBased_RoundsUpKilograms(t *testing.T) {
+	calc := WeightBased{Base: 3, PerKg: 1.5}
+
+	cost, err := calc.Rate(Quote{TotalWeight: 2.2})
+	assert.NoError(t, err)
+	assert.Equal(t, 7.5, cost)
+
+	cost, err = calc.Rate(Quote{TotalWeight: 0})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, cost)
+}
+
+func TestFreeOverThreshold(t *testing.T) {
+	calc := FreeOverThreshold{Threshold: 100, Fallback: FlatRate{Amount: 5}}
+
+	cost, err := calc.Rate(Quote{Subtotal: 99.99})
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, cost)
+
+	cost, err = calc.Rate(Quote{Subtotal: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, cost)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry("standard").
+		Register("standard", FlatRate{Amount: 5}).
+		Register("express", WeightBased{Base: 10, PerKg: 2})
+
+	assert.Equal(t, []string{"express", "standard"}, registry.Methods())
+
+	method, cost, err := registry.Rate("", Quote{})
+	assert.NoError(t, err)
+	assert.Equal(t, "standard", method)
+	assert.Equal(t, 5.0, cost)
+
+	method, cost, err = registry.Rate("express", Quote{TotalWeight: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "express", method)
+	assert.Equal(t, 12.0, cost)
+
+	_, _, err = registry.Rate("pigeon", Quote{})
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+}
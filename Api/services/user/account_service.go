@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/user_repo"
+	services_notifier "pruebaVertice/Api/services/notifier"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrEmptyProfileUpdate = apperr.Invalid("empty_profile_update", "username or email is required")
+	ErrProfileTaken       = apperr.Conflict("profile_taken", "username or email already in use")
+	// ErrImpersonating keeps an admin acting as a user from deleting the account.
+	ErrImpersonating = apperr.Forbidden("impersonation_not_allowed", "this action is not allowed while impersonating a user")
+)
+
+// AccountService lets users manage their own account. Unlike UserService it
+// only ever changes the fields a user is allowed to change.
+type AccountService interface {
+	UpdateProfile(user *models.User, req models.ProfileUpdateRequest, client models.ClientInfo) (*models.User, error)
+	ChangePassword(user *models.User, req models.ChangePasswordRequest, client models.ClientInfo) (*models.User, error)
+	DeleteAccount(user *models.User, req models.DeleteAccountRequest, tokenID string) error
+}
+
+type accountService struct {
+	users        repo.UserRepository
+	hasher       Hasher
+	tokens       TokenGenerator
+	sessions     SessionService
+	verification EmailVerificationService
+	notifier     services_notifier.Notifier
+	logger       *logrus.Logger
+	now          func() time.Time
+}
+
+func NewAccountService(users repo.UserRepository, hasher Hasher, tokens TokenGenerator, sessions SessionService, verification EmailVerificationService, notifier services_notifier.Notifier, logger *logrus.Logger) *accountService {
+	return &accountService{
+		users:        users,
+		hasher:       hasher,
+		tokens:       tokens,
+		sessions:     sessions,
+		verification: verification,
+		notifier:     notifier,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// UpdateProfile changes the username and/or email. Tokens carry the email,
+// so changing it signs the user out everywhere, revokes their API keys and
+// OAuth clients, and returns new tokens for the current client; the new address has to be verified again. An email
+// change needs the current password and is reported to the old address.
+func (s *accountService) UpdateProfile(user *models.User, req models.ProfileUpdateRequest, client models.ClientInfo) (*models.User, error) {
+	if req.Username == nil && req.Email == nil {
+		return nil, ErrEmptyProfileUpdate
+	}
+	if req.Email != nil && !s.hasher.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		s.logger.Warnf("User %d failed to confirm their current password", user.ID)
+		return nil, ErrInvalidPassword
+	}
+	updated := *user
+	if req.Username != nil {
+		updated.Username = strings.TrimSpace(*req.Username)
+	}
+	if req.Email != nil {
+		updated.Email = strings.TrimSpace(*req.Email)
+	}
+	if updated.Username == "" || updated.Email == "" {
+		return nil, ErrEmptyProfileUpdate
+	}
+
+	emailChanged := updated.Email != user.Email
+	if emailChanged {
+		now := s.now()
+		updated.EmailVerifiedAt = nil
+		updated.VerificationSentAt = nil
+		updated.SessionsRevokedAt = &now
+	}
+
+	err := s.users.UpdateProfile(&updated)
+	if errors.Is(err, repo.ErrProfileTaken) {
+		return nil, ErrProfileTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updated.Password = ""
+	updated.Token, updated.RefreshToken = "", ""
+	if !emailChanged {
+		return &updated, nil
+	}
+
+	s.logger.Infof("User %d changed their email address", user.ID)
+	s.notifyEmailChanged(user)
+	if err := s.verification.ResendVerification(&updated); err != nil {
+		s.logger.Warnln("Layer: account_service, Method: UpdateProfile, Error: Sending verification email:", err)
+	}
+	if err := s.reissueTokens(&updated, client); err != nil {
+		s.logger.Errorln("Layer: account_service, Method: UpdateProfile, Error: Generating token:", err)
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// ChangePassword replaces the password once the current one is confirmed.
+// Every other session is signed out; the current client gets new tokens.
+func (s *accountService) ChangePassword(user *models.User, req models.ChangePasswordRequest, client models.ClientInfo) (*models.User, error) {
+	if !s.hasher.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		s.logger.Warnf("User %d failed to confirm their current password", user.ID)
+		return nil, ErrInvalidPassword
+	}
+
+	hash, err := s.hasher.HashPassword(req.NewPassword)
+	if err != nil {
+		s.logger.Errorln("Layer: account_service, Method: ChangePassword, Error: Hashing password:", err)
+		return nil, err
+	}
+	if err := s.users.ChangePassword(user.ID, hash, s.now()); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("User %d changed their password", user.ID)
+
+	if err := s.reissueTokens(user, client); err != nil {
+		s.logger.Errorln("Layer: account_service, Method: ChangePassword, Error: Generating token:", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteAccount soft deletes and anonymises the account once the current
+// password is confirmed. Orders stay in place so the shop's order history is
+// preserved. Impersonated sessions may not delete the account.
+func (s *accountService) DeleteAccount(user *models.User, req models.DeleteAccountRequest, tokenID string) error {
+	if tokenID != "" {
+		session, err := s.sessions.GetSession(tokenID)
+		if err != nil {
+			s.logger.Errorln("Layer: account_service, Method: DeleteAccount, Error:", err)
+			return err
+		}
+		if session.ImpersonatedBy != "" {
+			s.logger.Warnf("%s tried to delete account %d while impersonating it", session.ImpersonatedBy, user.ID)
+			return ErrImpersonating
+		}
+	}
+	if !s.hasher.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		s.logger.Warnf("User %d failed to confirm their current password", user.ID)
+		return ErrInvalidPassword
+	}
+
+	if err := s.users.AnonymizeUser(user.ID, s.now()); err != nil {
+		return err
+	}
+	s.logger.Infof("User %d deleted their account", user.ID)
+	return nil
+}
+
+// notifyEmailChanged warns the old address, so the owner notices if someone
+// else took over the account.
+func (s *accountService) notifyEmailChanged(user *models.User) {
+	err := s.notifier.Notify(services_notifier.Notification{
+		To:      user.Email,
+		Subject: "Tu correo electrónico ha cambiado",
+		Body: fmt.Sprintf("Hola %s,\n\nEl correo electrónico de tu cuenta se ha cambiado. Si no has sido tú, ponte en contacto con soporte cuanto antes.\n",
+			user.Username),
+	})
+	if err != nil {
+		s.logger.Warnln("Layer: account_service, Method: notifyEmailChanged, Error:", err)
+	}
+}
+
+// reissueTokens starts a new session for the client after all earlier ones
+// were revoked.
+func (s *accountService) reissueTokens(user *models.User, client models.ClientInfo) error {
+	if err := startSession(s.sessions, s.tokens, user, client); err != nil {
+		return err
+	}
+	_, err := s.users.UpdateUserToken(user)
+	return err
+}
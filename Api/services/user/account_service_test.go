@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/user_repo"
+	services_notifier "pruebaVertice/Api/services/notifier"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var accountNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+type accountMocks struct {
+	users        *UserRepoMock
+	hasher       *HasherMock
+	tokens       *TokenGeneratorMock
+	sessions     *SessionServiceMock
+	verification *EmailVerificationMock
+	notifier     *services_notifier.MemoryNotifier
+}
+
+func newAccountService() (*accountService, accountMocks) {
+	m := accountMocks{
+		users:        new(UserRepoMock),
+		hasher:       new(HasherMock),
+		tokens:       new(TokenGeneratorMock),
+		sessions:     anySession(),
+		verification: new(EmailVerificationMock),
+		notifier:     services_notifier.NewMemoryNotifier(),
+	}
+	svc := NewAccountService(m.users, m.hasher, m.tokens, m.sessions, m.verification, m.notifier, logrus.New())
+	svc.now = func() time.Time { return accountNow }
+	return svc, m
+}
+
+func accountUser() *models.User {
+	verified := accountNow.Add(-48 * time.Hour)
+	return &models.User{
+		Model:           gorm.Model{ID: 3},
+		Username:        "ana",
+		Email:           "ana@example.com",
+		Password:        "hashed",
+		Token:           "old-token",
+		EmailVerifiedAt: &verified,
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestUpdateProfile_UsernameOnly(t *testing.T) {
+	svc, m := newAccountService()
+	m.users.On("UpdateProfile", mock.MatchedBy(func(u *models.User) bool {
+		return u.Username == "ana.p" && u.Email == "ana@example.com" && u.EmailVerifiedAt != nil && u.SessionsRevokedAt == nil
+	})).Return(nil)
+
+	updated, err := svc.UpdateProfile(accountUser(), models.ProfileUpdateRequest{Username: strPtr(" ana.p ")}, models.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "ana.p", updated.Username)
+	assert.Empty(t, updated.Password)
+	assert.Empty(t, updated.Token, "stored tokens are not echoed back")
+	m.tokens.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+	m.verification.AssertNotCalled(t, "ResendVerification", mock.Anything)
+}
+
+func TestUpdateProfile_EmailChangeReverifiesAndReissuesTokens(t *testing.T) {
+	svc, m := newAccountService()
+	m.hasher.On("CheckPasswordHash", "secret", "hashed").Return(true)
+	m.users.On("UpdateProfile", mock.MatchedBy(func(u *models.User) bool {
+		return u.Email == "new@example.com" && u.EmailVerifiedAt == nil &&
+			u.SessionsRevokedAt != nil && u.SessionsRevokedAt.Equal(accountNow)
+	})).Return(nil)
+	m.verification.On("ResendVerification", mock.AnythingOfType("*models.User")).Return(nil)
+	m.tokens.On("GenerateToken", "new@example.com", "sid").Return("token", "refresh", nil)
+	m.users.On("UpdateUserToken", mock.AnythingOfType("*models.User")).Return(&models.User{}, nil)
+
+	updated, err := svc.UpdateProfile(accountUser(), models.ProfileUpdateRequest{Email: strPtr("new@example.com"), CurrentPassword: "secret"}, models.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "token", updated.Token)
+	assert.Equal(t, "refresh", updated.RefreshToken)
+	m.verification.AssertExpectations(t)
+
+	sent := m.notifier.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "ana@example.com", sent[0].To)
+}
+
+func TestUpdateProfile_EmailChangeNeedsCurrentPassword(t *testing.T) {
+	svc, m := newAccountService()
+	m.hasher.On("CheckPasswordHash", "guess", "hashed").Return(false)
+
+	_, err := svc.UpdateProfile(accountUser(), models.ProfileUpdateRequest{Email: strPtr("new@example.com"), CurrentPassword: "guess"}, models.ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+	m.users.AssertNotCalled(t, "UpdateProfile", mock.Anything)
+	assert.Empty(t, m.notifier.Sent())
+}
+
+func TestUpdateProfile_Errors(t *testing.T) {
+	svc, m := newAccountService()
+
+	_, err := svc.UpdateProfile(accountUser(), models.ProfileUpdateRequest{}, models.ClientInfo{})
+	assert.ErrorIs(t, err, ErrEmptyProfileUpdate)
+	_, err = svc.UpdateProfile(accountUser(), models.ProfileUpdateRequest{Username: strPtr("  ")}, models.ClientInfo{})
+	assert.ErrorIs(t, err, ErrEmptyProfileUpdate)
+
+	m.users.On("UpdateProfile", mock.Anything).Return(repo.ErrProfileTaken)
+	_, err = svc.UpdateProfile(accountUser(), models.ProfileUpdateRequest{Username: strPtr("taken")}, models.ClientInfo{})
+	assert.ErrorIs(t, err, ErrProfileTaken)
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	svc, m := newAccountService()
+	m.hasher.On("CheckPasswordHash", "old-pass", "hashed").Return(true)
+	m.hasher.On("HashPassword", "new-password").Return("new-hash", nil)
+	m.users.On("ChangePassword", uint(3), "new-hash", accountNow).Return(nil)
+	m.tokens.On("GenerateToken", "ana@example.com", "sid").Return("token", "refresh", nil)
+	m.users.On("UpdateUserToken", mock.AnythingOfType("*models.User")).Return(&models.User{}, nil)
+
+	user, err := svc.ChangePassword(accountUser(), models.ChangePasswordRequest{CurrentPassword: "old-pass", NewPassword: "new-password"}, models.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "token", user.Token)
+	m.users.AssertExpectations(t)
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	svc, m := newAccountService()
+	m.hasher.On("CheckPasswordHash", "guess", "hashed").Return(false)
+
+	_, err := svc.ChangePassword(accountUser(), models.ChangePasswordRequest{CurrentPassword: "guess", NewPassword: "new-password"}, models.ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+	m.users.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteAccount(t *testing.T) {
+	svc, m := newAccountService()
+	req := models.DeleteAccountRequest{CurrentPassword: "secret"}
+	m.sessions.On("GetSession", "sid").Return(&models.Session{TokenID: "sid"}, nil)
+	m.hasher.On("CheckPasswordHash", "secret", "hashed").Return(true)
+	m.users.On("AnonymizeUser", uint(3), accountNow).Return(nil).Once()
+	assert.NoError(t, svc.DeleteAccount(accountUser(), req, "sid"))
+
+	m.users.On("AnonymizeUser", uint(3), accountNow).Return(errors.New("db down"))
+	assert.Error(t, svc.DeleteAccount(accountUser(), req, "sid"))
+}
+
+func TestDeleteAccount_WrongCurrentPassword(t *testing.T) {
+	svc, m := newAccountService()
+	m.sessions.On("GetSession", "sid").Return(&models.Session{TokenID: "sid"}, nil)
+	m.hasher.On("CheckPasswordHash", "guess", "hashed").Return(false)
+
+	err := svc.DeleteAccount(accountUser(), models.DeleteAccountRequest{CurrentPassword: "guess"}, "sid")
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+	m.users.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+}
+
+func TestDeleteAccount_WhileImpersonating(t *testing.T) {
+	svc, m := newAccountService()
+	m.sessions.On("GetSession", "sid").Return(&models.Session{TokenID: "sid", ImpersonatedBy: "admin@example.com"}, nil)
+
+	err := svc.DeleteAccount(accountUser(), models.DeleteAccountRequest{CurrentPassword: "secret"}, "sid")
+	assert.ErrorIs(t, err, ErrImpersonating)
+	m.hasher.AssertNotCalled(t, "CheckPasswordHash", mock.Anything, mock.Anything)
+	m.users.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AdminAuditRepoMock mocks audit_repo.AdminAuditRepository for service tests.
+type AdminAuditRepoMock struct {
+	mock.Mock
+}
+
+func (m *AdminAuditRepoMock) CreateEntry(entry *models.AdminAuditEntry) error {
+	return m.Called(entry).Error(0)
+}
+
+func (m *AdminAuditRepoMock) ListEntries(filter models.AdminAuditFilter) ([]models.AdminAuditEntry, int64, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.([]models.AdminAuditEntry), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, 0, args.Error(2)
+}
+
+// PasswordResetServiceMock mocks PasswordResetService for service tests.
+type PasswordResetServiceMock struct {
+	mock.Mock
+}
+
+func (m *PasswordResetServiceMock) RequestPasswordReset(email string) error {
+	return m.Called(email).Error(0)
+}
+
+func (m *PasswordResetServiceMock) ResetPassword(token, newPassword string) error {
+	return m.Called(token, newPassword).Error(0)
+}
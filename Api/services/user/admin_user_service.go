@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	audit_repo "pruebaVertice/Api/repo/admin_audit_repo"
+	repo "pruebaVertice/Api/repo/user_repo"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+var (
+	ErrUserNotFound              = apperr.NotFound("user_not_found", "user not found")
+	ErrInvalidUserFilter         = apperr.BadRequest("invalid_user_filter", "invalid user filter")
+	ErrCannotModifySelf          = apperr.Conflict("cannot_modify_self", "admins cannot disable, demote or impersonate themselves")
+	ErrCannotImpersonateAdmin    = apperr.Forbidden("cannot_impersonate_admin", "admin accounts cannot be impersonated")
+	ErrCannotImpersonateDisabled = apperr.Conflict("cannot_impersonate_disabled", "disabled accounts cannot be impersonated")
+)
+
+// AdminUserService lets admins find and manage user accounts. Every change
+// is written to the admin audit log.
+type AdminUserService interface {
+	SearchUsers(filter models.UserFilter) (*dto.AdminUserListResponse, error)
+	GetUser(id uint) (*models.User, error)
+	SetDisabled(admin *models.User, id uint, disabled bool, client models.ClientInfo) (*models.User, error)
+	ChangeRole(admin *models.User, id uint, role string, client models.ClientInfo) (*models.User, error)
+	ForcePasswordReset(admin *models.User, id uint, client models.ClientInfo) error
+	Impersonate(admin *models.User, id uint, client models.ClientInfo) (*dto.ImpersonationResponse, error)
+	ListAuditLog(filter models.AdminAuditFilter) (*dto.AdminAuditLogResponse, error)
+}
+
+type adminUserService struct {
+	users    repo.UserRepository
+	audit    audit_repo.AdminAuditRepository
+	resets   PasswordResetService
+	tokens   TokenGenerator
+	sessions SessionService
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+func NewAdminUserService(users repo.UserRepository, audit audit_repo.AdminAuditRepository, resets PasswordResetService, tokens TokenGenerator, sessions SessionService, logger *logrus.Logger) *adminUserService {
+	return &adminUserService{
+		users:    users,
+		audit:    audit,
+		resets:   resets,
+		tokens:   tokens,
+		sessions: sessions,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+func (s *adminUserService) SearchUsers(filter models.UserFilter) (*dto.AdminUserListResponse, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Role != "" && filter.Role != models.RoleCustomer && filter.Role != models.RoleWarehouse && filter.Role != models.RoleAdmin {
+		return nil, ErrInvalidUserFilter.Detailf("invalid user filter: unknown role %q", filter.Role)
+	}
+	if filter.Status != "" && filter.Status != models.UserStatusActive && filter.Status != models.UserStatusDisabled {
+		return nil, ErrInvalidUserFilter.Detailf("invalid user filter: status must be %q or %q", models.UserStatusActive, models.UserStatusDisabled)
+	}
+	filter.Page, filter.PageSize = normalizePage(filter.Page, filter.PageSize)
+
+	users, count, err := s.users.SearchUsers(filter)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]dto.AdminUserResponse, len(users))
+	for i := range users {
+		items[i] = dto.NewAdminUserResponse(&users[i])
+	}
+	return &dto.AdminUserListResponse{
+		Items:      items,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		TotalItems: count,
+		TotalPages: int(math.Ceil(float64(count) / float64(filter.PageSize))),
+	}, nil
+}
+
+func (s *adminUserService) GetUser(id uint) (*models.User, error) {
+	user, err := s.users.GetUserByID(strconv.FormatUint(uint64(id), 10))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// SetDisabled blocks or unblocks the account. Blocking signs the user out
+// everywhere and their API keys stop working while it lasts.
+func (s *adminUserService) SetDisabled(admin *models.User, id uint, disabled bool, client models.ClientInfo) (*models.User, error) {
+	user, err := s.target(admin, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if err := s.users.SetDisabled(user.ID, disabled, now); err != nil {
+		return nil, err
+	}
+	action := models.AdminActionEnableUser
+	user.DisabledAt = nil
+	if disabled {
+		action = models.AdminActionDisableUser
+		user.DisabledAt = &now
+	}
+	if err := s.record(admin, action, user.ID, "", client); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *adminUserService) ChangeRole(admin *models.User, id uint, role string, client models.ClientInfo) (*models.User, error) {
+	user, err := s.target(admin, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	if err := s.users.UpdateRole(user.ID, role); err != nil {
+		return nil, err
+	}
+	details := fmt.Sprintf("%s -> %s", user.Role, role)
+	user.Role = role
+	if err := s.record(admin, models.AdminActionChangeRole, user.ID, details, client); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ForcePasswordReset invalidates the current password and every session,
+// API key and OAuth client, then emails the user a reset link. Until they
+// follow it they cannot log in. The audit entry is written first, so no
+// reset goes unrecorded.
+func (s *adminUserService) ForcePasswordReset(admin *models.User, id uint, client models.ClientInfo) error {
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.record(admin, models.AdminActionPasswordReset, user.ID, "", client); err != nil {
+		return err
+	}
+	if err := s.users.RevokeCredentials(user.ID, s.now()); err != nil {
+		return err
+	}
+	return s.resets.RequestPasswordReset(user.Email)
+}
+
+// Impersonate opens a session as the user for support purposes. The audit
+// entry is written first, so no impersonation goes unrecorded, and the
+// session names the admin in the user's session list.
+func (s *adminUserService) Impersonate(admin *models.User, id uint, client models.ClientInfo) (*dto.ImpersonationResponse, error) {
+	user, err := s.target(admin, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == models.RoleAdmin {
+		return nil, ErrCannotImpersonateAdmin
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrCannotImpersonateDisabled
+	}
+
+	if err := s.record(admin, models.AdminActionImpersonate, user.ID, "", client); err != nil {
+		return nil, err
+	}
+	client.ImpersonatedBy = admin.Email
+	if err := startSession(s.sessions, s.tokens, user, client); err != nil {
+		s.logger.Errorln("Layer: admin_user_service, Method: Impersonate, Error: Generating token:", err)
+		return nil, err
+	}
+	return &dto.ImpersonationResponse{
+		Token:        user.Token,
+		RefreshToken: user.RefreshToken,
+		User:         dto.NewAdminUserResponse(user),
+	}, nil
+}
+
+func (s *adminUserService) ListAuditLog(filter models.AdminAuditFilter) (*dto.AdminAuditLogResponse, error) {
+	filter.Page, filter.PageSize = normalizePage(filter.Page, filter.PageSize)
+	entries, count, err := s.audit.ListEntries(filter)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []models.AdminAuditEntry{}
+	}
+	return &dto.AdminAuditLogResponse{
+		Items:      entries,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		TotalItems: count,
+		TotalPages: int(math.Ceil(float64(count) / float64(filter.PageSize))),
+	}, nil
+}
+
+// target loads the user an admin action applies to. Admins cannot act on
+// their own account, so they cannot lock themselves out.
+func (s *adminUserService) target(admin *models.User, id uint) (*models.User, error) {
+	if admin.ID == id {
+		return nil, ErrCannotModifySelf
+	}
+	return s.GetUser(id)
+}
+
+func (s *adminUserService) record(admin *models.User, action string, targetID uint, details string, client models.ClientInfo) error {
+	err := s.audit.CreateEntry(&models.AdminAuditEntry{
+		AdminID:      admin.ID,
+		AdminEmail:   admin.Email,
+		Action:       action,
+		TargetUserID: targetID,
+		Details:      details,
+		IP:           client.IP,
+	})
+	if err != nil {
+		s.logger.Errorln("Layer: admin_user_service, Method: record, Error:", err)
+		return err
+	}
+	s.logger.Infof("Admin %d: %s on user %d %s", admin.ID, action, targetID, details)
+	return nil
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultUserPageSize
+	}
+	if pageSize > maxUserPageSize {
+		pageSize = maxUserPageSize
+	}
+	return page, pageSize
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var adminNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+type adminMocks struct {
+	users    *UserRepoMock
+	audit    *AdminAuditRepoMock
+	resets   *PasswordResetServiceMock
+	tokens   *TokenGeneratorMock
+	sessions *SessionServiceMock
+}
+
+func newAdminUserService() (*adminUserService, adminMocks) {
+	m := adminMocks{
+		users:    new(UserRepoMock),
+		audit:    new(AdminAuditRepoMock),
+		resets:   new(PasswordResetServiceMock),
+		tokens:   new(TokenGeneratorMock),
+		sessions: new(SessionServiceMock),
+	}
+	svc := NewAdminUserService(m.users, m.audit, m.resets, m.tokens, m.sessions, logrus.New())
+	svc.now = func() time.Time { return adminNow }
+	return svc, m
+}
+
+var admin = &models.User{Model: gorm.Model{ID: 1}, Email: "admin@example.com", Role: models.RoleAdmin}
+
+func customer() *models.User {
+	return &models.User{Model: gorm.Model{ID: 7}, Email: "ana@example.com", Role: models.RoleCustomer, Password: "hashed"}
+}
+
+func auditEntry(action string) interface{} {
+	return mock.MatchedBy(func(e *models.AdminAuditEntry) bool {
+		return e.AdminID == 1 && e.AdminEmail == "admin@example.com" && e.Action == action && e.TargetUserID == 7 && e.IP == "203.0.113.7"
+	})
+}
+
+var adminClient = models.ClientInfo{IP: "203.0.113.7"}
+
+func TestSearchUsers_NormalisesAndHidesCredentials(t *testing.T) {
+	svc, m := newAdminUserService()
+	m.users.On("SearchUsers", models.UserFilter{Query: "ana", Page: 1, PageSize: 100}).
+		Return([]models.User{*customer()}, int64(150), nil)
+
+	res, err := svc.SearchUsers(models.UserFilter{Query: " ana ", PageSize: 500})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.TotalPages)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, "ana@example.com", res.Items[0].Email)
+
+	_, err = svc.SearchUsers(models.UserFilter{Role: "root"})
+	assert.ErrorIs(t, err, ErrInvalidUserFilter)
+	_, err = svc.SearchUsers(models.UserFilter{Status: "banned"})
+	assert.ErrorIs(t, err, ErrInvalidUserFilter)
+}
+
+func TestSetDisabled_RecordsAudit(t *testing.T) {
+	svc, m := newAdminUserService()
+	m.users.On("GetUserByID", "7").Return(customer(), nil)
+	m.users.On("SetDisabled", uint(7), true, adminNow).Return(nil)
+	m.audit.On("CreateEntry", auditEntry(models.AdminActionDisableUser)).Return(nil)
+
+	user, err := svc.SetDisabled(admin, 7, true, adminClient)
+	require.NoError(t, err)
+	require.NotNil(t, user.DisabledAt)
+	m.audit.AssertExpectations(t)
+}
+
+func TestAdminActions_RejectSelfAndUnknownUsers(t *testing.T) {
+	svc, m := newAdminUserService()
+	m.users.On("GetUserByID", "9").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.SetDisabled(admin, 1, true, adminClient)
+	assert.ErrorIs(t, err, ErrCannotModifySelf)
+	_, err = svc.ChangeRole(admin, 1, models.RoleCustomer, adminClient)
+	assert.ErrorIs(t, err, ErrCannotModifySelf)
+	_, err = svc.ChangeRole(admin, 9, models.RoleWarehouse, adminClient)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	m.audit.AssertNotCalled(t, "CreateEntry", mock.Anything)
+}
+
+func TestChangeRole(t *testing.T) {
+	svc, m := newAdminUserService()
+	m.users.On("GetUserByID", "7").Return(customer(), nil)
+	m.users.On("UpdateRole", uint(7), models.RoleWarehouse).Return(nil)
+	m.audit.On("CreateEntry", mock.MatchedBy(func(e *models.AdminAuditEntry) bool {
+		return e.Action == models.AdminActionChangeRole && e.Details == "customer -> warehouse"
+	})).Return(nil)
+
+	user, err := svc.ChangeRole(admin, 7, models.RoleWarehouse, adminClient)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleWarehouse, user.Role)
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	svc, m := newAdminUserService()
+	m.users.On("GetUserByID", "7").Return(customer(), nil)
+	m.users.On("RevokeCredentials", uint(7), adminNow).Return(nil)
+	m.audit.On("CreateEntry", auditEntry(models.AdminActionPasswordReset)).Return(nil)
+	m.resets.On("RequestPasswordReset", "ana@example.com").Return(nil)
+
+	require.NoError(t, svc.ForcePasswordReset(admin, 7, adminClient))
+	m.resets.AssertExpectations(t)
+}
+
+func TestForcePasswordReset_AuditFailureKeepsCredentials(t *testing.T) {
+	svc, m := newAdminUserService()
+	m.users.On("GetUserByID", "7").Return(customer(), nil)
+	m.audit.On("CreateEntry", auditEntry(models.AdminActionPasswordReset)).Return(errors.New("db down"))
+
+	assert.Error(t, svc.ForcePasswordReset(admin, 7, adminClient))
+	m.users.AssertNotCalled(t, "RevokeCredentials", mock.Anything, mock.Anything)
+	m.resets.AssertNotCalled(t, "RequestPasswordReset", mock.Anything)
+}
+
+func TestImpersonate_TagsSessionWithAdmin(t *testing.T) {
+	svc, m := newAdminUserService()
+	m.users.On("GetUserByID", "7").Return(customer(), nil)
+	m.audit.On("CreateEntry", auditEntry(models.AdminActionImpersonate)).Return(nil)
+	m.sessions.On("Start", mock.AnythingOfType("*models.User"), models.ClientInfo{IP: "203.0.113.7", ImpersonatedBy: "admin@example.com"}).
+		Return(&models.Session{TokenID: "sid"}, nil)
+	m.tokens.On("GenerateToken", "ana@example.com", "sid").Return("tok", "ref", nil)
+
+	res, err := svc.Impersonate(admin, 7, adminClient)
+	require.NoError(t, err)
+	assert.Equal(t, "tok", res.Token)
+	assert.Equal(t, uint(7), res.User.ID)
+	m.users.AssertNotCalled(t, "UpdateUserToken", mock.Anything)
+}
+
+func TestImpersonate_Refused(t *testing.T) {
+	svc, m := newAdminUserService()
+	otherAdmin := &models.User{Model: gorm.Model{ID: 8}, Role: models.RoleAdmin}
+	disabledAt := adminNow
+	disabled := &models.User{Model: gorm.Model{ID: 9}, Role: models.RoleCustomer, DisabledAt: &disabledAt}
+	m.users.On("GetUserByID", "8").Return(otherAdmin, nil)
+	m.users.On("GetUserByID", "9").Return(disabled, nil)
+
+	_, err := svc.Impersonate(admin, 8, adminClient)
+	assert.ErrorIs(t, err, ErrCannotImpersonateAdmin)
+	_, err = svc.Impersonate(admin, 9, adminClient)
+	assert.ErrorIs(t, err, ErrCannotImpersonateDisabled)
+	_, err = svc.Impersonate(admin, 1, adminClient)
+	assert.ErrorIs(t, err, ErrCannotModifySelf)
+}
+
+func TestImpersonate_NoTokensWithoutAudit(t *testing.T) {
+	svc, m := newAdminUserService()
+	m.users.On("GetUserByID", "7").Return(customer(), nil)
+	m.audit.On("CreateEntry", mock.Anything).Return(errors.New("db down"))
+
+	_, err := svc.Impersonate(admin, 7, adminClient)
+	assert.Error(t, err)
+	m.sessions.AssertNotCalled(t, "Start", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// EmailVerificationMock mocks EmailVerificationService for service tests.
+type EmailVerificationMock struct {
+	mock.Mock
+}
+
+func (m *EmailVerificationMock) ResendVerification(user *models.User) error {
+	return m.Called(user).Error(0)
+}
+
+func (m *EmailVerificationMock) VerifyEmail(token string) error {
+	return m.Called(token).Error(0)
+}
+
+func (m *EmailVerificationMock) RetryAfter() time.Duration {
+	return time.Minute
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/user_repo"
+	services_notifier "pruebaVertice/Api/services/notifier"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultVerificationTTL      = 24 * time.Hour
+	DefaultVerificationCooldown = time.Minute
+)
+
+var (
+	ErrInvalidVerificationToken = apperr.Invalid("invalid_verification_token", "invalid or expired verification link")
+	ErrEmailAlreadyVerified     = apperr.Conflict("email_already_verified", "email already verified")
+	ErrVerificationRateLimited  = apperr.RateLimited("verification_rate_limited", "a verification email was sent recently, try again later")
+)
+
+type EmailVerificationService interface {
+	ResendVerification(user *models.User) error
+	VerifyEmail(token string) error
+	RetryAfter() time.Duration
+}
+
+// EmailVerificationConfig controls the verification links. Secret signs the
+// links; VerifyURL is the address the token is appended to.
+type EmailVerificationConfig struct {
+	Secret    []byte
+	TTL       time.Duration
+	Cooldown  time.Duration
+	VerifyURL string
+}
+
+type emailVerificationService struct {
+	users    repo.UserRepository
+	notifier services_notifier.Notifier
+	config   EmailVerificationConfig
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+func NewEmailVerificationService(users repo.UserRepository, notifier services_notifier.Notifier, config EmailVerificationConfig, logger *logrus.Logger) *emailVerificationService {
+	if config.TTL <= 0 {
+		config.TTL = DefaultVerificationTTL
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultVerificationCooldown
+	}
+	return &emailVerificationService{
+		users:    users,
+		notifier: notifier,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+func (s *emailVerificationService) Name() string {
+	return "email_verification"
+}
+
+// Handle sends the first verification email when a user registers.
+func (s *emailVerificationService) Handle(event models.OutboxEvent) error {
+	if event.Type != models.EventUserRegistered {
+		return nil
+	}
+	var registration models.UserRegistration
+	if err := json.Unmarshal([]byte(event.Payload), &registration); err != nil {
+		return err
+	}
+	user, err := s.users.GetUserByEmail(registration.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.ID != registration.UserID || user.EmailVerifiedAt != nil {
+		// The account changed since it registered; nothing to send.
+		return nil
+	}
+	// Claim before sending so a redelivered event cannot mail the link
+	// twice. Only an account never sent a link can be claimed; if sending
+	// fails the user can still ask for a new one.
+	claimed, err := s.users.ClaimVerificationSend(user.ID, s.now(), time.Time{})
+	if err != nil || !claimed {
+		return err
+	}
+	return s.send(&user)
+}
+
+// ResendVerification emails a new link, at most once per cooldown.
+func (s *emailVerificationService) ResendVerification(user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	now := s.now()
+	claimed, err := s.users.ClaimVerificationSend(user.ID, now, now.Add(-s.config.Cooldown))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrVerificationRateLimited
+	}
+	return s.send(user)
+}
+
+func (s *emailVerificationService) RetryAfter() time.Duration {
+	return s.config.Cooldown
+}
+
+func (s *emailVerificationService) VerifyEmail(token string) error {
+	userID, email, err := s.parseToken(token)
+	if err != nil {
+		return err
+	}
+	verified, err := s.users.MarkEmailVerified(userID, email, s.now())
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+func (s *emailVerificationService) send(user *models.User) error {
+	token := s.signToken(user.ID, user.Email, s.now().Add(s.config.TTL))
+	err := s.notifier.Notify(services_notifier.Notification{
+		To:      user.Email,
+		Subject: "Confirma tu correo electrónico",
+		Body: fmt.Sprintf("Hola %s,\n\nConfirma tu dirección de correo abriendo este enlace:\n\n%s%s\n\nEl enlace caduca en %s.\n",
+			user.Username, s.config.VerifyURL, token, s.config.TTL),
+	})
+	if err != nil {
+		s.logger.Errorln("Layer: email_verification_service, Method: send, Error:", err)
+	}
+	return err
+}
+
+// signToken encodes "userID:expiry:email" and an HMAC of it. The email is
+// part of the signed data so a link stops working if the email changes.
+func (s *emailVerificationService) signToken(userID uint, email string, expires time.Time) string {
+	payload := fmt.Sprintf("%d:%d:%s", userID, expires.Unix(), email)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+func (s *emailVerificationService) parseToken(token string) (uint, string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(string(payload))) {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	parts := strings.SplitN(string(payload), ":", 3)
+	if len(parts) != 3 {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || s.now().Unix() >= expires {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	return uint(userID), parts[2], nil
+}
+
+func (s *emailVerificationService) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.config.Secret)
+	h.Write([]byte("email-verification:" + payload))
+	return h.Sum(nil)
+}
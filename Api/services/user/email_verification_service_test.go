@@ -0,0 +1,113 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+	services_notifier "pruebaVertice/Api/services/notifier"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var verifyNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func newVerificationService() (*emailVerificationService, *UserRepoMock, *services_notifier.MemoryNotifier) {
+	users := new(UserRepoMock)
+	notifier := services_notifier.NewMemoryNotifier()
+	svc := NewEmailVerificationService(users, notifier, EmailVerificationConfig{
+		Secret:    []byte("test-secret"),
+		VerifyURL: "https://tienda.example.com/api/auth/verify-email?token=",
+	}, logrus.New())
+	svc.now = func() time.Time { return verifyNow }
+	return svc, users, notifier
+}
+
+func sentToken(t *testing.T, n services_notifier.Notification) string {
+	match := regexp.MustCompile(`token=([A-Za-z0-9_.-]+)`).FindStringSubmatch(n.Body)
+	require.Len(t, match, 2)
+	return match[1]
+}
+
+func TestHandle_SendsVerificationOnRegistration(t *testing.T) {
+	svc, users, notifier := newVerificationService()
+	user := models.User{Model: gorm.Model{ID: 5}, Username: "ana", Email: "ana@example.com"}
+	users.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+	users.On("ClaimVerificationSend", uint(5), verifyNow, time.Time{}).Return(true, nil)
+
+	event, err := models.NewOutboxEvent(models.EventUserRegistered, models.AggregateUser, 5, models.UserRegistration{UserID: 5, Email: "ana@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Handle(event))
+
+	sent := notifier.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "ana@example.com", sent[0].To)
+
+	users.On("MarkEmailVerified", uint(5), "ana@example.com", verifyNow).Return(true, nil)
+	assert.NoError(t, svc.VerifyEmail(sentToken(t, sent[0])))
+	users.AssertExpectations(t)
+}
+
+func TestHandle_RedeliveredEventSendsNothing(t *testing.T) {
+	svc, users, notifier := newVerificationService()
+	user := models.User{Model: gorm.Model{ID: 5}, Username: "ana", Email: "ana@example.com"}
+	users.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+	users.On("ClaimVerificationSend", uint(5), verifyNow, time.Time{}).Return(true, nil).Once()
+	users.On("ClaimVerificationSend", uint(5), verifyNow, time.Time{}).Return(false, nil).Once()
+
+	event, err := models.NewOutboxEvent(models.EventUserRegistered, models.AggregateUser, 5, models.UserRegistration{UserID: 5, Email: "ana@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Handle(event))
+	require.NoError(t, svc.Handle(event))
+
+	assert.Len(t, notifier.Sent(), 1)
+	users.AssertExpectations(t)
+}
+
+func TestVerifyEmail_RejectsTamperedAndExpiredTokens(t *testing.T) {
+	svc, _, _ := newVerificationService()
+	valid := svc.signToken(5, "ana@example.com", verifyNow.Add(time.Hour))
+
+	forged := svc.signToken(6, "ana@example.com", verifyNow.Add(time.Hour))
+	forged = forged[:len(forged)-2] + "xx"
+	other := NewEmailVerificationService(nil, nil, EmailVerificationConfig{Secret: []byte("other")}, logrus.New())
+
+	for name, token := range map[string]string{
+		"garbage":       "not-a-token",
+		"bad signature": forged,
+		"other secret":  other.signToken(5, "ana@example.com", verifyNow.Add(time.Hour)),
+		"expired":       svc.signToken(5, "ana@example.com", verifyNow),
+		"payload swap":  forged[:len(forged)/2] + valid[len(valid)/2:],
+	} {
+		assert.ErrorIs(t, svc.VerifyEmail(token), ErrInvalidVerificationToken, name)
+	}
+}
+
+func TestVerifyEmail_EmailChanged(t *testing.T) {
+	svc, users, _ := newVerificationService()
+	users.On("MarkEmailVerified", uint(5), "old@example.com", verifyNow).Return(false, nil)
+
+	token := svc.signToken(5, "old@example.com", verifyNow.Add(time.Hour))
+	assert.ErrorIs(t, svc.VerifyEmail(token), ErrInvalidVerificationToken)
+}
+
+func TestResendVerification(t *testing.T) {
+	svc, users, notifier := newVerificationService()
+	user := &models.User{Model: gorm.Model{ID: 5}, Username: "ana", Email: "ana@example.com"}
+	users.On("ClaimVerificationSend", uint(5), verifyNow, verifyNow.Add(-DefaultVerificationCooldown)).Return(true, nil).Once()
+
+	require.NoError(t, svc.ResendVerification(user))
+	assert.Len(t, notifier.Sent(), 1)
+
+	users.On("ClaimVerificationSend", uint(5), verifyNow, verifyNow.Add(-DefaultVerificationCooldown)).Return(false, nil).Once()
+	assert.ErrorIs(t, svc.ResendVerification(user), ErrVerificationRateLimited)
+	assert.Len(t, notifier.Sent(), 1)
+
+	verified := verifyNow
+	user.EmailVerifiedAt = &verified
+	assert.ErrorIs(t, svc.ResendVerification(user), ErrEmailAlreadyVerified)
+}
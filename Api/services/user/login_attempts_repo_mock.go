@@ -0,0 +1,51 @@
+package services
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// LoginAttemptsRepoMock mocks login_attempts_repo.LoginAttemptsRepository
+// for service tests.
+type LoginAttemptsRepoMock struct {
+	mock.Mock
+}
+
+func (m *LoginAttemptsRepoMock) GetAttempt(scope, identifier string) (*models.LoginAttempt, error) {
+	args := m.Called(scope, identifier)
+	if res := args.Get(0); res != nil {
+		return res.(*models.LoginAttempt), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *LoginAttemptsRepoMock) RecordFailure(scope, identifier string, at, windowStart time.Time) (*models.LoginAttempt, error) {
+	args := m.Called(scope, identifier, at, windowStart)
+	if res := args.Get(0); res != nil {
+		return res.(*models.LoginAttempt), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *LoginAttemptsRepoMock) ExtendLock(id uint, until time.Time) error {
+	return m.Called(id, until).Error(0)
+}
+
+func (m *LoginAttemptsRepoMock) ResetAttempts(scope, identifier string) error {
+	return m.Called(scope, identifier).Error(0)
+}
+
+func (m *LoginAttemptsRepoMock) ListAttempts(lockedAfter *time.Time) ([]models.LoginAttempt, error) {
+	args := m.Called(lockedAfter)
+	if res := args.Get(0); res != nil {
+		return res.([]models.LoginAttempt), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *LoginAttemptsRepoMock) DeleteAttempt(id uint) error {
+	return m.Called(id).Error(0)
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"pruebaVertice/Api/models"
+	login_attempts_repo "pruebaVertice/Api/repo/login_attempts_repo"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLoginLocked     = apperr.RateLimited("login_locked", "too many failed login attempts, try again later")
+	ErrLockoutNotFound = apperr.NotFound("lockout_not_found", "lockout not found")
+)
+
+// LoginLockedError is returned while an account or client IP is throttled.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+type LoginThrottle interface {
+	Check(email, ip string) error
+	RecordFailure(email, ip string) error
+	RecordSuccess(email, ip string) error
+	ListLockouts(activeOnly bool) ([]models.LoginAttempt, error)
+	ClearLockout(id uint) error
+}
+
+// ThrottlePolicy allows FreeAttempts failures without delay. Every further
+// failure doubles the wait before the next attempt, and MaxFailures locks
+// the key for the whole lockout duration.
+type ThrottlePolicy struct {
+	FreeAttempts int
+	MaxFailures  int
+}
+
+type LoginThrottleConfig struct {
+	Account         ThrottlePolicy
+	IP              ThrottlePolicy
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+	// Window is how long a failure is remembered.
+	Window time.Duration
+}
+
+// DefaultLoginThrottleConfig is lenient with a single IP because offices
+// and mobile carriers put many users behind one address.
+func DefaultLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		Account:         ThrottlePolicy{FreeAttempts: 3, MaxFailures: 10},
+		IP:              ThrottlePolicy{FreeAttempts: 10, MaxFailures: 100},
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		Window:          time.Hour,
+	}
+}
+
+type loginThrottle struct {
+	repo   login_attempts_repo.LoginAttemptsRepository
+	config LoginThrottleConfig
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+func NewLoginThrottle(repo login_attempts_repo.LoginAttemptsRepository, config LoginThrottleConfig, logger *logrus.Logger) *loginThrottle {
+	return &loginThrottle{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+type throttleKey struct {
+	scope      string
+	identifier string
+	policy     ThrottlePolicy
+}
+
+// keys returns the counters a login touches. Emails are tracked whether or
+// not the account exists, so lockouts do not reveal registered addresses.
+func (t *loginThrottle) keys(email, ip string) []throttleKey {
+	keys := []throttleKey{{models.LoginScopeAccount, strings.ToLower(strings.TrimSpace(email)), t.config.Account}}
+	if ip != "" {
+		keys = append(keys, throttleKey{models.LoginScopeIP, ip, t.config.IP})
+	}
+	return keys
+}
+
+func (t *loginThrottle) Check(email, ip string) error {
+	now := t.now()
+	var wait time.Duration
+	for _, key := range t.keys(email, ip) {
+		attempt, err := t.repo.GetAttempt(key.scope, key.identifier)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			t.logger.Errorln("Layer: login_throttle, Method: Check, Error:", err)
+			return err
+		}
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			if remaining := attempt.LockedUntil.Sub(now); remaining > wait {
+				wait = remaining
+			}
+		}
+	}
+	if wait > 0 {
+		// Round up so clients that honour Retry-After are not refused again.
+		retry := wait.Truncate(time.Second)
+		if retry < wait {
+			retry += time.Second
+		}
+		return &LoginLockedError{RetryAfter: retry}
+	}
+	return nil
+}
+
+func (t *loginThrottle) RecordFailure(email, ip string) error {
+	now := t.now()
+	var errs []error
+	for _, key := range t.keys(email, ip) {
+		attempt, err := t.repo.RecordFailure(key.scope, key.identifier, now, now.Add(-t.config.Window))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if until, ok := t.lockUntil(key.policy, attempt.Failures, now); ok {
+			if attempt.Failures >= key.policy.MaxFailures {
+				t.logger.Warnf("Layer: login_throttle, Method: RecordFailure, %s %s locked after %d failures", key.scope, key.identifier, attempt.Failures)
+			}
+			if err := t.repo.ExtendLock(attempt.ID, until); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RecordSuccess clears the account counter. The IP counter is kept, or an
+// attacker could reset it by logging into an account of their own.
+func (t *loginThrottle) RecordSuccess(email, ip string) error {
+	return t.repo.ResetAttempts(models.LoginScopeAccount, strings.ToLower(strings.TrimSpace(email)))
+}
+
+func (t *loginThrottle) ListLockouts(activeOnly bool) ([]models.LoginAttempt, error) {
+	if !activeOnly {
+		return t.repo.ListAttempts(nil)
+	}
+	now := t.now()
+	return t.repo.ListAttempts(&now)
+}
+
+func (t *loginThrottle) ClearLockout(id uint) error {
+	err := t.repo.DeleteAttempt(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrLockoutNotFound.Detailf("lockout not found: %d", id)
+	}
+	return err
+}
+
+func (t *loginThrottle) lockUntil(policy ThrottlePolicy, failures int, now time.Time) (time.Time, bool) {
+	if failures >= policy.MaxFailures {
+		return now.Add(t.config.LockoutDuration), true
+	}
+	if failures <= policy.FreeAttempts {
+		return time.Time{}, false
+	}
+	delay := t.config.BaseDelay
+	for i := policy.FreeAttempts + 1; i < failures && delay < t.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.config.MaxDelay {
+		delay = t.config.MaxDelay
+	}
+	return now.Add(delay), true
+}
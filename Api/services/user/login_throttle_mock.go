@@ -0,0 +1,46 @@
+package services
+
+import (
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// LoginThrottleMock mocks LoginThrottle for service tests.
+type LoginThrottleMock struct {
+	mock.Mock
+}
+
+// allowLogins returns a throttle that never blocks, for tests that are not
+// about throttling.
+func allowLogins() *LoginThrottleMock {
+	m := new(LoginThrottleMock)
+	m.On("Check", mock.Anything, mock.Anything).Return(nil).Maybe()
+	m.On("RecordFailure", mock.Anything, mock.Anything).Return(nil).Maybe()
+	m.On("RecordSuccess", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return m
+}
+
+func (m *LoginThrottleMock) Check(email, ip string) error {
+	return m.Called(email, ip).Error(0)
+}
+
+func (m *LoginThrottleMock) RecordFailure(email, ip string) error {
+	return m.Called(email, ip).Error(0)
+}
+
+func (m *LoginThrottleMock) RecordSuccess(email, ip string) error {
+	return m.Called(email, ip).Error(0)
+}
+
+func (m *LoginThrottleMock) ListLockouts(activeOnly bool) ([]models.LoginAttempt, error) {
+	args := m.Called(activeOnly)
+	if res := args.Get(0); res != nil {
+		return res.([]models.LoginAttempt), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *LoginThrottleMock) ClearLockout(id uint) error {
+	return m.Called(id).Error(0)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var throttleNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func newLoginThrottle() (*loginThrottle, *LoginAttemptsRepoMock) {
+	repo := new(LoginAttemptsRepoMock)
+	throttle := NewLoginThrottle(repo, DefaultLoginThrottleConfig(), logrus.New())
+	throttle.now = func() time.Time { return throttleNow }
+	return throttle, repo
+}
+
+func TestLockUntil_ProgressiveThenLockout(t *testing.T) {
+	throttle, _ := newLoginThrottle()
+	policy := ThrottlePolicy{FreeAttempts: 3, MaxFailures: 10}
+
+	_, delayed := throttle.lockUntil(policy, 3, throttleNow)
+	assert.False(t, delayed)
+
+	expected := map[int]time.Duration{4: time.Second, 5: 2 * time.Second, 6: 4 * time.Second, 9: 32 * time.Second, 10: 15 * time.Minute}
+	for failures, delay := range expected {
+		until, ok := throttle.lockUntil(policy, failures, throttleNow)
+		require.True(t, ok)
+		assert.Equal(t, delay, until.Sub(throttleNow), "failures %d", failures)
+	}
+
+	// Delays are capped below the lockout.
+	until, _ := throttle.lockUntil(ThrottlePolicy{FreeAttempts: 0, MaxFailures: 100}, 50, throttleNow)
+	assert.Equal(t, time.Minute, until.Sub(throttleNow))
+}
+
+func TestCheck_ReportsLongestLock(t *testing.T) {
+	throttle, repo := newLoginThrottle()
+	accountLock := throttleNow.Add(1500 * time.Millisecond)
+	ipLock := throttleNow.Add(-time.Second)
+	repo.On("GetAttempt", models.LoginScopeAccount, "ana@example.com").Return(&models.LoginAttempt{LockedUntil: &accountLock}, nil)
+	repo.On("GetAttempt", models.LoginScopeIP, "10.0.0.1").Return(&models.LoginAttempt{LockedUntil: &ipLock}, nil)
+
+	err := throttle.Check(" Ana@Example.com", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrLoginLocked)
+	var locked *LoginLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, 2*time.Second, locked.RetryAfter)
+}
+
+func TestCheck_NoHistory(t *testing.T) {
+	throttle, repo := newLoginThrottle()
+	repo.On("GetAttempt", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	assert.NoError(t, throttle.Check("ana@example.com", "10.0.0.1"))
+}
+
+func TestRecordFailure_LocksAccountAndIP(t *testing.T) {
+	throttle, repo := newLoginThrottle()
+	windowStart := throttleNow.Add(-time.Hour)
+	repo.On("RecordFailure", models.LoginScopeAccount, "ana@example.com", throttleNow, windowStart).Return(&models.LoginAttempt{ID: 1, Failures: 10}, nil)
+	repo.On("RecordFailure", models.LoginScopeIP, "10.0.0.1", throttleNow, windowStart).Return(&models.LoginAttempt{ID: 2, Failures: 2}, nil)
+	repo.On("ExtendLock", uint(1), throttleNow.Add(15*time.Minute)).Return(nil)
+
+	require.NoError(t, throttle.RecordFailure("ana@example.com", "10.0.0.1"))
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "ExtendLock", uint(2), mock.Anything)
+}
+
+func TestRecordSuccess_KeepsIPCounter(t *testing.T) {
+	throttle, repo := newLoginThrottle()
+	repo.On("ResetAttempts", models.LoginScopeAccount, "ana@example.com").Return(nil)
+
+	require.NoError(t, throttle.RecordSuccess("ana@example.com", "10.0.0.1"))
+	repo.AssertNotCalled(t, "ResetAttempts", models.LoginScopeIP, mock.Anything)
+}
+
+func TestClearLockout_NotFound(t *testing.T) {
+	throttle, repo := newLoginThrottle()
+	repo.On("DeleteAttempt", uint(9)).Return(gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, throttle.ClearLockout(9), ErrLockoutNotFound)
+}
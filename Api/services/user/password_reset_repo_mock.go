@@ -0,0 +1,31 @@
+package services
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// PasswordResetRepoMock mocks password_reset_repo.PasswordResetRepository
+// for service tests.
+type PasswordResetRepoMock struct {
+	mock.Mock
+}
+
+func (m *PasswordResetRepoMock) CreateToken(token *models.PasswordResetToken) error {
+	return m.Called(token).Error(0)
+}
+
+func (m *PasswordResetRepoMock) GetTokenByHash(hash string) (*models.PasswordResetToken, error) {
+	args := m.Called(hash)
+	if res := args.Get(0); res != nil {
+		return res.(*models.PasswordResetToken), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PasswordResetRepoMock) ResetPassword(token *models.PasswordResetToken, passwordHash string, at time.Time) error {
+	return m.Called(token, passwordHash, at).Error(0)
+}
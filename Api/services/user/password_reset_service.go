@@ -0,0 +1,138 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"pruebaVertice/Api/models"
+	reset_repo "pruebaVertice/Api/repo/password_reset_repo"
+	repo "pruebaVertice/Api/repo/user_repo"
+	services_notifier "pruebaVertice/Api/services/notifier"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const DefaultPasswordResetTTL = time.Hour
+
+var ErrInvalidResetToken = apperr.Invalid("invalid_reset_token", "invalid or expired password reset token")
+
+type PasswordResetService interface {
+	RequestPasswordReset(email string) error
+	ResetPassword(token, newPassword string) error
+}
+
+// PasswordResetConfig controls the reset emails. ResetURL is the page of the
+// frontend that takes the token; it is prepended to the token in the email.
+type PasswordResetConfig struct {
+	TTL      time.Duration
+	ResetURL string
+}
+
+type passwordResetService struct {
+	users    repo.UserRepository
+	resets   reset_repo.PasswordResetRepository
+	hasher   Hasher
+	notifier services_notifier.Notifier
+	config   PasswordResetConfig
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+func NewPasswordResetService(users repo.UserRepository, resets reset_repo.PasswordResetRepository, hasher Hasher, notifier services_notifier.Notifier, config PasswordResetConfig, logger *logrus.Logger) *passwordResetService {
+	if config.TTL <= 0 {
+		config.TTL = DefaultPasswordResetTTL
+	}
+	return &passwordResetService{
+		users:    users,
+		resets:   resets,
+		hasher:   hasher,
+		notifier: notifier,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// RequestPasswordReset emails a reset token to the user. Unknown emails are
+// ignored without error so the endpoint cannot be used to find accounts.
+func (s *passwordResetService) RequestPasswordReset(email string) error {
+	user, err := s.users.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := newResetToken()
+	if err != nil {
+		return err
+	}
+	err = s.resets.CreateToken(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: s.now().Add(s.config.TTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.notifier.Notify(services_notifier.Notification{
+		To:      user.Email,
+		Subject: "Restablecer tu contraseña",
+		Body: fmt.Sprintf("Hola %s,\n\nPara elegir una nueva contraseña usa este enlace:\n\n%s%s\n\nEl enlace caduca en %s y solo se puede usar una vez. Si no lo pediste, ignora este correo.\n",
+			user.Username, s.config.ResetURL, token, s.config.TTL),
+	})
+	if err != nil {
+		s.logger.Errorln("Layer: password_reset_service, Method: RequestPasswordReset, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// ResetPassword sets a new password if the token is valid and signs the user
+// out everywhere.
+func (s *passwordResetService) ResetPassword(token, newPassword string) error {
+	stored, err := s.resets.GetTokenByHash(hashResetToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	now := s.now()
+	if stored.UsedAt != nil || !now.Before(stored.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	hash, err := s.hasher.HashPassword(newPassword)
+	if err != nil {
+		s.logger.Errorln("Layer: password_reset_service, Method: ResetPassword, Error: Hashing password:", err)
+		return err
+	}
+	err = s.resets.ResetPassword(stored, hash, now)
+	if errors.Is(err, reset_repo.ErrTokenAlreadyUsed) {
+		return ErrInvalidResetToken
+	}
+	return err
+}
+
+func newResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
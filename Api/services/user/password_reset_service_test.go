@@ -0,0 +1,104 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+	reset_repo "pruebaVertice/Api/repo/password_reset_repo"
+	services_notifier "pruebaVertice/Api/services/notifier"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var resetNow = time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC)
+
+func newResetService() (*passwordResetService, *UserRepoMock, *PasswordResetRepoMock, *HasherMock, *services_notifier.MemoryNotifier) {
+	users := new(UserRepoMock)
+	resets := new(PasswordResetRepoMock)
+	hasher := new(HasherMock)
+	notifier := services_notifier.NewMemoryNotifier()
+	svc := NewPasswordResetService(users, resets, hasher, notifier, PasswordResetConfig{ResetURL: "https://tienda.example.com/reset?token="}, logrus.New())
+	svc.now = func() time.Time { return resetNow }
+	return svc, users, resets, hasher, notifier
+}
+
+func TestRequestPasswordReset_EmailsTokenAndStoresHash(t *testing.T) {
+	svc, users, resets, _, notifier := newResetService()
+	users.On("GetUserByEmail", "ana@example.com").Return(models.User{Model: gorm.Model{ID: 3}, Username: "ana", Email: "ana@example.com"}, nil)
+	var stored *models.PasswordResetToken
+	resets.On("CreateToken", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.PasswordResetToken)
+	}).Return(nil)
+
+	require.NoError(t, svc.RequestPasswordReset("ana@example.com"))
+
+	sent := notifier.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "ana@example.com", sent[0].To)
+	match := regexp.MustCompile(`reset\?token=([A-Za-z0-9_-]+)`).FindStringSubmatch(sent[0].Body)
+	require.Len(t, match, 2)
+
+	require.NotNil(t, stored)
+	assert.Equal(t, uint(3), stored.UserID)
+	assert.Equal(t, hashResetToken(match[1]), stored.TokenHash)
+	assert.NotContains(t, stored.TokenHash, match[1])
+	assert.Equal(t, resetNow.Add(DefaultPasswordResetTTL), stored.ExpiresAt)
+}
+
+func TestRequestPasswordReset_UnknownEmail(t *testing.T) {
+	svc, users, resets, _, notifier := newResetService()
+	users.On("GetUserByEmail", "nadie@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	assert.NoError(t, svc.RequestPasswordReset("nadie@example.com"))
+	assert.Empty(t, notifier.Sent())
+	resets.AssertNotCalled(t, "CreateToken", mock.Anything)
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	svc, _, resets, hasher, _ := newResetService()
+	token := &models.PasswordResetToken{ID: 1, UserID: 3, ExpiresAt: resetNow.Add(time.Minute)}
+	resets.On("GetTokenByHash", hashResetToken("abc")).Return(token, nil)
+	hasher.On("HashPassword", "nueva-clave").Return("hashed", nil)
+	resets.On("ResetPassword", token, "hashed", resetNow).Return(nil)
+
+	assert.NoError(t, svc.ResetPassword("abc", "nueva-clave"))
+	resets.AssertExpectations(t)
+}
+
+func TestResetPassword_InvalidTokens(t *testing.T) {
+	used := resetNow.Add(-time.Minute)
+	cases := []struct {
+		name  string
+		token *models.PasswordResetToken
+		err   error
+	}{
+		{"unknown", nil, gorm.ErrRecordNotFound},
+		{"expired", &models.PasswordResetToken{ID: 1, ExpiresAt: resetNow}, nil},
+		{"used", &models.PasswordResetToken{ID: 1, ExpiresAt: resetNow.Add(time.Hour), UsedAt: &used}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _, resets, hasher, _ := newResetService()
+			resets.On("GetTokenByHash", hashResetToken("abc")).Return(tc.token, tc.err)
+
+			assert.ErrorIs(t, svc.ResetPassword("abc", "nueva-clave"), ErrInvalidResetToken)
+			hasher.AssertNotCalled(t, "HashPassword", mock.Anything)
+		})
+	}
+}
+
+func TestResetPassword_ConcurrentRedeem(t *testing.T) {
+	svc, _, resets, hasher, _ := newResetService()
+	token := &models.PasswordResetToken{ID: 1, UserID: 3, ExpiresAt: resetNow.Add(time.Minute)}
+	resets.On("GetTokenByHash", hashResetToken("abc")).Return(token, nil)
+	hasher.On("HashPassword", "nueva-clave").Return("hashed", nil)
+	resets.On("ResetPassword", token, "hashed", resetNow).Return(reset_repo.ErrTokenAlreadyUsed)
+
+	assert.ErrorIs(t, svc.ResetPassword("abc", "nueva-clave"), ErrInvalidResetToken)
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/repo/sessions_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSessionTTL = 60 * time.Minute
+	// sessionTouchInterval limits how often last_seen_at is written.
+	sessionTouchInterval = time.Minute
+	maxUserAgentLength   = 512
+)
+
+var (
+	ErrSessionNotFound = apperr.NotFound("session_not_found", "session not found")
+	ErrSessionEnded    = apperr.Unauthorized("session_ended", "session revoked or expired")
+)
+
+// SessionService tracks the devices a user is logged in on. Every login
+// starts a session whose ID is embedded in its tokens.
+type SessionService interface {
+	Start(user *models.User, client models.ClientInfo) (*models.Session, error)
+	ListSessions(user *models.User, currentTokenID string) ([]models.Session, error)
+	RevokeSession(user *models.User, id uint) error
+	CheckSession(tokenID string, userID uint) error
+	GetSession(tokenID string) (*models.Session, error)
+}
+
+type SessionConfig struct {
+	// TTL should match the refresh token lifetime.
+	TTL time.Duration
+}
+
+type sessionService struct {
+	repo   sessions_repo.SessionsRepository
+	ttl    time.Duration
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+func NewSessionService(repo sessions_repo.SessionsRepository, config SessionConfig, logger *logrus.Logger) *sessionService {
+	if config.TTL <= 0 {
+		config.TTL = defaultSessionTTL
+	}
+	return &sessionService{
+		repo:   repo,
+		ttl:    config.TTL,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (s *sessionService) Start(user *models.User, client models.ClientInfo) (*models.Session, error) {
+	tokenID, err := newSessionTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	now := s.now()
+	session := &models.Session{
+		UserID:         user.ID,
+		TokenID:        tokenID,
+		Device:         deviceName(client.UserAgent),
+		UserAgent:      userAgent,
+		IP:             client.IP,
+		ImpersonatedBy: client.ImpersonatedBy,
+		CreatedAt:      now,
+		LastSeenAt:     now,
+		ExpiresAt:      now.Add(s.ttl),
+	}
+	if err := s.repo.CreateSession(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ListSessions returns the user's active sessions, marking the one
+// currentTokenID belongs to. Sessions from before the user's sessions were
+// last revoked as a whole are left out.
+func (s *sessionService) ListSessions(user *models.User, currentTokenID string) ([]models.Session, error) {
+	var since time.Time
+	if user.SessionsRevokedAt != nil {
+		since = *user.SessionsRevokedAt
+	}
+
+	sessions, err := s.repo.ListActiveSessions(user.ID, since, s.now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = currentTokenID != "" && sessions[i].TokenID == currentTokenID
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs the session out. Revoking the current session is the
+// same as logging out.
+func (s *sessionService) RevokeSession(user *models.User, id uint) error {
+	err := s.repo.RevokeSession(user.ID, id, s.now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionNotFound
+	}
+	if err == nil {
+		s.logger.Infof("User %d revoked session %d", user.ID, id)
+	}
+	return err
+}
+
+// GetSession returns the session a token belongs to, or ErrSessionEnded
+// when there is none.
+func (s *sessionService) GetSession(tokenID string) (*models.Session, error) {
+	session, err := s.repo.GetSessionByTokenID(tokenID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionEnded
+	}
+	return session, err
+}
+
+// CheckSession fails with ErrSessionEnded once the session is revoked or
+// expired, or when it belongs to a user other than userID, and otherwise
+// records that it was seen.
+func (s *sessionService) CheckSession(tokenID string, userID uint) error {
+	session, err := s.repo.GetSessionByTokenID(tokenID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionEnded
+	}
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if session.UserID != userID || session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+		return ErrSessionEnded
+	}
+	if err := s.repo.TouchSession(session.ID, now, now.Add(-sessionTouchInterval)); err != nil {
+		s.logger.Warnln("Layer: session_service, Method: CheckSession, Error:", err)
+	}
+	return nil
+}
+
+// sessionTokenSigner signs the tokens of a session.
+type sessionTokenSigner interface {
+	GenerateToken(email, sessionID string) (string, string, error)
+}
+
+// startSession opens a session for user and signs its tokens into
+// user.Token and user.RefreshToken.
+func startSession(sessions SessionService, tokens sessionTokenSigner, user *models.User, client models.ClientInfo) error {
+	session, err := sessions.Start(user, client)
+	if err != nil {
+		return err
+	}
+	user.Token, user.RefreshToken, err = tokens.GenerateToken(user.Email, session.TokenID)
+	return err
+}
+
+func newSessionTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Markers are checked in order, so more specific ones come first: Edge and
+// Opera also send "chrome/", and iPhones "mac os".
+var (
+	browserMarkers = [][2]string{
+		{"edg/", "Edge"}, {"opr/", "Opera"}, {"firefox/", "Firefox"},
+		{"chrome/", "Chrome"}, {"safari/", "Safari"},
+		{"postmanruntime/", "Postman"}, {"curl/", "curl"},
+	}
+	platformMarkers = [][2]string{
+		{"iphone", "iPhone"}, {"ipad", "iPad"}, {"android", "Android"},
+		{"windows", "Windows"}, {"mac os", "macOS"}, {"linux", "Linux"},
+	}
+)
+
+// deviceName turns a user agent into a short label such as "Chrome on
+// Windows" for the session list.
+func deviceName(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	browser := firstMarker(ua, browserMarkers)
+	platform := firstMarker(ua, platformMarkers)
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
+
+func firstMarker(ua string, markers [][2]string) string {
+	for _, marker := range markers {
+		if strings.Contains(ua, marker[0]) {
+			return marker[1]
+		}
+	}
+	return ""
+}
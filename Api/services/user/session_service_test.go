@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var sessionNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func newSessionService() (*sessionService, *SessionsRepoMock) {
+	repo := new(SessionsRepoMock)
+	svc := NewSessionService(repo, SessionConfig{TTL: 24 * time.Hour}, logrus.New())
+	svc.now = func() time.Time { return sessionNow }
+	return svc, repo
+}
+
+func TestStart_RecordsClient(t *testing.T) {
+	svc, repo := newSessionService()
+	repo.On("CreateSession", mock.AnythingOfType("*models.Session")).Return(nil)
+
+	session, err := svc.Start(&models.User{Model: gorm.Model{ID: 4}}, models.ClientInfo{
+		IP:        "203.0.113.7",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36",
+	})
+	require.NoError(t, err)
+	assert.Len(t, session.TokenID, 32)
+	assert.Equal(t, uint(4), session.UserID)
+	assert.Equal(t, "Chrome on Windows", session.Device)
+	assert.Equal(t, "203.0.113.7", session.IP)
+	assert.True(t, session.ExpiresAt.Equal(sessionNow.Add(24*time.Hour)))
+}
+
+func TestDeviceName(t *testing.T) {
+	cases := map[string]string{
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Version/17.0 Mobile/15E148 Safari/604.1": "Safari on iPhone",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 Chrome/124.0 Safari/537.36 Edg/124.0":             "Edge on macOS",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0":                                              "Firefox on Linux",
+		"curl/8.5.0": "curl",
+		"":           "Unknown device",
+	}
+	for userAgent, want := range cases {
+		assert.Equal(t, want, deviceName(userAgent), userAgent)
+	}
+}
+
+func TestListSessions_MarksCurrentAndHonoursRevokeAll(t *testing.T) {
+	svc, repo := newSessionService()
+	revokedAt := sessionNow.Add(-time.Hour)
+	user := &models.User{Model: gorm.Model{ID: 4}, SessionsRevokedAt: &revokedAt}
+	repo.On("ListActiveSessions", uint(4), revokedAt, sessionNow).Return([]models.Session{
+		{ID: 1, TokenID: "aaa"}, {ID: 2, TokenID: "bbb"},
+	}, nil)
+
+	sessions, err := svc.ListSessions(user, "bbb")
+	require.NoError(t, err)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestRevokeSession_NotFound(t *testing.T) {
+	svc, repo := newSessionService()
+	repo.On("RevokeSession", uint(4), uint(9), sessionNow).Return(gorm.ErrRecordNotFound)
+
+	err := svc.RevokeSession(&models.User{Model: gorm.Model{ID: 4}}, 9)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestCheckSession(t *testing.T) {
+	svc, repo := newSessionService()
+	revokedAt := sessionNow.Add(-time.Minute)
+	repo.On("GetSessionByTokenID", "active").Return(&models.Session{ID: 1, UserID: 4, ExpiresAt: sessionNow.Add(time.Hour)}, nil)
+	repo.On("GetSessionByTokenID", "revoked").Return(&models.Session{ID: 2, UserID: 4, ExpiresAt: sessionNow.Add(time.Hour), RevokedAt: &revokedAt}, nil)
+	repo.On("GetSessionByTokenID", "expired").Return(&models.Session{ID: 3, UserID: 4, ExpiresAt: sessionNow}, nil)
+	repo.On("GetSessionByTokenID", "unknown").Return(nil, gorm.ErrRecordNotFound)
+	repo.On("TouchSession", uint(1), sessionNow, sessionNow.Add(-sessionTouchInterval)).Return(nil)
+
+	assert.NoError(t, svc.CheckSession("active", 4))
+	assert.ErrorIs(t, svc.CheckSession("revoked", 4), ErrSessionEnded)
+	assert.ErrorIs(t, svc.CheckSession("expired", 4), ErrSessionEnded)
+	assert.ErrorIs(t, svc.CheckSession("unknown", 4), ErrSessionEnded)
+	// A token of another account, e.g. one that used to own the email.
+	assert.ErrorIs(t, svc.CheckSession("active", 5), ErrSessionEnded)
+	repo.AssertNumberOfCalls(t, "TouchSession", 1)
+}
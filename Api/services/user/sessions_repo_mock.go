@@ -0,0 +1,41 @@
+package services
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// SessionsRepoMock mocks sessions_repo.SessionsRepository for service tests.
+type SessionsRepoMock struct {
+	mock.Mock
+}
+
+func (m *SessionsRepoMock) CreateSession(session *models.Session) error {
+	return m.Called(session).Error(0)
+}
+
+func (m *SessionsRepoMock) GetSessionByTokenID(tokenID string) (*models.Session, error) {
+	args := m.Called(tokenID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Session), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SessionsRepoMock) ListActiveSessions(userID uint, since, now time.Time) ([]models.Session, error) {
+	args := m.Called(userID, since, now)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Session), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SessionsRepoMock) RevokeSession(userID, id uint, at time.Time) error {
+	return m.Called(userID, id, at).Error(0)
+}
+
+func (m *SessionsRepoMock) TouchSession(id uint, at, notBefore time.Time) error {
+	return m.Called(id, at, notBefore).Error(0)
+}
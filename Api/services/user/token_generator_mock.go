@@ -1,13 +1,27 @@
 package services
 
-import "github.com/stretchr/testify/mock"
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
 
 // TokenGeneratorMock mocks jwtUtils.JWTGenerator for service tests.
 type TokenGeneratorMock struct {
 	mock.Mock
 }
 
-func (m *TokenGeneratorMock) GenerateToken(email string) (string, string, error) {
-	args := m.Called(email)
+func (m *TokenGeneratorMock) GenerateToken(email, sessionID string) (string, string, error) {
+	args := m.Called(email, sessionID)
 	return args.String(0), args.String(1), args.Error(2)
 }
+
+func (m *TokenGeneratorMock) GenerateChallengeToken(email string) (string, error) {
+	args := m.Called(email)
+	return args.String(0), args.Error(1)
+}
+
+func (m *TokenGeneratorMock) ParseChallengeToken(token string) (string, time.Time, error) {
+	args := m.Called(token)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
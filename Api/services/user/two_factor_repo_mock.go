@@ -0,0 +1,35 @@
+package services
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// TwoFactorRepoMock mocks two_factor_repo.TwoFactorRepository for service
+// tests.
+type TwoFactorRepoMock struct {
+	mock.Mock
+}
+
+func (m *TwoFactorRepoMock) SetPendingSecret(userID uint, secret string) error {
+	return m.Called(userID, secret).Error(0)
+}
+
+func (m *TwoFactorRepoMock) Enable(userID uint, step int64, codeHashes []string, at time.Time) error {
+	return m.Called(userID, step, codeHashes, at).Error(0)
+}
+
+func (m *TwoFactorRepoMock) ClaimStep(userID uint, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *TwoFactorRepoMock) UseRecoveryCode(userID uint, codeHash string, at time.Time) (bool, error) {
+	args := m.Called(userID, codeHash, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *TwoFactorRepoMock) Disable(userID uint) error {
+	return m.Called(userID).Error(0)
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"pruebaVertice/Api/dto"
+	"pruebaVertice/Api/models"
+	two_factor_repo "pruebaVertice/Api/repo/two_factor_repo"
+	repo "pruebaVertice/Api/repo/user_repo"
+	"pruebaVertice/Api/utils/apperr"
+	jwtUtils "pruebaVertice/Api/utils/jwt"
+	"pruebaVertice/Api/utils/totp"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultTwoFactorIssuer = "Vertice"
+	recoveryCodeCount      = 10
+	// totpSkew accepts codes from one step before and after the current one
+	// to tolerate clock drift on the phone.
+	totpSkew = 1
+)
+
+var (
+	ErrTwoFactorRequired       = apperr.Unauthorized("two_factor_required", "two-factor authentication required")
+	ErrTwoFactorAlreadyEnabled = apperr.Conflict("two_factor_already_enabled", "two-factor authentication already enabled")
+	ErrTwoFactorNotEnrolled    = apperr.Conflict("two_factor_not_enrolled", "two-factor enrollment not started")
+	ErrTwoFactorNotEnabled     = apperr.Conflict("two_factor_not_enabled", "two-factor authentication not enabled")
+	ErrInvalidTwoFactorCode    = apperr.Invalid("invalid_two_factor_code", "invalid two-factor code")
+	ErrInvalidChallenge        = jwtUtils.ErrInvalidChallenge
+)
+
+// TwoFactorRequiredError is returned by Login when the password is correct
+// but the account still needs a second factor. ChallengeToken must be sent
+// to CompleteLogin together with a code.
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorRequiredError) Unwrap() error {
+	return ErrTwoFactorRequired
+}
+
+type TwoFactorService interface {
+	Enroll(user *models.User) (*dto.TwoFactorEnrollmentResponse, error)
+	Confirm(user *models.User, code string) ([]string, error)
+	Disable(user *models.User, code string) error
+	CompleteLogin(challengeToken, code string, client models.ClientInfo) (*models.User, error)
+}
+
+// ChallengeTokens issues the final token pair once the challenge issued by
+// Login is redeemed.
+type ChallengeTokens interface {
+	GenerateToken(email, sessionID string) (string, string, error)
+	ParseChallengeToken(token string) (string, time.Time, error)
+}
+
+// TwoFactorConfig sets the issuer shown in authenticator apps.
+type TwoFactorConfig struct {
+	Issuer string
+}
+
+type twoFactorService struct {
+	users     repo.UserRepository
+	twoFactor two_factor_repo.TwoFactorRepository
+	tokens    ChallengeTokens
+	throttle  LoginThrottle
+	sessions  SessionService
+	config    TwoFactorConfig
+	logger    *logrus.Logger
+	now       func() time.Time
+}
+
+func NewTwoFactorService(users repo.UserRepository, twoFactor two_factor_repo.TwoFactorRepository, tokens ChallengeTokens, throttle LoginThrottle, sessions SessionService, config TwoFactorConfig, logger *logrus.Logger) *twoFactorService {
+	if config.Issuer == "" {
+		config.Issuer = DefaultTwoFactorIssuer
+	}
+	return &twoFactorService{
+		users:     users,
+		twoFactor: twoFactor,
+		tokens:    tokens,
+		throttle:  throttle,
+		sessions:  sessions,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Enroll creates a new secret for the user. It is not enforced until the
+// user proves the authenticator works by calling Confirm.
+func (s *twoFactorService) Enroll(user *models.User) (*dto.TwoFactorEnrollmentResponse, error) {
+	if user.TwoFactorEnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.Errorln("Layer: two_factor_service, Method: Enroll, Error:", err)
+		return nil, err
+	}
+	if err := s.twoFactor.SetPendingSecret(user.ID, secret); err != nil {
+		if errors.Is(err, two_factor_repo.ErrAlreadyEnabled) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		return nil, err
+	}
+	return &dto.TwoFactorEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, s.config.Issuer, user.Email),
+	}, nil
+}
+
+// Confirm enables two-factor login and returns the recovery codes. They are
+// only shown here; the database keeps their hashes.
+func (s *twoFactorService) Confirm(user *models.User, code string) ([]string, error) {
+	if user.TwoFactorEnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	step, ok := totp.Validate(user.TOTPSecret, normalizeCode(code), s.now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		s.logger.Errorln("Layer: two_factor_service, Method: Confirm, Error:", err)
+		return nil, err
+	}
+	if err := s.twoFactor.Enable(user.ID, step, hashes, s.now()); err != nil {
+		if errors.Is(err, two_factor_repo.ErrAlreadyEnabled) {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor login off. It takes a current code or a recovery
+// code so a stolen access token alone is not enough.
+func (s *twoFactorService) Disable(user *models.User, code string) error {
+	if user.TwoFactorEnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := s.verifyCode(user, code); err != nil {
+		return err
+	}
+	return s.twoFactor.Disable(user.ID)
+}
+
+// CompleteLogin exchanges the challenge token issued by Login and a valid
+// code for the real token pair. Wrong codes count as failed logins, so the
+// code cannot be brute-forced within the challenge lifetime.
+func (s *twoFactorService) CompleteLogin(challengeToken, code string, client models.ClientInfo) (*models.User, error) {
+	ip := client.IP
+	email, issuedAt, err := s.tokens.ParseChallengeToken(challengeToken)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	if err := s.throttle.Check(email, ip); err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetUserByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidChallenge
+	}
+	if err != nil {
+		s.logger.Errorln("Layer: two_factor_service, Method: CompleteLogin, Error:", err)
+		return nil, err
+	}
+	// The password may have been reset, or 2FA turned off, since the
+	// challenge was issued.
+	if user.TwoFactorEnabledAt == nil ||
+		(user.SessionsRevokedAt != nil && issuedAt.Unix() < user.SessionsRevokedAt.Unix()) {
+		return nil, ErrInvalidChallenge
+	}
+	if err := s.verifyCode(&user, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if err := s.throttle.RecordFailure(email, ip); err != nil {
+				s.logger.Errorln("Layer: two_factor_service, Method: CompleteLogin, Error: Recording failed attempt:", err)
+			}
+		}
+		return nil, err
+	}
+	if err := s.throttle.RecordSuccess(email, ip); err != nil {
+		s.logger.Errorln("Layer: two_factor_service, Method: CompleteLogin, Error: Clearing failed attempts:", err)
+	}
+
+	if err := startSession(s.sessions, s.tokens, &user, client); err != nil {
+		s.logger.Errorln("Layer: two_factor_service, Method: CompleteLogin, Error: Generating token:", err)
+		return nil, err
+	}
+	if _, err := s.users.UpdateUserToken(&user); err != nil {
+		s.logger.Errorln("Layer: two_factor_service, Method: CompleteLogin, Error: Updating user token:", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// verifyCode accepts a 6 digit authenticator code, each at most once, or an
+// unused recovery code.
+func (s *twoFactorService) verifyCode(user *models.User, code string) error {
+	code = normalizeCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(user.TOTPSecret, code, s.now(), totpSkew)
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		claimed, err := s.twoFactor.ClaimStep(user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.twoFactor.UseRecoveryCode(user.ID, hashRecoveryCode(code), s.now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns codes formatted as XXXXX-XXXXX and their
+// hashes. Each code carries 50 random bits, so a plain SHA-256 is enough.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 8)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		encoded := base32.StdEncoding.EncodeToString(raw)[:10]
+		codes[i] = encoded[:5] + "-" + encoded[5:]
+		hashes[i] = hashRecoveryCode(encoded)
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeCode drops the separators users type and upper-cases recovery
+// codes.
+func normalizeCode(code string) string {
+	code = strings.NewReplacer(" ", "", "-", "").Replace(code)
+	return strings.ToUpper(code)
+}
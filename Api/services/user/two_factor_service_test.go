@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+	two_factor_repo "pruebaVertice/Api/repo/two_factor_repo"
+	"pruebaVertice/Api/utils/totp"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+var twoFactorNow = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func newTwoFactorService() (*twoFactorService, *UserRepoMock, *TwoFactorRepoMock, *TokenGeneratorMock) {
+	users := new(UserRepoMock)
+	repo := new(TwoFactorRepoMock)
+	tokens := new(TokenGeneratorMock)
+	svc := NewTwoFactorService(users, repo, tokens, allowLogins(), anySession(), TwoFactorConfig{Issuer: "Tienda"}, logrus.New())
+	svc.now = func() time.Time { return twoFactorNow }
+	return svc, users, repo, tokens
+}
+
+func currentCode(t *testing.T) string {
+	code, err := totp.Code(testTOTPSecret, totp.Step(twoFactorNow))
+	require.NoError(t, err)
+	return code
+}
+
+func enabledUser() models.User {
+	enabledAt := twoFactorNow.Add(-time.Hour)
+	return models.User{Model: gorm.Model{ID: 4}, Email: "ana@example.com", TOTPSecret: testTOTPSecret, TwoFactorEnabledAt: &enabledAt}
+}
+
+func TestEnroll_ReturnsProvisioningURI(t *testing.T) {
+	svc, _, repo, _ := newTwoFactorService()
+	repo.On("SetPendingSecret", uint(4), mock.AnythingOfType("string")).Return(nil)
+
+	enrollment, err := svc.Enroll(&models.User{Model: gorm.Model{ID: 4}, Email: "ana@example.com"})
+	require.NoError(t, err)
+
+	uri, err := url.Parse(enrollment.ProvisioningURI)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Tienda", uri.Query().Get("issuer"))
+	repo.AssertCalled(t, "SetPendingSecret", uint(4), enrollment.Secret)
+}
+
+func TestEnroll_AlreadyEnabled(t *testing.T) {
+	svc, _, _, _ := newTwoFactorService()
+	user := enabledUser()
+
+	_, err := svc.Enroll(&user)
+	assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+}
+
+func TestConfirm_EnablesAndReturnsHashedRecoveryCodes(t *testing.T) {
+	svc, _, repo, _ := newTwoFactorService()
+	var hashes []string
+	repo.On("Enable", uint(4), totp.Step(twoFactorNow), mock.Anything, twoFactorNow).
+		Run(func(args mock.Arguments) { hashes = args.Get(2).([]string) }).
+		Return(nil)
+
+	user := &models.User{Model: gorm.Model{ID: 4}, TOTPSecret: testTOTPSecret}
+	codes, err := svc.Confirm(user, currentCode(t))
+	require.NoError(t, err)
+
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+	assert.Regexp(t, regexp.MustCompile(`^[A-Z2-7]{5}-[A-Z2-7]{5}$`), codes[0])
+	assert.Equal(t, hashRecoveryCode(normalizeCode(codes[0])), hashes[0])
+	assert.NotContains(t, hashes, codes[0])
+}
+
+func TestConfirm_RejectsWrongCodeAndMissingEnrollment(t *testing.T) {
+	svc, _, repo, _ := newTwoFactorService()
+
+	_, err := svc.Confirm(&models.User{Model: gorm.Model{ID: 4}}, "123456")
+	assert.ErrorIs(t, err, ErrTwoFactorNotEnrolled)
+
+	_, err = svc.Confirm(&models.User{Model: gorm.Model{ID: 4}, TOTPSecret: testTOTPSecret}, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	repo.AssertNotCalled(t, "Enable", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConfirm_LostRaceReportsAlreadyEnabled(t *testing.T) {
+	svc, _, repo, _ := newTwoFactorService()
+	repo.On("Enable", uint(4), mock.Anything, mock.Anything, mock.Anything).Return(two_factor_repo.ErrAlreadyEnabled)
+
+	_, err := svc.Confirm(&models.User{Model: gorm.Model{ID: 4}, TOTPSecret: testTOTPSecret}, currentCode(t))
+	assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+}
+
+func TestCompleteLogin_WithTOTPCode(t *testing.T) {
+	svc, users, repo, tokens := newTwoFactorService()
+	user := enabledUser()
+	tokens.On("ParseChallengeToken", "challenge").Return("ana@example.com", twoFactorNow, nil)
+	users.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+	repo.On("ClaimStep", uint(4), totp.Step(twoFactorNow)).Return(true, nil)
+	tokens.On("GenerateToken", "ana@example.com", "sid").Return("tok", "ref", nil)
+	users.On("UpdateUserToken", mock.AnythingOfType("*models.User")).Return(&user, nil)
+
+	logged, err := svc.CompleteLogin("challenge", currentCode(t), models.ClientInfo{IP: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, "tok", logged.Token)
+	assert.Equal(t, "ref", logged.RefreshToken)
+}
+
+func TestCompleteLogin_RejectsReplayedCode(t *testing.T) {
+	svc, users, repo, tokens := newTwoFactorService()
+	tokens.On("ParseChallengeToken", "challenge").Return("ana@example.com", twoFactorNow, nil)
+	users.On("GetUserByEmail", "ana@example.com").Return(enabledUser(), nil)
+	repo.On("ClaimStep", uint(4), totp.Step(twoFactorNow)).Return(false, nil)
+
+	_, err := svc.CompleteLogin("challenge", currentCode(t), models.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	tokens.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+}
+
+func TestCompleteLogin_WithRecoveryCode(t *testing.T) {
+	svc, users, repo, tokens := newTwoFactorService()
+	user := enabledUser()
+	tokens.On("ParseChallengeToken", "challenge").Return("ana@example.com", twoFactorNow, nil)
+	users.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+	repo.On("UseRecoveryCode", uint(4), hashRecoveryCode("ABCDE23456"), twoFactorNow).Return(true, nil)
+	tokens.On("GenerateToken", "ana@example.com", "sid").Return("tok", "ref", nil)
+	users.On("UpdateUserToken", mock.AnythingOfType("*models.User")).Return(&user, nil)
+
+	_, err := svc.CompleteLogin("challenge", "abcde-23456", models.ClientInfo{IP: "10.0.0.1"})
+	assert.NoError(t, err)
+}
+
+func TestCompleteLogin_InvalidChallenge(t *testing.T) {
+	svc, users, _, tokens := newTwoFactorService()
+	tokens.On("ParseChallengeToken", "bad").Return("", time.Time{}, errors.New("expired"))
+
+	_, err := svc.CompleteLogin("bad", "123456", models.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+
+	// A password reset after the challenge was issued invalidates it.
+	user := enabledUser()
+	revoked := twoFactorNow.Add(time.Minute)
+	user.SessionsRevokedAt = &revoked
+	tokens.On("ParseChallengeToken", "stale").Return("ana@example.com", twoFactorNow, nil)
+	users.On("GetUserByEmail", "ana@example.com").Return(user, nil)
+
+	_, err = svc.CompleteLogin("stale", currentCode(t), models.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+}
+
+func TestDisable_RequiresValidCode(t *testing.T) {
+	svc, _, repo, _ := newTwoFactorService()
+	user := enabledUser()
+	repo.On("UseRecoveryCode", uint(4), mock.Anything, twoFactorNow).Return(false, nil)
+
+	err := svc.Disable(&user, "WRONG-CODE1")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	repo.AssertNotCalled(t, "Disable", mock.Anything)
+
+	repo.On("ClaimStep", uint(4), totp.Step(twoFactorNow)).Return(true, nil)
+	repo.On("Disable", uint(4)).Return(nil)
+	assert.NoError(t, svc.Disable(&user, currentCode(t)))
+
+	assert.ErrorIs(t, svc.Disable(&models.User{}, "123456"), ErrTwoFactorNotEnabled)
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"pruebaVertice/Api/models"
+	"time"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -54,3 +56,49 @@ func (m *UserRepoMock) GetUserByEmail(email string) (models.User, error) {
 	}
 	return models.User{}, args.Error(1)
 }
+
+func (m *UserRepoMock) MarkEmailVerified(userID uint, email string, at time.Time) (bool, error) {
+	args := m.Called(userID, email, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *UserRepoMock) ClaimVerificationSend(userID uint, at, notBefore time.Time) (bool, error) {
+	args := m.Called(userID, at, notBefore)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *UserRepoMock) UpdateProfile(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *UserRepoMock) ChangePassword(userID uint, passwordHash string, at time.Time) error {
+	args := m.Called(userID, passwordHash, at)
+	return args.Error(0)
+}
+
+func (m *UserRepoMock) AnonymizeUser(userID uint, at time.Time) error {
+	args := m.Called(userID, at)
+	return args.Error(0)
+}
+
+func (m *UserRepoMock) RevokeCredentials(userID uint, at time.Time) error {
+	args := m.Called(userID, at)
+	return args.Error(0)
+}
+
+func (m *UserRepoMock) SearchUsers(filter models.UserFilter) ([]models.User, int64, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.([]models.User), args.Get(1).(int64), args.Error(2)
+	}
+	return nil, 0, args.Error(2)
+}
+
+func (m *UserRepoMock) SetDisabled(userID uint, disabled bool, at time.Time) error {
+	return m.Called(userID, disabled, at).Error(0)
+}
+
+func (m *UserRepoMock) UpdateRole(userID uint, role string) error {
+	return m.Called(userID, role).Error(0)
+}
@@ -4,10 +4,13 @@ import (
 	"errors"
 	"pruebaVertice/Api/models"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestCreateUser_Success(t *testing.T) {
@@ -15,7 +18,7 @@ func TestCreateUser_Success(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	userInput := &models.User{Email: "u@e.com", Password: "pwd"}
 	hashed := "hashedpwd"
@@ -26,13 +29,13 @@ func TestCreateUser_Success(t *testing.T) {
 
 	token := "tok"
 	refresh := "ref"
-	tokenMock.On("GenerateToken", "u@e.com").Return(token, refresh, nil)
+	tokenMock.On("GenerateToken", "u@e.com", "sid").Return(token, refresh, nil)
 
 	// updatedModel without timestamps
 	updatedModel := &models.User{Email: "u@e.com", Password: hashed, Token: token, RefreshToken: refresh}
 	repoMock.On("UpdateUserToken", mock.AnythingOfType("*models.User")).Return(updatedModel, nil)
 
-	res, err := svc.CreateUser(userInput)
+	res, err := svc.CreateUser(userInput, models.ClientInfo{})
 	assert.NoError(t, err)
 	// only check relevant fields
 	assert.Equal(t, token, res.Token)
@@ -49,10 +52,10 @@ func TestCreateUser_HashError(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	hasherMock.On("HashPassword", "pwd").Return("", errors.New("hash err"))
-	_, err := svc.CreateUser(&models.User{Password: "pwd"})
+	_, err := svc.CreateUser(&models.User{Password: "pwd"}, models.ClientInfo{})
 	assert.EqualError(t, err, "hash err")
 }
 
@@ -61,11 +64,11 @@ func TestCreateUser_CreateError(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	hasherMock.On("HashPassword", "pwd").Return("hashed", nil)
 	repoMock.On("CreateUser", mock.Anything).Return(nil, errors.New("create err"))
-	_, err := svc.CreateUser(&models.User{Password: "pwd"})
+	_, err := svc.CreateUser(&models.User{Password: "pwd"}, models.ClientInfo{})
 	assert.EqualError(t, err, "create err")
 }
 
@@ -74,12 +77,12 @@ func TestCreateUser_GenerateTokenError(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	hasherMock.On("HashPassword", "pwd").Return("hashed", nil)
 	repoMock.On("CreateUser", mock.Anything).Return(&models.User{Email: "e@e"}, nil)
-	tokenMock.On("GenerateToken", "e@e").Return("", "", errors.New("tok err"))
-	_, err := svc.CreateUser(&models.User{Email: "e@e", Password: "pwd"})
+	tokenMock.On("GenerateToken", "e@e", "sid").Return("", "", errors.New("tok err"))
+	_, err := svc.CreateUser(&models.User{Email: "e@e", Password: "pwd"}, models.ClientInfo{})
 	assert.EqualError(t, err, "tok err")
 }
 
@@ -88,20 +91,20 @@ func TestCreateUser_UpdateTokenError(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	hasherMock.On("HashPassword", "pwd").Return("hashed", nil)
 	repoMock.On("CreateUser", mock.Anything).Return(&models.User{Email: "e@e"}, nil)
-	tokenMock.On("GenerateToken", "e@e").Return("tok", "ref", nil)
+	tokenMock.On("GenerateToken", "e@e", "sid").Return("tok", "ref", nil)
 	repoMock.On("UpdateUserToken", mock.Anything).Return(nil, errors.New("upd err"))
-	_, err := svc.CreateUser(&models.User{Email: "e@e", Password: "pwd"})
+	_, err := svc.CreateUser(&models.User{Email: "e@e", Password: "pwd"}, models.ClientInfo{})
 	assert.EqualError(t, err, "upd err")
 }
 
 func TestGetUserByID(t *testing.T) {
 	repoMock := new(UserRepoMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, nil, nil, logger)
+	svc := NewUserService(repoMock, nil, nil, allowLogins(), anySession(), logger)
 
 	repoMock.On("GetUserByID", "1").Return(&models.User{Email: "x"}, nil)
 	res, err := svc.GetUserByID("1")
@@ -112,7 +115,7 @@ func TestGetUserByID(t *testing.T) {
 func TestGetUserByID_Error(t *testing.T) {
 	repoMock := new(UserRepoMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, nil, nil, logger)
+	svc := NewUserService(repoMock, nil, nil, allowLogins(), anySession(), logger)
 
 	repoMock.On("GetUserByID", "1").Return(nil, errors.New("not found"))
 	_, err := svc.GetUserByID("1")
@@ -122,7 +125,7 @@ func TestGetUserByID_Error(t *testing.T) {
 func TestUpdateUser(t *testing.T) {
 	repoMock := new(UserRepoMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, nil, nil, logger)
+	svc := NewUserService(repoMock, nil, nil, allowLogins(), anySession(), logger)
 
 	repoMock.On("UpdateUser", &models.User{Email: "u"}).Return(&models.User{Email: "u"}, nil)
 	res, err := svc.UpdateUser(&models.User{Email: "u"})
@@ -133,7 +136,7 @@ func TestUpdateUser(t *testing.T) {
 func TestUpdateUser_Error(t *testing.T) {
 	repoMock := new(UserRepoMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, nil, nil, logger)
+	svc := NewUserService(repoMock, nil, nil, allowLogins(), anySession(), logger)
 
 	repoMock.On("UpdateUser", mock.Anything).Return(nil, errors.New("upd err"))
 	_, err := svc.UpdateUser(&models.User{})
@@ -143,7 +146,7 @@ func TestUpdateUser_Error(t *testing.T) {
 func TestDeleteUser(t *testing.T) {
 	repoMock := new(UserRepoMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, nil, nil, logger)
+	svc := NewUserService(repoMock, nil, nil, allowLogins(), anySession(), logger)
 
 	repoMock.On("DeleteUser", "2").Return(nil)
 	err := svc.DeleteUser("2")
@@ -153,7 +156,7 @@ func TestDeleteUser(t *testing.T) {
 func TestDeleteUser_Error(t *testing.T) {
 	repoMock := new(UserRepoMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, nil, nil, logger)
+	svc := NewUserService(repoMock, nil, nil, allowLogins(), anySession(), logger)
 
 	repoMock.On("DeleteUser", "2").Return(errors.New("del err"))
 	err := svc.DeleteUser("2")
@@ -165,18 +168,18 @@ func TestLogin_Success(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	stored := models.User{Email: "e@e", Password: "hashed"}
 	repoMock.On("GetUserByEmail", "e@e").Return(stored, nil)
 	hasherMock.On("CheckPasswordHash", "pwd", "hashed").Return(true)
-	tokenMock.On("GenerateToken", "e@e").Return("tok", "ref", nil)
+	tokenMock.On("GenerateToken", "e@e", "sid").Return("tok", "ref", nil)
 
 	// updated without timestamps
 		updated := &models.User{Email: "e@e", Token: "tok", RefreshToken: "ref"}
 	repoMock.On("UpdateUserToken", mock.AnythingOfType("*models.User")).Return(updated, nil)
 
-	res, err := svc.Login("e@e", "pwd")
+	res, err := svc.Login("e@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
 	assert.NoError(t, err)
 	// assert key fields for login
 	assert.Equal(t, updated.Token, res.Token)
@@ -189,11 +192,11 @@ func TestLogin_InvalidPassword(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	repoMock.On("GetUserByEmail", "e@e").Return(models.User{Email: "e@e", Password: "hashed"}, nil)
 	hasherMock.On("CheckPasswordHash", "pwd", "hashed").Return(false)
-	_, err := svc.Login("e@e", "pwd")
+	_, err := svc.Login("e@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
 	assert.EqualError(t, err, ErrInvalidPassword.Error())
 }
 
@@ -202,12 +205,12 @@ func TestLogin_GenerateError(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	repoMock.On("GetUserByEmail", "e@e").Return(models.User{Email: "e@e", Password: "hashed"}, nil)
 	hasherMock.On("CheckPasswordHash", "pwd", "hashed").Return(true)
-	tokenMock.On("GenerateToken", "e@e").Return("", "", errors.New("tok err"))
-	_, err := svc.Login("e@e", "pwd")
+	tokenMock.On("GenerateToken", "e@e", "sid").Return("", "", errors.New("tok err"))
+	_, err := svc.Login("e@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
 	assert.EqualError(t, err, "tok err")
 }
 
@@ -216,20 +219,20 @@ func TestLogin_UpdateTokenError(t *testing.T) {
 	hasherMock := new(HasherMock)
 	tokenMock := new(TokenGeneratorMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, hasherMock, tokenMock, logger)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logger)
 
 	repoMock.On("GetUserByEmail", "e@e").Return(models.User{Email: "e@e", Password: "hashed"}, nil)
 	hasherMock.On("CheckPasswordHash", "pwd", "hashed").Return(true)
-	tokenMock.On("GenerateToken", "e@e").Return("tok", "ref", nil)
+	tokenMock.On("GenerateToken", "e@e", "sid").Return("tok", "ref", nil)
 	repoMock.On("UpdateUserToken", mock.Anything).Return(nil, errors.New("upd err"))
-	_, err := svc.Login("e@e", "pwd")
+	_, err := svc.Login("e@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
 	assert.EqualError(t, err, "upd err")
 }
 
 func TestGetUserByEmail(t *testing.T) {
 	repoMock := new(UserRepoMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, nil, nil, logger)
+	svc := NewUserService(repoMock, nil, nil, allowLogins(), anySession(), logger)
 
 	repoMock.On("GetUserByEmail", "a@b").Return(models.User{Email: "a@b"}, nil)
 	res, err := svc.GetUserByEmail("a@b")
@@ -240,9 +243,115 @@ func TestGetUserByEmail(t *testing.T) {
 func TestGetUserByEmail_Error(t *testing.T) {
 	repoMock := new(UserRepoMock)
 	logger := logrus.New()
-	svc := NewUserService(repoMock, nil, nil, logger)
+	svc := NewUserService(repoMock, nil, nil, allowLogins(), anySession(), logger)
 
 	repoMock.On("GetUserByEmail", "a@b").Return(models.User{}, errors.New("not found"))
 	_, err := svc.GetUserByEmail("a@b")
 	assert.EqualError(t, err, "not found")
 }
+
+func TestLogin_TwoFactorReturnsChallenge(t *testing.T) {
+	repoMock := new(UserRepoMock)
+	hasherMock := new(HasherMock)
+	tokenMock := new(TokenGeneratorMock)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logrus.New())
+
+	enabledAt := time.Now()
+	repoMock.On("GetUserByEmail", "e@e").Return(models.User{Email: "e@e", Password: "hashed", TwoFactorEnabledAt: &enabledAt}, nil)
+	hasherMock.On("CheckPasswordHash", "pwd", "hashed").Return(true)
+	tokenMock.On("GenerateChallengeToken", "e@e").Return("challenge", nil)
+
+	res, err := svc.Login("e@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
+	assert.Nil(t, res)
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+	var required *TwoFactorRequiredError
+	require.ErrorAs(t, err, &required)
+	assert.Equal(t, "challenge", required.ChallengeToken)
+	tokenMock.AssertNotCalled(t, "GenerateToken", "e@e", mock.Anything)
+	repoMock.AssertNotCalled(t, "UpdateUserToken", mock.Anything)
+}
+
+func TestLogin_LockedOut(t *testing.T) {
+	repoMock := new(UserRepoMock)
+	throttle := new(LoginThrottleMock)
+	throttle.On("Check", "e@e", "10.0.0.1").Return(&LoginLockedError{RetryAfter: time.Minute})
+	svc := NewUserService(repoMock, new(HasherMock), new(TokenGeneratorMock), throttle, anySession(), logrus.New())
+
+	_, err := svc.Login("e@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrLoginLocked)
+	repoMock.AssertNotCalled(t, "GetUserByEmail", mock.Anything)
+}
+
+func TestLogin_UnknownEmailChecksDummyHashAndCountsFailure(t *testing.T) {
+	repoMock := new(UserRepoMock)
+	hasherMock := new(HasherMock)
+	throttle := new(LoginThrottleMock)
+	throttle.On("Check", "ghost@e", "10.0.0.1").Return(nil)
+	throttle.On("RecordFailure", "ghost@e", "10.0.0.1").Return(nil)
+	svc := NewUserService(repoMock, hasherMock, new(TokenGeneratorMock), throttle, anySession(), logrus.New())
+
+	repoMock.On("GetUserByEmail", "ghost@e").Return(nil, gorm.ErrRecordNotFound)
+	hasherMock.On("HashPassword", mock.Anything).Return("dummy-hash", nil).Once()
+	hasherMock.On("CheckPasswordHash", "pwd", "dummy-hash").Return(true)
+
+	_, err := svc.Login("ghost@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+	_, err = svc.Login("ghost@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+
+	hasherMock.AssertNumberOfCalls(t, "CheckPasswordHash", 2)
+	throttle.AssertNumberOfCalls(t, "RecordFailure", 2)
+}
+
+func TestLogin_WrongPasswordCountsFailure(t *testing.T) {
+	repoMock := new(UserRepoMock)
+	hasherMock := new(HasherMock)
+	throttle := new(LoginThrottleMock)
+	throttle.On("Check", "e@e", "10.0.0.1").Return(nil)
+	throttle.On("RecordFailure", "e@e", "10.0.0.1").Return(nil)
+	svc := NewUserService(repoMock, hasherMock, new(TokenGeneratorMock), throttle, anySession(), logrus.New())
+
+	repoMock.On("GetUserByEmail", "e@e").Return(models.User{Email: "e@e", Password: "hashed"}, nil)
+	hasherMock.On("CheckPasswordHash", "bad", "hashed").Return(false)
+
+	_, err := svc.Login("e@e", "bad", models.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+	throttle.AssertExpectations(t)
+	throttle.AssertNotCalled(t, "RecordSuccess", mock.Anything, mock.Anything)
+}
+
+func TestLogin_StartsSessionForClient(t *testing.T) {
+	repoMock := new(UserRepoMock)
+	hasherMock := new(HasherMock)
+	tokenMock := new(TokenGeneratorMock)
+	sessions := new(SessionServiceMock)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), sessions, logrus.New())
+
+	client := models.ClientInfo{IP: "10.0.0.1", UserAgent: "curl/8.5.0"}
+	repoMock.On("GetUserByEmail", "e@e").Return(models.User{Email: "e@e", Password: "hashed"}, nil)
+	hasherMock.On("CheckPasswordHash", "pwd", "hashed").Return(true)
+	sessions.On("Start", mock.MatchedBy(func(u *models.User) bool { return u.Email == "e@e" }), client).
+		Return(&models.Session{TokenID: "laptop"}, nil)
+	tokenMock.On("GenerateToken", "e@e", "laptop").Return("tok", "ref", nil)
+	repoMock.On("UpdateUserToken", mock.AnythingOfType("*models.User")).Return(&models.User{}, nil)
+
+	_, err := svc.Login("e@e", "pwd", client)
+	assert.NoError(t, err)
+	sessions.AssertExpectations(t)
+	tokenMock.AssertExpectations(t)
+}
+
+func TestLogin_DisabledAccount(t *testing.T) {
+	repoMock := new(UserRepoMock)
+	hasherMock := new(HasherMock)
+	tokenMock := new(TokenGeneratorMock)
+	svc := NewUserService(repoMock, hasherMock, tokenMock, allowLogins(), anySession(), logrus.New())
+
+	disabledAt := time.Now()
+	repoMock.On("GetUserByEmail", "e@e").Return(models.User{Email: "e@e", Password: "hashed", DisabledAt: &disabledAt}, nil)
+	hasherMock.On("CheckPasswordHash", "pwd", "hashed").Return(true)
+
+	_, err := svc.Login("e@e", "pwd", models.ClientInfo{IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrAccountDisabled)
+	tokenMock.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+}
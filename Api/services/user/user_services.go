@@ -4,16 +4,19 @@ import (
 	"errors"
 	"pruebaVertice/Api/models"
 	repo "pruebaVertice/Api/repo/user_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"sync"
 
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
-	CreateUser(user *models.User) (*models.User, error)
+	CreateUser(user *models.User, client models.ClientInfo) (*models.User, error)
 	GetUserByID(id string) (*models.User, error)
 	UpdateUser(user *models.User) (*models.User, error)
 	DeleteUser(id string) error
-	Login(email, password string) (*models.User, error)
+	Login(email, password string, client models.ClientInfo) (*models.User, error)
 	GetUserByEmail(email string) (*models.User, error)
 }
 
@@ -22,9 +25,17 @@ type userService struct {
 	logger         *logrus.Logger
 	hasher         Hasher
 	tokenGenerator TokenGenerator
+	throttle       LoginThrottle
+	sessions       SessionService
+
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
-var ErrInvalidPassword = errors.New("invalid password")
+var (
+	ErrInvalidPassword = apperr.Unauthorized("invalid_credentials", "invalid password")
+	ErrAccountDisabled = apperr.Forbidden("account_disabled", "account disabled")
+)
 
 // Hasher defines password hashing behavior
 type Hasher interface {
@@ -34,25 +45,31 @@ type Hasher interface {
 
 // TokenGenerator defines JWT behavior
  type TokenGenerator interface {
-	GenerateToken(email string) (string, string, error)
+	GenerateToken(email, sessionID string) (string, string, error)
+	GenerateChallengeToken(email string) (string, error)
 }
 
- func NewUserService(repo repo.UserRepository, hasher Hasher, tokenGen TokenGenerator, logger *logrus.Logger) *userService {
+ func NewUserService(repo repo.UserRepository, hasher Hasher, tokenGen TokenGenerator, throttle LoginThrottle, sessions SessionService, logger *logrus.Logger) *userService {
 
 	return &userService{
 		repo:           repo,
 		hasher:         hasher,
 		tokenGenerator: tokenGen,
+		throttle:       throttle,
+		sessions:       sessions,
 		logger:         logger,
 	}
 }
-func (s *userService) CreateUser(user *models.User) (*models.User, error) {
+func (s *userService) CreateUser(user *models.User, client models.ClientInfo) (*models.User, error) {
 	hashedPassword, err := s.hasher.HashPassword(user.Password)
 	if err != nil {
 		s.logger.Errorln("Layer:user_service, Method:CreateUser, Error: Hashing password:", err)
 		return nil, err
 	}
 	user.Password = hashedPassword
+	// Roles and verification are never taken from the registration payload.
+	user.Role = models.RoleCustomer
+	user.EmailVerifiedAt = nil
 
 	_, err = s.repo.CreateUser(user)
 	if err != nil {
@@ -60,15 +77,11 @@ func (s *userService) CreateUser(user *models.User) (*models.User, error) {
 		return nil, err
 	}
 
-	token, refreshToken, err := s.tokenGenerator.GenerateToken(user.Email)
-	if err != nil {
+	if err := startSession(s.sessions, s.tokenGenerator, user, client); err != nil {
 		s.logger.Errorln("Layer:user_service, Method:CreateUser, Error: Generating token:", err)
 		return nil, err
 	}
 
-	user.Token = token
-	user.RefreshToken = refreshToken
-
 	_, err = s.repo.UpdateUserToken(user)
 	if err != nil {
 		s.logger.Errorln("Layer:user_service, Method:CreateUser, Error: Updating user token:", err)
@@ -79,7 +92,11 @@ func (s *userService) CreateUser(user *models.User) (*models.User, error) {
 }
 
 func (s *userService) GetUserByID(id string) (*models.User, error) {
-	return s.repo.GetUserByID(id)
+	user, err := s.repo.GetUserByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
 }
 
 func (s *userService) UpdateUser(user *models.User) (*models.User, error) {
@@ -89,21 +106,53 @@ func (s *userService) DeleteUser(id string) error {
 	return s.repo.DeleteUser(id)
 }
 
-func (s *userService) Login(email, password string) (*models.User, error) {
-	user, _ := s.repo.GetUserByEmail(email)
+func (s *userService) Login(email, password string, client models.ClientInfo) (*models.User, error) {
+	ip := client.IP
+	if err := s.throttle.Check(email, ip); err != nil {
+		return nil, err
+	}
 
-	if !s.hasher.CheckPasswordHash(password, user.Password) {
+	user, err := s.repo.GetUserByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Errorln("Layer:user_service, Method:Login, Error:", err)
+		return nil, err
+	}
+	// Unknown emails are checked against a dummy hash so they take as long
+	// as a wrong password for an existing account.
+	hash := user.Password
+	if err != nil {
+		hash = s.timingHash()
+	}
+	if !s.hasher.CheckPasswordHash(password, hash) || err != nil {
 		s.logger.Errorln("Layer:user_service, Method:Login, Error: Invalid password")
+		if err := s.throttle.RecordFailure(email, ip); err != nil {
+			s.logger.Errorln("Layer:user_service, Method:Login, Error: Recording failed attempt:", err)
+		}
 		return nil, ErrInvalidPassword
 	}
+	if err := s.throttle.RecordSuccess(email, ip); err != nil {
+		s.logger.Errorln("Layer:user_service, Method:Login, Error: Clearing failed attempts:", err)
+	}
+	// Only reported once the password is right, so it reveals nothing
+	// about other people's accounts.
+	if user.DisabledAt != nil {
+		s.logger.Warnln("Layer:user_service, Method:Login, Error: Login to disabled account", user.ID)
+		return nil, ErrAccountDisabled
+	}
 
-	token, refreshToken, err := s.tokenGenerator.GenerateToken(user.Email)
-	if err != nil {
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := s.tokenGenerator.GenerateChallengeToken(user.Email)
+		if err != nil {
+			s.logger.Errorln("Layer:user_service, Method:Login, Error: Generating challenge token:", err)
+			return nil, err
+		}
+		return nil, &TwoFactorRequiredError{ChallengeToken: challenge}
+	}
+
+	if err := startSession(s.sessions, s.tokenGenerator, &user, client); err != nil {
 		s.logger.Errorln("Layer:user_service, Method:Login, Error: Generating token:", err)
 		return nil, err
 	}
-	user.Token = token
-	user.RefreshToken = refreshToken
 
 	userr, err := s.repo.UpdateUserToken(&user)
 	if err != nil {
@@ -116,10 +165,20 @@ func (s *userService) Login(email, password string) (*models.User, error) {
 	return &user, nil
 }
 
+func (s *userService) timingHash() string {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.hasher.HashPassword("timing-equalization-only")
+	})
+	return s.dummyHash
+}
+
 func (s *userService) GetUserByEmail(email string) (*models.User, error) {
 	user, err := s.repo.GetUserByEmail(email)
 	if err != nil {
 		s.logger.Errorln("Layer:user_service, Method:GetUserByEmail, Error:", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
@@ -0,0 +1,57 @@
+package services_webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultDispatchInterval = 5 * time.Second
+
+// Dispatcher periodically sends the webhook deliveries that are due.
+type Dispatcher struct {
+	service  WebhookService
+	interval time.Duration
+	logger   *logrus.Logger
+}
+
+func NewDispatcher(service WebhookService, interval time.Duration, logger *logrus.Logger) *Dispatcher {
+	if interval <= 0 {
+		interval = DefaultDispatchInterval
+	}
+	return &Dispatcher{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start runs the dispatcher in the background until ctx is cancelled.
+func (d *Dispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		d.tick()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.tick()
+			}
+		}
+	}()
+}
+
+func (d *Dispatcher) tick() {
+	sent, err := d.service.DispatchDue()
+	if err != nil {
+		d.logger.Errorln("Layer: webhook_dispatcher, Method: tick, Error:", err)
+		return
+	}
+	if sent > 0 {
+		d.logger.Infof("Layer: webhook_dispatcher, Method: tick, attempted %d deliveries", sent)
+	}
+}
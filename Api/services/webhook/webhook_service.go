@@ -0,0 +1,450 @@
+package services_webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"pruebaVertice/Api/models"
+	repo "pruebaVertice/Api/repo/webhooks_repo"
+	"pruebaVertice/Api/utils/apperr"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it is dead.
+	MaxAttempts = 8
+	// First retry waits baseBackoff, doubling on every failure up to maxBackoff.
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+
+	dispatchBatch        = 50
+	requestTimeout       = 10 * time.Second
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+var (
+	ErrSubscriptionNotFound = apperr.NotFound("webhook_subscription_not_found", "webhook subscription not found")
+	ErrInvalidSubscription  = apperr.Invalid("invalid_webhook_subscription", "invalid webhook subscription")
+	ErrDeliveryNotFound     = apperr.NotFound("webhook_delivery_not_found", "webhook delivery not found")
+	ErrDeliveryNotDead      = apperr.Conflict("webhook_delivery_not_dead", "only dead deliveries can be retried")
+	ErrInvalidFilter        = apperr.BadRequest("invalid_delivery_filter", "invalid delivery filter")
+)
+
+// Publisher fans an event out to the webhook subscriptions interested in it.
+// Publishing never fails the caller; problems are logged.
+type Publisher interface {
+	Publish(eventType string, data interface{})
+}
+
+// NopPublisher discards every event.
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(string, interface{}) {}
+
+type WebhookService interface {
+	Publisher
+	CreateSubscription(req models.WebhookSubscriptionRequest, createdBy string) (*models.WebhookSubscription, error)
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+	GetSubscription(id uint) (*models.WebhookSubscription, error)
+	UpdateSubscription(id uint, req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+	ListDeliveries(filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+	GetDelivery(id uint) (*models.WebhookDelivery, error)
+	RetryDelivery(id uint) (*models.WebhookDelivery, error)
+	DispatchDue() (int, error)
+}
+
+type webhookService struct {
+	repo   repo.WebhooksRepository
+	client *http.Client
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+func NewWebhookService(repo repo.WebhooksRepository, logger *logrus.Logger) *webhookService {
+	return &webhookService{
+		repo:   repo,
+		client: &http.Client{Timeout: requestTimeout},
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// envelope is the JSON body posted to subscribers.
+type envelope struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Publish queues one delivery per active subscription wanting eventType.
+// The dispatcher sends them in the background.
+func (s *webhookService) Publish(eventType string, data interface{}) {
+	if err := s.enqueue(randomHex(16), eventType, data); err != nil {
+		s.logger.Errorln("Layer: webhook_service, Method: Publish, Error:", err)
+	}
+}
+
+// outboxEvents maps the domain events forwarded to subscribers onto their
+// webhook event type.
+var outboxEvents = map[string]string{
+	models.EventOrderPlaced:         models.WebhookEventOrderCreated,
+	models.EventOrderCancelled:      models.WebhookEventOrderStatusChanged,
+	models.EventOrderStatusChanged:  models.WebhookEventOrderStatusChanged,
+	models.EventProductCreated:      models.WebhookEventProductCreated,
+	models.EventProductPriceChanged: models.WebhookEventProductPriceChanged,
+}
+
+func (s *webhookService) Name() string {
+	return "webhooks"
+}
+
+// Handle lets the outbox dispatcher feed domain events to subscribers. The
+// envelope ID is derived from the outbox event so a redelivered event
+// carries the same ID.
+func (s *webhookService) Handle(event models.OutboxEvent) error {
+	eventType, ok := outboxEvents[event.Type]
+	if !ok {
+		return nil
+	}
+	return s.enqueue(fmt.Sprintf("evt_%d", event.ID), eventType, json.RawMessage(event.Payload))
+}
+
+func (s *webhookService) enqueue(id, eventType string, data interface{}) error {
+	subscriptions, err := s.repo.GetActiveSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(eventType) {
+			continue
+		}
+		if payload == nil {
+			payload, err = json.Marshal(envelope{
+				ID:        id,
+				Type:      eventType,
+				CreatedAt: s.now().UTC(),
+				Data:      data,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  s.now(),
+		})
+	}
+
+	return s.repo.CreateDeliveries(deliveries)
+}
+
+// CreateSubscription stores a new subscription. A secret is generated when
+// none is given; this is the only response that includes it.
+func (s *webhookService) CreateSubscription(req models.WebhookSubscriptionRequest, createdBy string) (*models.WebhookSubscription, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		secret = randomHex(32)
+	}
+	subscription := &models.WebhookSubscription{
+		URL:       strings.TrimSpace(req.URL),
+		Secret:    secret,
+		Events:    models.StringList(req.Events),
+		Active:    req.Active == nil || *req.Active,
+		CreatedBy: createdBy,
+	}
+
+	created, err := s.repo.CreateSubscription(subscription)
+	if err != nil {
+		s.logger.Errorln("Layer: webhook_service, Method: CreateSubscription, Error:", err)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *webhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.repo.GetSubscriptions()
+	if err != nil {
+		s.logger.Errorln("Layer: webhook_service, Method: ListSubscriptions, Error:", err)
+		return nil, err
+	}
+	if subscriptions == nil {
+		subscriptions = []models.WebhookSubscription{}
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+func (s *webhookService) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	subscription, err := s.repo.GetSubscriptionByID(id)
+	if err != nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// UpdateSubscription replaces URL, events and active flag. The secret is
+// only rotated when a new one is sent.
+func (s *webhookService) UpdateSubscription(id uint, req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	subscription, err := s.repo.GetSubscriptionByID(id)
+	if err != nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	subscription.URL = strings.TrimSpace(req.URL)
+	subscription.Events = models.StringList(req.Events)
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+	if req.Secret != "" {
+		subscription.Secret = req.Secret
+	}
+
+	updated, err := s.repo.UpdateSubscription(subscription)
+	if err != nil {
+		s.logger.Errorln("Layer: webhook_service, Method: UpdateSubscription, Error:", err)
+		return nil, err
+	}
+	updated.Secret = ""
+	return updated, nil
+}
+
+func (s *webhookService) DeleteSubscription(id uint) error {
+	if _, err := s.repo.GetSubscriptionByID(id); err != nil {
+		return ErrSubscriptionNotFound
+	}
+	if err := s.repo.DeleteSubscription(id); err != nil {
+		s.logger.Errorln("Layer: webhook_service, Method: DeleteSubscription, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// ListDeliveries is the delivery log, newest first. Filtering by status
+// "dead" gives the dead-letter list.
+func (s *webhookService) ListDeliveries(filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	switch filter.Status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryDead:
+	default:
+		return nil, ErrInvalidFilter
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultDeliveryLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxDeliveryLimit {
+		return nil, ErrInvalidFilter
+	}
+
+	deliveries, err := s.repo.GetDeliveries(filter)
+	if err != nil {
+		s.logger.Errorln("Layer: webhook_service, Method: ListDeliveries, Error:", err)
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+func (s *webhookService) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDeliveryByID(id)
+	if err != nil {
+		return nil, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// RetryDelivery puts a dead delivery back in the queue with a fresh set of
+// attempts.
+func (s *webhookService) RetryDelivery(id uint) (*models.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDeliveryByID(id)
+	if err != nil {
+		return nil, ErrDeliveryNotFound
+	}
+	if delivery.Status != models.WebhookDeliveryDead {
+		return nil, ErrDeliveryNotDead
+	}
+
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = s.now()
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		s.logger.Errorln("Layer: webhook_service, Method: RetryDelivery, Error:", err)
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DispatchDue sends every delivery whose next attempt is due and returns how
+// many were attempted.
+func (s *webhookService) DispatchDue() (int, error) {
+	deliveries, err := s.repo.GetDueDeliveries(s.now(), dispatchBatch)
+	if err != nil {
+		s.logger.Errorln("Layer: webhook_service, Method: DispatchDue, Error:", err)
+		return 0, err
+	}
+
+	subscriptions := map[uint]*models.WebhookSubscription{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, _ = s.repo.GetSubscriptionByID(delivery.SubscriptionID)
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		if subscription == nil || !subscription.Active {
+			delivery.Status = models.WebhookDeliveryDead
+			delivery.LastError = "subscription is missing or inactive"
+			if err := s.repo.UpdateDelivery(delivery); err != nil {
+				s.logger.Errorln("Layer: webhook_service, Method: DispatchDue, Error:", err)
+			}
+			continue
+		}
+
+		attempt := s.send(subscription, delivery)
+		s.applyOutcome(delivery, attempt)
+		if err := s.repo.RecordAttempt(delivery, attempt); err != nil {
+			s.logger.Errorln("Layer: webhook_service, Method: DispatchDue, Error:", err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (s *webhookService) send(subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) *models.WebhookAttempt {
+	started := s.now()
+	attempt := &models.WebhookAttempt{AttemptedAt: started}
+
+	timestamp := strconv.FormatInt(started.Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(subscription.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	attempt.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// applyOutcome moves the delivery to succeeded, schedules the next retry, or
+// gives up after MaxAttempts.
+func (s *webhookService) applyOutcome(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) {
+	delivery.Attempts++
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+
+	switch {
+	case attempt.Error == "":
+		delivery.Status = models.WebhookDeliverySucceeded
+	case delivery.Attempts >= MaxAttempts:
+		delivery.Status = models.WebhookDeliveryDead
+	default:
+		delivery.NextAttemptAt = s.now().Add(Backoff(delivery.Attempts))
+	}
+}
+
+// Backoff is the wait after the given number of failed attempts.
+func Backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<payload>". Receivers
+// recompute it with their copy of the secret to authenticate a delivery.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validate(req models.WebhookSubscriptionRequest) error {
+	u, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidSubscription.Detailf("invalid webhook subscription: url must be an absolute http or https URL")
+	}
+	if len(req.Events) == 0 {
+		return ErrInvalidSubscription.Detailf("invalid webhook subscription: at least one event is required")
+	}
+	for _, event := range req.Events {
+		known := false
+		for _, e := range models.WebhookEvents {
+			if e == event {
+				known = true
+			}
+		}
+		if !known {
+			return ErrInvalidSubscription.Detailf("invalid webhook subscription: unknown event %q", event)
+		}
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
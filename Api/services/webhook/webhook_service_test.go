@@ -0,0 +1,232 @@
+package services_webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pruebaVertice/Api/models"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var fixedNow = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newService(repoMock *WebhooksRepoMock) *webhookService {
+	svc := NewWebhookService(repoMock, logrus.New())
+	svc.now = func() time.Time { return fixedNow }
+	return svc
+}
+
+func TestPublish_QueuesForInterestedSubscriptions(t *testing.T) {
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("GetActiveSubscriptions").Return([]models.WebhookSubscription{
+		{ID: 1, Events: models.StringList{models.WebhookEventOrderCreated}},
+		{ID: 2, Events: models.StringList{models.WebhookEventProductCreated}},
+	}, nil)
+	repoMock.On("CreateDeliveries", mock.MatchedBy(func(d []models.WebhookDelivery) bool {
+		if len(d) != 1 || d[0].SubscriptionID != 1 || d[0].Status != models.WebhookDeliveryPending {
+			return false
+		}
+		var body envelope
+		return json.Unmarshal([]byte(d[0].Payload), &body) == nil &&
+			body.Type == models.WebhookEventOrderCreated && body.ID != ""
+	})).Return(nil)
+
+	svc.Publish(models.WebhookEventOrderCreated, models.Order{ID: 9})
+	repoMock.AssertExpectations(t)
+}
+
+func TestHandle_ForwardsOutboxEvents(t *testing.T) {
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("GetActiveSubscriptions").Return([]models.WebhookSubscription{
+		{ID: 1, Events: models.StringList{models.WebhookEventOrderCreated}},
+	}, nil)
+	repoMock.On("CreateDeliveries", mock.MatchedBy(func(d []models.WebhookDelivery) bool {
+		return len(d) == 1 && d[0].Payload == `{"id":"evt_42","type":"order.created","created_at":"2025-03-01T12:00:00Z","data":{"id":9}}`
+	})).Return(nil)
+
+	err := svc.Handle(models.OutboxEvent{ID: 42, Type: models.EventOrderPlaced, Payload: `{"id":9}`})
+	assert.NoError(t, err)
+	// Events without a webhook counterpart are accepted and dropped.
+	assert.NoError(t, svc.Handle(models.OutboxEvent{ID: 43, Type: models.EventUserRegistered}))
+	repoMock.AssertNumberOfCalls(t, "GetActiveSubscriptions", 1)
+
+	repoMock.ExpectedCalls = nil
+	repoMock.On("GetActiveSubscriptions").Return(nil, errors.New("db err"))
+	assert.EqualError(t, svc.Handle(models.OutboxEvent{ID: 44, Type: models.EventProductCreated}), "db err")
+}
+
+func TestHandle_ForwardsPriceChanges(t *testing.T) {
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("GetActiveSubscriptions").Return([]models.WebhookSubscription{
+		{ID: 1, Events: models.StringList{models.WebhookEventProductPriceChanged}},
+	}, nil)
+	repoMock.On("CreateDeliveries", mock.MatchedBy(func(d []models.WebhookDelivery) bool {
+		return len(d) == 1 && d[0].EventType == models.WebhookEventProductPriceChanged
+	})).Return(nil)
+
+	err := svc.Handle(models.OutboxEvent{ID: 45, Type: models.EventProductPriceChanged, Payload: `{"product_id":3,"price":8}`})
+	assert.NoError(t, err)
+	repoMock.AssertExpectations(t)
+}
+
+func TestDispatchDue_SignsAndSucceeds(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	delivery := models.WebhookDelivery{ID: 5, SubscriptionID: 1, EventType: models.WebhookEventOrderCreated, Payload: `{"id":"x"}`, Status: models.WebhookDeliveryPending}
+	repoMock.On("GetDueDeliveries", fixedNow, dispatchBatch).Return([]models.WebhookDelivery{delivery}, nil)
+	repoMock.On("GetSubscriptionByID", uint(1)).Return(&models.WebhookSubscription{ID: 1, URL: receiver.URL, Secret: "shh", Active: true}, nil)
+	repoMock.On("RecordAttempt", mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.Status == models.WebhookDeliverySucceeded && d.Attempts == 1 && d.LastStatusCode == 204
+	}), mock.AnythingOfType("*models.WebhookAttempt")).Return(nil)
+
+	n, err := svc.DispatchDue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repoMock.AssertExpectations(t)
+
+	require.NotNil(t, got)
+	assert.Equal(t, `{"id":"x"}`, string(body))
+	assert.Equal(t, models.WebhookEventOrderCreated, got.Header.Get(HeaderEvent))
+	assert.Equal(t, "5", got.Header.Get(HeaderDelivery))
+	assert.Equal(t, "sha256="+Sign("shh", got.Header.Get(HeaderTimestamp), body), got.Header.Get(HeaderSignature))
+}
+
+func TestDispatchDue_FailureSchedulesRetry(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	delivery := models.WebhookDelivery{ID: 5, SubscriptionID: 1, Attempts: 2, Status: models.WebhookDeliveryPending}
+	repoMock.On("GetDueDeliveries", fixedNow, dispatchBatch).Return([]models.WebhookDelivery{delivery}, nil)
+	repoMock.On("GetSubscriptionByID", uint(1)).Return(&models.WebhookSubscription{ID: 1, URL: receiver.URL, Active: true}, nil)
+	repoMock.On("RecordAttempt", mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.Status == models.WebhookDeliveryPending && d.Attempts == 3 &&
+			d.NextAttemptAt.Equal(fixedNow.Add(2*time.Minute)) && d.LastStatusCode == 500
+	}), mock.MatchedBy(func(a *models.WebhookAttempt) bool {
+		return a.StatusCode == 500 && a.Error != ""
+	})).Return(nil)
+
+	_, err := svc.DispatchDue()
+	require.NoError(t, err)
+	repoMock.AssertExpectations(t)
+}
+
+func TestDispatchDue_LastAttemptGoesDead(t *testing.T) {
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	delivery := models.WebhookDelivery{ID: 5, SubscriptionID: 1, Attempts: MaxAttempts - 1, Status: models.WebhookDeliveryPending}
+	repoMock.On("GetDueDeliveries", fixedNow, dispatchBatch).Return([]models.WebhookDelivery{delivery}, nil)
+	// Nothing listens on this address, so the request fails.
+	repoMock.On("GetSubscriptionByID", uint(1)).Return(&models.WebhookSubscription{ID: 1, URL: "http://127.0.0.1:1", Active: true}, nil)
+	repoMock.On("RecordAttempt", mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.Status == models.WebhookDeliveryDead && d.Attempts == MaxAttempts && d.LastError != ""
+	}), mock.Anything).Return(nil)
+
+	_, err := svc.DispatchDue()
+	require.NoError(t, err)
+	repoMock.AssertExpectations(t)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 32*time.Minute, Backoff(7))
+	assert.Equal(t, maxBackoff, Backoff(30))
+}
+
+func TestCreateSubscription_GeneratesSecret(t *testing.T) {
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("CreateSubscription", mock.MatchedBy(func(s *models.WebhookSubscription) bool {
+		return len(s.Secret) == 64 && s.Active && s.URL == "https://erp.local/hook"
+	})).Return(&models.WebhookSubscription{ID: 1, Secret: "generated"}, nil)
+
+	res, err := svc.CreateSubscription(models.WebhookSubscriptionRequest{
+		URL:    " https://erp.local/hook ",
+		Events: []string{models.WebhookEventOrderCreated},
+	}, "admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "generated", res.Secret)
+}
+
+func TestCreateSubscription_Invalid(t *testing.T) {
+	svc := newService(new(WebhooksRepoMock))
+
+	for _, req := range []models.WebhookSubscriptionRequest{
+		{URL: "ftp://erp.local", Events: []string{models.WebhookEventOrderCreated}},
+		{URL: "https://erp.local"},
+		{URL: "https://erp.local", Events: []string{"order.exploded"}},
+	} {
+		_, err := svc.CreateSubscription(req, "admin@example.com")
+		assert.True(t, errors.Is(err, ErrInvalidSubscription))
+	}
+}
+
+func TestGetSubscription_HidesSecret(t *testing.T) {
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("GetSubscriptionByID", uint(1)).Return(&models.WebhookSubscription{ID: 1, Secret: "shh"}, nil)
+	res, err := svc.GetSubscription(1)
+	require.NoError(t, err)
+	assert.Empty(t, res.Secret)
+
+	repoMock.On("GetSubscriptionByID", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+	_, err = svc.GetSubscription(2)
+	assert.Equal(t, ErrSubscriptionNotFound, err)
+}
+
+func TestRetryDelivery(t *testing.T) {
+	repoMock := new(WebhooksRepoMock)
+	svc := newService(repoMock)
+
+	repoMock.On("GetDeliveryByID", uint(5)).Return(&models.WebhookDelivery{ID: 5, Status: models.WebhookDeliveryDead, Attempts: MaxAttempts}, nil)
+	repoMock.On("UpdateDelivery", mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.Status == models.WebhookDeliveryPending && d.Attempts == 0 && d.NextAttemptAt.Equal(fixedNow)
+	})).Return(nil)
+
+	_, err := svc.RetryDelivery(5)
+	assert.NoError(t, err)
+
+	repoMock.On("GetDeliveryByID", uint(6)).Return(&models.WebhookDelivery{ID: 6, Status: models.WebhookDeliverySucceeded}, nil)
+	_, err = svc.RetryDelivery(6)
+	assert.Equal(t, ErrDeliveryNotDead, err)
+}
+
+func TestListDeliveries_InvalidStatus(t *testing.T) {
+	svc := newService(new(WebhooksRepoMock))
+
+	_, err := svc.ListDeliveries(models.WebhookDeliveryFilter{Status: "lost"})
+	assert.Equal(t, ErrInvalidFilter, err)
+}
@@ -0,0 +1,97 @@
+package services_webhook
+
+import (
+	"pruebaVertice/Api/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// WebhooksRepoMock mocks repo.WebhooksRepository for service tests.
+type WebhooksRepoMock struct {
+	mock.Mock
+}
+
+func (m *WebhooksRepoMock) CreateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(subscription)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhooksRepoMock) GetSubscriptionByID(id uint) (*models.WebhookSubscription, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhooksRepoMock) GetSubscriptions() ([]models.WebhookSubscription, error) {
+	args := m.Called()
+	if res := args.Get(0); res != nil {
+		return res.([]models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhooksRepoMock) GetActiveSubscriptions() ([]models.WebhookSubscription, error) {
+	args := m.Called()
+	if res := args.Get(0); res != nil {
+		return res.([]models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhooksRepoMock) UpdateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(subscription)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookSubscription), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhooksRepoMock) DeleteSubscription(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *WebhooksRepoMock) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	args := m.Called(deliveries)
+	return args.Error(0)
+}
+
+func (m *WebhooksRepoMock) GetDeliveryByID(id uint) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WebhookDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhooksRepoMock) GetDeliveries(filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	args := m.Called(filter)
+	if res := args.Get(0); res != nil {
+		return res.([]models.WebhookDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhooksRepoMock) GetDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(now, limit)
+	if res := args.Get(0); res != nil {
+		return res.([]models.WebhookDelivery), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhooksRepoMock) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *WebhooksRepoMock) RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error {
+	args := m.Called(delivery, attempt)
+	return args.Error(0)
+}
@@ -0,0 +1,120 @@
+package services_wishlist
+
+import (
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// WishlistRepoMock mocks repo.WishlistRepository for service tests.
+type WishlistRepoMock struct {
+	mock.Mock
+}
+
+func (m *WishlistRepoMock) AddItem(userID, productID uint) (*models.WishlistItem, error) {
+	args := m.Called(userID, productID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.WishlistItem), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WishlistRepoMock) RemoveItem(userID, productID uint) error {
+	return m.Called(userID, productID).Error(0)
+}
+
+func (m *WishlistRepoMock) GetItems(userID uint) ([]models.WishlistItem, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.WishlistItem), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WishlistRepoMock) CreateStockAlert(userID, productID uint) (*models.StockAlert, error) {
+	args := m.Called(userID, productID)
+	if res := args.Get(0); res != nil {
+		return res.(*models.StockAlert), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WishlistRepoMock) DeleteStockAlert(userID, productID uint) error {
+	return m.Called(userID, productID).Error(0)
+}
+
+func (m *WishlistRepoMock) GetUserStockAlerts(userID uint) ([]models.StockAlert, error) {
+	args := m.Called(userID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.StockAlert), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WishlistRepoMock) GetPendingStockAlerts(productID uint) ([]models.StockAlert, error) {
+	args := m.Called(productID)
+	if res := args.Get(0); res != nil {
+		return res.([]models.StockAlert), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WishlistRepoMock) MarkStockAlertNotified(id uint, at time.Time) error {
+	return m.Called(id, at).Error(0)
+}
+
+// ProductsRepoMock mocks the products repository for service tests.
+type ProductsRepoMock struct {
+	mock.Mock
+}
+
+func (m *ProductsRepoMock) GetAllProducts(sort models.ProductSort) ([]models.Product, error) {
+	args := m.Called(sort)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) CreateProduct(product *models.Product, createdBy string) (*models.Product, error) {
+	args := m.Called(product, createdBy)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) CreateProducts(products []models.Product) ([]models.Product, error) {
+	args := m.Called(products)
+	if res := args.Get(0); res != nil {
+		return res.([]models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) GetProductByID(id uint) (*models.Product, error) {
+	args := m.Called(id)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) UpdateProduct(product *models.Product) error {
+	return m.Called(product).Error(0)
+}
+
+func (m *ProductsRepoMock) RestockProduct(id uint, quantity int) (*models.Product, error) {
+	args := m.Called(id, quantity)
+	if res := args.Get(0); res != nil {
+		return res.(*models.Product), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductsRepoMock) SaveTranslation(translation *models.ProductTranslation) error {
+	args := m.Called(translation)
+	return args.Error(0)
+}
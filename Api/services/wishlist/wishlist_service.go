@@ -0,0 +1,193 @@
+package services_wishlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pruebaVertice/Api/models"
+	products_repo "pruebaVertice/Api/repo/products_repo"
+	repo "pruebaVertice/Api/repo/wishlist_repo"
+	services_notifier "pruebaVertice/Api/services/notifier"
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound      = apperr.NotFound("product_not_found", "product not found")
+	ErrWishlistItemNotFound = apperr.NotFound("wishlist_item_not_found", "product is not in the wishlist")
+	ErrStockAlertNotFound   = apperr.NotFound("stock_alert_not_found", "stock alert not found")
+	ErrProductInStock       = apperr.Conflict("product_in_stock", "product is in stock")
+)
+
+type WishlistService interface {
+	GetWishlist(userID uint) ([]models.WishlistItem, error)
+	AddToWishlist(userID, productID uint) (*models.WishlistItem, error)
+	RemoveFromWishlist(userID, productID uint) error
+	SubscribeStockAlert(userID, productID uint) (*models.StockAlert, error)
+	UnsubscribeStockAlert(userID, productID uint) error
+	GetStockAlerts(userID uint) ([]models.StockAlert, error)
+}
+
+type wishlistService struct {
+	repo     repo.WishlistRepository
+	products products_repo.ProductsRepository
+	notifier services_notifier.Notifier
+	logger   *logrus.Logger
+	now      func() time.Time
+}
+
+func NewWishlistService(repo repo.WishlistRepository, products products_repo.ProductsRepository, notifier services_notifier.Notifier, logger *logrus.Logger) *wishlistService {
+	return &wishlistService{repo: repo, products: products, notifier: notifier, logger: logger, now: time.Now}
+}
+
+func (s *wishlistService) GetWishlist(userID uint) ([]models.WishlistItem, error) {
+	items, err := s.repo.GetItems(userID)
+	if err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: GetWishlist, Error:", err)
+		return nil, err
+	}
+	if items == nil {
+		items = []models.WishlistItem{}
+	}
+	return items, nil
+}
+
+func (s *wishlistService) AddToWishlist(userID, productID uint) (*models.WishlistItem, error) {
+	if _, err := s.product(productID); err != nil {
+		return nil, err
+	}
+	item, err := s.repo.AddItem(userID, productID)
+	if err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: AddToWishlist, Error:", err)
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *wishlistService) RemoveFromWishlist(userID, productID uint) error {
+	err := s.repo.RemoveItem(userID, productID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWishlistItemNotFound
+	}
+	if err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: RemoveFromWishlist, Error:", err)
+		return err
+	}
+	return nil
+}
+
+// SubscribeStockAlert asks for a notification when an out of stock product
+// is restocked. Products that are available can be ordered right away.
+func (s *wishlistService) SubscribeStockAlert(userID, productID uint) (*models.StockAlert, error) {
+	product, err := s.product(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Stock > 0 {
+		return nil, ErrProductInStock
+	}
+	alert, err := s.repo.CreateStockAlert(userID, productID)
+	if err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: SubscribeStockAlert, Error:", err)
+		return nil, err
+	}
+	return alert, nil
+}
+
+func (s *wishlistService) UnsubscribeStockAlert(userID, productID uint) error {
+	err := s.repo.DeleteStockAlert(userID, productID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrStockAlertNotFound
+	}
+	if err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: UnsubscribeStockAlert, Error:", err)
+		return err
+	}
+	return nil
+}
+
+func (s *wishlistService) GetStockAlerts(userID uint) ([]models.StockAlert, error) {
+	alerts, err := s.repo.GetUserStockAlerts(userID)
+	if err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: GetStockAlerts, Error:", err)
+		return nil, err
+	}
+	if alerts == nil {
+		alerts = []models.StockAlert{}
+	}
+	return alerts, nil
+}
+
+func (s *wishlistService) product(id uint) (*models.Product, error) {
+	product, err := s.products.GetProductByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: product, Error:", err)
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *wishlistService) Name() string {
+	return "back_in_stock"
+}
+
+// Handle notifies the subscribers of a product when a stock change takes it
+// from zero to available. Each alert is marked as soon as it is sent, so a
+// retried event only reaches the subscribers that were missed.
+func (s *wishlistService) Handle(event models.OutboxEvent) error {
+	if event.Type != models.EventStockChanged {
+		return nil
+	}
+	var change models.StockChange
+	if err := json.Unmarshal([]byte(event.Payload), &change); err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: Handle, Error:", err)
+		return err
+	}
+	if change.PreviousStock > 0 || change.Stock <= 0 {
+		return nil
+	}
+
+	alerts, err := s.repo.GetPendingStockAlerts(change.ProductID)
+	if err != nil {
+		s.logger.Errorln("Layer: wishlist_service, Method: Handle, Error:", err)
+		return err
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+	product, err := s.product(change.ProductID)
+	if errors.Is(err, ErrProductNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, alert := range alerts {
+		err := s.notifier.Notify(services_notifier.Notification{
+			To:      alert.User.Email,
+			Subject: fmt.Sprintf("%s vuelve a estar disponible", product.Name),
+			Body:    fmt.Sprintf("Hola %s,\n\n%s vuelve a estar disponible. Hay %d unidades en stock.\n", alert.User.Username, product.Name, change.Stock),
+		})
+		if err == nil {
+			err = s.repo.MarkStockAlertNotified(alert.ID, s.now())
+		}
+		if err != nil {
+			s.logger.Errorln("Layer: wishlist_service, Method: Handle, Error:", err)
+			failed = append(failed, fmt.Sprintf("alert %d: %v", alert.ID, err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
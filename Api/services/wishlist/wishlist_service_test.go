@@ -0,0 +1,129 @@
+package services_wishlist
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+	services_notifier "pruebaVertice/Api/services/notifier"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTestService() (*wishlistService, *WishlistRepoMock, *ProductsRepoMock, *services_notifier.MemoryNotifier) {
+	repoMock := new(WishlistRepoMock)
+	productsMock := new(ProductsRepoMock)
+	notifier := services_notifier.NewMemoryNotifier()
+	svc := NewWishlistService(repoMock, productsMock, notifier, logrus.New())
+	svc.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return svc, repoMock, productsMock, notifier
+}
+
+func stockEvent(previous, stock int) models.OutboxEvent {
+	event, err := models.NewOutboxEvent(models.EventStockChanged, models.AggregateProduct, 5, models.StockChange{
+		ProductID: 5, PreviousStock: previous, Stock: stock, Reason: models.StockReasonRestock,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return event
+}
+
+func TestAddToWishlist(t *testing.T) {
+	svc, repoMock, productsMock, _ := newTestService()
+	productsMock.On("GetProductByID", uint(5)).Return(&models.Product{Name: "Teclado"}, nil)
+	repoMock.On("AddItem", uint(1), uint(5)).Return(&models.WishlistItem{ID: 3, UserID: 1, ProductID: 5}, nil)
+
+	item, err := svc.AddToWishlist(1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), item.ID)
+}
+
+func TestAddToWishlist_ProductNotFound(t *testing.T) {
+	svc, repoMock, productsMock, _ := newTestService()
+	productsMock.On("GetProductByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.AddToWishlist(1, 9)
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	repoMock.AssertNotCalled(t, "AddItem", mock.Anything, mock.Anything)
+}
+
+func TestRemoveFromWishlist_NotFound(t *testing.T) {
+	svc, repoMock, _, _ := newTestService()
+	repoMock.On("RemoveItem", uint(1), uint(5)).Return(gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, svc.RemoveFromWishlist(1, 5), ErrWishlistItemNotFound)
+}
+
+func TestSubscribeStockAlert(t *testing.T) {
+	svc, repoMock, productsMock, _ := newTestService()
+	productsMock.On("GetProductByID", uint(5)).Return(&models.Product{Name: "Teclado", Stock: 0}, nil)
+	repoMock.On("CreateStockAlert", uint(1), uint(5)).Return(&models.StockAlert{ID: 2, UserID: 1, ProductID: 5}, nil)
+
+	alert, err := svc.SubscribeStockAlert(1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), alert.ID)
+}
+
+func TestSubscribeStockAlert_InStock(t *testing.T) {
+	svc, repoMock, productsMock, _ := newTestService()
+	productsMock.On("GetProductByID", uint(5)).Return(&models.Product{Name: "Teclado", Stock: 3}, nil)
+
+	_, err := svc.SubscribeStockAlert(1, 5)
+	assert.ErrorIs(t, err, ErrProductInStock)
+	repoMock.AssertNotCalled(t, "CreateStockAlert", mock.Anything, mock.Anything)
+}
+
+func TestUnsubscribeStockAlert_NotFound(t *testing.T) {
+	svc, repoMock, _, _ := newTestService()
+	repoMock.On("DeleteStockAlert", uint(1), uint(5)).Return(gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, svc.UnsubscribeStockAlert(1, 5), ErrStockAlertNotFound)
+}
+
+func TestHandle_NotifiesSubscribersOnRestock(t *testing.T) {
+	svc, repoMock, productsMock, notifier := newTestService()
+	repoMock.On("GetPendingStockAlerts", uint(5)).Return([]models.StockAlert{
+		{ID: 1, User: models.User{Username: "ana", Email: "ana@example.com"}},
+		{ID: 2, User: models.User{Username: "luis", Email: "luis@example.com"}},
+	}, nil)
+	productsMock.On("GetProductByID", uint(5)).Return(&models.Product{Name: "Teclado", Stock: 4}, nil)
+	repoMock.On("MarkStockAlertNotified", uint(1), svc.now()).Return(nil)
+	repoMock.On("MarkStockAlertNotified", uint(2), svc.now()).Return(nil)
+
+	require.NoError(t, svc.Handle(stockEvent(0, 4)))
+
+	sent := notifier.Sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "ana@example.com", sent[0].To)
+	assert.Equal(t, "Teclado vuelve a estar disponible", sent[0].Subject)
+	assert.Contains(t, sent[1].Body, "Hola luis")
+	repoMock.AssertExpectations(t)
+}
+
+func TestHandle_IgnoresOtherChanges(t *testing.T) {
+	svc, repoMock, _, notifier := newTestService()
+
+	assert.NoError(t, svc.Handle(stockEvent(2, 5)))
+	assert.NoError(t, svc.Handle(stockEvent(3, 0)))
+	assert.NoError(t, svc.Handle(models.OutboxEvent{Type: models.EventOrderPlaced, Payload: "{}"}))
+
+	assert.Empty(t, notifier.Sent())
+	repoMock.AssertNotCalled(t, "GetPendingStockAlerts", mock.Anything)
+}
+
+func TestHandle_FailedNotificationIsRetried(t *testing.T) {
+	svc, repoMock, productsMock, notifier := newTestService()
+	notifier.Err = errors.New("smtp down")
+	repoMock.On("GetPendingStockAlerts", uint(5)).Return([]models.StockAlert{{ID: 1, User: models.User{Email: "ana@example.com"}}}, nil)
+	productsMock.On("GetProductByID", uint(5)).Return(&models.Product{Name: "Teclado", Stock: 4}, nil)
+
+	err := svc.Handle(stockEvent(0, 4))
+	assert.ErrorContains(t, err, "alert 1: smtp down")
+	repoMock.AssertNotCalled(t, "MarkStockAlertNotified", mock.Anything, mock.Anything)
+}
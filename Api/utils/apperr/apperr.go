@@ -0,0 +1,114 @@
+// Package apperr defines the typed errors returned by services and repos.
+//
+// Every error has a Kind, which decides the HTTP status it is answered with,
+// and a stable Code that clients can match on instead of parsing messages.
+// The message is written for clients and must never carry driver or database
+// details. Errors are declared once as package-level values so callers keep
+// using errors.Is, also when the value is wrapped with fmt.Errorf("%w: ...").
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	// KindInternal is the zero Kind: anything not classified is a server fault.
+	KindInternal Kind = iota
+	// KindBadRequest is a malformed request, e.g. an unparsable filter.
+	KindBadRequest
+	// KindInvalid is a well-formed request that breaks a business rule.
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	// KindConflict is a request that clashes with the current state of a
+	// resource, e.g. a duplicate or an order with too little stock.
+	KindConflict
+	KindRateLimited
+)
+
+// Error is a classified error that is safe to show to clients.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Detailf returns e with a more specific message, e.g. naming the record
+// involved; errors.Is(err, e) still holds. format is kept so the message
+// can be translated, so it should be a constant.
+func (e *Error) Detailf(format string, args ...interface{}) error {
+	return &detailed{err: e, format: format, args: args}
+}
+
+type detailed struct {
+	err    *Error
+	format string
+	args   []interface{}
+}
+
+func (d *detailed) Error() string { return fmt.Sprintf(d.format, d.args...) }
+
+func (d *detailed) Unwrap() error { return d.err }
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func BadRequest(code, message string) *Error {
+	return New(KindBadRequest, code, message)
+}
+
+func Invalid(code, message string) *Error {
+	return New(KindInvalid, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func RateLimited(code, message string) *Error {
+	return New(KindRateLimited, code, message)
+}
+
+// Template returns the format and arguments err's message was built from,
+// when err is an *Error or the result of Detailf and was not wrapped with
+// more text. Translations are keyed by the format.
+func Template(err error) (format string, args []interface{}, ok bool) {
+	var d *detailed
+	if errors.As(err, &d) && err.Error() == d.Error() {
+		return d.format, d.args, true
+	}
+	if appErr, isAppErr := As(err); isAppErr && err.Error() == appErr.Message {
+		return appErr.Message, nil, true
+	}
+	return "", nil, false
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,189 @@
+package i18n
+
+// spanish translates the English messages and formats of the API. Keys must
+// match the text in the code exactly, including the verbs of formats.
+var spanish = map[string]string{
+	// Problem titles
+	"Bad Request":           "Solicitud incorrecta",
+	"Unauthorized":          "No autorizado",
+	"Forbidden":             "Prohibido",
+	"Not Found":             "No encontrado",
+	"Conflict":              "Conflicto",
+	"Unprocessable Entity":  "Entidad no procesable",
+	"Too Many Requests":     "Demasiadas solicitudes",
+	"Internal Server Error": "Error interno del servidor",
+
+	// Generic problems
+	"The requested resource was not found.": "No se encontró el recurso solicitado.",
+	"An unexpected error occurred.":         "Ocurrió un error inesperado.",
+	"The request has invalid fields.":       "La solicitud tiene campos inválidos.",
+	"No route matches the request":          "Ninguna ruta coincide con la solicitud",
+	"Invalid ID":                            "ID inválido",
+	"Invalid address ID":                    "ID de dirección inválido",
+	"Invalid change ID":                     "ID de cambio inválido",
+	"Invalid lockout ID":                    "ID de bloqueo inválido",
+	"Invalid order ID":                      "ID de orden inválido",
+	"Invalid product ID":                    "ID de producto inválido",
+	"Invalid review ID":                     "ID de reseña inválido",
+	"Invalid session ID":                    "ID de sesión inválido",
+	"Invalid shipment ID":                   "ID de envío inválido",
+	"Invalid user ID":                       "ID de usuario inválido",
+
+	// Field validation
+	"is required":                   "es obligatorio",
+	"must not be blank":             "no debe estar vacío",
+	"must be a valid email address": "debe ser un correo electrónico válido",
+	"must be at least %d characters and contain upper and lower case letters and a digit": "debe tener al menos %d caracteres y contener mayúsculas, minúsculas y un dígito",
+	"must not contain the same product_id more than once":                                 "no debe repetir el mismo product_id",
+	"must be at least %s characters":                                                      "debe tener al menos %s caracteres",
+	"must be at least %s items":                                                           "debe tener al menos %s elementos",
+	"must be at least %s":                                                                 "debe ser como mínimo %s",
+	"must be at most %s characters":                                                       "debe tener como máximo %s caracteres",
+	"must be at most %s items":                                                            "debe tener como máximo %s elementos",
+	"must be at most %s":                                                                  "debe ser como máximo %s",
+	"must be exactly %s characters":                                                       "debe tener exactamente %s caracteres",
+	"must be exactly %s items":                                                            "debe tener exactamente %s elementos",
+	"must be exactly %s":                                                                  "debe ser exactamente %s",
+	"must be greater than %s":                                                             "debe ser mayor que %s",
+	"must be greater than or equal to %s":                                                 "debe ser mayor o igual que %s",
+	"must be one of: %s":                                                                  "debe ser uno de: %s",
+	"must be a valid URL":                                                                 "debe ser una URL válida",
+	"failed the %s rule":                                                                  "no cumple la regla %s",
+	"must be of type %s":                                                                  "debe ser de tipo %s",
+	"request body is not valid JSON":                                                      "el cuerpo de la solicitud no es un JSON válido",
+	"request body is required":                                                            "el cuerpo de la solicitud es obligatorio",
+
+	// Authentication and authorization
+	"Authorization header is required":                        "El encabezado Authorization es obligatorio",
+	"Invalid authorization header format":                     "Formato del encabezado Authorization inválido",
+	"Invalid or expired token":                                "Token inválido o expirado",
+	"Session has been revoked, please log in again":           "La sesión fue revocada, vuelva a iniciar sesión",
+	"Account disabled":                                        "Cuenta deshabilitada",
+	"Email address not verified":                              "Correo electrónico no verificado",
+	"This route is not available to API credentials":          "Esta ruta no está disponible para credenciales de API",
+	"Missing scope":                                           "Falta el alcance requerido",
+	"Missing scope %s":                                        "Falta el alcance %s",
+	"invalid or expired challenge token":                      "token de desafío inválido o expirado",
+	"invalid or expired client token":                         "token de cliente inválido o expirado",
+	"invalid password":                                        "contraseña incorrecta",
+	"account disabled":                                        "cuenta deshabilitada",
+	"username or email already in use":                        "el nombre de usuario o el correo ya están en uso",
+	"username or email is required":                           "el nombre de usuario o el correo es obligatorio",
+	"user not found":                                          "usuario no encontrado",
+	"current password is incorrect":                           "la contraseña actual es incorrecta",
+	"this action is not allowed while impersonating a user":   "esta acción no está permitida mientras se suplanta a un usuario",
+	"session not found":                                       "sesión no encontrada",
+	"session revoked or expired":                              "sesión revocada o expirada",
+	"invalid or expired password reset token":                 "token de restablecimiento de contraseña inválido o expirado",
+	"password reset token already used":                       "el token de restablecimiento de contraseña ya fue usado",
+	"invalid or expired verification link":                    "enlace de verificación inválido o expirado",
+	"email already verified":                                  "el correo electrónico ya está verificado",
+	"a verification email was sent recently, try again later": "se envió un correo de verificación recientemente, inténtelo más tarde",
+	"too many failed login attempts, try again later":         "demasiados intentos fallidos de inicio de sesión, inténtelo más tarde",
+	"lockout not found":                                       "bloqueo no encontrado",
+	"lockout not found: %d":                                   "bloqueo no encontrado: %d",
+	"Invalid active filter":                                   "Filtro active inválido",
+	"two-factor authentication required":                      "se requiere autenticación de dos factores",
+	"two-factor authentication already enabled":               "la autenticación de dos factores ya está habilitada",
+	"two-factor authentication not enabled":                   "la autenticación de dos factores no está habilitada",
+	"two-factor enrollment not started":                       "no se inició la configuración de dos factores",
+	"invalid two-factor code":                                 "código de dos factores inválido",
+
+	// Admin
+	"invalid user filter":                                     "filtro de usuarios inválido",
+	"invalid user filter: unknown role %q":                    "filtro de usuarios inválido: rol desconocido %q",
+	"invalid user filter: status must be %q or %q":            "filtro de usuarios inválido: el estado debe ser %q o %q",
+	"admins cannot disable, demote or impersonate themselves": "los administradores no pueden deshabilitarse, degradarse ni suplantarse a sí mismos",
+	"admin accounts cannot be impersonated":                   "las cuentas de administrador no se pueden suplantar",
+	"disabled accounts cannot be impersonated":                "las cuentas deshabilitadas no se pueden suplantar",
+	"an erasure of this user is already pending":              "ya hay un borrado pendiente de este usuario",
+
+	// API keys and OAuth clients
+	"api key not found":              "clave de API no encontrada",
+	"oauth client not found":         "cliente OAuth no encontrado",
+	"invalid scope":                  "alcance inválido",
+	"invalid scope: %s":              "alcance inválido: %s",
+	"invalid scope: %q":              "alcance inválido: %q",
+	"invalid client credentials":     "credenciales de cliente inválidas",
+	"invalid or expired credentials": "credenciales inválidas o expiradas",
+	"unsupported grant type":         "tipo de concesión no soportado",
+
+	// Products and prices
+	"product not found":                                            "producto no encontrado",
+	"product with ID %d not found":                                 "no se encontró el producto con ID %d",
+	"invalid restock quantity":                                     "cantidad de reposición inválida",
+	"invalid restock quantity: quantity must be greater than zero": "cantidad de reposición inválida: la cantidad debe ser mayor que cero",
+	"invalid product sort":                                         "orden de productos inválido",
+	"invalid product sort: sort_dir requires sort_by":              "orden de productos inválido: sort_dir requiere sort_by",
+	"invalid product sort: cannot sort by %q":                      "orden de productos inválido: no se puede ordenar por %q",
+	"invalid product sort: sort_dir must be 'asc' or 'desc'":       "orden de productos inválido: sort_dir debe ser 'asc' o 'desc'",
+	"unsupported language":                                         "idioma no soportado",
+	"unsupported language: %q":                                     "idioma no soportado: %q",
+	"price must be greater than zero":                              "el precio debe ser mayor que cero",
+	"kind must be 'regular' or 'sale'":                             "kind debe ser 'regular' o 'sale'",
+	"ends_at must be after starts_at":                              "ends_at debe ser posterior a starts_at",
+	"a sale requires ends_at":                                      "una oferta requiere ends_at",
+	"scheduled price change not found":                             "cambio de precio programado no encontrado",
+	"scheduled price change can no longer be cancelled":            "el cambio de precio programado ya no se puede cancelar",
+
+	// Orders, shipping and invoices
+	"order not found":                                                          "orden no encontrada",
+	"insufficient stock":                                                       "stock insuficiente",
+	"insufficient stock for product ID %d":                                     "stock insuficiente para el producto con ID %d",
+	"order can no longer be cancelled":                                         "la orden ya no se puede cancelar",
+	"invalid order filter":                                                     "filtro de órdenes inválido",
+	"invalid order filter: unknown status %q":                                  "filtro de órdenes inválido: estado desconocido %q",
+	"invalid order filter: cannot sort by %q":                                  "filtro de órdenes inválido: no se puede ordenar por %q",
+	"invalid order filter: sort_dir must be 'asc' or 'desc'":                   "filtro de órdenes inválido: sort_dir debe ser 'asc' o 'desc'",
+	"invalid order filter: from must be before to":                             "filtro de órdenes inválido: from debe ser anterior a to",
+	"invalid order filter: min_total must not exceed max_total":                "filtro de órdenes inválido: min_total no debe superar a max_total",
+	"a shipping address is required: send address_id or set a default address": "se requiere una dirección de envío: envíe address_id o defina una dirección por defecto",
+	"shipping address not found":                                               "dirección de envío no encontrada",
+	"unknown shipping method":                                                  "método de envío desconocido",
+	"unknown shipping method: %q":                                              "método de envío desconocido: %q",
+	"cancelled orders cannot be invoiced":                                      "las órdenes canceladas no se pueden facturar",
+	"Invalid format, use pdf or html":                                          "Formato inválido, use pdf o html",
+	"order cannot be shipped in its current status":                            "la orden no se puede enviar en su estado actual",
+	"all order lines have already been shipped":                                "todas las líneas de la orden ya fueron enviadas",
+	"shipment not found":                                                       "envío no encontrado",
+	"shipment has already been delivered":                                      "el envío ya fue entregado",
+	"invalid shipment":                                                         "envío inválido",
+	"invalid shipment: carrier and tracking_number are required":               "envío inválido: carrier y tracking_number son obligatorios",
+	"invalid shipment: order line %d does not belong to order %d":              "envío inválido: la línea %d no pertenece a la orden %d",
+	"invalid shipment: quantity for order line %d must be between 1 and %d":    "envío inválido: la cantidad de la línea %d debe estar entre 1 y %d",
+	"invalid shipment status":                                                  "estado de envío inválido",
+
+	// Addresses
+	"address not found": "dirección no encontrada",
+	"recipient, line1, city, postal_code and country are required": "recipient, line1, city, postal_code y country son obligatorios",
+
+	// Reviews
+	"review not found": "reseña no encontrada",
+	"only customers who bought the product can review it":            "solo los clientes que compraron el producto pueden reseñarlo",
+	"product already reviewed":                                       "el producto ya fue reseñado",
+	"invalid review":                                                 "reseña inválida",
+	"invalid review: rating must be between 1 and 5":                 "reseña inválida: la calificación debe estar entre 1 y 5",
+	"invalid review: title must be at most %d characters":            "reseña inválida: el título debe tener como máximo %d caracteres",
+	"invalid review status":                                          "estado de reseña inválido",
+	"invalid review status: %q":                                      "estado de reseña inválido: %q",
+	"invalid review status: status must be 'approved' or 'rejected'": "estado de reseña inválido: el estado debe ser 'approved' o 'rejected'",
+
+	// Wishlist
+	"product is not in the wishlist": "el producto no está en la lista de deseos",
+	"stock alert not found":          "alerta de stock no encontrada",
+	"product is in stock":            "el producto tiene stock",
+
+	// Reports, webhooks and realtime
+	"invalid report filter":                                                   "filtro de reporte inválido",
+	"Invalid format, use json or csv":                                         "Formato inválido, use json o csv",
+	"webhook subscription not found":                                          "suscripción de webhook no encontrada",
+	"invalid webhook subscription":                                            "suscripción de webhook inválida",
+	"invalid webhook subscription: url must be an absolute http or https URL": "suscripción de webhook inválida: url debe ser una URL http o https absoluta",
+	"invalid webhook subscription: at least one event is required":            "suscripción de webhook inválida: se requiere al menos un evento",
+	"invalid webhook subscription: unknown event %q":                          "suscripción de webhook inválida: evento desconocido %q",
+	"webhook delivery not found":                                              "entrega de webhook no encontrada",
+	"only dead deliveries can be retried":                                     "solo se pueden reintentar las entregas fallidas definitivamente",
+	"invalid delivery filter":                                                 "filtro de entregas inválido",
+	"products must be a comma separated list of IDs":                          "products debe ser una lista de IDs separados por comas",
+	"Invalid Last-Event-ID":                                                   "Last-Event-ID inválido",
+}
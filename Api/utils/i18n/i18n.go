@@ -0,0 +1,116 @@
+// Package i18n picks the language of each response from the Accept-Language
+// header and translates client-facing messages into it.
+//
+// Messages are written in English in the code. The English text, or the
+// format string of a message with arguments, is the key of the catalog of
+// every other language, as in gettext, so a message without a translation is
+// simply served in English.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	English = "en"
+	Spanish = "es"
+	// Default is served when the client accepts none of the supported
+	// languages or sends no Accept-Language header.
+	Default = English
+
+	languageKey = "language"
+)
+
+// Supported lists the languages responses can be served in.
+var Supported = []string{English, Spanish}
+
+var catalogs = map[string]map[string]string{
+	Spanish: spanish,
+}
+
+// Supports reports whether lang is one of the supported languages.
+func Supports(lang string) bool {
+	for _, supported := range Supported {
+		if lang == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate returns the supported language the client prefers according to
+// an Accept-Language header. Regional tags match their language, so es-AR
+// is served in Spanish.
+func Negotiate(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if lang == "*" {
+			lang = Default
+		}
+		if Supports(lang) {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// Middleware negotiates the language of the request and announces it in
+// the Content-Language header of the response.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(languageKey, lang)
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// Language returns the language of the request, negotiating it when
+// Middleware did not run.
+func Language(c *gin.Context) string {
+	if lang := c.GetString(languageKey); lang != "" {
+		return lang
+	}
+	if c.Request == nil {
+		return Default
+	}
+	return Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// T translates message into lang and formats it with args, if any.
+func T(lang, message string, args ...interface{}) string {
+	if translated, ok := catalogs[lang][message]; ok {
+		message = translated
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
@@ -0,0 +1,84 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                          English,
+		"es":                        Spanish,
+		"es-AR":                     Spanish,
+		"ES-mx, en;q=0.8":           Spanish,
+		"en-US,en;q=0.9,es;q=0.8":   English,
+		"fr-FR, es;q=0.5, en;q=0.4": Spanish,
+		"en;q=0.2, es;q=0.9":        Spanish,
+		"es;q=0, en":                English,
+		"fr, de":                    Default,
+		"*":                         Default,
+		"es;q=abc":                  Default,
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, Negotiate(header), header)
+	}
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "orden no encontrada", T(Spanish, "order not found"))
+	assert.Equal(t, "stock insuficiente para el producto con ID 7", T(Spanish, "insufficient stock for product ID %d", 7))
+	assert.Equal(t, "insufficient stock for product ID 7", T(English, "insufficient stock for product ID %d", 7))
+	// Untranslated messages are served in English.
+	assert.Equal(t, "brand new message", T(Spanish, "brand new message"))
+	// Messages without arguments are not formatted.
+	assert.Equal(t, "100% cotton", T(Spanish, "100% cotton"))
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/", func(c *gin.Context) { c.String(http.StatusOK, Language(c)) })
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "es-ES,es;q=0.9")
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, Spanish, rec.Body.String())
+	assert.Equal(t, Spanish, rec.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+}
+
+func TestLanguage_WithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, Default, Language(c))
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Accept-Language", "es")
+	assert.Equal(t, Spanish, Language(c))
+}
+
+// Every format in the catalog must keep the verbs of its English key, or
+// the translated message would be garbled.
+func TestSpanishCatalog_KeepsVerbs(t *testing.T) {
+	for key, translated := range spanish {
+		assert.Equal(t, verbs(key), verbs(translated), key)
+	}
+}
+
+func verbs(format string) []string {
+	var found []string
+	for i := 0; i < len(format)-1; i++ {
+		if format[i] == '%' {
+			found = append(found, format[i:i+2])
+			i++
+		}
+	}
+	return found
+}
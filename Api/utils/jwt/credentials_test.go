@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubCredentials struct{}
+
+func (stubCredentials) AuthenticateAPIKey(key string) (*Principal, error) {
+	if key != "vk_abcd1234_secret" {
+		return nil, errors.New("unknown key")
+	}
+	return &Principal{Email: "robot@example.com", Scopes: []string{"products:read"}}, nil
+}
+
+func (stubCredentials) AuthenticateClientToken(token string) (*Principal, error) {
+	if token != "client-token" {
+		return nil, errors.New("bad token")
+	}
+	return &Principal{Email: "robot@example.com", Scopes: []string{"products:read", "products:write"}}, nil
+}
+
+var testRoutes = []RouteScope{
+	{Prefix: "/api/auth/products", Read: "products:read", Write: "products:write"},
+	{Prefix: "/api/auth/products/:id/reviews"},
+}
+
+func serveAuth(t *testing.T, method, path string, header http.Header) (int, string) {
+	t.Setenv("SECRET_KEY", "test-secret")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var email string
+	handler := func(c *gin.Context) {
+		email = c.GetString("userEmail")
+		c.Status(http.StatusOK)
+	}
+	group := router.Group("/api/auth", GinAuthMiddleware(JWTGenerator{}, stubCredentials{}, testRoutes, logrus.New()))
+	group.GET("/products", handler)
+	group.POST("/products", handler)
+	group.POST("/products/:id/reviews", handler)
+	group.GET("/me", handler)
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header = header
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code, email
+}
+
+func TestGinAuthMiddleware_UserTokenReachesEveryRoute(t *testing.T) {
+	t.Setenv("SECRET_KEY", "test-secret")
+	token, _, err := JWTGenerator{}.GenerateToken("ana@example.com", "session-1")
+	require.NoError(t, err)
+
+	code, email := serveAuth(t, http.MethodGet, "/api/auth/me", http.Header{"Authorization": {"Bearer " + token}})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ana@example.com", email)
+}
+
+func TestGinAuthMiddleware_ApiKeyScopes(t *testing.T) {
+	viaHeader := http.Header{"X-Api-Key": {"vk_abcd1234_secret"}}
+	viaBearer := http.Header{"Authorization": {"Bearer vk_abcd1234_secret"}}
+
+	code, email := serveAuth(t, http.MethodGet, "/api/auth/products", viaHeader)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "robot@example.com", email)
+
+	code, _ = serveAuth(t, http.MethodGet, "/api/auth/products", viaBearer)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = serveAuth(t, http.MethodPost, "/api/auth/products", viaHeader)
+	assert.Equal(t, http.StatusForbidden, code, "key lacks products:write")
+
+	code, _ = serveAuth(t, http.MethodGet, "/api/auth/me", viaHeader)
+	assert.Equal(t, http.StatusForbidden, code, "unlisted routes are user-only")
+
+	code, _ = serveAuth(t, http.MethodGet, "/api/auth/products", http.Header{"X-Api-Key": {"vk_abcd1234_wrong"}})
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestGinAuthMiddleware_ClientTokenAndCarveOut(t *testing.T) {
+	header := http.Header{"Authorization": {"Bearer client-token"}}
+
+	code, _ := serveAuth(t, http.MethodPost, "/api/auth/products", header)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = serveAuth(t, http.MethodPost, "/api/auth/products/5/reviews", header)
+	assert.Equal(t, http.StatusForbidden, code, "a longer prefix without scopes keeps the route user-only")
+
+	code, _ = serveAuth(t, http.MethodGet, "/api/auth/products", http.Header{"Authorization": {"Bearer garbage"}})
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = serveAuth(t, http.MethodGet, "/api/auth/products", http.Header{})
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
@@ -1,19 +1,42 @@
 package jwt
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"os"
 	"strconv"
 	"time"
 
+	"pruebaVertice/Api/utils/apperr"
+
 	"github.com/golang-jwt/jwt"
 )
 
 const defaultExpirationTimeToken = 3600
 
+const (
+	challengeAudience = "2fa-challenge"
+	challengeTTL      = 5 * time.Minute
+	clientAudience    = "oauth-client"
+)
+
+var (
+	ErrInvalidChallenge   = apperr.Unauthorized("invalid_challenge", "invalid or expired challenge token")
+	ErrInvalidClientToken = apperr.Unauthorized("invalid_client_token", "invalid or expired client token")
+)
+
+// clientClaims are carried by OAuth client-credentials access tokens.
+type clientClaims struct {
+	Scope string `json:"scope"`
+	jwt.StandardClaims
+}
+
 type JWTGenerator struct{}
 
-func (j JWTGenerator) GenerateToken(email string) (string, string, error) {
+// GenerateToken signs the access and refresh tokens of a session. The
+// session ID goes in the "jti" claim so the session can be revoked.
+func (j JWTGenerator) GenerateToken(email, sessionID string) (string, string, error) {
 	key := os.Getenv("SECRET_KEY")
 	secretKey := []byte(key)
 
@@ -23,21 +46,20 @@ func (j JWTGenerator) GenerateToken(email string) (string, string, error) {
 		expirationTimeDuration = defaultExpirationTimeToken
 	}
 
-	refreshExpirationTimeStr := os.Getenv("TIME_REFRESH_TOKEN")
-	refreshExpirationTimeDuration, err := strconv.Atoi(refreshExpirationTimeStr)
-	if err != nil {
-		refreshExpirationTimeDuration = defaultExpirationTimeToken
-	}
-
-	expirationTime := time.Now().Add(time.Duration(expirationTimeDuration) * time.Minute)
-	refreshExpirationTime := time.Now().Add(time.Duration(refreshExpirationTimeDuration) * time.Minute)
+	now := time.Now()
+	expirationTime := now.Add(time.Duration(expirationTimeDuration) * time.Minute)
+	refreshExpirationTime := now.Add(RefreshTokenTTL())
 
 	claims := &jwt.StandardClaims{
 		ExpiresAt: expirationTime.Unix(),
+		Id:        sessionID,
+		IssuedAt:  now.Unix(),
 		Subject:   email,
 	}
 	refreshClaims := &jwt.StandardClaims{
 		ExpiresAt: refreshExpirationTime.Unix(),
+		Id:        sessionID,
+		IssuedAt:  now.Unix(),
 		Subject:   email,
 	}
 
@@ -53,6 +75,16 @@ func (j JWTGenerator) GenerateToken(email string) (string, string, error) {
 	return token, refreshToken, nil
 }
 
+// RefreshTokenTTL reads TIME_REFRESH_TOKEN in minutes. A session lasts as
+// long as its refresh token.
+func RefreshTokenTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("TIME_REFRESH_TOKEN"))
+	if err != nil {
+		minutes = defaultExpirationTimeToken
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
 func (j JWTGenerator) ValidateToken(token string) (bool, error) {
 	key := os.Getenv("SECRET_KEY")
 	secretKey := []byte(key)
@@ -79,3 +111,73 @@ func (j JWTGenerator) ValidateToken(token string) (bool, error) {
 
 	return true, nil
 }
+
+// GenerateChallengeToken issues the short-lived token login returns when a
+// second factor is still required. It is signed with a key derived from
+// SECRET_KEY, so it is never accepted as an access token.
+func (j JWTGenerator) GenerateChallengeToken(email string) (string, error) {
+	now := time.Now()
+	claims := &jwt.StandardClaims{
+		Audience:  challengeAudience,
+		ExpiresAt: now.Add(challengeTTL).Unix(),
+		IssuedAt:  now.Unix(),
+		Subject:   email,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(derivedKey(challengeAudience))
+}
+
+// ParseChallengeToken returns the email and issue time of a valid challenge
+// token.
+func (j JWTGenerator) ParseChallengeToken(token string) (string, time.Time, error) {
+	claims := &jwt.StandardClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return derivedKey(challengeAudience), nil
+	})
+	if err != nil || !parsed.Valid || !claims.VerifyAudience(challengeAudience, true) || claims.Subject == "" {
+		return "", time.Time{}, ErrInvalidChallenge
+	}
+	return claims.Subject, time.Unix(claims.IssuedAt, 0), nil
+}
+
+// GenerateClientToken issues an OAuth client-credentials access token for
+// clientID. Like challenge tokens it has its own signing key, so it is never
+// mistaken for a user token.
+func (j JWTGenerator) GenerateClientToken(clientID, scope string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &clientClaims{
+		Scope: scope,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  clientAudience,
+			ExpiresAt: now.Add(ttl).Unix(),
+			IssuedAt:  now.Unix(),
+			Subject:   clientID,
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(derivedKey(clientAudience))
+}
+
+// ParseClientToken returns the client ID, granted scope and issue time of a
+// valid client token.
+func (j JWTGenerator) ParseClientToken(token string) (string, string, time.Time, error) {
+	claims := &clientClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return derivedKey(clientAudience), nil
+	})
+	if err != nil || !parsed.Valid || !claims.VerifyAudience(clientAudience, true) || claims.Subject == "" {
+		return "", "", time.Time{}, ErrInvalidClientToken
+	}
+	return claims.Subject, claims.Scope, time.Unix(claims.IssuedAt, 0), nil
+}
+
+// derivedKey gives each token type its own key derived from SECRET_KEY.
+func derivedKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SECRET_KEY")))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeToken_RoundTrip(t *testing.T) {
+	t.Setenv("SECRET_KEY", "test-secret")
+	gen := JWTGenerator{}
+
+	challenge, err := gen.GenerateChallengeToken("ana@example.com")
+	require.NoError(t, err)
+
+	email, issuedAt, err := gen.ParseChallengeToken(challenge)
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", email)
+	assert.False(t, issuedAt.IsZero())
+}
+
+func TestChallengeToken_NotInterchangeableWithAccessToken(t *testing.T) {
+	t.Setenv("SECRET_KEY", "test-secret")
+	gen := JWTGenerator{}
+
+	challenge, err := gen.GenerateChallengeToken("ana@example.com")
+	require.NoError(t, err)
+	valid, _ := gen.ValidateToken(challenge)
+	assert.False(t, valid)
+
+	access, _, err := gen.GenerateToken("ana@example.com", "session-1")
+	require.NoError(t, err)
+	_, _, err = gen.ParseChallengeToken(access)
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+}
+
+func TestClientToken_RoundTripAndIsolation(t *testing.T) {
+	t.Setenv("SECRET_KEY", "test-secret")
+	gen := JWTGenerator{}
+
+	token, err := gen.GenerateClientToken("vc_0123", "products:read orders:read", time.Hour)
+	require.NoError(t, err)
+
+	clientID, scope, issuedAt, err := gen.ParseClientToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "vc_0123", clientID)
+	assert.Equal(t, "products:read orders:read", scope)
+	assert.False(t, issuedAt.IsZero())
+
+	valid, _ := gen.ValidateToken(token)
+	assert.False(t, valid)
+	_, _, err = gen.ParseChallengeToken(token)
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+
+	expired, err := gen.GenerateClientToken("vc_0123", "products:read", -time.Minute)
+	require.NoError(t, err)
+	_, _, _, err = gen.ParseClientToken(expired)
+	assert.ErrorIs(t, err, ErrInvalidClientToken)
+}
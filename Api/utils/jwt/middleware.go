@@ -1,53 +1,106 @@
 package jwt
 
 import (
+	"errors"
 	"os"
 	"strings"
 
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/problem"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 	"github.com/sirupsen/logrus"
 )
 
+// Errors the middlewares of this package answer with.
+var (
+	ErrMissingAuthorization   = apperr.Unauthorized("authorization_required", "Authorization header is required")
+	ErrMalformedAuthorization = apperr.Unauthorized("invalid_authorization_header", "Invalid authorization header format")
+	ErrInvalidToken           = apperr.Unauthorized("invalid_token", "Invalid or expired token")
+	ErrSessionRevoked         = apperr.Unauthorized("session_revoked", "Session has been revoked, please log in again")
+	ErrForbidden              = apperr.Forbidden("forbidden", "Forbidden")
+	ErrAccountDisabled        = apperr.Forbidden("account_disabled", "Account disabled")
+	ErrEmailNotVerified       = apperr.Forbidden("email_not_verified", "Email address not verified")
+	ErrUserOnlyRoute          = apperr.Forbidden("user_only_route", "This route is not available to API credentials")
+	ErrMissingScope           = apperr.Forbidden("missing_scope", "Missing scope")
+)
+
 type TokenValidator interface {
 	ValidateToken(token string) (bool, error)
 }
 
 func GinJWTMiddleware(tokenValidator TokenValidator, logger *logrus.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			logger.Warn("Authorization header missing")
-			c.AbortWithStatusJSON(401, gin.H{"error": "Authorization header is required"})
-			return
-		}
-
-		fields := strings.Fields(authHeader)
-		if len(fields) != 2 || strings.ToLower(fields[0]) != "bearer" {
-			logger.Warn("Invalid authorization header format")
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid authorization header format"})
+		tokenStr, ok := bearerToken(c, logger)
+		if !ok {
 			return
 		}
 
-		tokenStr := fields[1]
-		valid, err := tokenValidator.ValidateToken(tokenStr)
-		if err != nil || !valid {
+		claims, err := userClaims(tokenValidator, tokenStr)
+		if err != nil {
 			logger.Warn("Invalid or expired token:", err)
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid or expired token"})
+			problem.Respond(c, ErrInvalidToken)
 			return
 		}
 
-		parsedToken, _ := jwt.ParseWithClaims(tokenStr, &jwt.StandardClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("SECRET_KEY")), nil
-		})
+		c.Set("userEmail", claims.Subject)
+		c.Set("tokenIssuedAt", claims.IssuedAt)
+		c.Set("sessionID", claims.Id)
+		c.Next()
+	}
+}
 
-		if claims, ok := parsedToken.Claims.(*jwt.StandardClaims); ok && parsedToken.Valid {
-			c.Set("userEmail", claims.Subject)
-		} else {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token claims"})
-			return
+// bearerToken reads the token from the Authorization header, answering 401
+// when it is missing or malformed.
+func bearerToken(c *gin.Context, logger *logrus.Logger) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		logger.Warn("Authorization header missing")
+		problem.Respond(c, ErrMissingAuthorization)
+		return "", false
+	}
+
+	fields := strings.Fields(authHeader)
+	if len(fields) != 2 || strings.ToLower(fields[0]) != "bearer" {
+		logger.Warn("Invalid authorization header format")
+		problem.Respond(c, ErrMalformedAuthorization)
+		return "", false
+	}
+	return fields[1], true
+}
+
+// userClaims validates a user JWT and returns its claims.
+func userClaims(tokenValidator TokenValidator, tokenStr string) (*jwt.StandardClaims, error) {
+	valid, err := tokenValidator.ValidateToken(tokenStr)
+	if err != nil || !valid {
+		if err == nil {
+			err = jwt.ErrSignatureInvalid
 		}
+		return nil, err
+	}
 
+	parsedToken, _ := jwt.ParseWithClaims(tokenStr, &jwt.StandardClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("SECRET_KEY")), nil
+	})
+	claims, ok := parsedToken.Claims.(*jwt.StandardClaims)
+	if !ok || !parsedToken.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// AllowQueryToken accepts the bearer token as ?access_token= for clients
+// that cannot set headers, such as a browser EventSource or WebSocket. It
+// must run before GinJWTMiddleware and only on the routes that need it,
+// since query strings end up in access logs.
+func AllowQueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/utils/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type UserLookup interface {
+	GetUserByEmail(email string) (*models.User, error)
+}
+
+// RequireRole only lets through users whose role is one of roles. It must
+// run after GinJWTMiddleware, which sets userEmail.
+func RequireRole(users UserLookup, logger *logrus.Logger, roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *gin.Context) {
+		emailVal, exists := c.Get("userEmail")
+		if !exists {
+			logger.Warn("User email not found in context")
+			problem.Respond(c, problem.ErrUnauthorized)
+			return
+		}
+
+		user, err := users.GetUserByEmail(emailVal.(string))
+		if err != nil {
+			logger.Warn("Role check failed, user not found:", err)
+			problem.Respond(c, problem.ErrUnauthorized)
+			return
+		}
+
+		if !allowed[user.Role] {
+			logger.Warn("Forbidden: role ", user.Role, " cannot access ", c.FullPath())
+			problem.Respond(c, ErrForbidden)
+			return
+		}
+
+		c.Set("userRole", user.Role)
+		c.Next()
+	}
+}
@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"pruebaVertice/Api/utils/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RejectRevokedSessions refuses tokens issued before the user's sessions were
+// revoked, e.g. by a password reset, and any credential of a disabled user.
+// It must run after GinJWTMiddleware.
+func RejectRevokedSessions(users UserLookup, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := users.GetUserByEmail(c.GetString("userEmail"))
+		if err != nil {
+			logger.Warn("Session check failed, user not found:", err)
+			problem.Respond(c, problem.ErrUnauthorized)
+			return
+		}
+
+		if user.DisabledAt != nil {
+			logger.Warn("Rejected request of disabled user ", user.Email)
+			problem.Respond(c, ErrAccountDisabled)
+			return
+		}
+
+		if user.SessionsRevokedAt != nil && c.GetInt64("tokenIssuedAt") < user.SessionsRevokedAt.Unix() {
+			logger.Warn("Rejected token issued before sessions were revoked for ", user.Email)
+			problem.Respond(c, ErrSessionRevoked)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SessionChecker confirms that the session a token belongs to is still
+// active and was opened by userID.
+type SessionChecker interface {
+	CheckSession(tokenID string, userID uint) error
+}
+
+// RejectEndedSessions refuses tokens whose session has been revoked or has
+// expired, or was opened by another account than the one the token's email
+// now belongs to. Tokens without a session, such as API keys, are left to
+// the other checks. It must run after GinJWTMiddleware or GinAuthMiddleware.
+func RejectEndedSessions(users UserLookup, sessions SessionChecker, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.GetString("sessionID")
+		if sessionID == "" {
+			c.Next()
+			return
+		}
+
+		user, err := users.GetUserByEmail(c.GetString("userEmail"))
+		if err != nil {
+			logger.Warn("Session check failed, user not found:", err)
+			problem.Respond(c, problem.ErrUnauthorized)
+			return
+		}
+
+		if err := sessions.CheckSession(sessionID, user.ID); err != nil {
+			logger.Warn("Rejected token of ended session for ", c.GetString("userEmail"), ": ", err)
+			problem.Respond(c, ErrSessionRevoked)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail blocks users that have not confirmed their email
+// address yet. It must run after GinJWTMiddleware.
+func RequireVerifiedEmail(users UserLookup, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := users.GetUserByEmail(c.GetString("userEmail"))
+		if err != nil {
+			logger.Warn("Email verification check failed, user not found:", err)
+			problem.Respond(c, problem.ErrUnauthorized)
+			return
+		}
+
+		if user.EmailVerifiedAt == nil {
+			logger.Warn("Blocked unverified user ", user.Email, " on ", c.FullPath())
+			problem.Respond(c, ErrEmailNotVerified)
+			return
+		}
+
+		c.Next()
+	}
+}
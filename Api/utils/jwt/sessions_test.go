@@ -0,0 +1,126 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pruebaVertice/Api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type userLookupFunc func(email string) (*models.User, error)
+
+func (f userLookupFunc) GetUserByEmail(email string) (*models.User, error) { return f(email) }
+
+func run(middleware gin.HandlerFunc, issuedAt int64) int {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/me", nil)
+	c.Set("userEmail", "ana@example.com")
+	c.Set("tokenIssuedAt", issuedAt)
+
+	middleware(c)
+	if c.IsAborted() {
+		return rec.Code
+	}
+	return http.StatusOK
+}
+
+func TestRejectRevokedSessions(t *testing.T) {
+	revokedAt := time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC)
+	revoked := userLookupFunc(func(email string) (*models.User, error) {
+		return &models.User{Email: email, SessionsRevokedAt: &revokedAt}, nil
+	})
+	neverRevoked := userLookupFunc(func(email string) (*models.User, error) {
+		return &models.User{Email: email}, nil
+	})
+	missing := userLookupFunc(func(email string) (*models.User, error) {
+		return nil, errors.New("not found")
+	})
+	disabled := userLookupFunc(func(email string) (*models.User, error) {
+		return &models.User{Email: email, DisabledAt: &revokedAt}, nil
+	})
+
+	logger := logrus.New()
+	assert.Equal(t, http.StatusForbidden, run(RejectRevokedSessions(disabled, logger), revokedAt.Add(time.Minute).Unix()))
+	assert.Equal(t, http.StatusUnauthorized, run(RejectRevokedSessions(revoked, logger), revokedAt.Add(-time.Minute).Unix()))
+	assert.Equal(t, http.StatusOK, run(RejectRevokedSessions(revoked, logger), revokedAt.Add(time.Minute).Unix()))
+	assert.Equal(t, http.StatusOK, run(RejectRevokedSessions(neverRevoked, logger), 0))
+	assert.Equal(t, http.StatusUnauthorized, run(RejectRevokedSessions(missing, logger), 0))
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	verified := userLookupFunc(func(email string) (*models.User, error) {
+		return &models.User{Email: email, EmailVerifiedAt: &verifiedAt}, nil
+	})
+	unverified := userLookupFunc(func(email string) (*models.User, error) {
+		return &models.User{Email: email}, nil
+	})
+
+	logger := logrus.New()
+	assert.Equal(t, http.StatusOK, run(RequireVerifiedEmail(verified, logger), 0))
+	assert.Equal(t, http.StatusForbidden, run(RequireVerifiedEmail(unverified, logger), 0))
+}
+
+type sessionCheckerFunc func(tokenID string, userID uint) error
+
+func (f sessionCheckerFunc) CheckSession(tokenID string, userID uint) error {
+	return f(tokenID, userID)
+}
+
+func TestRejectEndedSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// The session "active" was opened by user 1.
+	checker := sessionCheckerFunc(func(tokenID string, userID uint) error {
+		if tokenID == "revoked" || userID != 1 {
+			return errors.New("session revoked or expired")
+		}
+		return nil
+	})
+	owner := userLookupFunc(func(email string) (*models.User, error) {
+		return &models.User{Model: gorm.Model{ID: 1}, Email: email}, nil
+	})
+	middleware := RejectEndedSessions(owner, checker, logrus.New())
+
+	runWithSession := func(sessionID string) int {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/me", nil)
+		c.Set("userEmail", "ana@example.com")
+		if sessionID != "" {
+			c.Set("sessionID", sessionID)
+		}
+		middleware(c)
+		if c.IsAborted() {
+			return rec.Code
+		}
+		return http.StatusOK
+	}
+
+	assert.Equal(t, http.StatusOK, runWithSession("active"))
+	assert.Equal(t, http.StatusUnauthorized, runWithSession("revoked"))
+	// API keys and tokens from before sessions existed carry no session.
+	assert.Equal(t, http.StatusOK, runWithSession(""))
+
+	// The email now belongs to another account than the one that opened
+	// the session.
+	newOwner := userLookupFunc(func(email string) (*models.User, error) {
+		return &models.User{Model: gorm.Model{ID: 2}, Email: email}, nil
+	})
+	middleware = RejectEndedSessions(newOwner, checker, logrus.New())
+	assert.Equal(t, http.StatusUnauthorized, runWithSession("active"))
+
+	middleware = RejectEndedSessions(userLookupFunc(func(email string) (*models.User, error) {
+		return nil, errors.New("not found")
+	}), checker, logrus.New())
+	assert.Equal(t, http.StatusUnauthorized, runWithSession("active"))
+}
@@ -0,0 +1,215 @@
+// Package problem writes every error response as an RFC 7807
+// application/problem+json document.
+//
+// Respond is the single place where errors become HTTP statuses: typed
+// apperr errors keep their status, code and message, a bare
+// gorm.ErrRecordNotFound becomes a generic 404 and anything else is a 500
+// whose detail says nothing about the cause. Every problem carries the trace
+// ID of the request, which Middleware also logs next to server faults.
+//
+// Titles and details are written in the language negotiated by
+// i18n.Middleware; codes never change with the language.
+package problem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"pruebaVertice/Api/utils/apperr"
+	"pruebaVertice/Api/utils/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	ContentType = "application/problem+json"
+	// TraceHeader carries the trace ID in both directions: a caller may
+	// send its own and every response echoes the one in use.
+	TraceHeader = "X-Request-ID"
+
+	traceIDKey = "traceID"
+)
+
+// Stable codes for problems that do not come from a typed domain error.
+const (
+	CodeInternal   = "internal_error"
+	CodeNotFound   = "not_found"
+	CodeValidation = "validation_failed"
+)
+
+// ErrUnauthorized is raised when a request reaches a handler without a
+// usable identity.
+var ErrUnauthorized = apperr.Unauthorized("unauthorized", "Unauthorized")
+
+var errNoRoute = apperr.NotFound("route_not_found", "No route matches the request")
+
+var validTraceID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// FieldError describes one invalid field. Field is the JSON path of the
+// value, e.g. "order_items[1].quantity"; it is empty when the body as a
+// whole could not be read.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Problem is the body of every error response.
+type Problem struct {
+	Type     string       `json:"type" example:"about:blank"`
+	Title    string       `json:"title" example:"Not Found"`
+	Status   int          `json:"status" example:"404"`
+	Detail   string       `json:"detail,omitempty" example:"order not found"`
+	Instance string       `json:"instance,omitempty" example:"/api/auth/orders/7"`
+	Code     string       `json:"code" example:"order_not_found"`
+	TraceID  string       `json:"trace_id" example:"5f0c8a3e9b1d4c7a2e6f8b0d1c3a5e7f"`
+	Errors   []FieldError `json:"errors,omitempty"`
+
+	// cause is the error the problem was built from, kept to translate it.
+	cause error
+}
+
+var statuses = map[apperr.Kind]int{
+	apperr.KindInternal:     http.StatusInternalServerError,
+	apperr.KindBadRequest:   http.StatusBadRequest,
+	apperr.KindInvalid:      http.StatusUnprocessableEntity,
+	apperr.KindUnauthorized: http.StatusUnauthorized,
+	apperr.KindForbidden:    http.StatusForbidden,
+	apperr.KindNotFound:     http.StatusNotFound,
+	apperr.KindConflict:     http.StatusConflict,
+	apperr.KindRateLimited:  http.StatusTooManyRequests,
+}
+
+// Middleware assigns the trace ID of the request, turns panics into a 500
+// problem and renders any error a handler attached with c.Error without
+// writing a response itself. It must run before every other handler.
+func Middleware(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader(TraceHeader)
+		if !validTraceID.MatchString(traceID) {
+			traceID = newTraceID()
+		}
+		c.Set(traceIDKey, traceID)
+		c.Header(TraceHeader, traceID)
+
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("Layer: problem, Method: Middleware, TraceID: %s, Error: panic: %v", traceID, r)
+				if !c.Writer.Written() {
+					write(c, From(nil))
+				}
+				c.Abort()
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		last := c.Errors.Last().Err
+		p := From(last)
+		if p.Status >= http.StatusInternalServerError {
+			logger.Errorf("Layer: problem, Method: Middleware, TraceID: %s, Error: %v", traceID, last)
+		}
+		if !c.Writer.Written() {
+			write(c, p)
+		}
+	}
+}
+
+// TraceID returns the trace ID of the request, creating one when Middleware
+// did not run.
+func TraceID(c *gin.Context) string {
+	if traceID := c.GetString(traceIDKey); traceID != "" {
+		return traceID
+	}
+	traceID := newTraceID()
+	c.Set(traceIDKey, traceID)
+	return traceID
+}
+
+// From maps err to the problem it is answered with.
+func From(err error) Problem {
+	if appErr, ok := apperr.As(err); ok {
+		status, known := statuses[appErr.Kind]
+		if known && status < http.StatusInternalServerError {
+			return Problem{Status: status, Code: appErr.Code, Detail: err.Error(), cause: err}
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Problem{Status: http.StatusNotFound, Code: CodeNotFound, Detail: "The requested resource was not found."}
+	}
+	return Problem{Status: http.StatusInternalServerError, Code: CodeInternal, Detail: "An unexpected error occurred."}
+}
+
+// Respond records err on the context and writes its problem, aborting the
+// rest of the chain.
+func Respond(c *gin.Context, err error) {
+	_ = c.Error(err)
+	write(c, From(err))
+}
+
+// NoRoute answers requests for paths the router does not know.
+func NoRoute(c *gin.Context) {
+	Respond(c, errNoRoute)
+}
+
+// InvalidID is the 400 for a path parameter that is not a valid ID of the
+// named resource, which may be left empty.
+func InvalidID(resource string) *apperr.Error {
+	message := "Invalid ID"
+	if resource != "" {
+		message = "Invalid " + resource + " ID"
+	}
+	return apperr.BadRequest("invalid_id", message)
+}
+
+// Validation writes the 400 for a request whose fields failed validation.
+func Validation(c *gin.Context, fields []FieldError) {
+	write(c, Problem{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidation,
+		Detail: "The request has invalid fields.",
+		Errors: fields,
+	})
+}
+
+func write(c *gin.Context, p Problem) {
+	lang := i18n.Language(c)
+	p.Type = "about:blank"
+	p.Title = i18n.T(lang, http.StatusText(p.Status))
+	p.Detail = detail(lang, p)
+	if c.Request != nil {
+		p.Instance = c.Request.URL.Path
+	}
+	p.TraceID = TraceID(c)
+	c.Header("Content-Type", ContentType)
+	c.Header(TraceHeader, p.TraceID)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// detail translates the detail of p. Only the generic details and the
+// messages of typed errors are known to the catalogs; text that a caller
+// appended with fmt.Errorf("%w: ...") is kept as is.
+func detail(lang string, p Problem) string {
+	if format, args, ok := apperr.Template(p.cause); ok {
+		return i18n.T(lang, format, args...)
+	}
+	if appErr, ok := apperr.As(p.cause); ok && strings.HasPrefix(p.Detail, appErr.Message) {
+		return i18n.T(lang, appErr.Message) + strings.TrimPrefix(p.Detail, appErr.Message)
+	}
+	return i18n.T(lang, p.Detail)
+}
+
+func newTraceID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,182 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pruebaVertice/Api/utils/apperr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var errOrderNotFound = apperr.NotFound("order_not_found", "order not found")
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) Problem {
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	var p Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	return p
+}
+
+func TestFrom(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{"typed", errOrderNotFound, http.StatusNotFound, "order_not_found", "order not found"},
+		{"wrapped", fmt.Errorf("%w: id 7", errOrderNotFound), http.StatusNotFound, "order_not_found", "order not found: id 7"},
+		{"detailed", errOrderNotFound.Detailf("order %d not found", 7), http.StatusNotFound, "order_not_found", "order 7 not found"},
+		{"invalid", apperr.Invalid("invalid_price", "price must be greater than zero"), http.StatusUnprocessableEntity, "invalid_price", "price must be greater than zero"},
+		{"conflict", apperr.Conflict("insufficient_stock", "insufficient stock"), http.StatusConflict, "insufficient_stock", "insufficient stock"},
+		{"record not found", gorm.ErrRecordNotFound, http.StatusNotFound, CodeNotFound, "The requested resource was not found."},
+		{"untyped", errors.New("dial tcp 10.0.0.1:3306: connection refused"), http.StatusInternalServerError, CodeInternal, "An unexpected error occurred."},
+		{"typed internal", apperr.New(apperr.KindInternal, "secret", "smtp password rejected"), http.StatusInternalServerError, CodeInternal, "An unexpected error occurred."},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := From(tc.err)
+			assert.Equal(t, tc.status, p.Status)
+			assert.Equal(t, tc.code, p.Code)
+			assert.Equal(t, tc.detail, p.Detail)
+		})
+	}
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/auth/orders/7", nil)
+
+	Respond(c, errOrderNotFound)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.True(t, c.IsAborted())
+	p := decode(t, rec)
+	assert.Equal(t, Problem{
+		Type:     "about:blank",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "order not found",
+		Instance: "/api/auth/orders/7",
+		Code:     "order_not_found",
+		TraceID:  p.TraceID,
+	}, p)
+	assert.NotEmpty(t, p.TraceID)
+	assert.Equal(t, p.TraceID, rec.Header().Get(TraceHeader))
+}
+
+func TestRespond_Spanish(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name   string
+		err    error
+		title  string
+		detail string
+	}{
+		{"typed", errOrderNotFound, "No encontrado", "orden no encontrada"},
+		{"detailed", apperr.Conflict("insufficient_stock", "insufficient stock").Detailf("insufficient stock for product ID %d", 7), "Conflicto", "stock insuficiente para el producto con ID 7"},
+		{"wrapped", fmt.Errorf("%w: id 7", errOrderNotFound), "No encontrado", "orden no encontrada: id 7"},
+		{"record not found", gorm.ErrRecordNotFound, "No encontrado", "No se encontró el recurso solicitado."},
+		{"typed internal", apperr.New(apperr.KindInternal, "secret", "order not found"), "Error interno del servidor", "Ocurrió un error inesperado."},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/auth/orders/7", nil)
+			c.Request.Header.Set("Accept-Language", "es")
+
+			Respond(c, tc.err)
+
+			p := decode(t, rec)
+			assert.Equal(t, tc.title, p.Title)
+			assert.Equal(t, tc.detail, p.Detail)
+			assert.Equal(t, From(tc.err).Code, p.Code)
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/auth/orders", nil)
+
+	Validation(c, []FieldError{{Field: "order_items", Rule: "required", Message: "is required"}})
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	p := decode(t, rec)
+	assert.Equal(t, CodeValidation, p.Code)
+	assert.Equal(t, []FieldError{{Field: "order_items", Rule: "required", Message: "is required"}}, p.Errors)
+}
+
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(logrus.New()))
+	router.NoRoute(NoRoute)
+	router.GET("/typed", func(c *gin.Context) { Respond(c, errOrderNotFound) })
+	router.GET("/attached", func(c *gin.Context) { _ = c.Error(errors.New("pq: relation \"orders\" does not exist")) })
+	router.GET("/panic", func(c *gin.Context) { panic("nil map write in orders handler") })
+	router.GET("/ok", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"trace_id": TraceID(c)}) })
+	return router
+}
+
+func serve(router *gin.Engine, path, traceID string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	if traceID != "" {
+		req.Header.Set(TraceHeader, traceID)
+	}
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware_TraceID(t *testing.T) {
+	router := newRouter()
+
+	rec := serve(router, "/typed", "client-trace-1")
+	assert.Equal(t, "client-trace-1", rec.Header().Get(TraceHeader))
+	assert.Equal(t, "client-trace-1", decode(t, rec).TraceID)
+
+	rec = serve(router, "/ok", "not a valid\ttrace id")
+	generated := rec.Header().Get(TraceHeader)
+	assert.Len(t, generated, 32)
+	assert.Contains(t, rec.Body.String(), generated)
+}
+
+func TestMiddleware_RendersErrorsWithoutLeakingThem(t *testing.T) {
+	router := newRouter()
+
+	rec := serve(router, "/attached", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	p := decode(t, rec)
+	assert.Equal(t, CodeInternal, p.Code)
+	assert.NotContains(t, rec.Body.String(), "relation")
+
+	rec = serve(router, "/panic", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, CodeInternal, decode(t, rec).Code)
+	assert.NotContains(t, rec.Body.String(), "nil map")
+}
+
+func TestNoRoute(t *testing.T) {
+	rec := serve(newRouter(), "/missing", "")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	p := decode(t, rec)
+	assert.Equal(t, "route_not_found", p.Code)
+	assert.Equal(t, "/missing", p.Instance)
+}
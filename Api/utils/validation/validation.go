@@ -0,0 +1,255 @@
+// Package validation checks request payloads against their `binding` struct
+// tags and reports every failing field in one response.
+//
+// Importing the package registers the custom rules on gin's validator, so
+// they apply to ShouldBindJSON and ShouldBindQuery as well:
+//
+//	password         at least 8 characters with upper and lower case letters and a digit
+//	notblank         not empty once surrounding spaces are trimmed
+//	unique_products  order lines do not repeat a product_id
+//
+// Messages are written in English and translated by Respond into the
+// language of the request.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"pruebaVertice/Api/utils/i18n"
+	"pruebaVertice/Api/utils/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const MinPasswordLength = 8
+
+// FieldError describes one invalid field; the problem body lists them under
+// "errors".
+type FieldError = problem.FieldError
+
+// Error is a set of field errors found outside gin's binding, e.g. when
+// validating the values of a map.
+type Error struct {
+	Fields []FieldError
+
+	// entries keeps what Fields was built from, to report it in other
+	// languages.
+	entries []entry
+}
+
+type entry struct {
+	key string
+	err error
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = strings.TrimSpace(f.Field + " " + f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// Report fields by the name clients send, not the Go field name.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	_ = v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return StrongPassword(fl.Field().String())
+	})
+	_ = v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	_ = v.RegisterValidation("unique_products", uniqueProducts)
+}
+
+// StrongPassword reports whether password satisfies the "password" rule.
+func StrongPassword(password string) bool {
+	if len(password) < MinPasswordLength {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// uniqueProducts applies to a slice of structs with a ProductID field.
+func uniqueProducts(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.Slice {
+		return false
+	}
+	seen := make(map[uint64]bool, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		item := reflect.Indirect(field.Index(i))
+		id := item.FieldByName("ProductID")
+		if !id.IsValid() {
+			return false
+		}
+		if seen[id.Uint()] {
+			return false
+		}
+		seen[id.Uint()] = true
+	}
+	return true
+}
+
+// Map validates every value of m, reporting fields under their map key.
+// gin does not descend into maps when binding.
+func Map[T any](m map[string]T) error {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var entries []entry
+	for _, key := range keys {
+		value := m[key]
+		if err := binding.Validator.ValidateStruct(&value); err != nil {
+			entries = append(entries, entry{key: key, err: err})
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return &Error{Fields: mapFields(i18n.Default, entries), entries: entries}
+}
+
+func mapFields(lang string, entries []entry) []FieldError {
+	var fields []FieldError
+	for _, e := range entries {
+		for _, f := range localized(lang, e.err) {
+			f.Field = strings.TrimSuffix(e.key+"."+f.Field, ".")
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Fields converts a binding or validation error into field errors with
+// English messages.
+func Fields(err error) []FieldError {
+	return localized(i18n.Default, err)
+}
+
+func localized(lang string, err error) []FieldError {
+	if err == nil {
+		return nil
+	}
+
+	var own *Error
+	var invalid validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &own):
+		if own.entries == nil {
+			return own.Fields
+		}
+		return mapFields(lang, own.entries)
+	case errors.As(err, &invalid):
+		fields := make([]FieldError, len(invalid))
+		for i, fe := range invalid {
+			format, args := message(fe)
+			fields[i] = FieldError{Field: fieldPath(fe), Rule: fe.Tag(), Message: i18n.T(lang, format, args...)}
+		}
+		return fields
+	case errors.As(err, &typeErr):
+		return []FieldError{{Field: typeErr.Field, Rule: "type", Message: i18n.T(lang, "must be of type %s", typeErr.Type.String())}}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return []FieldError{{Rule: "json", Message: i18n.T(lang, "request body is not valid JSON")}}
+	case errors.Is(err, io.EOF):
+		return []FieldError{{Rule: "required", Message: i18n.T(lang, "request body is required")}}
+	default:
+		return []FieldError{{Rule: "invalid", Message: err.Error()}}
+	}
+}
+
+// Respond writes the 400 problem for an invalid request, in the language of
+// the request.
+func Respond(c *gin.Context, err error) {
+	problem.Validation(c, localized(i18n.Language(c), err))
+}
+
+// fieldPath drops the struct name from the namespace, which is all the
+// client cannot know about.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+// message returns the English format of the message for fe and its
+// arguments; the format is the key of its translations.
+func message(fe validator.FieldError) (string, []interface{}) {
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required", "required_with":
+		return "is required", nil
+	case "notblank":
+		return "must not be blank", nil
+	case "email":
+		return "must be a valid email address", nil
+	case "password":
+		return "must be at least %d characters and contain upper and lower case letters and a digit", []interface{}{MinPasswordLength}
+	case "unique_products":
+		return "must not contain the same product_id more than once", nil
+	case "min":
+		return "must be at least %s" + unit, []interface{}{fe.Param()}
+	case "max":
+		return "must be at most %s" + unit, []interface{}{fe.Param()}
+	case "len":
+		return "must be exactly %s" + unit, []interface{}{fe.Param()}
+	case "gt":
+		return "must be greater than %s", []interface{}{fe.Param()}
+	case "gte":
+		return "must be greater than or equal to %s", []interface{}{fe.Param()}
+	case "oneof":
+		return "must be one of: %s", []interface{}{strings.ReplaceAll(fe.Param(), " ", ", ")}
+	case "url":
+		return "must be a valid URL", nil
+	default:
+		return "failed the %s rule", []interface{}{fe.Tag()}
+	}
+}
@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pruebaVertice/Api/models"
+	"pruebaVertice/Api/utils/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bind(t *testing.T, body string, obj interface{}) []FieldError {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return Fields(c.ShouldBindJSON(obj))
+}
+
+func TestStrongPassword(t *testing.T) {
+	assert.True(t, StrongPassword("Clave-Segura1"))
+	for _, weak := range []string{"", "Ab1", "alllowercase1", "ALLUPPERCASE1", "NoDigitsHere"} {
+		assert.False(t, StrongPassword(weak), weak)
+	}
+}
+
+func TestFields_RegisterRequest(t *testing.T) {
+	var req models.RegisterRequest
+	fields := bind(t, `{"username":"  ","email":"not-an-email","password":"weak"}`, &req)
+
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "username", Rule: "notblank", Message: "must not be blank"},
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "password", Rule: "password", Message: "must be at least 8 characters and contain upper and lower case letters and a digit"},
+	}, fields)
+}
+
+func TestFields_CreateOrderRequest(t *testing.T) {
+	fields := bind(t, `{"order_items":[{"product_id":1,"quantity":1},{"product_id":1,"quantity":2}]}`, &models.CreateOrderRequest{})
+	assert.Equal(t, []FieldError{{Field: "order_items", Rule: "unique_products", Message: "must not contain the same product_id more than once"}}, fields)
+
+	fields = bind(t, `{"order_items":[{"product_id":1,"quantity":0},{"quantity":1}]}`, &models.CreateOrderRequest{})
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "order_items[0].quantity", Rule: "gt", Message: "must be greater than 0"},
+		{Field: "order_items[1].product_id", Rule: "required", Message: "is required"},
+	}, fields)
+
+	fields = bind(t, `{"order_items":[]}`, &models.CreateOrderRequest{})
+	assert.Equal(t, []FieldError{{Field: "order_items", Rule: "min", Message: "must be at least 1 items"}}, fields)
+
+	assert.Empty(t, bind(t, `{"order_items":[{"product_id":1,"quantity":1},{"product_id":2,"quantity":3}]}`, &models.CreateOrderRequest{}))
+}
+
+func TestFields_MalformedBody(t *testing.T) {
+	var req models.CreateOrderRequest
+
+	assert.Equal(t, []FieldError{{Rule: "json", Message: "request body is not valid JSON"}}, bind(t, `{"order_items":`, &req))
+	assert.Equal(t, []FieldError{{Rule: "required", Message: "request body is required"}}, bind(t, ``, &req))
+
+	fields := bind(t, `{"order_items":[{"product_id":"one","quantity":1}]}`, &req)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "type", fields[0].Rule)
+	assert.Equal(t, "order_items.0.product_id", fields[0].Field)
+}
+
+func TestMap_PrefixesFieldsWithKey(t *testing.T) {
+	products := map[string]models.Product{
+		"keyboard": {Name: "Keyboard", Price: 10, Stock: 5},
+		"mouse":    {Name: " ", Price: -1, Stock: -2},
+	}
+
+	err := Map(products)
+	require.Error(t, err)
+	assert.Equal(t, []FieldError{
+		{Field: "mouse.name", Rule: "notblank", Message: "must not be blank"},
+		{Field: "mouse.price", Rule: "gt", Message: "must be greater than 0"},
+		{Field: "mouse.stock", Rule: "gte", Message: "must be greater than or equal to 0"},
+	}, Fields(err))
+
+	assert.NoError(t, Map(map[string]models.Product{"keyboard": products["keyboard"]}))
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	var req models.LoginRequest
+
+	Respond(c, binding.Validator.ValidateStruct(&req))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var resp problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, problem.CodeValidation, resp.Code)
+	assert.Len(t, resp.Errors, 2)
+}
+
+func TestRespond_Spanish(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/auth/products/", nil)
+	c.Request.Header.Set("Accept-Language", "es-AR,es;q=0.9,en;q=0.5")
+	err := Map(map[string]models.Product{
+		"mouse": {Name: " ", Price: 1, Translations: []models.ProductTranslation{{Language: "fr", Name: "Souris"}}},
+	})
+
+	Respond(c, err)
+
+	var resp problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "La solicitud tiene campos inválidos.", resp.Detail)
+	assert.Equal(t, []FieldError{
+		{Field: "mouse.name", Rule: "notblank", Message: "no debe estar vacío"},
+		{Field: "mouse.translations[0].language", Rule: "oneof", Message: "debe ser uno de: en, es"},
+	}, resp.Errors)
+	// The error itself stays in English for the logs.
+	assert.Equal(t, "mouse.name must not be blank; mouse.translations[0].language must be one of: en, es", err.Error())
+}